@@ -0,0 +1,91 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+// exportBlocksAction is the action for the "export-blocks" subcommand, it writes
+// the blocks of the best chain to the output file or to the standard output.
+func exportBlocksAction(ctx *cli.Context) (err error) {
+	outputPath := ctx.String(BlocksOutputFlag.Name)
+	if outputPath == "" {
+		// set logger to critical, so the standard output only contains blocks
+		if err = ctx.Set(LogFlag.Name, "crit"); err != nil {
+			return err
+		}
+	}
+
+	if _, err = setupLogger(ctx); err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(filepath.Clean(outputPath))
+		if err != nil {
+			return fmt.Errorf("cannot create output file: %w", err)
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("cannot close output file: %w", closeErr)
+			}
+		}()
+		w = file
+	}
+
+	from := ctx.Uint(FromBlockFlag.Name)
+	to := ctx.Uint(ToBlockFlag.Name)
+	return dot.ExportBlocks(cfg, from, to, w)
+}
+
+// importBlocksAction is the action for the "import-blocks" subcommand, it imports
+// the blocks from the input file or from the standard input.
+func importBlocksAction(ctx *cli.Context) error {
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	var r io.Reader = os.Stdin
+	if inputPath := ctx.String(BlocksInputFlag.Name); inputPath != "" {
+		file, err := os.Open(filepath.Clean(inputPath))
+		if err != nil {
+			return fmt.Errorf("cannot open input file: %w", err)
+		}
+		defer file.Close() //nolint:errcheck
+		r = file
+	}
+
+	return dot.ImportBlocks(cfg, r, ctx.Bool(SkipExecutionFlag.Name))
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+// checkBlocksAction is the action for the "check-blocks" subcommand, it re-executes
+// the blocks of the best chain and writes the state diff of the first block whose
+// state root does not match to the standard output.
+func checkBlocksAction(ctx *cli.Context) error {
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	from := ctx.Uint(CheckFromBlockFlag.Name)
+	to := ctx.Uint(CheckToBlockFlag.Name)
+	return dot.CheckBlocks(cfg, from, to, os.Stdout)
+}
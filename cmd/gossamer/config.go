@@ -22,6 +22,7 @@
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/runtime/life"
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/urfave/cli"
 )
@@ -138,9 +139,9 @@ func createDotConfig(ctx *cli.Context) (*dot.Config, error) {
 	setDotNetworkConfig(ctx, tomlCfg.Network, &cfg.Network)
 	setDotRPCConfig(ctx, tomlCfg.RPC, &cfg.RPC)
 	setDotPprofConfig(ctx, tomlCfg.Pprof, &cfg.Pprof)
-
-	if rewind := ctx.GlobalUint(RewindFlag.Name); rewind != 0 {
-		cfg.State.Rewind = rewind
+	if err := setDotStateConfig(ctx, tomlCfg.State, &cfg.State); err != nil {
+		logger.Errorf("failed to set state configuration: %s", err)
+		return nil, err
 	}
 
 	// set system info
@@ -214,6 +215,38 @@ func createImportStateConfig(ctx *cli.Context) (*dot.Config, error) {
 	return cfg, nil
 }
 
+// createBlocksConfig creates the configuration required to export and import blocks
+func createBlocksConfig(ctx *cli.Context) (*dot.Config, error) {
+	tomlCfg, cfg, err := setupConfigFromChain(ctx)
+	if err != nil {
+		logger.Errorf("failed to set chain configuration: %s", err)
+		return nil, err
+	}
+
+	// set log config
+	err = setLogConfig(ctx, tomlCfg, &cfg.Global, &cfg.Log)
+	if err != nil {
+		logger.Errorf("failed to set log configuration: %s", err)
+		return nil, err
+	}
+
+	// set global configuration values
+	if err := setDotGlobalConfig(ctx, tomlCfg, &cfg.Global); err != nil {
+		logger.Errorf("failed to set global node configuration: %s", err)
+		return nil, err
+	}
+
+	// set core config since the runtime is needed to execute imported blocks
+	setDotCoreConfig(ctx, tomlCfg.Core, &cfg.Core)
+
+	if err := setDotStateConfig(ctx, tomlCfg.State, &cfg.State); err != nil {
+		logger.Errorf("failed to set state configuration: %s", err)
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
 func createBuildSpecConfig(ctx *cli.Context) (*dot.Config, error) {
 	var tomlCfg *ctoml.Config
 	cfg := &dot.Config{}
@@ -406,6 +439,16 @@ func setLogConfig(flagsKVStore stringKVStore, tomlConfig *ctoml.Config,
 		*levelData.levelPtr = level
 	}
 
+	logCfg.RuntimeTargets = tomlConfig.Log.RuntimeTargets
+	if runtimeTargets := flagsKVStore.String(LogRuntimeTargetsFlag.Name); runtimeTargets != "" {
+		logCfg.RuntimeTargets = runtimeTargets
+	}
+
+	_, err = log.ParseDirectives(logCfg.RuntimeTargets)
+	if err != nil {
+		return fmt.Errorf("cannot parse runtime log targets: %w", err)
+	}
+
 	logger.Debugf("set log configuration: --log %s global %s", flagsKVStore.String(LogFlag.Name), globalCfg.LogLvl)
 	return nil
 }
@@ -490,6 +533,8 @@ func setDotGlobalConfigFromFlags(ctx *cli.Context, cfg *dot.GlobalConfig) error
 		cfg.MetricsAddress = metricsAddress
 	}
 
+	cfg.RuntimeProfiling = ctx.Bool(RuntimeProfilingFlag.Name)
+
 	cfg.RetainBlocks = ctx.Int64(RetainBlockNumberFlag.Name)
 	cfg.Pruning = pruner.Mode(ctx.String(PruningFlag.Name))
 	cfg.NoTelemetry = ctx.Bool("no-telemetry")
@@ -580,6 +625,7 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 	cfg.BabeAuthority = tomlCfg.Roles == types.AuthorityRole
 	cfg.GrandpaAuthority = tomlCfg.Roles == types.AuthorityRole
 	cfg.GrandpaInterval = time.Second * time.Duration(tomlCfg.GrandpaInterval)
+	cfg.TimestampDrift = time.Second * time.Duration(tomlCfg.TimestampDrift)
 
 	cfg.BABELead = tomlCfg.BABELead
 	if ctx.IsSet(BABELeadFlag.Name) {
@@ -622,11 +668,18 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 		cfg.GrandpaAuthority = false
 	}
 
-	switch tomlCfg.WasmInterpreter {
+	wasmInterpreter := tomlCfg.WasmInterpreter
+	if interpreter := ctx.GlobalString(WasmInterpreterFlag.Name); interpreter != "" {
+		wasmInterpreter = interpreter
+	}
+
+	switch wasmInterpreter {
 	case wasmer.Name:
 		cfg.WasmInterpreter = wasmer.Name
 	case life.Name:
 		cfg.WasmInterpreter = life.Name
+	case wazero.Name:
+		cfg.WasmInterpreter = wazero.Name
 	case "":
 		cfg.WasmInterpreter = gssmr.DefaultWasmInterpreter
 	default:
@@ -635,8 +688,9 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 	}
 
 	logger.Debugf(
-		"core configuration: babe-authority=%t, grandpa-authority=%t wasm-interpreter=%s grandpa-interval=%s",
-		cfg.BabeAuthority, cfg.GrandpaAuthority, cfg.WasmInterpreter, cfg.GrandpaInterval)
+		"core configuration: babe-authority=%t, grandpa-authority=%t wasm-interpreter=%s grandpa-interval=%s "+
+			"timestamp-drift=%s",
+		cfg.BabeAuthority, cfg.GrandpaAuthority, cfg.WasmInterpreter, cfg.GrandpaInterval, cfg.TimestampDrift)
 }
 
 // setDotNetworkConfig sets dot.NetworkConfig using flag values from the cli context
@@ -719,6 +773,13 @@ func setDotRPCConfig(ctx *cli.Context, tomlCfg ctoml.RPCConfig, cfg *dot.RPCConf
 	cfg.WSExternal = tomlCfg.WSExternal
 	cfg.WSUnsafe = tomlCfg.WSUnsafe
 	cfg.WSUnsafeExternal = tomlCfg.WSUnsafeExternal
+	cfg.RateLimit = tomlCfg.RateLimit
+	cfg.RateLimitBurst = tomlCfg.RateLimitBurst
+	cfg.MethodCosts = tomlCfg.MethodCosts
+	cfg.AllowedMethods = tomlCfg.AllowedMethods
+	cfg.DeniedMethods = tomlCfg.DeniedMethods
+	cfg.WSAllowedMethods = tomlCfg.WSAllowedMethods
+	cfg.WSDeniedMethods = tomlCfg.WSDeniedMethods
 
 	// check --rpc flag and update node configuration
 	if enabled := ctx.GlobalBool(RPCEnabledFlag.Name); enabled || cfg.Enabled {
@@ -915,3 +976,40 @@ func setDotPprofConfig(ctx *cli.Context, tomlCfg ctoml.PprofConfig, cfg *dot.Ppr
 
 	logger.Debug("pprof configuration: " + cfg.String())
 }
+
+// setDotStateConfig sets dot.StateConfig using flag values from the cli context
+func setDotStateConfig(ctx *cli.Context, tomlCfg ctoml.StateConfig, cfg *dot.StateConfig) (err error) {
+	if tomlCfg.CacheSize != 0 {
+		cfg.CacheSize = tomlCfg.CacheSize
+	}
+
+	if cacheSize := ctx.Uint64(StateCacheSizeFlag.Name); cacheSize != 0 {
+		cfg.CacheSize = cacheSize
+	}
+
+	blocksPruning := tomlCfg.BlocksPruning
+	if flagValue := ctx.String(BlocksPruningFlag.Name); flagValue != "" {
+		blocksPruning = flagValue
+	}
+
+	if blocksPruning != "" {
+		cfg.BlocksPruning, err = pruner.ParseBlocksConfig(blocksPruning)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", BlocksPruningFlag.Name, err)
+		}
+	}
+
+	if tomlCfg.RuntimePoolSize != 0 {
+		cfg.RuntimePoolSize = tomlCfg.RuntimePoolSize
+	}
+
+	if poolSize := ctx.Uint(RuntimePoolSizeFlag.Name); poolSize != 0 {
+		cfg.RuntimePoolSize = poolSize
+	}
+
+	if rewind := ctx.GlobalUint(RewindFlag.Name); rewind != 0 {
+		cfg.Rewind = rewind
+	}
+
+	return nil
+}
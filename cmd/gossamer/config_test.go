@@ -18,6 +18,7 @@
 	"github.com/ChainSafe/gossamer/dot"
 	ctoml "github.com/ChainSafe/gossamer/dot/config/toml"
 	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/genesis"
@@ -208,6 +209,20 @@ func TestGlobalConfigFromFlags(t *testing.T) {
 				MetricsAddress: testCfg.Global.MetricsAddress,
 			},
 		},
+		{
+			"Test gossamer --runtime-profiling",
+			[]string{"config", "runtime-profiling", "name"},
+			[]interface{}{testCfgFile.Name(), true, testCfg.Global.Name},
+			dot.GlobalConfig{
+				Name:             testCfg.Global.Name,
+				ID:               testCfg.Global.ID,
+				BasePath:         testCfg.Global.BasePath,
+				LogLvl:           log.Info,
+				PublishMetrics:   testCfg.Global.PublishMetrics,
+				MetricsAddress:   testCfg.Global.MetricsAddress,
+				RuntimeProfiling: true,
+			},
+		},
 		{
 			"Test gossamer --metrics-address",
 			[]string{"config", "metrics-address", "name"},
@@ -423,6 +438,62 @@ func TestCoreConfigFromFlags(t *testing.T) {
 	}
 }
 
+// TestStateConfigFromFlags tests createDotConfig using relevant state flags
+func TestStateConfigFromFlags(t *testing.T) {
+	testCfg, testCfgFile := newTestConfigWithFile(t)
+	require.NotNil(t, testCfg)
+	require.NotNil(t, testCfgFile)
+
+	testApp := cli.NewApp()
+	testApp.Writer = io.Discard
+
+	testcases := []struct {
+		description string
+		flags       []string
+		values      []interface{}
+		expected    dot.StateConfig
+	}{
+		{
+			"Test gossamer --state-cache-size",
+			[]string{"config", "state-cache-size"},
+			[]interface{}{testCfgFile.Name(), uint(1024)},
+			dot.StateConfig{
+				CacheSize: 1024,
+			},
+		},
+		{
+			"Test gossamer --blocks-pruning",
+			[]string{"config", "blocks-pruning"},
+			[]interface{}{testCfgFile.Name(), "256"},
+			dot.StateConfig{
+				BlocksPruning: pruner.BlocksConfig{
+					Mode:           pruner.BlocksKeepFinalised,
+					RetainedBlocks: 256,
+				},
+			},
+		},
+		{
+			"Test gossamer --rewind",
+			[]string{"config", "rewind"},
+			[]interface{}{testCfgFile.Name(), uint(10)},
+			dot.StateConfig{
+				Rewind: 10,
+			},
+		},
+	}
+
+	for _, c := range testcases {
+		c := c // bypass scopelint false positive
+		t.Run(c.description, func(t *testing.T) {
+			ctx, err := newTestContext(c.description, c.flags, c.values)
+			require.NoError(t, err)
+			cfg, err := createDotConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, cfg.State)
+		})
+	}
+}
+
 // TestNetworkConfigFromFlags tests createDotNetworkConfig using relevant network flags
 func TestNetworkConfigFromFlags(t *testing.T) {
 	testCfg, testCfgFile := newTestConfigWithFile(t)
@@ -1315,6 +1386,66 @@ func Test_setLogConfig(t *testing.T) {
 				FinalityGadgetLvl: log.Info,
 			},
 		},
+		"runtime targets": {
+			ctx: newMockGetStringer(map[string]string{
+				LogRuntimeTargetsFlag.Name: "runtime::system=dbug",
+			}),
+			initialCfg: ctoml.Config{
+				Log: ctoml.LogConfig{
+					RuntimeTargets: "pallet_babe=trce",
+				},
+			},
+			expectedCfg: ctoml.Config{
+				Global: ctoml.GlobalConfig{
+					LogLvl: log.Info.String(),
+				},
+				Log: ctoml.LogConfig{
+					RuntimeTargets: "pallet_babe=trce",
+				},
+			},
+			expectedGlobalCfg: dot.GlobalConfig{
+				LogLvl: log.Info,
+			},
+			expectedLogCfg: dot.LogConfig{
+				CoreLvl:           log.Info,
+				DigestLvl:         log.Info,
+				SyncLvl:           log.Info,
+				NetworkLvl:        log.Info,
+				RPCLvl:            log.Info,
+				StateLvl:          log.Info,
+				RuntimeLvl:        log.Info,
+				BlockProducerLvl:  log.Info,
+				FinalityGadgetLvl: log.Info,
+				RuntimeTargets:    "runtime::system=dbug",
+			},
+		},
+		"malformed runtime targets": {
+			ctx: newMockGetStringer(map[string]string{
+				LogRuntimeTargetsFlag.Name: "runtime::system=dbug=trce",
+			}),
+			expectedCfg: ctoml.Config{
+				Global: ctoml.GlobalConfig{
+					LogLvl: log.Info.String(),
+				},
+			},
+			expectedGlobalCfg: dot.GlobalConfig{
+				LogLvl: log.Info,
+			},
+			expectedLogCfg: dot.LogConfig{
+				CoreLvl:           log.Info,
+				DigestLvl:         log.Info,
+				SyncLvl:           log.Info,
+				NetworkLvl:        log.Info,
+				RPCLvl:            log.Info,
+				StateLvl:          log.Info,
+				RuntimeLvl:        log.Info,
+				BlockProducerLvl:  log.Info,
+				FinalityGadgetLvl: log.Info,
+				RuntimeTargets:    "runtime::system=dbug=trce",
+			},
+			err: errors.New("cannot parse runtime log targets: " +
+				"log directive is malformed: runtime::system=dbug=trce"),
+		},
 	}
 
 	for name, testCase := range testCases {
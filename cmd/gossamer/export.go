@@ -85,6 +85,15 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		RuntimeLvl:        dcfg.Log.RuntimeLvl.String(),
 		BlockProducerLvl:  dcfg.Log.BlockProducerLvl.String(),
 		FinalityGadgetLvl: dcfg.Log.FinalityGadgetLvl.String(),
+		RuntimeTargets:    dcfg.Log.RuntimeTargets,
+	}
+
+	cfg.State = ctoml.StateConfig{
+		CacheSize:       dcfg.State.CacheSize,
+		RuntimePoolSize: dcfg.State.RuntimePoolSize,
+	}
+	if dcfg.State.BlocksPruning.Mode != "" {
+		cfg.State.BlocksPruning = dcfg.State.BlocksPruning.String()
 	}
 
 	cfg.Init = ctoml.InitConfig{
@@ -101,6 +110,7 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		BabeAuthority:    dcfg.Core.BabeAuthority,
 		GrandpaAuthority: dcfg.Core.GrandpaAuthority,
 		GrandpaInterval:  uint32(dcfg.Core.GrandpaInterval / time.Second),
+		TimestampDrift:   uint32(dcfg.Core.TimestampDrift / time.Second),
 	}
 
 	cfg.Network = ctoml.NetworkConfig{
@@ -123,6 +133,14 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		WSPort:     dcfg.RPC.WSPort,
 		WS:         dcfg.RPC.WS,
 		WSExternal: dcfg.RPC.WSExternal,
+
+		RateLimit:        dcfg.RPC.RateLimit,
+		RateLimitBurst:   dcfg.RPC.RateLimitBurst,
+		MethodCosts:      dcfg.RPC.MethodCosts,
+		AllowedMethods:   dcfg.RPC.AllowedMethods,
+		DeniedMethods:    dcfg.RPC.DeniedMethods,
+		WSAllowedMethods: dcfg.RPC.WSAllowedMethods,
+		WSDeniedMethods:  dcfg.RPC.WSDeniedMethods,
 	}
 
 	return cfg
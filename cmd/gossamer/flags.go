@@ -74,6 +74,11 @@
 		Name:  "log-runtime",
 		Usage: "Runtime package log level. Supports levels crit (silent), eror, warn, info, dbug and trce (trace)",
 	}
+	LogRuntimeTargetsFlag = cli.StringFlag{
+		Name: "log-runtime-targets",
+		Usage: "Comma separated log levels of the runtime log targets, such as runtime::system=dbug,pallet_babe=trce. " +
+			"The runtime only emits the log messages up to the most verbose level configured",
+	}
 	LogBabeLevelFlag = cli.StringFlag{
 		Name:  "log-babe",
 		Usage: "BABE package log level. Supports levels crit (silent), eror, warn, info, dbug and trce (trace)",
@@ -134,6 +139,12 @@
 		Usage: "Set the metric server listening address",
 	}
 
+	// RuntimeProfilingFlag profiles the host function calls of the runtime.
+	RuntimeProfilingFlag = cli.BoolFlag{
+		Name:  "runtime-profiling",
+		Usage: "Profile the host function calls of the runtime and publish them with the node metrics",
+	}
+
 	// NoTelemetryFlag stops publishing telemetry to default defined in genesis.json
 	NoTelemetryFlag = cli.BoolFlag{
 		Name:  "no-telemetry",
@@ -179,6 +190,113 @@
 	}
 )
 
+// ExportBlocks and ImportBlocks flags
+var (
+	// FromBlockFlag is the number of the first block to export
+	FromBlockFlag = cli.UintFlag{
+		Name:  "from",
+		Usage: "Number of the first block to export",
+		Value: 1,
+	}
+	// ToBlockFlag is the number of the last block to export
+	ToBlockFlag = cli.UintFlag{
+		Name:  "to",
+		Usage: "Number of the last block to export, defaults to the best block",
+	}
+	// BlocksOutputFlag is the path of the file to export the blocks to
+	BlocksOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of the file to export the blocks to, defaults to the standard output",
+	}
+	// BlocksInputFlag is the path of the file to import the blocks from
+	BlocksInputFlag = cli.StringFlag{
+		Name:  "input",
+		Usage: "Path of the file to import the blocks from, defaults to the standard input",
+	}
+	// SkipExecutionFlag stores the imported blocks without executing them
+	SkipExecutionFlag = cli.BoolFlag{
+		Name:  "skip-execution",
+		Usage: "Store the imported blocks without executing them, only for blocks from trusted sources",
+	}
+)
+
+// Snapshot flags
+var (
+	// SnapshotBlockFlag is the number of the block to export the state of
+	SnapshotBlockFlag = cli.UintFlag{
+		Name:  "block",
+		Usage: "Number of the block to export the state of, defaults to the highest finalised block",
+	}
+	// SnapshotOutputFlag is the path of the file to export the state snapshot to
+	SnapshotOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of the file to export the state snapshot to, defaults to the standard output",
+	}
+	// SnapshotInputFlag is the path of the file to import the state snapshot from
+	SnapshotInputFlag = cli.StringFlag{
+		Name:  "input",
+		Usage: "Path of the file to import the state snapshot from, defaults to the standard input",
+	}
+)
+
+// Revert flags
+var (
+	// ForceRevertFlag allows to revert finalised blocks
+	ForceRevertFlag = cli.BoolFlag{
+		Name:  "force",
+		Usage: "Revert finalised blocks if needed",
+	}
+)
+
+// StateDiff flags
+var (
+	// StateDiffFromFlag is the block whose state is compared
+	StateDiffFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Number or hex encoded hash of the block whose state is compared",
+	}
+	// StateDiffToFlag is the block whose state is compared to
+	StateDiffToFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Number or hex encoded hash of the block whose state is compared to, defaults to the best block",
+	}
+)
+
+// CheckBlocks flags
+var (
+	// CheckFromBlockFlag is the number of the first block to check
+	CheckFromBlockFlag = cli.UintFlag{
+		Name:  "from",
+		Usage: "Number of the first block to check",
+		Value: 1,
+	}
+	// CheckToBlockFlag is the number of the last block to check
+	CheckToBlockFlag = cli.UintFlag{
+		Name:  "to",
+		Usage: "Number of the last block to check, defaults to the best block",
+	}
+)
+
+// TryRuntime flags
+var (
+	// TryRuntimeWasmFlag is the path of the runtime code to try
+	TryRuntimeWasmFlag = cli.StringFlag{
+		Name:  "wasm",
+		Usage: "Path of the runtime wasm code to try",
+	}
+	// TryRuntimeAtFlag is the block on top of which the runtime upgrade is tried
+	TryRuntimeAtFlag = cli.StringFlag{
+		Name:  "at",
+		Usage: "Number or hex encoded hash of the block on top of which the upgrade is tried, defaults to the best block",
+	}
+	// TryRuntimeBlocksFlag is the number of blocks executed if the runtime does not export the TryRuntime API
+	TryRuntimeBlocksFlag = cli.UintFlag{
+		Name:  "blocks",
+		Usage: "Number of the blocks following the block executed on the new runtime if it does not export TryRuntime",
+		Value: 1,
+	}
+)
+
 // BuildSpec-only flags
 var (
 	RawFlag = cli.BoolFlag{
@@ -373,6 +491,32 @@
 		Usage: `State trie online pruning ("full", "archive")`,
 		Value: dev.DefaultPruningMode,
 	}
+
+	// StateCacheSizeFlag sets the maximum size of the state trie node cache.
+	StateCacheSizeFlag = cli.Uint64Flag{
+		Name:  "state-cache-size",
+		Usage: "Maximum size of the state trie node cache in bytes",
+	}
+
+	// BlocksPruningFlag sets the pruning mode of the finalised blocks.
+	BlocksPruningFlag = cli.StringFlag{
+		Name: "blocks-pruning",
+		Usage: `Finalised blocks pruning ("archive", "archive-canonical" or the number of ` +
+			`last finalised blocks to keep the bodies of). Defaults to "archive-canonical", ` +
+			`keeping all finalised blocks and deleting the blocks of the pruned forks`,
+	}
+
+	// RuntimePoolSizeFlag sets the maximum number of instances of each runtime pool.
+	RuntimePoolSizeFlag = cli.UintFlag{
+		Name:  "runtime-pool-size",
+		Usage: "Maximum number of instances of each runtime, to execute concurrent runtime calls",
+	}
+
+	// WasmInterpreterFlag sets the interpreter executing the runtime.
+	WasmInterpreterFlag = cli.StringFlag{
+		Name:  "wasm-interpreter",
+		Usage: `Interpreter executing the runtime ("wasmer", "wazero" or "life")`,
+	}
 )
 
 // BABE flags
@@ -395,6 +539,7 @@
 		LogRPCLevelFlag,
 		LogStateLevelFlag,
 		LogRuntimeLevelFlag,
+		LogRuntimeTargetsFlag,
 		LogBabeLevelFlag,
 		LogGrandpaLevelFlag,
 		NameFlag,
@@ -408,6 +553,7 @@
 		RewindFlag,
 		DBPathFlag,
 		BloomFilterSizeFlag,
+		WasmInterpreterFlag,
 	}
 
 	// StartupFlags are flags that are valid for use with the root command and the export subcommand
@@ -443,6 +589,7 @@
 		// metrics flag
 		PublishMetricsFlag,
 		MetricsAddressFlag,
+		RuntimeProfilingFlag,
 
 		// telemetry flags
 		NoTelemetryFlag,
@@ -450,6 +597,11 @@
 
 		// BABE flags
 		BABELeadFlag,
+
+		// state flags
+		StateCacheSizeFlag,
+		BlocksPruningFlag,
+		RuntimePoolSizeFlag,
 	}
 )
 
@@ -499,6 +651,56 @@
 		FirstSlotFlag,
 	}
 
+	// ExportBlocksFlags are the flags that are valid for use with the export-blocks subcommand
+	ExportBlocksFlags = append([]cli.Flag{
+		FromBlockFlag,
+		ToBlockFlag,
+		BlocksOutputFlag,
+	}, GlobalFlags...)
+
+	// ImportBlocksFlags are the flags that are valid for use with the import-blocks subcommand
+	ImportBlocksFlags = append([]cli.Flag{
+		BlocksInputFlag,
+		SkipExecutionFlag,
+		BlocksPruningFlag,
+		StateCacheSizeFlag,
+	}, GlobalFlags...)
+
+	// ExportSnapshotFlags are the flags that are valid for use with the snapshot export subcommand
+	ExportSnapshotFlags = append([]cli.Flag{
+		SnapshotBlockFlag,
+		SnapshotOutputFlag,
+	}, GlobalFlags...)
+
+	// ImportSnapshotFlags are the flags that are valid for use with the snapshot import subcommand
+	ImportSnapshotFlags = append([]cli.Flag{
+		SnapshotInputFlag,
+	}, GlobalFlags...)
+
+	// CheckBlocksFlags are the flags that are valid for use with the check-blocks subcommand
+	CheckBlocksFlags = append([]cli.Flag{
+		CheckFromBlockFlag,
+		CheckToBlockFlag,
+	}, GlobalFlags...)
+
+	// TryRuntimeFlags are the flags that are valid for use with the try-runtime subcommand
+	TryRuntimeFlags = append([]cli.Flag{
+		TryRuntimeWasmFlag,
+		TryRuntimeAtFlag,
+		TryRuntimeBlocksFlag,
+	}, GlobalFlags...)
+
+	// StateDiffFlags are the flags that are valid for use with the state-diff subcommand
+	StateDiffFlags = append([]cli.Flag{
+		StateDiffFromFlag,
+		StateDiffToFlag,
+	}, GlobalFlags...)
+
+	// RevertFlags are the flags that are valid for use with the revert subcommand
+	RevertFlags = append([]cli.Flag{
+		ForceRevertFlag,
+	}, GlobalFlags...)
+
 	PruningFlags = []cli.Flag{
 		ChainFlag,
 		ConfigFlag,
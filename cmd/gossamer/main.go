@@ -7,6 +7,7 @@
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ChainSafe/gossamer/dot"
 	"github.com/ChainSafe/gossamer/dot/state"
@@ -24,6 +25,16 @@
 	importRuntimeCommandName = "import-runtime"
 	importStateCommandName   = "import-state"
 	pruningStateCommandName  = "prune-state"
+	exportBlocksCommandName  = "export-blocks"
+	importBlocksCommandName  = "import-blocks"
+
+	revertCommandName         = "revert"
+	checkBlocksCommandName    = "check-blocks"
+	stateDiffCommandName      = "state-diff"
+	tryRuntimeCommandName     = "try-runtime"
+	snapshotCommandName       = "snapshot"
+	exportSnapshotCommandName = "export"
+	importSnapshotCommandName = "import"
 )
 
 // app is the cli application
@@ -110,6 +121,116 @@
 			"\tUsage: gossamer import-state --state state.json --header header.json --first-slot <first slot of network>\n",
 	}
 
+	exportBlocksCommand = cli.Command{
+		Action:    FixFlagOrder(exportBlocksAction),
+		Name:      exportBlocksCommandName,
+		Usage:     "Export the blocks of the best chain to a binary file",
+		ArgsUsage: "",
+		Flags:     ExportBlocksFlags,
+		Category:  "EXPORT-BLOCKS",
+		Description: "The export-blocks command writes the SCALE encoded blocks of the best chain " +
+			"and their justifications to a file or to the standard output.\n" +
+			"\tUsage: gossamer export-blocks --from 1 --to 1000 --output blocks.bin\n",
+	}
+
+	importBlocksCommand = cli.Command{
+		Action:    FixFlagOrder(importBlocksAction),
+		Name:      importBlocksCommandName,
+		Usage:     "Import blocks from a binary file created by export-blocks",
+		ArgsUsage: "",
+		Flags:     ImportBlocksFlags,
+		Category:  "IMPORT-BLOCKS",
+		Description: "The import-blocks command verifies, executes and imports the blocks " +
+			"from a file or from the standard input, and finalises the blocks with a justification.\n" +
+			"With --skip-execution, blocks from a trusted source are stored without being executed.\n" +
+			"\tUsage: gossamer import-blocks --input blocks.bin\n",
+	}
+
+	revertCommand = cli.Command{
+		Action:    FixFlagOrder(revertAction),
+		Name:      revertCommandName,
+		Usage:     "Revert the given number of blocks from the head of the best chain",
+		ArgsUsage: "<blocks>",
+		Flags:     RevertFlags,
+		Category:  "REVERT",
+		Description: "The revert command removes the given number of unfinalised blocks from the head " +
+			"of the best chain, and restores the BABE epoch and GRANDPA authority set state of the new " +
+			"best block. Finalised blocks are only reverted with --force.\n" +
+			"\tUsage: gossamer revert 10\n",
+	}
+
+	checkBlocksCommand = cli.Command{
+		Action:    FixFlagOrder(checkBlocksAction),
+		Name:      checkBlocksCommandName,
+		Usage:     "Re-execute blocks of the best chain and verify their state root",
+		ArgsUsage: "",
+		Flags:     CheckBlocksFlags,
+		Category:  "CHECK-BLOCKS",
+		Description: "The check-blocks command executes each block on top of the state of its parent " +
+			"with the runtime of its parent, and verifies the state root computed matches the state " +
+			"root of the block header. It stops at the first mismatch and prints the keys whose value differ.\n" +
+			"\tUsage: gossamer check-blocks --from 1000 --to 2000\n",
+	}
+
+	stateDiffCommand = cli.Command{
+		Action:    FixFlagOrder(stateDiffAction),
+		Name:      stateDiffCommandName,
+		Usage:     "Print the storage keys changed between the states of two blocks",
+		ArgsUsage: "",
+		Flags:     StateDiffFlags,
+		Category:  "STATE-DIFF",
+		Description: "The state-diff command prints the storage keys added, modified and removed between " +
+			"the states of two blocks, including the keys of child tries, in key order.\n" +
+			"\tUsage: gossamer state-diff --from 1000 --to 1001\n",
+	}
+
+	tryRuntimeCommand = cli.Command{
+		Action:    FixFlagOrder(tryRuntimeAction),
+		Name:      tryRuntimeCommandName,
+		Usage:     "Dry-run a runtime upgrade on top of the state of a block",
+		ArgsUsage: "",
+		Flags:     TryRuntimeFlags,
+		Category:  "TRY-RUNTIME",
+		Description: "The try-runtime command swaps in the given runtime code on top of the state of a block, " +
+			"verifies its spec version is bumped, and runs its migrations with TryRuntime_on_runtime_upgrade " +
+			"if it exports it, or executes the blocks following the block otherwise. It prints the weight and " +
+			"time of the execution and the storage keys changed. Nothing is stored.\n" +
+			"\tUsage: gossamer try-runtime --wasm new.wasm --at 1000\n",
+	}
+
+	snapshotCommand = cli.Command{
+		Name:     snapshotCommandName,
+		Usage:    "Export and import snapshots of the chain state",
+		Category: "SNAPSHOT",
+		Description: "The snapshot command exports and imports binary snapshots of the state of a block, " +
+			"including its child tries and a checksum.\n" +
+			"\tUsage: gossamer snapshot export --block 1000 --output state.snap\n" +
+			"\tUsage: gossamer snapshot import --input state.snap\n",
+		Subcommands: []cli.Command{
+			{
+				Action:    FixFlagOrder(exportSnapshotAction),
+				Name:      exportSnapshotCommandName,
+				Usage:     "Export a snapshot of the state of a block to a binary file",
+				ArgsUsage: "",
+				Flags:     ExportSnapshotFlags,
+				Description: "The snapshot export command writes the header and the trie nodes of the state " +
+					"of a block, defaulting to the highest finalised block, to a file or to the standard output.\n" +
+					"\tUsage: gossamer snapshot export --block 1000 --output state.snap\n",
+			},
+			{
+				Action:    FixFlagOrder(importSnapshotAction),
+				Name:      importSnapshotCommandName,
+				Usage:     "Import a state snapshot and set its block as the chain head",
+				ArgsUsage: "",
+				Flags:     ImportSnapshotFlags,
+				Description: "The snapshot import command imports the trie nodes of a state snapshot from a file " +
+					"or from the standard input, and sets its block as the chain head once the state root " +
+					"of the block header and the checksum of the snapshot are verified.\n" +
+					"\tUsage: gossamer snapshot import --input state.snap\n",
+			},
+		},
+	}
+
 	pruningCommand = cli.Command{
 		Action:    FixFlagOrder(pruneState),
 		Name:      pruningStateCommandName,
@@ -139,6 +260,13 @@ func init() {
 		importRuntimeCommand,
 		importStateCommand,
 		pruningCommand,
+		exportBlocksCommand,
+		importBlocksCommand,
+		revertCommand,
+		checkBlocksCommand,
+		stateDiffCommand,
+		tryRuntimeCommand,
+		snapshotCommand,
 	}
 	app.Flags = RootFlags
 }
@@ -244,6 +372,19 @@ func gossamerAction(ctx *cli.Context) error {
 	}
 
 	ks := keystore.NewGlobalKeystore()
+	// the keys generated by the runtime, such as the rotated session keys, are stored
+	// in the directory of their key type in the keystore directory, encrypted with the
+	// first password given if any, and are loaded into the keystores of their key type
+	// when the node restarts. They are not part of the keys indexed by --unlock.
+	password := strings.Split(ctx.String(PasswordFlag.Name), ",")[0]
+	ks.SetBasepath(cfg.Global.BasePath, []byte(password))
+
+	err = ks.LoadGenerated()
+	if err != nil {
+		logger.Errorf("failed to load generated keys: %s", err)
+		return err
+	}
+
 	// load built-in test keys if specified by `cfg.Account.Key`
 	err = keystore.LoadKeystore(cfg.Account.Key, ks.Acco)
 	if err != nil {
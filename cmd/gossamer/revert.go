@@ -0,0 +1,50 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+var errRevertArgument = errors.New("must provide the number of blocks to revert")
+
+// revertAction is the action for the "revert" subcommand, it removes the
+// given number of blocks from the head of the best chain of the node.
+func revertAction(ctx *cli.Context) error {
+	arguments := ctx.Args()
+	if len(arguments) != 1 {
+		return errRevertArgument
+	}
+
+	blocks, err := strconv.ParseUint(arguments[0], 10, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errRevertArgument, err)
+	}
+
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	return dot.RevertBlocks(cfg, uint(blocks), ctx.Bool(ForceRevertFlag.Name))
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+// exportSnapshotAction is the action for the "snapshot export" subcommand, it writes
+// a snapshot of the state of a block to the output file or to the standard output.
+func exportSnapshotAction(ctx *cli.Context) (err error) {
+	outputPath := ctx.String(SnapshotOutputFlag.Name)
+	if outputPath == "" {
+		// set logger to critical, so the standard output only contains the snapshot
+		if err = ctx.Set(LogFlag.Name, "crit"); err != nil {
+			return err
+		}
+	}
+
+	if _, err = setupLogger(ctx); err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(filepath.Clean(outputPath))
+		if err != nil {
+			return fmt.Errorf("cannot create output file: %w", err)
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("cannot close output file: %w", closeErr)
+			}
+		}()
+		w = file
+	}
+
+	var blockNumber *uint
+	if ctx.IsSet(SnapshotBlockFlag.Name) {
+		number := ctx.Uint(SnapshotBlockFlag.Name)
+		blockNumber = &number
+	}
+
+	return dot.ExportSnapshot(cfg, blockNumber, w)
+}
+
+// importSnapshotAction is the action for the "snapshot import" subcommand, it imports
+// the state snapshot from the input file or from the standard input.
+func importSnapshotAction(ctx *cli.Context) error {
+	if _, err := setupLogger(ctx); err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createImportStateConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	var r io.Reader = os.Stdin
+	if inputPath := ctx.String(SnapshotInputFlag.Name); inputPath != "" {
+		file, err := os.Open(filepath.Clean(inputPath))
+		if err != nil {
+			return fmt.Errorf("cannot open input file: %w", err)
+		}
+		defer file.Close() //nolint:errcheck
+		r = file
+	}
+
+	return dot.ImportSnapshot(cfg.Global.BasePath, r)
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+var errStateDiffFrom = errors.New("must provide the block to compare with --from")
+
+// stateDiffAction is the action for the "state-diff" subcommand, it writes the storage
+// keys changed between the states of two blocks to the standard output.
+func stateDiffAction(ctx *cli.Context) error {
+	from := ctx.String(StateDiffFromFlag.Name)
+	if from == "" {
+		return errStateDiffFrom
+	}
+
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	return dot.StateDiff(cfg, from, ctx.String(StateDiffToFlag.Name), os.Stdout)
+}
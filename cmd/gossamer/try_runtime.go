@@ -0,0 +1,51 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+var errTryRuntimeWasm = errors.New("must provide the runtime code to try with --wasm")
+
+// tryRuntimeAction is the action for the "try-runtime" subcommand, it dry-runs the upgrade
+// to the given runtime code and writes the result to the standard output.
+func tryRuntimeAction(ctx *cli.Context) error {
+	wasmPath := ctx.String(TryRuntimeWasmFlag.Name)
+	if wasmPath == "" {
+		return errTryRuntimeWasm
+	}
+
+	code, err := os.ReadFile(filepath.Clean(wasmPath))
+	if err != nil {
+		return fmt.Errorf("cannot read runtime code: %w", err)
+	}
+
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createBlocksConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.NodeInitialized(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	return dot.TryRuntime(cfg, code, ctx.String(TryRuntimeAtFlag.Name), ctx.Uint(TryRuntimeBlocksFlag.Name), os.Stdout)
+}
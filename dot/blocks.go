@@ -0,0 +1,396 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/core"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/babe"
+	"github.com/ChainSafe/gossamer/lib/grandpa"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// blocksStreamMagic is written at the start of a blocks stream,
+// followed by the version of the stream format.
+var blocksStreamMagic = []byte("gssmrblk")
+
+const blocksStreamVersion byte = 1
+
+var (
+	// ErrBlocksStreamMalformed is returned when a blocks stream cannot be read.
+	ErrBlocksStreamMalformed = errors.New("blocks stream is malformed")
+	errInvalidBlockRange     = errors.New("invalid block range")
+)
+
+// exportedBlock is a block as written in a blocks stream,
+// each one prefixed with its encoded length as a little endian uint32.
+type exportedBlock struct {
+	Header        types.Header
+	Body          types.Body
+	Justification *[]byte
+}
+
+// blocksWriter writes blocks to a blocks stream.
+type blocksWriter struct {
+	w *bufio.Writer
+}
+
+func newBlocksWriter(w io.Writer) (*blocksWriter, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(append(blocksStreamMagic, blocksStreamVersion)); err != nil {
+		return nil, err
+	}
+
+	return &blocksWriter{w: bw}, nil
+}
+
+func (bw *blocksWriter) write(block *types.Block, justification []byte) error {
+	eb := exportedBlock{
+		Header: block.Header,
+		Body:   block.Body,
+	}
+	if justification != nil {
+		eb.Justification = &justification
+	}
+
+	encoded, err := scale.Marshal(eb)
+	if err != nil {
+		return fmt.Errorf("cannot encode block: %w", err)
+	}
+
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(encoded)))
+	if _, err = bw.w.Write(length); err != nil {
+		return err
+	}
+
+	_, err = bw.w.Write(encoded)
+	return err
+}
+
+func (bw *blocksWriter) flush() error {
+	return bw.w.Flush()
+}
+
+// blocksReader reads blocks from a blocks stream.
+type blocksReader struct {
+	r *bufio.Reader
+}
+
+func newBlocksReader(r io.Reader) (*blocksReader, error) {
+	br := bufio.NewReader(r)
+
+	prefix := make([]byte, len(blocksStreamMagic)+1)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, fmt.Errorf("%w: cannot read prefix: %s", ErrBlocksStreamMalformed, err)
+	}
+
+	if !bytes.Equal(prefix[:len(blocksStreamMagic)], blocksStreamMagic) {
+		return nil, fmt.Errorf("%w: unknown prefix 0x%x", ErrBlocksStreamMalformed, prefix)
+	}
+
+	if version := prefix[len(blocksStreamMagic)]; version != blocksStreamVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBlocksStreamMalformed, version)
+	}
+
+	return &blocksReader{r: br}, nil
+}
+
+// read returns the next block and its justification, which is nil if the
+// block has no justification. It returns io.EOF at the end of the stream.
+func (br *blocksReader) read() (block *types.Block, justification []byte, err error) {
+	length := make([]byte, 4)
+	_, err = io.ReadFull(br.r, length)
+	if errors.Is(err, io.EOF) {
+		return nil, nil, io.EOF
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("%w: cannot read block length: %s", ErrBlocksStreamMalformed, err)
+	}
+
+	encoded := make([]byte, binary.LittleEndian.Uint32(length))
+	if _, err = io.ReadFull(br.r, encoded); err != nil {
+		return nil, nil, fmt.Errorf("%w: cannot read block: %s", ErrBlocksStreamMalformed, err)
+	}
+
+	eb := exportedBlock{
+		Header: *types.NewEmptyHeader(),
+	}
+	if err = scale.Unmarshal(encoded, &eb); err != nil {
+		return nil, nil, fmt.Errorf("%w: cannot decode block: %s", ErrBlocksStreamMalformed, err)
+	}
+
+	block = &types.Block{
+		Header: eb.Header,
+		Body:   eb.Body,
+	}
+	if eb.Body == nil {
+		block.Body = types.Body{}
+	}
+	if eb.Justification != nil {
+		justification = *eb.Justification
+	}
+
+	return block, justification, nil
+}
+
+// ExportBlocks writes the blocks of the best chain from the block number from
+// to the block number to included, with their justifications, to the writer.
+// If to is 0, the blocks are exported until the best block.
+func ExportBlocks(cfg *Config, from, to uint, w io.Writer) error {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if err := stateSrvc.Stop(); err != nil {
+			logger.Errorf("cannot stop state service: %s", err)
+		}
+	}()
+
+	if to == 0 {
+		bestHeader, err := stateSrvc.Block.BestBlockHeader()
+		if err != nil {
+			return fmt.Errorf("cannot get best block header: %w", err)
+		}
+		to = bestHeader.Number
+	}
+
+	if from > to {
+		return fmt.Errorf("%w: from block %d is after to block %d", errInvalidBlockRange, from, to)
+	}
+
+	bw, err := newBlocksWriter(w)
+	if err != nil {
+		return fmt.Errorf("cannot write blocks stream prefix: %w", err)
+	}
+
+	for number := from; number <= to; number++ {
+		block, err := stateSrvc.Block.GetBlockByNumber(number)
+		if err != nil {
+			return fmt.Errorf("cannot get block %d: %w", number, err)
+		}
+
+		justification, err := stateSrvc.Block.GetJustification(block.Header.Hash())
+		if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return fmt.Errorf("cannot get justification of block %d: %w", number, err)
+		}
+
+		if err = bw.write(block, justification); err != nil {
+			return fmt.Errorf("cannot write block %d: %w", number, err)
+		}
+	}
+
+	if err = bw.flush(); err != nil {
+		return fmt.Errorf("cannot flush blocks stream: %w", err)
+	}
+
+	logger.Infof("exported blocks %d to %d", from, to)
+	return nil
+}
+
+// blockImporter imports blocks through the verification and execution
+// pipeline used by the sync service.
+type blockImporter struct {
+	stateSrvc     *state.Service
+	verifier      *babe.VerificationManager
+	coreSrvc      *core.Service
+	skipExecution bool
+}
+
+// ImportBlocks imports the blocks read from the blocks stream given on top of the
+// node state. Blocks are verified, executed and imported as they would be by the sync
+// service, and their justifications are verified before finalising them. If skipExecution
+// is true, blocks from trusted sources are stored without being executed, and the state
+// of the imported head must then be imported separately.
+func ImportBlocks(cfg *Config, r io.Reader, skipExecution bool) (err error) {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = startStateService(cfg, stateSrvc); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if stopErr := stateSrvc.Stop(); stopErr != nil && err == nil {
+			err = fmt.Errorf("cannot stop state service: %w", stopErr)
+		}
+	}()
+
+	ks := keystore.NewGlobalKeystore()
+
+	ns, err := nb.createRuntimeStorage(stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot create runtime storage: %w", err)
+	}
+
+	if err = nb.loadRuntime(cfg, ns, stateSrvc, ks, nil); err != nil {
+		return fmt.Errorf("cannot load runtime: %w", err)
+	}
+
+	verifier, err := nb.createBlockVerifier(stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot create block verifier: %w", err)
+	}
+
+	dh, err := nb.createDigestHandler(cfg.Log.DigestLvl, stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot create digest handler: %w", err)
+	}
+
+	coreSrvc, err := nb.createCoreService(cfg, ks, stateSrvc, nil, dh)
+	if err != nil {
+		return fmt.Errorf("cannot create core service: %w", err)
+	}
+
+	// the digest handler and core service are stopped before the state service
+	if err = dh.Start(); err != nil {
+		return fmt.Errorf("cannot start digest handler: %w", err)
+	}
+	defer func() {
+		if stopErr := dh.Stop(); stopErr != nil {
+			logger.Errorf("cannot stop digest handler: %s", stopErr)
+		}
+	}()
+
+	if err = coreSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start core service: %w", err)
+	}
+	defer func() {
+		if stopErr := coreSrvc.Stop(); stopErr != nil {
+			logger.Errorf("cannot stop core service: %s", stopErr)
+		}
+	}()
+
+	importer := &blockImporter{
+		stateSrvc:     stateSrvc,
+		verifier:      verifier,
+		coreSrvc:      coreSrvc,
+		skipExecution: skipExecution,
+	}
+
+	br, err := newBlocksReader(r)
+	if err != nil {
+		return err
+	}
+
+	var imported, skipped uint
+	for {
+		var (
+			block         *types.Block
+			justification []byte
+			ok            bool
+		)
+		block, justification, err = br.read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		ok, err = importer.importBlock(block, justification)
+		if err != nil {
+			return fmt.Errorf("cannot import block %d with hash %s: %w",
+				block.Header.Number, block.Header.Hash(), err)
+		}
+
+		if ok {
+			imported++
+		} else {
+			skipped++
+		}
+	}
+
+	logger.Infof("imported %d blocks, skipped %d blocks already known", imported, skipped)
+	return nil
+}
+
+// importBlock verifies and imports the block, and finalises it if it has a
+// justification. It returns false if the block is already known.
+func (bi *blockImporter) importBlock(block *types.Block, justification []byte) (imported bool, err error) {
+	hash := block.Header.Hash()
+
+	has, err := bi.stateSrvc.Block.HasHeader(hash)
+	if err != nil {
+		return false, fmt.Errorf("cannot check for header: %w", err)
+	}
+
+	if has {
+		logger.Debugf("skipping block number %d with hash %s, already have", block.Header.Number, hash)
+		return false, nil
+	}
+
+	if err = bi.verifier.VerifyBlock(&block.Header); err != nil {
+		return false, fmt.Errorf("cannot verify block: %w", err)
+	}
+
+	if bi.skipExecution {
+		err = bi.stateSrvc.Block.AddBlock(block)
+	} else {
+		err = bi.executeBlock(block)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if justification != nil {
+		err = grandpa.VerifyBlockJustification(bi.stateSrvc.Block, bi.stateSrvc.Grandpa, hash, justification)
+		if err != nil {
+			return false, fmt.Errorf("cannot verify justification: %w", err)
+		}
+
+		if err = bi.stateSrvc.Block.SetJustification(hash, justification); err != nil {
+			return false, fmt.Errorf("cannot store justification: %w", err)
+		}
+	}
+
+	logger.Debugf("🔗 imported block number %d with hash %s", block.Header.Number, hash)
+	return true, nil
+}
+
+// executeBlock executes the block on top of the state of its parent
+// and imports it with the resulting state.
+func (bi *blockImporter) executeBlock(block *types.Block) error {
+	parent, err := bi.stateSrvc.Block.GetHeader(block.Header.ParentHash)
+	if err != nil {
+		return fmt.Errorf("cannot get parent header: %w", err)
+	}
+
+	bi.stateSrvc.Storage.Lock()
+	defer bi.stateSrvc.Storage.Unlock()
+
+	ts, err := bi.stateSrvc.Storage.TrieState(&parent.StateRoot)
+	if err != nil {
+		return fmt.Errorf("cannot get parent state: %w", err)
+	}
+
+	parentHash := parent.Hash()
+	rt, err := bi.stateSrvc.Block.GetRuntime(&parentHash)
+	if err != nil {
+		return fmt.Errorf("cannot get parent runtime: %w", err)
+	}
+
+	rt.SetContextStorage(ts)
+	if _, err = rt.ExecuteBlock(block); err != nil {
+		return fmt.Errorf("cannot execute block: %w", err)
+	}
+
+	return bi.coreSrvc.HandleBlockImport(block, ts)
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blocksStream(t *testing.T) {
+	t.Parallel()
+
+	digest := types.NewDigest()
+	err := digest.Add(types.PreRuntimeDigest{
+		ConsensusEngineID: types.BabeEngineID,
+		Data:              []byte{1, 2, 3},
+	})
+	require.NoError(t, err)
+
+	blocks := []*types.Block{
+		{
+			Header: types.Header{
+				ParentHash: common.Hash{1},
+				Number:     1,
+				StateRoot:  common.Hash{2},
+				Digest:     digest,
+			},
+			Body: types.Body{{4, 5}, {6}},
+		},
+		{
+			Header: types.Header{
+				ParentHash: common.Hash{3},
+				Number:     2,
+				Digest:     types.NewDigest(),
+			},
+			Body: types.Body{},
+		},
+	}
+	justifications := [][]byte{nil, {7, 8, 9}}
+
+	buffer := bytes.NewBuffer(nil)
+	bw, err := newBlocksWriter(buffer)
+	require.NoError(t, err)
+	for i, block := range blocks {
+		err = bw.write(block, justifications[i])
+		require.NoError(t, err)
+	}
+	err = bw.flush()
+	require.NoError(t, err)
+
+	br, err := newBlocksReader(buffer)
+	require.NoError(t, err)
+	for i, expected := range blocks {
+		block, justification, err := br.read()
+		require.NoError(t, err)
+		assert.Equal(t, expected.Header.Hash(), block.Header.Hash())
+		assert.Equal(t, expected.Body, block.Body)
+		assert.Equal(t, justifications[i], justification)
+	}
+
+	_, _, err = br.read()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func Test_newBlocksReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		stream     []byte
+		errWrapped error
+		errMessage string
+	}{
+		"valid prefix": {
+			stream: append([]byte("gssmrblk"), 1),
+		},
+		"empty stream": {
+			errWrapped: ErrBlocksStreamMalformed,
+			errMessage: "blocks stream is malformed: cannot read prefix: EOF",
+		},
+		"unknown prefix": {
+			stream:     append([]byte("notblock"), 1),
+			errWrapped: ErrBlocksStreamMalformed,
+			errMessage: "blocks stream is malformed: unknown prefix 0x6e6f74626c6f636b01",
+		},
+		"unsupported version": {
+			stream:     append([]byte("gssmrblk"), 2),
+			errWrapped: ErrBlocksStreamMalformed,
+			errMessage: "blocks stream is malformed: unsupported version 2",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newBlocksReader(bytes.NewReader(testCase.stream))
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+func Test_blocksReader_truncated(t *testing.T) {
+	t.Parallel()
+
+	buffer := bytes.NewBuffer(nil)
+	bw, err := newBlocksWriter(buffer)
+	require.NoError(t, err)
+	err = bw.write(&types.Block{
+		Header: types.Header{Digest: types.NewDigest()},
+		Body:   types.Body{},
+	}, nil)
+	require.NoError(t, err)
+	err = bw.flush()
+	require.NoError(t, err)
+
+	truncated := buffer.Bytes()[:buffer.Len()-1]
+	br, err := newBlocksReader(bytes.NewReader(truncated))
+	require.NoError(t, err)
+
+	_, _, err = br.read()
+	assert.ErrorIs(t, err, ErrBlocksStreamMalformed)
+}
@@ -0,0 +1,258 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// ErrStateRootMismatch is returned when the state root computed by
+// executing a block does not match the state root of its header.
+var ErrStateRootMismatch = errors.New("state root mismatch")
+
+// checkerBlockState is the block state used by the blockChecker.
+type checkerBlockState interface {
+	GetHeader(hash common.Hash) (*types.Header, error)
+	GetRuntime(hash *common.Hash) (runtime.Instance, error)
+}
+
+// checkerStorageState is the storage state used by the blockChecker.
+type checkerStorageState interface {
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+	Entries(root *common.Hash) (map[string][]byte, error)
+}
+
+// checkerInstanceKey identifies the runtime instances which are interchangeable,
+// which are the instances of the same code with the same heap pages.
+type checkerInstanceKey struct {
+	codeHash  common.Hash
+	heapPages uint64
+}
+
+// blockChecker re-executes blocks on top of the state of their parent,
+// with the runtime code stored in the state of their parent.
+type blockChecker struct {
+	blockState   checkerBlockState
+	storageState checkerStorageState
+	// newInstance creates a runtime instance of the code given
+	// on top of the state given.
+	newInstance func(code []byte, ts *rtstorage.TrieState, codeHash common.Hash) (runtime.Instance, error)
+	// instances holds the runtime instances by runtime code hash and heap pages,
+	// so the runtime is only instantiated again on runtime upgrades.
+	instances map[checkerInstanceKey]runtime.Instance
+}
+
+// CheckBlocks re-executes the blocks of the best chain from the given number to the given
+// number with the runtime of their parent, and verifies the state root computed matches
+// the state root of their header. It stops at the first mismatch, writes the keys whose
+// value differ to the writer, and returns an error wrapping ErrStateRootMismatch.
+// If to is zero, the blocks are checked up to the best block.
+func CheckBlocks(cfg *Config, from, to uint, w io.Writer) (err error) {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if stopErr := stateSrvc.Stop(); stopErr != nil && err == nil {
+			err = fmt.Errorf("cannot stop state service: %w", stopErr)
+		}
+	}()
+
+	if to == 0 {
+		bestHeader, err := stateSrvc.Block.BestBlockHeader()
+		if err != nil {
+			return fmt.Errorf("cannot get best block header: %w", err)
+		}
+		to = bestHeader.Number
+	}
+
+	if from == 0 || from > to {
+		return fmt.Errorf("%w: cannot check blocks from %d to %d", errInvalidBlockRange, from, to)
+	}
+
+	ns, err := nb.createRuntimeStorage(stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot create runtime storage: %w", err)
+	}
+
+	ks := keystore.NewGlobalKeystore()
+	checker := &blockChecker{
+		blockState:   stateSrvc.Block,
+		storageState: stateSrvc.Storage,
+		newInstance: func(code []byte, ts *rtstorage.TrieState, codeHash common.Hash) (runtime.Instance, error) {
+			return newRuntimeInstance(cfg, *ns, ks, nil, code, ts, codeHash)
+		},
+		instances: make(map[checkerInstanceKey]runtime.Instance),
+	}
+	defer checker.stop()
+
+	for number := from; number <= to; number++ {
+		block, err := stateSrvc.Block.GetBlockByNumber(number)
+		if err != nil {
+			return fmt.Errorf("cannot get block %d: %w", number, err)
+		}
+
+		if err = checker.checkBlock(block, w); err != nil {
+			return fmt.Errorf("cannot check block %d with hash %s: %w",
+				number, block.Header.Hash(), err)
+		}
+	}
+
+	logger.Infof("checked blocks %d to %d", from, to)
+	return nil
+}
+
+// checkBlock executes the block on top of the state of its parent and verifies the
+// state root computed. On mismatch, it writes the keys whose value differ between the
+// state computed and the state of the block, or between the state computed and the
+// state of its parent if the state of the block is not in the database.
+func (bc *blockChecker) checkBlock(block *types.Block, w io.Writer) error {
+	parent, err := bc.blockState.GetHeader(block.Header.ParentHash)
+	if err != nil {
+		return fmt.Errorf("cannot get parent header: %w", err)
+	}
+
+	ts, err := bc.storageState.TrieState(&parent.StateRoot)
+	if err != nil {
+		return fmt.Errorf("cannot get parent state: %w", err)
+	}
+
+	rt, err := bc.runtime(parent, ts)
+	if err != nil {
+		return err
+	}
+
+	rt.SetContextStorage(ts)
+	if _, err = rt.ExecuteBlock(block); err != nil {
+		return fmt.Errorf("cannot execute block: %w", err)
+	}
+
+	root, err := ts.Root()
+	if err != nil {
+		return fmt.Errorf("cannot compute state root: %w", err)
+	}
+
+	if root == block.Header.StateRoot {
+		logger.Debugf("state root %s of block number %d matches", root, block.Header.Number)
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "state root mismatch at block number %d with hash %s: expected %s but got %s\n",
+		block.Header.Number, block.Header.Hash(), block.Header.StateRoot, root)
+	if err != nil {
+		return err
+	}
+
+	expectedRoot := block.Header.StateRoot
+	expected, err := bc.storageState.Entries(&expectedRoot)
+	if err != nil {
+		logger.Warnf("cannot get state of block number %d, showing the keys changed by the execution instead: %s",
+			block.Header.Number, err)
+
+		expected, err = bc.storageState.Entries(&parent.StateRoot)
+		if err != nil {
+			return fmt.Errorf("cannot get parent state entries: %w", err)
+		}
+	}
+
+	if err = writeEntriesDiff(w, expected, ts.TrieEntries()); err != nil {
+		return fmt.Errorf("cannot write state diff: %w", err)
+	}
+
+	return fmt.Errorf("%w: expected %s but got %s", ErrStateRootMismatch, block.Header.StateRoot, root)
+}
+
+// runtime returns the runtime instance of the code stored in the state of the parent
+// given. The runtime of the parent stored in the block tree is used if there is one.
+func (bc *blockChecker) runtime(parent *types.Header, ts *rtstorage.TrieState) (runtime.Instance, error) {
+	parentHash := parent.Hash()
+	rt, err := bc.blockState.GetRuntime(&parentHash)
+	if err == nil {
+		return rt, nil
+	}
+
+	codeHash, err := ts.LoadCodeHash()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load runtime code hash: %w", err)
+	}
+
+	key := checkerInstanceKey{
+		codeHash:  codeHash,
+		heapPages: runtime.HeapPages(ts),
+	}
+	rt, ok := bc.instances[key]
+	if ok {
+		return rt, nil
+	}
+
+	logger.Infof("creating runtime with code hash %s and %d heap pages for block number %d...",
+		codeHash, key.heapPages, parent.Number+1)
+	rt, err = bc.newInstance(ts.LoadCode(), ts, codeHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create runtime: %w", err)
+	}
+
+	bc.instances[key] = rt
+	return rt, nil
+}
+
+func (bc *blockChecker) stop() {
+	for _, rt := range bc.instances {
+		rt.Stop()
+	}
+}
+
+// writeEntriesDiff writes the keys added, removed and modified in the got entries
+// compared to the expected entries, sorted by key.
+func writeEntriesDiff(w io.Writer, expected, got map[string][]byte) error {
+	keys := make([]string, 0, len(got))
+	for key := range got {
+		keys = append(keys, key)
+	}
+	for key := range expected {
+		if _, ok := got[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		expectedValue, inExpected := expected[key]
+		gotValue, inGot := got[key]
+
+		change := trie.Change{Key: []byte(key)}
+		switch {
+		case !inExpected:
+			change.Type, change.NewValue = trie.Added, gotValue
+		case !inGot:
+			change.Type, change.OldValue = trie.Removed, expectedValue
+		case !bytes.Equal(expectedValue, gotValue):
+			change.Type, change.OldValue, change.NewValue = trie.Modified, expectedValue, gotValue
+		default:
+			continue
+		}
+
+		if err := writeChange(w, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
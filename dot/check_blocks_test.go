@@ -0,0 +1,173 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate mockgen -source=check_blocks.go -destination=mock_check_blocks_test.go -package=$GOPACKAGE
+
+func Test_blockChecker_checkBlock(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockcheckerBlockState(ctrl)
+	storageState := NewMockcheckerStorageState(ctrl)
+
+	// the parent state roots are labels of the trie states
+	// returned by the storage state mock.
+	rootA, rootB, rootAHeapPages := common.Hash{0xa}, common.Hash{0xb}, common.Hash{0xc}
+	newTrieState := func(root common.Hash) *rtstorage.TrieState {
+		tr := trie.NewEmptyTrie()
+		switch root {
+		case rootA:
+			tr.Put(common.CodeKey, []byte("code A"))
+		case rootB:
+			tr.Put(common.CodeKey, []byte("code B"))
+		case rootAHeapPages:
+			tr.Put(common.CodeKey, []byte("code A"))
+			tr.Put(common.HeapPagesKey, []byte{1, 0, 0, 0, 0, 0, 0, 0})
+		}
+		ts, err := rtstorage.NewTrieState(tr)
+		require.NoError(t, err)
+		return ts
+	}
+	storageState.EXPECT().TrieState(gomock.Any()).
+		DoAndReturn(func(root *common.Hash) (*rtstorage.TrieState, error) {
+			return newTrieState(*root), nil
+		}).AnyTimes()
+
+	var instancesCreated []common.Hash
+	checker := &blockChecker{
+		blockState:   blockState,
+		storageState: storageState,
+		newInstance: func(code []byte, ts *rtstorage.TrieState, codeHash common.Hash) (runtime.Instance, error) {
+			assert.Equal(t, ts.LoadCode(), code)
+			instancesCreated = append(instancesCreated, codeHash)
+			rt := new(mocks.Instance)
+			rt.On("SetContextStorage", mock.Anything)
+			rt.On("ExecuteBlock", mock.Anything).Return([]byte(nil), nil)
+			return rt, nil
+		},
+		instances: make(map[checkerInstanceKey]runtime.Instance),
+	}
+
+	// newBlock returns a block with a parent of the given state root, and
+	// with the state root of the parent state unchanged by the runtime mocks.
+	newBlock := func(number uint, parentRoot common.Hash, inBlockTree bool) *types.Block {
+		parent := &types.Header{Number: number - 1, StateRoot: parentRoot}
+		parentHash := parent.Hash()
+		blockState.EXPECT().GetHeader(parentHash).Return(parent, nil)
+		if inBlockTree {
+			rt := new(mocks.Instance)
+			rt.On("SetContextStorage", mock.Anything)
+			rt.On("ExecuteBlock", mock.Anything).Return([]byte(nil), nil)
+			blockState.EXPECT().GetRuntime(&parentHash).Return(rt, nil)
+		} else {
+			blockState.EXPECT().GetRuntime(&parentHash).Return(nil, errors.New("not in block tree"))
+		}
+
+		return &types.Block{
+			Header: types.Header{
+				ParentHash: parentHash,
+				Number:     number,
+				StateRoot:  newTrieState(parentRoot).MustRoot(),
+			},
+			Body: types.Body{},
+		}
+	}
+
+	buffer := bytes.NewBuffer(nil)
+
+	// the runtime of the block tree is used if the parent is in the block tree
+	err := checker.checkBlock(newBlock(1, rootA, true), buffer)
+	require.NoError(t, err)
+	assert.Empty(t, instancesCreated)
+
+	// the runtime is created from the code of the parent state otherwise,
+	// and reused for the following blocks with the same code and heap pages
+	err = checker.checkBlock(newBlock(2, rootA, false), buffer)
+	require.NoError(t, err)
+	err = checker.checkBlock(newBlock(3, rootA, false), buffer)
+	require.NoError(t, err)
+	hashA, err := newTrieState(rootA).LoadCodeHash()
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{hashA}, instancesCreated)
+
+	// runtime upgrades and heap pages changes create new runtimes
+	err = checker.checkBlock(newBlock(4, rootB, false), buffer)
+	require.NoError(t, err)
+	err = checker.checkBlock(newBlock(5, rootAHeapPages, false), buffer)
+	require.NoError(t, err)
+	err = checker.checkBlock(newBlock(6, rootB, false), buffer)
+	require.NoError(t, err)
+	hashB, err := newTrieState(rootB).LoadCodeHash()
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{hashA, hashB, hashA}, instancesCreated)
+	assert.Len(t, checker.instances, 3)
+	assert.Empty(t, buffer.String())
+
+	// the keys differing from the state of the parent are written on mismatch
+	// if the state of the block is not in the database
+	block := newBlock(7, rootA, false)
+	block.Header.StateRoot = common.Hash{1}
+	storageState.EXPECT().Entries(&block.Header.StateRoot).Return(nil, errors.New("not found"))
+	storageState.EXPECT().Entries(&rootA).Return(map[string][]byte{":code": []byte("code")}, nil)
+
+	err = checker.checkBlock(block, buffer)
+	assert.ErrorIs(t, err, ErrStateRootMismatch)
+	expectedOutput := "state root mismatch at block number 7 with hash " + block.Header.Hash().String() +
+		": expected 0x0100000000000000000000000000000000000000000000000000000000000000 but got " +
+		newTrieState(rootA).MustRoot().String() + "\n" +
+		"~ 0x3a636f6465: 0x636f6465 -> 0x636f64652041\n"
+	assert.Equal(t, expectedOutput, buffer.String())
+}
+
+func Test_writeEntriesDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		expected map[string][]byte
+		got      map[string][]byte
+		diff     string
+	}{
+		"no difference": {
+			expected: map[string][]byte{"a": {1}},
+			got:      map[string][]byte{"a": {1}},
+		},
+		"added removed and modified": {
+			expected: map[string][]byte{"a": {1}, "b": {2}, "d": {4}},
+			got:      map[string][]byte{"a": {1}, "b": {3}, "c": {5}},
+			diff: "~ 0x62: 0x02 -> 0x03\n" +
+				"+ 0x63: 0x05\n" +
+				"- 0x64: 0x04\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := bytes.NewBuffer(nil)
+			err := writeEntriesDiff(buffer, testCase.expected, testCase.got)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.diff, buffer.String())
+		})
+	}
+}
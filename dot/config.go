@@ -48,6 +48,9 @@ type GlobalConfig struct {
 	TelemetryURLs  []genesis.TelemetryEndpoint
 	RetainBlocks   int64
 	Pruning        pruner.Mode
+	// RuntimeProfiling enables the profiling of the host function
+	// calls of the runtime, published with the node metrics.
+	RuntimeProfiling bool
 }
 
 // LogConfig represents the log levels for individual packages
@@ -61,6 +64,9 @@ type LogConfig struct {
 	RuntimeLvl        log.Level
 	BlockProducerLvl  log.Level
 	FinalityGadgetLvl log.Level
+	// RuntimeTargets are comma separated log directives for the runtime
+	// log targets, such as `runtime::system=debug,pallet_babe=trace`.
+	RuntimeTargets string
 }
 
 func (l LogConfig) String() string {
@@ -75,6 +81,9 @@ func (l LogConfig) String() string {
 		fmt.Sprintf("block producer: %s", l.BlockProducerLvl),
 		fmt.Sprintf("finality gadget: %s", l.FinalityGadgetLvl),
 	}
+	if l.RuntimeTargets != "" {
+		entries = append(entries, fmt.Sprintf("runtime targets: %s", l.RuntimeTargets))
+	}
 	return strings.Join(entries, ", ")
 }
 
@@ -112,6 +121,7 @@ type CoreConfig struct {
 	GrandpaAuthority bool
 	WasmInterpreter  string
 	GrandpaInterval  time.Duration
+	TimestampDrift   time.Duration
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
@@ -128,6 +138,15 @@ type RPCConfig struct {
 	WSExternal       bool
 	WSUnsafe         bool
 	WSUnsafeExternal bool
+	// RateLimit is the number of call tokens refilled per second for each
+	// client IP address, a zero value disables rate limiting.
+	RateLimit        float64
+	RateLimitBurst   uint32
+	MethodCosts      map[string]uint32
+	AllowedMethods   []string
+	DeniedMethods    []string
+	WSAllowedMethods []string
+	WSDeniedMethods  []string
 }
 
 func (r *RPCConfig) isRPCEnabled() bool {
@@ -153,12 +172,27 @@ func (r *RPCConfig) String() string {
 		"ws=" + fmt.Sprint(r.WS) + " " +
 		"wsexternal=" + fmt.Sprint(r.WSExternal) + " " +
 		"wsunsafe=" + fmt.Sprint(r.WSUnsafe) + " " +
-		"wsunsafeexternal=" + fmt.Sprint(r.WSUnsafeExternal)
+		"wsunsafeexternal=" + fmt.Sprint(r.WSUnsafeExternal) + " " +
+		"ratelimit=" + fmt.Sprint(r.RateLimit) + " " +
+		"ratelimitburst=" + fmt.Sprint(r.RateLimitBurst) + " " +
+		"methodcosts=" + fmt.Sprint(r.MethodCosts) + " " +
+		"allowedmethods=" + strings.Join(r.AllowedMethods, ",") + " " +
+		"deniedmethods=" + strings.Join(r.DeniedMethods, ",") + " " +
+		"wsallowedmethods=" + strings.Join(r.WSAllowedMethods, ",") + " " +
+		"wsdeniedmethods=" + strings.Join(r.WSDeniedMethods, ",")
 }
 
 // StateConfig is the config for the State service
 type StateConfig struct {
 	Rewind uint
+	// CacheSize is the maximum size of the state trie node cache in bytes,
+	// the state service default is used if it is zero.
+	CacheSize uint64
+	// BlocksPruning is the pruning mode of the finalised blocks.
+	BlocksPruning pruner.BlocksConfig
+	// RuntimePoolSize is the maximum number of instances of each runtime pool,
+	// the state service default is used if it is zero.
+	RuntimePoolSize uint
 }
 
 // networkServiceEnabled returns true if the network service is enabled
@@ -13,6 +13,7 @@ type Config struct {
 	Network NetworkConfig `toml:"network,omitempty"`
 	RPC     RPCConfig     `toml:"rpc,omitempty"`
 	Pprof   PprofConfig   `toml:"pprof,omitempty"`
+	State   StateConfig   `toml:"state,omitempty"`
 }
 
 // GlobalConfig is to marshal/unmarshal toml global config vars
@@ -37,6 +38,7 @@ type LogConfig struct {
 	RuntimeLvl        string `toml:"runtime,omitempty"`
 	BlockProducerLvl  string `toml:"babe,omitempty"`
 	FinalityGadgetLvl string `toml:"grandpa,omitempty"`
+	RuntimeTargets    string `toml:"runtime-targets,omitempty"`
 }
 
 // InitConfig is the configuration for the node initialization
@@ -75,6 +77,7 @@ type CoreConfig struct {
 	WasmInterpreter  string `toml:"wasm-interpreter,omitempty"`
 	GrandpaInterval  uint32 `toml:"grandpa-interval,omitempty"`
 	BABELead         bool   `toml:"babe-lead,omitempty"`
+	TimestampDrift   uint32 `toml:"timestamp-drift,omitempty"`
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
@@ -91,6 +94,14 @@ type RPCConfig struct {
 	WSExternal       bool     `toml:"ws-external,omitempty"`
 	WSUnsafe         bool     `toml:"ws-unsafe,omitempty"`
 	WSUnsafeExternal bool     `toml:"ws-unsafe-external,omitempty"`
+
+	RateLimit        float64           `toml:"rate-limit,omitempty"`
+	RateLimitBurst   uint32            `toml:"rate-limit-burst,omitempty"`
+	MethodCosts      map[string]uint32 `toml:"method-costs,omitempty"`
+	AllowedMethods   []string          `toml:"allowed-methods,omitempty"`
+	DeniedMethods    []string          `toml:"denied-methods,omitempty"`
+	WSAllowedMethods []string          `toml:"ws-allowed-methods,omitempty"`
+	WSDeniedMethods  []string          `toml:"ws-denied-methods,omitempty"`
 }
 
 // PprofConfig contains the configuration for Pprof.
@@ -100,3 +111,10 @@ type PprofConfig struct {
 	BlockRate        int    `toml:"block-rate,omitempty"`
 	MutexRate        int    `toml:"mutex-rate,omitempty"`
 }
+
+// StateConfig contains the configuration for the state service.
+type StateConfig struct {
+	CacheSize       uint64 `toml:"cache-size,omitempty"`
+	BlocksPruning   string `toml:"blocks-pruning,omitempty"`
+	RuntimePoolSize uint   `toml:"runtime-pool-size,omitempty"`
+}
@@ -407,7 +407,8 @@ func TestRPCConfig_String(t *testing.T) {
 			name:      "default base case",
 			rpcConfig: RPCConfig{},
 			want: "enabled=false external=false unsafe=false unsafeexternal=false port=0 host= modules= wsport=0 ws" +
-				"=false wsexternal=false wsunsafe=false wsunsafeexternal=false",
+				"=false wsexternal=false wsunsafe=false wsunsafeexternal=false ratelimit=0 ratelimitburst=0 methodcosts=map[] " +
+				"allowedmethods= deniedmethods= wsallowedmethods= wsdeniedmethods=",
 		},
 		{
 			name: "fields changed",
@@ -426,7 +427,8 @@ func TestRPCConfig_String(t *testing.T) {
 				WSUnsafeExternal: true,
 			},
 			want: "enabled=true external=true unsafe=true unsafeexternal=true port=1234 host=5678 modules= wsport" +
-				"=2345 ws=true wsexternal=true wsunsafe=true wsunsafeexternal=true",
+				"=2345 ws=true wsexternal=true wsunsafe=true wsunsafeexternal=true ratelimit=0 ratelimitburst=0 " +
+				"methodcosts=map[] allowedmethods= deniedmethods= wsallowedmethods= wsdeniedmethods=",
 		},
 	}
 	for _, tt := range tests {
@@ -464,6 +466,14 @@ func TestLogConfig_String(t *testing.T) {
 			want: "core: DBUG, digest: INFO, sync: WARN, network: EROR, rpc: CRIT, state: DBUG, runtime: INFO, " +
 				"block producer: WARN, finality gadget: EROR",
 		},
+		{
+			name: "runtime targets case",
+			logConfig: LogConfig{
+				RuntimeTargets: "runtime::system=debug",
+			},
+			want: "core: CRIT, digest: CRIT, sync: CRIT, network: CRIT, rpc: CRIT, state: CRIT, runtime: CRIT, " +
+				"block producer: CRIT, finality gadget: CRIT, runtime targets: runtime::system=debug",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
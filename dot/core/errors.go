@@ -44,7 +44,14 @@
 	// ErrEmptyRuntimeCode is returned when the storage :code is empty
 	ErrEmptyRuntimeCode = errors.New("new :code is empty")
 
+	// ErrCannotTraceGenesis is returned when trying to trace the execution of the genesis block
+	ErrCannotTraceGenesis = errors.New("cannot trace genesis block")
+
+	// ErrCannotProfileGenesis is returned when trying to profile the execution of the genesis block
+	ErrCannotProfileGenesis = errors.New("cannot profile genesis block")
+
 	errNilCodeSubstitutedState = errors.New("cannot have nil CodeSubstitutedStat")
+	errInstanceNotProfilable   = errors.New("runtime instance cannot be profiled")
 )
 
 // ErrNilChannel is returned if a channel is nil
@@ -27,6 +27,8 @@ type BlockState interface {
 	BestBlockStateRoot() (common.Hash, error)
 	BestBlock() (*types.Block, error)
 	AddBlock(*types.Block) error
+	AddIndexedTransactions(block *types.Block, operations []rtstorage.IndexOperation) error
+	GetHeader(hash common.Hash) (*types.Header, error)
 	GetAllBlocksAtDepth(hash common.Hash) []common.Hash
 	GetBlockByHash(common.Hash) (*types.Block, error)
 	GetBlockStateRoot(bhash common.Hash) (common.Hash, error)
@@ -43,6 +45,7 @@ type BlockState interface {
 	GetBlockBody(hash common.Hash) (*types.Body, error)
 	HandleRuntimeChanges(newState *rtstorage.TrieState, in runtime.Instance, bHash common.Hash) error
 	GetRuntime(*common.Hash) (runtime.Instance, error)
+	GetRuntimePool(*common.Hash) (*runtime.Pool, error)
 	StoreRuntime(common.Hash, runtime.Instance)
 }
 
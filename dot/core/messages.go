@@ -30,7 +30,7 @@ func (s *Service) validateTransaction(peerID peer.ID, head *types.Header, rt run
 
 	// validate each transaction
 	externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, tx...))
-	validity, err = rt.ValidateTransaction(externalExt)
+	validity, err = rt.ValidateTransaction(externalExt, head.Hash())
 	if err != nil {
 		if errors.Is(err, runtime.ErrInvalidTransaction) {
 			s.net.ReportPeer(peerset.ReputationChange{
@@ -73,11 +73,17 @@ func (s *Service) HandleTransactionMessage(peerID peer.ID, msg *network.Transact
 	}
 
 	hash := head.Hash()
-	rt, err := s.blockState.GetRuntime(&hash)
+	pool, err := s.blockState.GetRuntimePool(&hash)
 	if err != nil {
 		return false, err
 	}
 
+	rt, err := pool.Checkout()
+	if err != nil {
+		return false, err
+	}
+	defer pool.Checkin(rt)
+
 	allTxsAreValid := true
 	for _, tx := range txs {
 		validity, isValidTxn, err := s.validateTransaction(peerID, head, rt, tx)
@@ -335,8 +335,12 @@ type args struct {
 					tt.mockBlockState.bestHeader.err)
 
 				if tt.mockBlockState.getRuntime != nil {
-					blockState.EXPECT().GetRuntime(gomock.Any()).Return(
-						tt.mockBlockState.getRuntime.runtime,
+					var pool *runtime.Pool
+					if tt.mockBlockState.getRuntime.runtime != nil {
+						pool = runtime.NewPool(tt.mockBlockState.getRuntime.runtime, 1)
+					}
+					blockState.EXPECT().GetRuntimePool(gomock.Any()).Return(
+						pool,
 						tt.mockBlockState.getRuntime.err)
 				}
 				s.blockState = blockState
@@ -358,7 +362,8 @@ type args struct {
 			if tt.mockRuntime != nil {
 				rt := tt.mockRuntime.runtime
 				rt.On("SetContextStorage", tt.mockRuntime.setContextStorage.trieState)
-				rt.On("ValidateTransaction", tt.mockRuntime.validateTxn.input).
+				rt.On("ValidateTransaction", tt.mockRuntime.validateTxn.input,
+					tt.mockBlockState.bestHeader.header.Hash()).
 					Return(tt.mockRuntime.validateTxn.validity, tt.mockRuntime.validateTxn.err)
 			}
 
@@ -55,6 +55,20 @@ func (mr *MockBlockStateMockRecorder) AddBlock(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlock", reflect.TypeOf((*MockBlockState)(nil).AddBlock), arg0)
 }
 
+// AddIndexedTransactions mocks base method.
+func (m *MockBlockState) AddIndexedTransactions(arg0 *types.Block, arg1 []storage.IndexOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIndexedTransactions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddIndexedTransactions indicates an expected call of AddIndexedTransactions.
+func (mr *MockBlockStateMockRecorder) AddIndexedTransactions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIndexedTransactions", reflect.TypeOf((*MockBlockState)(nil).AddIndexedTransactions), arg0, arg1)
+}
+
 // BestBlock mocks base method.
 func (m *MockBlockState) BestBlock() (*types.Block, error) {
 	m.ctrl.T.Helper()
@@ -270,6 +284,21 @@ func (mr *MockBlockStateMockRecorder) GetFinalisedNotifierChannel() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinalisedNotifierChannel", reflect.TypeOf((*MockBlockState)(nil).GetFinalisedNotifierChannel))
 }
 
+// GetHeader mocks base method.
+func (m *MockBlockState) GetHeader(arg0 common.Hash) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeader", arg0)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeader indicates an expected call of GetHeader.
+func (mr *MockBlockStateMockRecorder) GetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeader", reflect.TypeOf((*MockBlockState)(nil).GetHeader), arg0)
+}
+
 // GetImportedBlockNotifierChannel mocks base method.
 func (m *MockBlockState) GetImportedBlockNotifierChannel() chan *types.Block {
 	m.ctrl.T.Helper()
@@ -299,6 +328,21 @@ func (mr *MockBlockStateMockRecorder) GetRuntime(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuntime", reflect.TypeOf((*MockBlockState)(nil).GetRuntime), arg0)
 }
 
+// GetRuntimePool mocks base method.
+func (m *MockBlockState) GetRuntimePool(arg0 *common.Hash) (*runtime.Pool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuntimePool", arg0)
+	ret0, _ := ret[0].(*runtime.Pool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuntimePool indicates an expected call of GetRuntimePool.
+func (mr *MockBlockStateMockRecorder) GetRuntimePool(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuntimePool", reflect.TypeOf((*MockBlockState)(nil).GetRuntimePool), arg0)
+}
+
 // GetSlotForBlock mocks base method.
 func (m *MockBlockState) GetSlotForBlock(arg0 common.Hash) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/ChainSafe/gossamer/dot/network"
@@ -33,8 +34,6 @@
 // QueryKeyValueChanges represents the key-value data inside a block storage
 type QueryKeyValueChanges map[string]string
 
-type wasmerInstanceFunc func(code []byte, cfg *wasmer.Config) (instance *wasmer.Instance, err error)
-
 // Service is an overhead layer that allows communication between the runtime,
 // BABE session, and network service. It deals with the validation of transactions
 // and blocks by calling their respective validation functions in the runtime.
@@ -57,6 +56,12 @@ type Service struct {
 
 	// Keystore
 	keys *keystore.GlobalKeystore
+
+	// newInstance creates the runtime instances with the wasm interpreter of the node
+	newInstance runtime.NewInstanceFunc
+	// precompile compiles runtime code ahead of the creation of its instances,
+	// and is nil if the wasm interpreter of the node does not support it.
+	precompile func(code []byte) error
 }
 
 // Config holds the configuration for the core Service.
@@ -73,6 +78,14 @@ type Config struct {
 
 	CodeSubstitutes      map[common.Hash]string
 	CodeSubstitutedState CodeSubstitutedState
+
+	// NewInstance creates the runtime instances with the wasm interpreter
+	// of the node, and defaults to creating wasmer instances.
+	NewInstance runtime.NewInstanceFunc
+	// Precompile compiles runtime code ahead of the creation of its instances.
+	// It defaults to precompiling wasmer modules if NewInstance is not set,
+	// and runtime code is not precompiled if it is nil.
+	Precompile func(code []byte) error
 }
 
 // NewService returns a new core service that connects the runtime, BABE
@@ -102,6 +115,12 @@ func NewService(cfg *Config) (*Service, error) {
 
 	blockAddCh := make(chan *types.Block, 256)
 
+	newInstance, precompile := cfg.NewInstance, cfg.Precompile
+	if newInstance == nil {
+		newInstance = wasmer.NewRuntimeInstance
+		precompile = wasmer.PrecompileModule
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	srv := &Service{
 		ctx:                  ctx,
@@ -115,6 +134,8 @@ func NewService(cfg *Config) (*Service, error) {
 		blockAddCh:           blockAddCh,
 		codeSubstitute:       cfg.CodeSubstitutes,
 		codeSubstitutedState: cfg.CodeSubstitutedState,
+		newInstance:          newInstance,
+		precompile:           precompile,
 	}
 
 	return srv, nil
@@ -123,9 +144,25 @@ func NewService(cfg *Config) (*Service, error) {
 // Start starts the core service
 func (s *Service) Start() error {
 	go s.handleBlocksAsync()
+	go s.precompileCodeSubstitutes()
 	return nil
 }
 
+// precompileCodeSubstitutes compiles the code substitutes in the background,
+// so their runtimes are created without delay when their blocks are imported.
+func (s *Service) precompileCodeSubstitutes() {
+	if s.precompile == nil {
+		return
+	}
+
+	for hash, value := range s.codeSubstitute {
+		err := s.precompile(common.MustHexToBytes(value))
+		if err != nil {
+			logger.Warnf("cannot precompile code substitute for block %s: %s", hash, err)
+		}
+	}
+}
+
 // Stop stops the core service
 func (s *Service) Stop() error {
 	s.Lock()
@@ -189,6 +226,8 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 		return ErrNilBlockHandlerParameter
 	}
 
+	s.precompileCodeChange(block, state)
+
 	// store updates state trie nodes in database
 	err := s.storageState.StoreTrie(state, &block.Header)
 	if err != nil {
@@ -208,6 +247,13 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 		}
 	}
 
+	// store the data of the transactions indexed by the runtime
+	if operations := state.IndexOperations(); len(operations) > 0 {
+		if err = s.blockState.AddIndexedTransactions(block, operations); err != nil {
+			return fmt.Errorf("cannot add indexed transactions of block %s: %w", block.Header.Hash(), err)
+		}
+	}
+
 	logger.Debugf("imported block %s and stored state trie with root %s",
 		block.Header.Hash(), state.MustRoot())
 
@@ -223,7 +269,7 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 	}
 
 	// check if there was a runtime code substitution
-	if err := s.handleCodeSubstitution(block.Header.Hash(), state, wasmer.NewInstance); err != nil {
+	if err := s.handleCodeSubstitution(block.Header.Hash(), state); err != nil {
 		logger.Criticalf("failed to substitute runtime code: %s", err)
 		return err
 	}
@@ -241,11 +287,40 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 	return nil
 }
 
-func (s *Service) handleCodeSubstitution(
-	hash common.Hash,
-	state *rtstorage.TrieState,
-	instance wasmerInstanceFunc,
-) error {
+// precompileCodeChange compiles in the background the runtime code set by the given block
+// if it differs from the code of its parent, so the runtime is compiled while the block
+// is stored and is ready when the runtime changes of the block are handled.
+func (s *Service) precompileCodeChange(block *types.Block, state *rtstorage.TrieState) {
+	if s.precompile == nil {
+		return
+	}
+
+	codeHash, err := state.LoadCodeHash()
+	if err != nil {
+		logger.Warnf("cannot load runtime code hash of block %s: %s", block.Header.Hash(), err)
+		return
+	}
+
+	parentRuntime, err := s.blockState.GetRuntime(&block.Header.ParentHash)
+	if err != nil {
+		// the error is handled with the runtime changes of the block.
+		return
+	}
+
+	if codeHash == parentRuntime.GetCodeHash() {
+		return
+	}
+
+	code := state.LoadCode()
+	go func() {
+		err := s.precompile(code)
+		if err != nil {
+			logger.Warnf("cannot precompile runtime code of block %s: %s", block.Header.Hash(), err)
+		}
+	}()
+}
+
+func (s *Service) handleCodeSubstitution(hash common.Hash, state *rtstorage.TrieState) error {
 	value := s.codeSubstitute[hash]
 	if value == "" {
 		return nil
@@ -262,22 +337,30 @@ func (s *Service) handleCodeSubstitution(
 		return err
 	}
 
+	version, err := rt.CheckRuntimeVersion(code)
+	if err != nil {
+		return fmt.Errorf("cannot get substituted runtime version: %w", err)
+	}
+
+	if err = runtime.CheckRequiredAPIs(version); err != nil {
+		return fmt.Errorf("cannot substitute runtime code: %w", err)
+	}
+
 	// this needs to create a new runtime instance, otherwise it will update
 	// the blocks that reference the current runtime version to use the code substition
-	cfg := &wasmer.Config{
-		Imports: wasmer.ImportsNodeRuntime,
+	cfg := runtime.InstanceConfig{
+		Storage:     state,
+		CodeHash:    common.MustBlake2bHash(code),
+		Keystore:    rt.Keystore(),
+		NodeStorage: rt.NodeStorage(),
+		Network:     rt.NetworkService(),
 	}
 
-	cfg.Storage = state
-	cfg.Keystore = rt.Keystore()
-	cfg.NodeStorage = rt.NodeStorage()
-	cfg.Network = rt.NetworkService()
-
 	if rt.Validator() {
 		cfg.Role = 4
 	}
 
-	next, err := instance(code, cfg)
+	next, err := s.newInstance(code, cfg)
 	if err != nil {
 		return err
 	}
@@ -346,15 +429,30 @@ func (s *Service) handleChainReorg(prev, curr common.Hash) error {
 	}
 
 	// Check transaction validation on the best block.
-	rt, err := s.blockState.GetRuntime(nil)
+	ts, err := s.storageState.TrieState(nil)
+	if err != nil {
+		return err
+	}
+
+	bestBlockHash := s.blockState.BestBlockHash()
+
+	pool, err := s.blockState.GetRuntimePool(nil)
+	if err != nil {
+		return err
+	}
+
+	rt, err := pool.Checkout()
 	if err != nil {
 		return err
 	}
+	defer pool.Checkin(rt)
 
 	if rt == nil {
 		return ErrNilRuntime
 	}
 
+	rt.SetContextStorage(ts)
+
 	// for each block in the previous chain, re-add its extrinsics back into the pool
 	for _, hash := range subchain {
 		body, err := s.blockState.GetBlockBody(hash)
@@ -378,7 +476,7 @@ func (s *Service) handleChainReorg(prev, curr common.Hash) error {
 			externalExt := make(types.Extrinsic, 0, 1+len(ext))
 			externalExt = append(externalExt, byte(types.TxnExternal))
 			externalExt = append(externalExt, ext...)
-			txv, err := rt.ValidateTransaction(externalExt)
+			txv, err := rt.ValidateTransaction(externalExt, bestBlockHash)
 			if err != nil {
 				logger.Debugf("failed to validate transaction for extrinsic %s: %s", ext, err)
 				continue
@@ -401,17 +499,39 @@ func (s *Service) maintainTransactionPool(block *types.Block) {
 		s.transactionState.RemoveExtrinsic(ext)
 	}
 
-	// re-validate transactions in the pool and move them to the queue
 	txs := s.transactionState.PendingInPool()
-	for _, tx := range txs {
-		// get the best block corresponding runtime
-		rt, err := s.blockState.GetRuntime(nil)
-		if err != nil {
-			logger.Warnf("failed to get runtime to re-validate transactions in pool: %s", err)
-			continue
-		}
+	if len(txs) == 0 {
+		return
+	}
+
+	ts, err := s.storageState.TrieState(nil)
+	if err != nil {
+		logger.Warnf("failed to get state to re-validate transactions in pool: %s", err)
+		return
+	}
+
+	bestBlockHash := s.blockState.BestBlockHash()
 
-		txnValidity, err := rt.ValidateTransaction(tx.Extrinsic)
+	// get the best block corresponding runtime
+	pool, err := s.blockState.GetRuntimePool(nil)
+	if err != nil {
+		logger.Warnf("failed to get runtime to re-validate transactions in pool: %s", err)
+		return
+	}
+
+	rt, err := pool.Checkout()
+	if err != nil {
+		logger.Warnf("failed to get runtime to re-validate transactions in pool: %s", err)
+		return
+	}
+	defer pool.Checkin(rt)
+
+	rt.SetContextStorage(ts)
+
+	// re-validate transactions in the pool and move them to the queue
+	for _, tx := range txs {
+		externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, tx.Extrinsic...))
+		txnValidity, err := rt.ValidateTransaction(externalExt, bestBlockHash)
 		if err != nil {
 			s.transactionState.RemoveExtrinsic(tx.Extrinsic)
 			continue
@@ -450,14 +570,49 @@ func (s *Service) HasKey(pubKeyStr, keystoreType string) (bool, error) {
 
 // DecodeSessionKeys executes the runtime DecodeSessionKeys and return the scale encoded keys
 func (s *Service) DecodeSessionKeys(enc []byte) ([]byte, error) {
-	rt, err := s.blockState.GetRuntime(nil)
+	ts, err := s.storageState.TrieState(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := s.blockState.GetRuntimePool(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pool.Checkout()
 	if err != nil {
 		return nil, err
 	}
+	defer pool.Checkin(rt)
 
+	rt.SetContextStorage(ts)
 	return rt.DecodeSessionKeys(enc)
 }
 
+// GenerateSessionKeys executes the runtime GenerateSessionKeys, inserting a new key in the
+// keystore for each key type of the session keys, and returns the scale encoded session keys
+func (s *Service) GenerateSessionKeys() ([]byte, error) {
+	ts, err := s.storageState.TrieState(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := s.blockState.GetRuntimePool(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pool.Checkout()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Checkin(rt)
+
+	rt.SetContextStorage(ts)
+	return rt.GenerateSessionKeys(nil)
+}
+
 // GetRuntimeVersion gets the current RuntimeVersion
 func (s *Service) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error) {
 	var stateRootHash *common.Hash
@@ -476,11 +631,17 @@ func (s *Service) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error)
 		return nil, err
 	}
 
-	rt, err := s.blockState.GetRuntime(bhash)
+	pool, err := s.blockState.GetRuntimePool(bhash)
 	if err != nil {
 		return nil, err
 	}
 
+	rt, err := pool.Checkout()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Checkin(rt)
+
 	rt.SetContextStorage(ts)
 	return rt.Version()
 }
@@ -500,16 +661,24 @@ func (s *Service) HandleSubmittedExtrinsic(ext types.Extrinsic) error {
 		return err
 	}
 
-	rt, err := s.blockState.GetRuntime(nil)
+	bestBlockHash := s.blockState.BestBlockHash()
+
+	pool, err := s.blockState.GetRuntimePool(nil)
 	if err != nil {
 		logger.Critical("failed to get runtime")
 		return err
 	}
 
+	rt, err := pool.Checkout()
+	if err != nil {
+		return err
+	}
+	defer pool.Checkin(rt)
+
 	rt.SetContextStorage(ts)
 	// the transaction source is External
 	externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, ext...))
-	txv, err := rt.ValidateTransaction(externalExt)
+	txv, err := rt.ValidateTransaction(externalExt, bestBlockHash)
 	if err != nil {
 		return err
 	}
@@ -543,15 +712,182 @@ func (s *Service) GetMetadata(bhash *common.Hash) ([]byte, error) {
 		return nil, err
 	}
 
-	rt, err := s.blockState.GetRuntime(bhash)
+	pool, err := s.blockState.GetRuntimePool(bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pool.Checkout()
 	if err != nil {
 		return nil, err
 	}
+	defer pool.Checkin(rt)
 
 	rt.SetContextStorage(ts)
 	return rt.Metadata()
 }
 
+// DryRun applies the extrinsic on top of the state of the block with the given hash,
+// or of the best block if the hash is nil, and returns the SCALE encoded ApplyExtrinsicResult.
+// The extrinsic is applied by a dedicated runtime instance and all storage changes are discarded.
+func (s *Service) DryRun(ext types.Extrinsic, bhash *common.Hash) ([]byte, error) {
+	parentHash := s.blockState.BestBlockHash()
+	if bhash != nil {
+		parentHash = *bhash
+	}
+
+	parent, err := s.blockState.GetHeader(parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header of block %s: %w", parentHash, err)
+	}
+
+	// the trie state is a snapshot, changes made to it are never stored
+	ts, err := s.storageState.TrieState(&parent.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state: %w", err)
+	}
+
+	dryRunInstance, err := s.newDedicatedInstance(parentHash, ts, ts.LoadCode(), s.newInstance)
+	if err != nil {
+		return nil, err
+	}
+	defer dryRunInstance.Stop()
+
+	header, err := types.NewHeader(parentHash, common.Hash{}, common.Hash{}, parent.Number+1, types.NewDigest())
+	if err != nil {
+		return nil, fmt.Errorf("cannot create header: %w", err)
+	}
+
+	err = dryRunInstance.InitializeBlock(header)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialise block: %w", err)
+	}
+
+	return dryRunInstance.ApplyExtrinsic(ext)
+}
+
+// TraceBlock re-executes the block with the given hash on top of the state of its parent
+// and returns the tracing spans entered and storage accesses made by the runtime matching
+// the given filter. The block is executed by a dedicated runtime instance and all storage
+// changes are discarded.
+func (s *Service) TraceBlock(hash common.Hash, filter rtstorage.TraceFilter) (*rtstorage.Trace, error) {
+	block, err := s.blockState.GetBlockByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get block %s: %w", hash, err)
+	}
+
+	if block.Header.Number == 0 {
+		return nil, ErrCannotTraceGenesis
+	}
+
+	ts, err := s.parentTrieState(block)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := rtstorage.NewTracingStorage(ts, filter)
+
+	traceInstance, err := s.newDedicatedInstance(block.Header.ParentHash, tracer, ts.LoadCode(), s.newInstance)
+	if err != nil {
+		return nil, err
+	}
+	defer traceInstance.Stop()
+
+	_, err = traceInstance.ExecuteBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute block: %w", err)
+	}
+
+	return tracer.Trace(), nil
+}
+
+// ProfileBlock re-executes the block with the given hash on top of the state of its parent
+// and returns the host function calls made by the runtime, by runtime entrypoint.
+// The block is executed by a dedicated runtime instance and all storage changes are discarded.
+func (s *Service) ProfileBlock(hash common.Hash) (runtime.Profile, error) {
+	block, err := s.blockState.GetBlockByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get block %s: %w", hash, err)
+	}
+
+	if block.Header.Number == 0 {
+		return nil, ErrCannotProfileGenesis
+	}
+
+	ts, err := s.parentTrieState(block)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := s.newDedicatedInstance(block.Header.ParentHash, ts, ts.LoadCode(), s.newInstance)
+	if err != nil {
+		return nil, err
+	}
+	defer instance.Stop()
+
+	profileInstance, ok := instance.(runtime.ProfilableInstance)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errInstanceNotProfilable, instance)
+	}
+
+	// the profiler publishing metrics, if any, is replaced so
+	// the re-execution is not mixed up with the block imports.
+	profiler := runtime.NewProfiler(false)
+	profileInstance.SetProfiler(profiler)
+
+	_, err = profileInstance.ExecuteBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute block: %w", err)
+	}
+
+	return profiler.Profile(), nil
+}
+
+// parentTrieState returns the state of the parent of the given block, for the block to be re-executed.
+func (s *Service) parentTrieState(block *types.Block) (*rtstorage.TrieState, error) {
+	parentHash := block.Header.ParentHash
+	parent, err := s.blockState.GetHeader(parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header of block %s: %w", parentHash, err)
+	}
+
+	ts, err := s.storageState.TrieState(&parent.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state: %w", err)
+	}
+
+	return ts, nil
+}
+
+// newDedicatedInstance creates a new runtime instance from the given code, configured
+// like the runtime of the given block, so the runtime calls made by the caller
+// cannot interfere with the ones made for block production and import.
+func (s *Service) newDedicatedInstance(blockHash common.Hash, storage runtime.Storage,
+	code []byte, newInstance runtime.NewInstanceFunc) (runtime.Instance, error) {
+	rt, err := s.blockState.GetRuntime(&blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runtime: %w", err)
+	}
+
+	cfg := runtime.InstanceConfig{
+		Storage:     storage,
+		Keystore:    rt.Keystore(),
+		NodeStorage: rt.NodeStorage(),
+		Network:     rt.NetworkService(),
+	}
+
+	if rt.Validator() {
+		cfg.Role = 4
+	}
+
+	next, err := newInstance(code, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create runtime instance: %w", err)
+	}
+
+	return next, nil
+}
+
 // QueryStorage returns the key-value data by block based on `keys` params
 // on every block starting `from` until `to` block, if `to` is not nil
 func (s *Service) QueryStorage(from, to common.Hash, keys ...string) (map[common.Hash]QueryKeyValueChanges, error) {
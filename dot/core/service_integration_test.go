@@ -6,6 +6,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math/big"
 	"os"
@@ -227,7 +228,8 @@ func TestService_InsertKey(t *testing.T) {
 		{
 			description:  "Test that insertKey fails when keystore type is invalid ",
 			keystoreType: "some-invalid-type",
-			err:          keystore.ErrInvalidKeystoreName,
+			err: fmt.Errorf("%w: %q is not 4 bytes long",
+				keystore.ErrInvalidKeystoreName, "some-invalid-type"),
 		},
 		{
 			description:  "Test that insertKey fails when keystore type is valid but inappropriate",
@@ -299,7 +301,7 @@ func TestService_HasKey_UnknownType(t *testing.T) {
 
 	s := NewTestService(t, cfg)
 	res, err := s.HasKey(kr.Alice().Public().Hex(), "xxxx")
-	require.EqualError(t, err, "invalid keystore name")
+	require.EqualError(t, err, "unknown key type: xxxx")
 	require.False(t, res)
 }
 
@@ -416,7 +418,7 @@ func TestHandleChainReorg_WithReorg_Transactions(t *testing.T) {
 	rt, err := s.blockState.GetRuntime(&bhash)
 	require.NoError(t, err)
 
-	validity, err := rt.ValidateTransaction(tx)
+	validity, err := rt.ValidateTransaction(tx, bhash)
 	require.NoError(t, err)
 
 	// get common ancestor
@@ -606,6 +608,67 @@ func TestService_HandleSubmittedExtrinsic(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestService_DryRun(t *testing.T) {
+	s := NewTestService(t, nil)
+
+	genHeader, err := s.blockState.BestBlockHeader()
+	require.NoError(t, err)
+
+	rt, err := s.blockState.GetRuntime(nil)
+	require.NoError(t, err)
+
+	ts, err := s.storageState.TrieState(nil)
+	require.NoError(t, err)
+	rt.SetContextStorage(ts)
+
+	block := sync.BuildBlock(t, rt, genHeader, nil)
+
+	err = s.handleBlock(block, ts)
+	require.NoError(t, err)
+
+	rootBefore, err := s.storageState.GetStateRootFromBlock(nil)
+	require.NoError(t, err)
+
+	extBytes := createExtrinsic(t, rt, genHeader.Hash(), 0)
+
+	res, err := s.DryRun(extBytes, nil)
+	require.NoError(t, err)
+	// Ok(Ok(()))
+	require.Equal(t, []byte{0, 0}, res)
+
+	rootAfter, err := s.storageState.GetStateRootFromBlock(nil)
+	require.NoError(t, err)
+	require.Equal(t, rootBefore, rootAfter)
+}
+
+func TestService_TraceBlock(t *testing.T) {
+	s := NewTestService(t, nil)
+
+	genHeader, err := s.blockState.BestBlockHeader()
+	require.NoError(t, err)
+
+	rt, err := s.blockState.GetRuntime(nil)
+	require.NoError(t, err)
+
+	ts, err := s.storageState.TrieState(nil)
+	require.NoError(t, err)
+	rt.SetContextStorage(ts)
+
+	block := sync.BuildBlock(t, rt, genHeader, nil)
+
+	err = s.handleBlock(block, ts)
+	require.NoError(t, err)
+
+	trace, err := s.TraceBlock(block.Header.Hash(), rtstorage.TraceFilter{
+		Operations: []string{rtstorage.OperationSet},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, trace.Events)
+	for _, event := range trace.Events {
+		require.Equal(t, rtstorage.OperationSet, event.Operation)
+	}
+}
+
 func TestService_GetMetadata(t *testing.T) {
 	s := NewTestService(t, nil)
 	res, err := s.GetMetadata(nil)
@@ -693,6 +756,35 @@ func TestService_HandleRuntimeChanges(t *testing.T) {
 	require.Equal(t, v.SpecVersion(), updatedSpecVersion)
 }
 
+func TestService_HandleRuntimeChanges_heapPages(t *testing.T) {
+	s := NewTestService(t, nil)
+
+	hash := s.blockState.BestBlockHash()
+	parentRt, err := s.blockState.GetRuntime(&hash)
+	require.NoError(t, err)
+	require.Equal(t, runtime.DefaultHeapPages, parentRt.GetHeapPages())
+
+	ts, err := s.storageState.TrieState(nil)
+	require.NoError(t, err)
+
+	heapPages := make([]byte, 8)
+	binary.LittleEndian.PutUint64(heapPages, 64)
+	ts.Set(common.HeapPagesKey, heapPages)
+
+	blockHash := common.Hash{1}
+	err = s.blockState.HandleRuntimeChanges(ts, parentRt, blockHash)
+	require.NoError(t, err)
+
+	rt, err := s.blockState.GetRuntime(&blockHash)
+	require.NoError(t, err)
+	require.NotSame(t, parentRt, rt)
+	require.Equal(t, parentRt.GetCodeHash(), rt.GetCodeHash())
+	require.Equal(t, uint64(64), rt.GetHeapPages())
+
+	_, err = rt.Version()
+	require.NoError(t, err)
+}
+
 func TestService_HandleCodeSubstitutes(t *testing.T) {
 	s := NewTestService(t, nil)
 
@@ -713,7 +805,7 @@ func TestService_HandleCodeSubstitutes(t *testing.T) {
 	ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = s.handleCodeSubstitution(blockHash, ts, wasmer.NewInstance)
+	err = s.handleCodeSubstitution(blockHash, ts)
 	require.NoError(t, err)
 	codSub := s.codeSubstitutedState.LoadCodeSubstitutedBlockHash()
 	require.Equal(t, blockHash, codSub)
@@ -742,7 +834,7 @@ func TestService_HandleRuntimeChangesAfterCodeSubstitutes(t *testing.T) {
 	ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = s.handleCodeSubstitution(blockHash, ts, wasmer.NewInstance)
+	err = s.handleCodeSubstitution(blockHash, ts)
 	require.NoError(t, err)
 	require.Equal(t, codeHashBefore, parentRt.GetCodeHash()) // codeHash should remain unchanged after code substitute
 
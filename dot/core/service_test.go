@@ -30,6 +30,7 @@
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -177,22 +178,28 @@ func Test_Service_StorageRoot(t *testing.T) {
 
 func Test_Service_handleCodeSubstitution(t *testing.T) {
 	t.Parallel()
-	newTestInstance := func(code []byte, cfg *wasmer.Config) (*wasmer.Instance, error) {
+	newTestInstance := func(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
 		return &wasmer.Instance{}, nil
 	}
 
 	execTest := func(t *testing.T, s *Service, blockHash common.Hash, expErr error) {
-		err := s.handleCodeSubstitution(blockHash, nil, newTestInstance)
+		err := s.handleCodeSubstitution(blockHash, nil)
 		assert.ErrorIs(t, err, expErr)
 		if expErr != nil {
 			assert.EqualError(t, err, errTestDummyError.Error())
 		}
 	}
 	testRuntime := []byte{21}
+	apiItems := make([]runtime.APIItem, len(runtime.RequiredAPIs))
+	for i, api := range runtime.RequiredAPIs {
+		apiItems[i] = runtime.APIItem{Name: runtime.APIID(api.Name), Ver: api.MinVersion}
+	}
+	testVersion := runtime.NewVersionData([]byte("node"), nil, 0, 1, 0, apiItems, 0)
+
 	t.Run("nil value", func(t *testing.T) {
 		t.Parallel()
 		service := &Service{codeSubstitute: map[common.Hash]string{}}
-		err := service.handleCodeSubstitution(common.Hash{}, nil, newTestInstance)
+		err := service.handleCodeSubstitution(common.Hash{}, nil)
 		assert.NoError(t, err)
 	})
 
@@ -214,6 +221,31 @@ func Test_Service_handleCodeSubstitution(t *testing.T) {
 		execTest(t, service, blockHash, errTestDummyError)
 	})
 
+	t.Run("runtime API missing", func(t *testing.T) {
+		t.Parallel()
+		// hash for known test code substitution
+		blockHash := common.MustHexToHash("0x86aa36a140dfc449c30dbce16ce0fea33d5c3786766baa764e33f336841b9e29")
+		testCodeSubstitute := map[common.Hash]string{
+			blockHash: common.BytesToHex(testRuntime),
+		}
+
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("CheckRuntimeVersion", testRuntime).
+			Return(runtime.NewVersionData([]byte("node"), nil, 0, 1, 0, apiItems[1:], 0), nil)
+
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntime(&blockHash).Return(runtimeMock, nil)
+		service := &Service{
+			codeSubstitute: testCodeSubstitute,
+			blockState:     mockBlockState,
+		}
+		err := service.handleCodeSubstitution(blockHash, nil)
+		assert.ErrorIs(t, err, runtime.ErrAPIMissing)
+		assert.EqualError(t, err, "cannot substitute runtime code: runtime node version 1: "+
+			"runtime API not implemented: Core")
+	})
+
 	t.Run("code substitute error", func(t *testing.T) {
 		t.Parallel()
 		// hash for known test code substitution
@@ -227,6 +259,7 @@ func Test_Service_handleCodeSubstitution(t *testing.T) {
 		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
 		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
 		runtimeMock.On("Validator").Return(true)
+		runtimeMock.On("CheckRuntimeVersion", testRuntime).Return(testVersion, nil)
 
 		ctrl := gomock.NewController(t)
 		mockBlockState := NewMockBlockState(ctrl)
@@ -237,6 +270,7 @@ func Test_Service_handleCodeSubstitution(t *testing.T) {
 			codeSubstitute:       testCodeSubstitute,
 			blockState:           mockBlockState,
 			codeSubstitutedState: mockCodeSubState,
+			newInstance:          newTestInstance,
 		}
 		execTest(t, service, blockHash, errTestDummyError)
 	})
@@ -254,6 +288,7 @@ func Test_Service_handleCodeSubstitution(t *testing.T) {
 		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
 		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
 		runtimeMock.On("Validator").Return(true)
+		runtimeMock.On("CheckRuntimeVersion", testRuntime).Return(testVersion, nil)
 
 		ctrl := gomock.NewController(t)
 		mockBlockState := NewMockBlockState(ctrl)
@@ -265,12 +300,93 @@ func Test_Service_handleCodeSubstitution(t *testing.T) {
 			codeSubstitute:       testCodeSubstitute,
 			blockState:           mockBlockState,
 			codeSubstitutedState: mockCodeSubState,
+			newInstance:          newTestInstance,
 		}
-		err := service.handleCodeSubstitution(blockHash, nil, newTestInstance)
+		err := service.handleCodeSubstitution(blockHash, nil)
 		assert.NoError(t, err)
 	})
 }
 
+func Test_Service_precompileCodeChange(t *testing.T) {
+	t.Parallel()
+
+	parentCode := []byte{1}
+	parentCodeHash := common.MustBlake2bHash(parentCode)
+
+	newTrieState := func(t *testing.T, code []byte) *rtstorage.TrieState {
+		t.Helper()
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+		trieState.Set(common.CodeKey, code)
+		return trieState
+	}
+
+	testCases := map[string]struct {
+		code            []byte
+		getRuntimeErr   error
+		precompiledCode []byte
+	}{
+		"same code": {
+			code: parentCode,
+		},
+		"parent runtime error": {
+			code:          []byte{2},
+			getRuntimeErr: errTestDummyError,
+		},
+		"code change": {
+			code:            []byte{2},
+			precompiledCode: []byte{2},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			block := types.NewBlock(*types.NewEmptyHeader(), *types.NewBody(nil))
+			block.Header.ParentHash = common.Hash{1}
+
+			runtimeMock := new(mocksruntime.Instance)
+			runtimeMock.On("GetCodeHash").Return(parentCodeHash)
+
+			ctrl := gomock.NewController(t)
+			mockBlockState := NewMockBlockState(ctrl)
+			if testCase.getRuntimeErr != nil {
+				mockBlockState.EXPECT().GetRuntime(&block.Header.ParentHash).
+					Return(nil, testCase.getRuntimeErr)
+			} else {
+				mockBlockState.EXPECT().GetRuntime(&block.Header.ParentHash).Return(runtimeMock, nil)
+			}
+
+			precompiled := make(chan []byte, 1)
+			service := &Service{
+				blockState: mockBlockState,
+				precompile: func(code []byte) error {
+					precompiled <- code
+					return nil
+				},
+			}
+
+			service.precompileCodeChange(&block, newTrieState(t, testCase.code))
+
+			if testCase.precompiledCode == nil {
+				assert.Empty(t, precompiled)
+				return
+			}
+			assert.Equal(t, testCase.precompiledCode, <-precompiled)
+		})
+	}
+
+	t.Run("precompile not supported", func(t *testing.T) {
+		t.Parallel()
+
+		block := types.NewBlock(*types.NewEmptyHeader(), *types.NewBody(nil))
+		service := &Service{}
+		service.precompileCodeChange(&block, newTrieState(t, []byte{2}))
+	})
+}
+
 func Test_Service_handleBlock(t *testing.T) {
 	t.Parallel()
 
@@ -376,6 +492,33 @@ func Test_Service_handleBlock(t *testing.T) {
 		execTest(t, service, &block, trieState, errTestDummyError)
 	})
 
+	t.Run("add indexed transactions error", func(t *testing.T) {
+		t.Parallel()
+		emptyTrie := trie.NewEmptyTrie()
+		trieState, err := rtstorage.NewTrieState(emptyTrie)
+		require.NoError(t, err)
+		trieState.IndexTransaction(0, common.Hash{1}, 1)
+
+		testHeader := types.NewEmptyHeader()
+		block := types.NewBlock(*testHeader, *types.NewBody([]types.Extrinsic{[]byte{21}}))
+		block.Header.Number = 21
+
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().StoreTrie(trieState, &block.Header).Return(nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().AddBlock(&block).Return(nil)
+		mockBlockState.EXPECT().AddIndexedTransactions(&block, trieState.IndexOperations()).
+			Return(errTestDummyError)
+
+		service := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+		err = service.handleBlock(&block, trieState)
+		assert.ErrorIs(t, err, errTestDummyError)
+	})
+
 	t.Run("handle runtime changes error", func(t *testing.T) {
 		t.Parallel()
 		emptyTrie := trie.NewEmptyTrie()
@@ -517,17 +660,25 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		runtimeMock := new(mocksruntime.Instance)
-		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).Return(nil, errTestDummyError)
+		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
+		// the transactions in the pool are re-validated as external transactions.
+		externalExt := types.Extrinsic{byte(types.TxnExternal), 21}
+		runtimeMock.On("ValidateTransaction", externalExt, common.Hash{}).Return(nil, errTestDummyError)
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21}).Times(2)
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{})
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil)
 		service := &Service{
 			transactionState: mockTxnState,
+			storageState:     mockStorageState,
 			blockState:       mockBlockState,
 		}
 		service.maintainTransactionPool(&block)
+		runtimeMock.AssertExpectations(t)
 	})
 
 	t.Run("Validate Transaction ok", func(t *testing.T) {
@@ -552,20 +703,27 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		runtimeMock := new(mocksruntime.Instance)
-		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).
+		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
+		externalExt := types.Extrinsic{byte(types.TxnExternal), 21}
+		runtimeMock.On("ValidateTransaction", externalExt, common.Hash{}).
 			Return(&transaction.Validity{Propagate: true}, nil)
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21})
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
 		mockTxnState.EXPECT().Push(tx).Return(common.Hash{}, nil)
 		mockTxnState.EXPECT().RemoveExtrinsicFromPool(types.Extrinsic{21})
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockStateOk := NewMockBlockState(ctrl)
-		mockBlockStateOk.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		mockBlockStateOk.EXPECT().BestBlockHash().Return(common.Hash{})
+		mockBlockStateOk.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil)
 		service := &Service{
 			transactionState: mockTxnState,
+			storageState:     mockStorageState,
 			blockState:       mockBlockStateOk,
 		}
 		service.maintainTransactionPool(&block)
+		runtimeMock.AssertExpectations(t)
 	})
 }
 
@@ -641,12 +799,16 @@ func Test_Service_handleBlocksAsync(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		runtimeMock := new(mocksruntime.Instance)
-		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).Return(nil, errTestDummyError)
+		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
+		runtimeMock.On("ValidateTransaction", types.Extrinsic{byte(types.TxnExternal), 21}, common.Hash{}).
+			Return(nil, errTestDummyError)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{}).Times(2)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{}).Times(3)
 		mockBlockState.EXPECT().HighestCommonAncestor(common.Hash{}, block.Header.Hash()).
 			Return(common.Hash{}, errTestDummyError)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil)
 		mockTxnStateErr := NewMockTransactionState(ctrl)
 		mockTxnStateErr.EXPECT().RemoveExtrinsic(types.Extrinsic{21}).Times(2)
 		mockTxnStateErr.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
@@ -657,6 +819,7 @@ func Test_Service_handleBlocksAsync(t *testing.T) {
 		}()
 		service := &Service{
 			blockState:       mockBlockState,
+			storageState:     mockStorageState,
 			transactionState: mockTxnStateErr,
 			blockAddCh:       blockAddChan,
 			ctx:              context.Background(),
@@ -759,10 +922,14 @@ func TestService_handleChainReorg(t *testing.T) {
 		mockBlockState.EXPECT().HighestCommonAncestor(testPrevHash, testCurrentHash).
 			Return(testAncestorHash, nil)
 		mockBlockState.EXPECT().SubChain(testAncestorHash, testPrevHash).Return(testSubChain, nil)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(nil, errDummyErr)
+		mockBlockState.EXPECT().BestBlockHash().Return(testCurrentHash)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(nil, errDummyErr)
 
 		service := &Service{
-			blockState: mockBlockState,
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
 		}
 		execTest(t, service, testPrevHash, testCurrentHash, errDummyErr)
 	})
@@ -775,13 +942,18 @@ func TestService_handleChainReorg(t *testing.T) {
 		mockBlockState.EXPECT().HighestCommonAncestor(testPrevHash, testCurrentHash).
 			Return(testAncestorHash, nil)
 		mockBlockState.EXPECT().SubChain(testAncestorHash, testPrevHash).Return(testSubChain, nil)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMockErr, nil)
+		mockBlockState.EXPECT().BestBlockHash().Return(testCurrentHash)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMockErr, 1), nil)
 		mockBlockState.EXPECT().GetBlockBody(testCurrentHash).Return(nil, errDummyErr)
 		mockBlockState.EXPECT().GetBlockBody(testAncestorHash).Return(body, nil)
-		runtimeMockErr.On("ValidateTransaction", externExt).Return(nil, errTestDummyError)
+		runtimeMockErr.On("SetContextStorage", &rtstorage.TrieState{})
+		runtimeMockErr.On("ValidateTransaction", externExt, testCurrentHash).Return(nil, errTestDummyError)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 
 		service := &Service{
-			blockState: mockBlockState,
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
 		}
 		execTest(t, service, testPrevHash, testCurrentHash, nil)
 	})
@@ -794,16 +966,21 @@ func TestService_handleChainReorg(t *testing.T) {
 		mockBlockState.EXPECT().HighestCommonAncestor(testPrevHash, testCurrentHash).
 			Return(testAncestorHash, nil)
 		mockBlockState.EXPECT().SubChain(testAncestorHash, testPrevHash).Return(testSubChain, nil)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMockOk, nil)
+		mockBlockState.EXPECT().BestBlockHash().Return(testCurrentHash)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMockOk, 1), nil)
 		mockBlockState.EXPECT().GetBlockBody(testCurrentHash).Return(nil, errDummyErr)
 		mockBlockState.EXPECT().GetBlockBody(testAncestorHash).Return(body, nil)
-		runtimeMockOk.On("ValidateTransaction", externExt).
+		runtimeMockOk.On("SetContextStorage", &rtstorage.TrieState{})
+		runtimeMockOk.On("ValidateTransaction", externExt, testCurrentHash).
 			Return(testValidity, nil)
 		mockTxnStateOk := NewMockTransactionState(ctrl)
 		mockTxnStateOk.EXPECT().AddToPool(vtx).Return(common.Hash{})
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 
 		service := &Service{
 			blockState:       mockBlockState,
+			storageState:     mockStorageState,
 			transactionState: mockTxnStateOk,
 		}
 		execTest(t, service, testPrevHash, testCurrentHash, nil)
@@ -846,10 +1023,10 @@ type args struct {
 			},
 			args: args{
 				kp:           aliceKeypair,
-				keystoreType: "test",
+				keystoreType: "invalid",
 			},
 			expErr:    keystore.ErrInvalidKeystoreName,
-			expErrMsg: keystore.ErrInvalidKeystoreName.Error(),
+			expErrMsg: `invalid keystore name: "invalid" is not 4 bytes long`,
 		},
 	}
 	for _, tt := range tests {
@@ -902,10 +1079,10 @@ type args struct {
 			},
 			args: args{
 				pubKeyStr:    aliceKeypair.Public().Hex(),
-				keystoreType: "test",
+				keystoreType: "invalid",
 			},
 			expErr:    keystore.ErrInvalidKeystoreName,
-			expErrMsg: keystore.ErrInvalidKeystoreName.Error(),
+			expErrMsg: `invalid keystore name: "invalid" is not 4 bytes long`,
 		},
 	}
 	for _, tt := range tests {
@@ -938,11 +1115,15 @@ func TestService_DecodeSessionKeys(t *testing.T) {
 		t.Parallel()
 		ctrl := gomock.NewController(t)
 		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
 		runtimeMock.On("DecodeSessionKeys", testEncKeys).Return(testEncKeys, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil)
 		service := &Service{
-			blockState: mockBlockState,
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
 		}
 		execTest(t, service, testEncKeys, testEncKeys, nil)
 	})
@@ -950,15 +1131,73 @@ func TestService_DecodeSessionKeys(t *testing.T) {
 	t.Run("err case", func(t *testing.T) {
 		t.Parallel()
 		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(nil, errDummyErr)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(nil, errDummyErr)
 		service := &Service{
-			blockState: mockBlockState,
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
 		}
 		execTest(t, service, testEncKeys, nil, errDummyErr)
 	})
 }
 
+func TestService_GenerateSessionKeys(t *testing.T) {
+	t.Parallel()
+	testKeys := []byte{1, 2, 3, 4}
+	execTest := func(t *testing.T, s *Service, exp []byte, expErr error) {
+		res, err := s.GenerateSessionKeys()
+		assert.ErrorIs(t, err, expErr)
+		if expErr != nil {
+			assert.EqualError(t, err, expErr.Error())
+		}
+		assert.Equal(t, exp, res)
+	}
+
+	t.Run("ok case", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
+		runtimeMock.On("GenerateSessionKeys", (*[]byte)(nil)).Return(testKeys, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil)
+		service := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+		execTest(t, service, testKeys, nil)
+	})
+
+	t.Run("trie state err", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(nil, errDummyErr)
+		service := &Service{
+			storageState: mockStorageState,
+		}
+		execTest(t, service, nil, errDummyErr)
+	})
+
+	t.Run("runtime pool err", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(nil, errDummyErr)
+		service := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+		execTest(t, service, nil, errDummyErr)
+	})
+}
+
 func TestServiceGetRuntimeVersion(t *testing.T) {
 	t.Parallel()
 	testAPIItem := runtime.APIItem{
@@ -1018,7 +1257,7 @@ func TestServiceGetRuntimeVersion(t *testing.T) {
 		mockStorageState.EXPECT().TrieState(&common.Hash{}).Return(ts, nil).MaxTimes(2)
 
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(&common.Hash{}).Return(nil, errDummyErr)
+		mockBlockState.EXPECT().GetRuntimePool(&common.Hash{}).Return(nil, errDummyErr)
 		service := &Service{
 			storageState: mockStorageState,
 			blockState:   mockBlockState,
@@ -1035,7 +1274,7 @@ func TestServiceGetRuntimeVersion(t *testing.T) {
 
 		runtimeMock := new(mocksruntime.Instance)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(&common.Hash{}).Return(runtimeMock, nil)
+		mockBlockState.EXPECT().GetRuntimePool(&common.Hash{}).Return(runtime.NewPool(runtimeMock, 1), nil)
 		runtimeMock.On("SetContextStorage", ts)
 		runtimeMock.On("Version").Return(rv, nil)
 		service := &Service{
@@ -1085,7 +1324,8 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 		mockStorageState := NewMockStorageState(ctrl)
 		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(nil, errDummyErr)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{})
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(nil, errDummyErr)
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(nil).MaxTimes(2)
 		service := &Service{
@@ -1106,9 +1346,10 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 		mockTxnState.EXPECT().Exists(types.Extrinsic{})
 		runtimeMockErr := new(mocksruntime.Instance)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMockErr, nil).MaxTimes(2)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{})
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMockErr, 1), nil).MaxTimes(2)
 		runtimeMockErr.On("SetContextStorage", &rtstorage.TrieState{})
-		runtimeMockErr.On("ValidateTransaction", externalExt).Return(nil, errDummyErr)
+		runtimeMockErr.On("ValidateTransaction", externalExt, common.Hash{}).Return(nil, errDummyErr)
 		service := &Service{
 			storageState:     mockStorageState,
 			transactionState: mockTxnState,
@@ -1125,9 +1366,10 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		runtimeMock := new(mocksruntime.Instance)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil).MaxTimes(2)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{})
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMock, 1), nil).MaxTimes(2)
 		runtimeMock.On("SetContextStorage", &rtstorage.TrieState{})
-		runtimeMock.On("ValidateTransaction", externalExt).
+		runtimeMock.On("ValidateTransaction", externalExt, common.Hash{}).
 			Return(&transaction.Validity{Propagate: true}, nil)
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(types.Extrinsic{}).MaxTimes(2)
@@ -1183,7 +1425,7 @@ func TestServiceGetMetadata(t *testing.T) {
 		mockStorageState := NewMockStorageState(ctrl)
 		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(nil, errDummyErr)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(nil, errDummyErr)
 		service := &Service{
 			storageState: mockStorageState,
 			blockState:   mockBlockState,
@@ -1198,7 +1440,7 @@ func TestServiceGetMetadata(t *testing.T) {
 		mockStorageState.EXPECT().TrieState(nil).Return(&rtstorage.TrieState{}, nil)
 		runtimeMockOk := new(mocksruntime.Instance)
 		mockBlockState := NewMockBlockState(ctrl)
-		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMockOk, nil)
+		mockBlockState.EXPECT().GetRuntimePool(nil).Return(runtime.NewPool(runtimeMockOk, 1), nil)
 		runtimeMockOk.On("SetContextStorage", &rtstorage.TrieState{})
 		runtimeMockOk.On("Metadata").Return([]byte{1, 2, 3}, nil)
 		service := &Service{
@@ -1305,6 +1547,342 @@ func TestService_QueryStorage(t *testing.T) {
 	})
 }
 
+func TestService_dryRun(t *testing.T) {
+	t.Parallel()
+
+	ext := types.Extrinsic{1, 2, 3}
+	parentHeader := &types.Header{
+		Number:    1,
+		StateRoot: common.Hash{3},
+	}
+
+	t.Run("get header error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{2})
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(nil, errDummyErr)
+		service := &Service{
+			blockState: mockBlockState,
+		}
+
+		_, err := service.DryRun(ext, nil)
+		assert.ErrorIs(t, err, errDummyErr)
+		assert.EqualError(t, err, "cannot get header of block "+
+			"0x0200000000000000000000000000000000000000000000000000000000000000: dummy error for testing")
+	})
+
+	t.Run("trie state error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{2})
+		mockBlockState.EXPECT().GetHeader(common.Hash{1}).Return(parentHeader, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(nil, errDummyErr)
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+		}
+
+		_, err := service.DryRun(ext, &common.Hash{1})
+		assert.ErrorIs(t, err, errDummyErr)
+		assert.EqualError(t, err, "cannot get trie state: dummy error for testing")
+	})
+
+	t.Run("get runtime error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{2})
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockBlockState.EXPECT().GetRuntime(&common.Hash{2}).Return(nil, errDummyErr)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(trieState, nil)
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+		}
+
+		_, err = service.DryRun(ext, nil)
+		assert.ErrorIs(t, err, errDummyErr)
+		assert.EqualError(t, err, "cannot get runtime: dummy error for testing")
+	})
+
+	t.Run("create instance error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(&keystore.GlobalKeystore{})
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(false)
+
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{2})
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockBlockState.EXPECT().GetRuntime(&common.Hash{2}).Return(runtimeMock, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(trieState, nil)
+		newTestInstance := func(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+			assert.Equal(t, trieState, cfg.Storage)
+			assert.Equal(t, byte(0), cfg.Role)
+			return nil, errTestDummyError
+		}
+
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+			newInstance:  newTestInstance,
+		}
+
+		_, err = service.DryRun(ext, nil)
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.EqualError(t, err, "cannot create runtime instance: test dummy error")
+	})
+}
+
+func TestService_traceBlock(t *testing.T) {
+	t.Parallel()
+
+	blockHash := common.Hash{1}
+	block := &types.Block{
+		Header: types.Header{
+			ParentHash: common.Hash{2},
+			Number:     2,
+		},
+	}
+	parentHeader := &types.Header{
+		Number:    1,
+		StateRoot: common.Hash{3},
+	}
+
+	t.Run("get block error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(nil, errDummyErr)
+		service := &Service{
+			blockState: mockBlockState,
+		}
+
+		_, err := service.TraceBlock(blockHash, rtstorage.TraceFilter{})
+		assert.ErrorIs(t, err, errDummyErr)
+	})
+
+	t.Run("genesis block error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(&types.Block{}, nil)
+		service := &Service{
+			blockState: mockBlockState,
+		}
+
+		_, err := service.TraceBlock(blockHash, rtstorage.TraceFilter{})
+		assert.ErrorIs(t, err, ErrCannotTraceGenesis)
+	})
+
+	t.Run("trie state error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(nil, errDummyErr)
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+		}
+
+		_, err := service.TraceBlock(blockHash, rtstorage.TraceFilter{})
+		assert.ErrorIs(t, err, errDummyErr)
+		assert.EqualError(t, err, "cannot get trie state: dummy error for testing")
+	})
+
+	t.Run("create instance error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(&keystore.GlobalKeystore{})
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(false)
+
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockBlockState.EXPECT().GetRuntime(&common.Hash{2}).Return(runtimeMock, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(trieState, nil)
+		newTestInstance := func(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+			assert.IsType(t, &rtstorage.TracingStorage{}, cfg.Storage)
+			return nil, errTestDummyError
+		}
+
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+			newInstance:  newTestInstance,
+		}
+
+		_, err = service.TraceBlock(blockHash, rtstorage.TraceFilter{})
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.EqualError(t, err, "cannot create runtime instance: test dummy error")
+	})
+}
+
+func TestService_profileBlock(t *testing.T) {
+	t.Parallel()
+
+	blockHash := common.Hash{1}
+	block := &types.Block{
+		Header: types.Header{
+			ParentHash: common.Hash{2},
+			Number:     2,
+		},
+	}
+	parentHeader := &types.Header{
+		Number:    1,
+		StateRoot: common.Hash{3},
+	}
+
+	t.Run("genesis block error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(&types.Block{}, nil)
+		service := &Service{
+			blockState: mockBlockState,
+		}
+
+		_, err := service.ProfileBlock(blockHash)
+		assert.ErrorIs(t, err, ErrCannotProfileGenesis)
+	})
+
+	t.Run("get parent header error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(nil, errDummyErr)
+		service := &Service{
+			blockState: mockBlockState,
+		}
+
+		_, err := service.ProfileBlock(blockHash)
+		assert.ErrorIs(t, err, errDummyErr)
+	})
+
+	t.Run("create instance error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(&keystore.GlobalKeystore{})
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(false)
+
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockBlockState.EXPECT().GetRuntime(&common.Hash{2}).Return(runtimeMock, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(trieState, nil)
+		newTestInstance := func(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+			assert.Equal(t, trieState, cfg.Storage)
+			return nil, errTestDummyError
+		}
+		service := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+			newInstance:  newTestInstance,
+		}
+
+		_, err = service.ProfileBlock(blockHash)
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.EqualError(t, err, "cannot create runtime instance: test dummy error")
+	})
+
+	newParentRuntime := func() *mocksruntime.Instance {
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(&keystore.GlobalKeystore{})
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(false)
+		return runtimeMock
+	}
+
+	newService := func(t *testing.T, instance runtime.Instance) *Service {
+		t.Helper()
+		ctrl := gomock.NewController(t)
+		trieState, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(parentHeader, nil)
+		mockBlockState.EXPECT().GetRuntime(&common.Hash{2}).Return(newParentRuntime(), nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&common.Hash{3}).Return(trieState, nil)
+		return &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+			newInstance: func(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+				return instance, nil
+			},
+		}
+	}
+
+	t.Run("instance not profilable", func(t *testing.T) {
+		t.Parallel()
+		instance := new(mocksruntime.Instance)
+		instance.On("Stop").Return()
+
+		_, err := newService(t, instance).ProfileBlock(blockHash)
+		assert.ErrorIs(t, err, errInstanceNotProfilable)
+		instance.AssertExpectations(t)
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		instance := &testProfilableInstance{Instance: new(mocksruntime.Instance)}
+		instance.On("Stop").Return()
+		instance.On("ExecuteBlock", block).Return(nil, nil).Run(func(mock.Arguments) {
+			instance.profiler.StartEntrypoint(runtime.CoreExecuteBlock)
+			instance.profiler.StartHostFunction("ext_storage_get_version_1")
+			instance.profiler.EndHostFunction()
+		})
+
+		profile, err := newService(t, instance).ProfileBlock(blockHash)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), profile[runtime.CoreExecuteBlock]["ext_storage_get_version_1"].Calls)
+		instance.AssertExpectations(t)
+	})
+}
+
+// testProfilableInstance is a runtime instance mock implementing runtime.ProfilableInstance.
+type testProfilableInstance struct {
+	*mocksruntime.Instance
+	profiler *runtime.Profiler
+}
+
+func (in *testProfilableInstance) SetProfiler(profiler *runtime.Profiler) {
+	in.profiler = profiler
+}
+
 func TestService_GetReadProofAt(t *testing.T) {
 	t.Parallel()
 	execTest := func(t *testing.T, s *Service, block common.Hash, keys [][]byte,
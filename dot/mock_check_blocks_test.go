@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: check_blocks.go
+
+// Package dot is a generated GoMock package.
+package dot
+
+import (
+	reflect "reflect"
+
+	types "github.com/ChainSafe/gossamer/dot/types"
+	common "github.com/ChainSafe/gossamer/lib/common"
+	runtime "github.com/ChainSafe/gossamer/lib/runtime"
+	storage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockcheckerBlockState is a mock of checkerBlockState interface.
+type MockcheckerBlockState struct {
+	ctrl     *gomock.Controller
+	recorder *MockcheckerBlockStateMockRecorder
+}
+
+// MockcheckerBlockStateMockRecorder is the mock recorder for MockcheckerBlockState.
+type MockcheckerBlockStateMockRecorder struct {
+	mock *MockcheckerBlockState
+}
+
+// NewMockcheckerBlockState creates a new mock instance.
+func NewMockcheckerBlockState(ctrl *gomock.Controller) *MockcheckerBlockState {
+	mock := &MockcheckerBlockState{ctrl: ctrl}
+	mock.recorder = &MockcheckerBlockStateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcheckerBlockState) EXPECT() *MockcheckerBlockStateMockRecorder {
+	return m.recorder
+}
+
+// GetHeader mocks base method.
+func (m *MockcheckerBlockState) GetHeader(hash common.Hash) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeader", hash)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeader indicates an expected call of GetHeader.
+func (mr *MockcheckerBlockStateMockRecorder) GetHeader(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeader", reflect.TypeOf((*MockcheckerBlockState)(nil).GetHeader), hash)
+}
+
+// GetRuntime mocks base method.
+func (m *MockcheckerBlockState) GetRuntime(hash *common.Hash) (runtime.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuntime", hash)
+	ret0, _ := ret[0].(runtime.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuntime indicates an expected call of GetRuntime.
+func (mr *MockcheckerBlockStateMockRecorder) GetRuntime(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuntime", reflect.TypeOf((*MockcheckerBlockState)(nil).GetRuntime), hash)
+}
+
+// MockcheckerStorageState is a mock of checkerStorageState interface.
+type MockcheckerStorageState struct {
+	ctrl     *gomock.Controller
+	recorder *MockcheckerStorageStateMockRecorder
+}
+
+// MockcheckerStorageStateMockRecorder is the mock recorder for MockcheckerStorageState.
+type MockcheckerStorageStateMockRecorder struct {
+	mock *MockcheckerStorageState
+}
+
+// NewMockcheckerStorageState creates a new mock instance.
+func NewMockcheckerStorageState(ctrl *gomock.Controller) *MockcheckerStorageState {
+	mock := &MockcheckerStorageState{ctrl: ctrl}
+	mock.recorder = &MockcheckerStorageStateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcheckerStorageState) EXPECT() *MockcheckerStorageStateMockRecorder {
+	return m.recorder
+}
+
+// Entries mocks base method.
+func (m *MockcheckerStorageState) Entries(root *common.Hash) (map[string][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Entries", root)
+	ret0, _ := ret[0].(map[string][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Entries indicates an expected call of Entries.
+func (mr *MockcheckerStorageStateMockRecorder) Entries(root interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Entries", reflect.TypeOf((*MockcheckerStorageState)(nil).Entries), root)
+}
+
+// TrieState mocks base method.
+func (m *MockcheckerStorageState) TrieState(root *common.Hash) (*storage.TrieState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrieState", root)
+	ret0, _ := ret[0].(*storage.TrieState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrieState indicates an expected call of TrieState.
+func (mr *MockcheckerStorageStateMockRecorder) TrieState(root interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrieState", reflect.TypeOf((*MockcheckerStorageState)(nil).TrieState), root)
+}
@@ -77,6 +77,18 @@ func (mr *MockSyncerMockRecorder) HandleBlockAnnounceHandshake(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBlockAnnounceHandshake", reflect.TypeOf((*MockSyncer)(nil).HandleBlockAnnounceHandshake), arg0, arg1)
 }
 
+// HandlePeerDisconnect mocks base method.
+func (m *MockSyncer) HandlePeerDisconnect(arg0 peer.ID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandlePeerDisconnect", arg0)
+}
+
+// HandlePeerDisconnect indicates an expected call of HandlePeerDisconnect.
+func (mr *MockSyncerMockRecorder) HandlePeerDisconnect(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePeerDisconnect", reflect.TypeOf((*MockSyncer)(nil).HandlePeerDisconnect), arg0)
+}
+
 // IsSynced mocks base method.
 func (m *MockSyncer) IsSynced() bool {
 	m.ctrl.T.Helper()
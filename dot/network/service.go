@@ -294,6 +294,7 @@ func (s *Service) Start() error {
 			prtl.peersData.deleteInboundHandshakeData(peerID)
 			prtl.peersData.deleteOutboundHandshakeData(peerID)
 		}
+		s.syncer.HandlePeerDisconnect(peerID)
 	}
 
 	// log listening addresses to console
@@ -36,6 +36,9 @@ type Syncer interface {
 
 	// CreateBlockResponse is called upon receipt of a BlockRequestMessage to create the response
 	CreateBlockResponse(*BlockRequestMessage) (*BlockResponseMessage, error)
+
+	// HandlePeerDisconnect is called when the given peer disconnects
+	HandlePeerDisconnect(from peer.ID)
 }
 
 // TransactionHandler is the interface used by the transactions sub-protocol
@@ -19,9 +19,10 @@
 )
 
 // DoBlockRequest sends a request to the given peer.
-// If a response is received within a certain time period, it is returned,
-// otherwise an error is returned.
-func (s *Service) DoBlockRequest(to peer.ID, req *BlockRequestMessage) (*BlockResponseMessage, error) {
+// If a response is received within a certain time period, it is returned
+// with the size in bytes of the encoded response, otherwise an error is returned.
+func (s *Service) DoBlockRequest(to peer.ID, req *BlockRequestMessage) (
+	resp *BlockResponseMessage, size int, err error) {
 	fullSyncID := s.host.protocolID + syncID
 
 	s.host.h.ConnManager().Protect(to, "")
@@ -32,7 +33,7 @@ func (s *Service) DoBlockRequest(to peer.ID, req *BlockRequestMessage) (*BlockRe
 
 	stream, err := s.host.h.NewStream(ctx, to, fullSyncID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer func() {
@@ -40,13 +41,14 @@ func (s *Service) DoBlockRequest(to peer.ID, req *BlockRequestMessage) (*BlockRe
 	}()
 
 	if err = s.host.writeToStream(stream, req); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	return s.receiveBlockResponse(stream)
 }
 
-func (s *Service) receiveBlockResponse(stream libp2pnetwork.Stream) (*BlockResponseMessage, error) {
+func (s *Service) receiveBlockResponse(stream libp2pnetwork.Stream) (
+	msg *BlockResponseMessage, size int, err error) {
 	// allocating a new (large) buffer every time slows down the syncing by a dramatic amount,
 	// as malloc is one of the most CPU intensive tasks.
 	// thus we should allocate buffers at startup and re-use them instead of allocating new ones each time.
@@ -60,24 +62,24 @@ func (s *Service) receiveBlockResponse(stream libp2pnetwork.Stream) (*BlockRespo
 
 	n, err := readStream(stream, &buf)
 	if err != nil {
-		return nil, fmt.Errorf("read stream error: %w", err)
+		return nil, 0, fmt.Errorf("read stream error: %w", err)
 	}
 
 	if n == 0 {
-		return nil, fmt.Errorf("received empty message")
+		return nil, 0, fmt.Errorf("received empty message")
 	}
 
-	msg := new(BlockResponseMessage)
+	msg = new(BlockResponseMessage)
 	err = msg.Decode(buf[:n])
 	if err != nil {
 		s.host.cm.peerSetHandler.ReportPeer(peerset.ReputationChange{
 			Value:  peerset.BadMessageValue,
 			Reason: peerset.BadMessageReason,
 		}, stream.Conn().RemotePeer())
-		return nil, fmt.Errorf("failed to decode block response: %w", err)
+		return nil, 0, fmt.Errorf("failed to decode block response: %w", err)
 	}
 
-	return msg, nil
+	return msg, int(n), nil
 }
 
 // handleSyncStream handles streams with the <protocol-id>/sync/2 protocol ID
@@ -63,6 +63,16 @@
 	// BadJustificationReason is used when peer send invalid justification.
 	BadJustificationReason = "Bad justification"
 
+	// BadBlockResponseValue is used when peer sends an invalid block response.
+	BadBlockResponseValue Reputation = -(1 << 12)
+	// BadBlockResponseReason is used when peer sends an invalid block response.
+	BadBlockResponseReason = "Bad block response"
+
+	// RepeatedSyncFailuresValue is used when peer repeatedly fails our block requests.
+	RepeatedSyncFailuresValue Reputation = -(1 << 14)
+	// RepeatedSyncFailuresReason is used when peer repeatedly fails our block requests.
+	RepeatedSyncFailuresReason = "Repeated sync failures"
+
 	// GenesisMismatch is used when peer has a different genesis
 	GenesisMismatch Reputation = math.MinInt32
 	// GenesisMismatchReason used when a peer has a different genesis
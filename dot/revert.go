@@ -0,0 +1,37 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+)
+
+// RevertBlocks removes the given number of blocks from the head of the best chain of the
+// node, and restores the BABE epoch and GRANDPA authority set state of the new best block.
+// Finalised blocks are only reverted if force is true.
+func RevertBlocks(cfg *Config, blocks uint, force bool) error {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if err := stateSrvc.Stop(); err != nil {
+			logger.Errorf("cannot stop state service: %s", err)
+		}
+	}()
+
+	best, err := stateSrvc.Revert(blocks, force)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("reverted %d blocks, best block is number %d with hash %s",
+		blocks, best.Number, best.Hash())
+	return nil
+}
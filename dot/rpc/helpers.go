@@ -4,12 +4,16 @@
 package rpc
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"strings"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+	"github.com/ChainSafe/gossamer/dot/rpc/subscription"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/rpc/v2"
 	"github.com/jpillora/ipfilter"
@@ -48,7 +52,35 @@ funcName = strings.ToLower(string(funcName[0])) + funcName[1:]
 	return strings.Join([]string{service, funcName}, "_"), nil
 }
 
-func rpcValidator(cfg *HTTPServerConfig, validate *validator.Validate) func(r *rpc.RequestInfo, i interface{}) error {
+// newForwardedCallSecret returns a random secret authenticating the websocket
+// calls forwarded to the HTTP listener by the node itself.
+func newForwardedCallSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("cannot generate forwarded call secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// isForwardedWSCall returns true if the request is a websocket call forwarded
+// to the HTTP listener by the node itself, in which case the websocket
+// listener policy has already been applied. The forwarded call header must
+// hold the secret of the node process, since any client can set the header.
+func isForwardedWSCall(r *rpc.RequestInfo, secret string) bool {
+	header := r.Request.Header.Get(subscription.ForwardedCallHeader)
+	if secret == "" || subtle.ConstantTimeCompare([]byte(header), []byte(secret)) != 1 {
+		return false
+	}
+
+	ip, _, err := net.SplitHostPort(r.Request.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	return LocalhostFilter().Allowed(ip)
+}
+
+func rpcValidator(cfg *HTTPServerConfig, policy *listenerPolicy, forwardedCallSecret string,
+	validate *validator.Validate) func(r *rpc.RequestInfo, i interface{}) error {
 	return func(r *rpc.RequestInfo, v interface{}) error {
 		var (
 			err       error
@@ -64,6 +96,17 @@ func rpcValidator(cfg *HTTPServerConfig, validate *validator.Validate) func(r *r
 			return fmt.Errorf("unsafe rpc method %s cannot be reachable", rpcmethod)
 		}
 
+		if !isForwardedWSCall(r, forwardedCallSecret) {
+			ip, _, err := net.SplitHostPort(r.Request.RemoteAddr)
+			if err != nil {
+				return errors.New("unable to parse IP")
+			}
+
+			if err = policy.check(ip, rpcmethod); err != nil {
+				return err
+			}
+		}
+
 		if err = validate.Struct(v); err != nil {
 			return err
 		}
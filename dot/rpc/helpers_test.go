@@ -0,0 +1,71 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/subscription"
+	"github.com/gorilla/rpc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isForwardedWSCall(t *testing.T) {
+	t.Parallel()
+
+	secret, err := newForwardedCallSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 64)
+
+	testCases := map[string]struct {
+		remoteAddr string
+		header     string
+		secret     string
+		forwarded  bool
+	}{
+		"no header": {
+			remoteAddr: "127.0.0.1:1234",
+			secret:     secret,
+		},
+		"client set header": {
+			remoteAddr: "127.0.0.1:1234",
+			header:     "true",
+			secret:     secret,
+		},
+		"no secret": {
+			remoteAddr: "127.0.0.1:1234",
+		},
+		"external request with secret": {
+			remoteAddr: "10.0.0.1:1234",
+			header:     secret,
+			secret:     secret,
+		},
+		"forwarded call": {
+			remoteAddr: "127.0.0.1:1234",
+			header:     secret,
+			secret:     secret,
+			forwarded:  true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			request := &http.Request{
+				RemoteAddr: testCase.remoteAddr,
+				Header:     http.Header{},
+			}
+			if testCase.header != "" {
+				request.Header.Set(subscription.ForwardedCallHeader, testCase.header)
+			}
+
+			forwarded := isForwardedWSCall(&rpc.RequestInfo{Request: request}, testCase.secret)
+			assert.Equal(t, testCase.forwarded, forwarded)
+		})
+	}
+}
@@ -26,6 +26,10 @@ type HTTPServer struct {
 	rpcServer    *rpc.Server // Actual RPC call handler
 	serverConfig *HTTPServerConfig
 	wsConns      []*subscription.WSConn
+	rpcPolicy    *listenerPolicy
+	wsPolicy     *listenerPolicy
+	// forwardedCallSecret authenticates the websocket calls forwarded to the HTTP listener
+	forwardedCallSecret string
 }
 
 // HTTPServerConfig configures the HTTPServer
@@ -55,6 +59,16 @@ type HTTPServerConfig struct {
 	WSUnsafeExternal    bool
 	WSPort              uint32
 	Modules             []string
+	// RateLimit is the number of call tokens refilled per second for
+	// each client IP address, shared by the HTTP and websocket listeners.
+	// A zero value disables rate limiting.
+	RateLimit         float64
+	RateLimitBurst    uint32
+	MethodCosts       map[string]uint32
+	RPCAllowedMethods []string
+	RPCDeniedMethods  []string
+	WSAllowedMethods  []string
+	WSDeniedMethods   []string
 }
 
 func (h *HTTPServerConfig) rpcUnsafeEnabled() bool {
@@ -80,10 +94,22 @@ func NewHTTPServer(cfg *HTTPServerConfig) *HTTPServer {
 	logger = log.NewFromGlobal(log.AddContext("pkg", "rpc"))
 	logger.Patch(log.SetLevel(cfg.LogLvl))
 
+	limiter := newRateLimiter(cfg.RateLimit, cfg.RateLimitBurst, cfg.MethodCosts)
+
 	server := &HTTPServer{
 		logger:       logger,
 		rpcServer:    rpc.NewServer(),
 		serverConfig: cfg,
+		rpcPolicy: &listenerPolicy{
+			listener: httpListener,
+			filter:   newMethodFilter(cfg.RPCAllowedMethods, cfg.RPCDeniedMethods),
+			limiter:  limiter,
+		},
+		wsPolicy: &listenerPolicy{
+			listener: wsListener,
+			filter:   newMethodFilter(cfg.WSAllowedMethods, cfg.WSDeniedMethods),
+			limiter:  limiter,
+		},
 	}
 
 	server.RegisterModules(cfg.Modules)
@@ -119,7 +145,7 @@ func (h *HTTPServer) RegisterModules(mods []string) {
 		case "syncstate":
 			srvc = modules.NewSyncStateModule(h.serverConfig.SyncStateAPI)
 		case "payment":
-			srvc = modules.NewPaymentModule(h.serverConfig.BlockAPI)
+			srvc = modules.NewPaymentModule(h.serverConfig.BlockAPI, h.serverConfig.StorageAPI)
 		default:
 			h.logger.Warn("Unrecognised module: " + mod)
 			continue
@@ -150,7 +176,13 @@ func (h *HTTPServer) Start() error {
 	// Add custom validator for `common.Hash`
 	validate.RegisterCustomTypeFunc(common.HashValidator, common.Hash{})
 
-	h.rpcServer.RegisterValidateRequestFunc(rpcValidator(h.serverConfig, validate))
+	var err error
+	h.forwardedCallSecret, err = newForwardedCallSecret()
+	if err != nil {
+		return err
+	}
+
+	h.rpcServer.RegisterValidateRequestFunc(rpcValidator(h.serverConfig, h.rpcPolicy, h.forwardedCallSecret, validate))
 
 	go func() {
 		err := http.ListenAndServe(fmt.Sprintf(":%d", h.serverConfig.RPCPort), r)
@@ -231,11 +263,26 @@ func (h *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	// create wsConn
 	wsc := NewWSConn(ws, h.serverConfig)
+	wsc.CallFilter = wsCallFilter(ws, h.wsPolicy)
+	wsc.ForwardedCallSecret = h.forwardedCallSecret
 	h.wsConns = append(h.wsConns, wsc)
 
 	go wsc.HandleComm()
 }
 
+// wsCallFilter returns a filter applying the websocket listener policy
+// to the calls received on the given connection.
+func wsCallFilter(conn *websocket.Conn, policy *listenerPolicy) func(method string) error {
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		ip = conn.RemoteAddr().String()
+	}
+
+	return func(method string) error {
+		return policy.check(ip, method)
+	}
+}
+
 // NewWSConn to create new WebSocket Connection struct
 func NewWSConn(conn *websocket.Conn, cfg *HTTPServerConfig) *subscription.WSConn {
 	c := &subscription.WSConn{
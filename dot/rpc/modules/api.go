@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/grandpa"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/trie"
 )
@@ -28,6 +29,8 @@ type StorageAPI interface {
 	Entries(root *common.Hash) (map[string][]byte, error)
 	GetStateRootFromBlock(bhash *common.Hash) (*common.Hash, error)
 	GetKeysWithPrefix(root *common.Hash, prefix []byte) ([][]byte, error)
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+	Diff(fromRoot, toRoot common.Hash, fn func(change trie.Change) error) error
 	RegisterStorageObserver(observer state.Observer)
 	UnregisterStorageObserver(observer state.Observer)
 }
@@ -44,6 +47,7 @@ type BlockAPI interface {
 	GetHighestFinalisedHash() (common.Hash, error)
 	HasJustification(hash common.Hash) (bool, error)
 	GetJustification(hash common.Hash) ([]byte, error)
+	GetIndexedTransaction(hash common.Hash) ([]byte, error)
 	GetImportedBlockNotifierChannel() chan *types.Block
 	FreeImportedBlockNotifierChannel(ch chan *types.Block)
 	GetFinalisedNotifierChannel() chan *types.FinalisationInfo
@@ -52,6 +56,7 @@ type BlockAPI interface {
 	RegisterRuntimeUpdatedChannel(ch chan<- runtime.Version) (uint32, error)
 	UnregisterRuntimeUpdatedChannel(id uint32) bool
 	GetRuntime(hash *common.Hash) (runtime.Instance, error)
+	GetRuntimePool(hash *common.Hash) (*runtime.Pool, error)
 }
 
 //go:generate mockery --name NetworkAPI --structname NetworkAPI --case underscore --keeptree
@@ -103,7 +108,11 @@ type CoreAPI interface {
 	GetMetadata(bhash *common.Hash) ([]byte, error)
 	QueryStorage(from, to common.Hash, keys ...string) (map[common.Hash]core.QueryKeyValueChanges, error)
 	DecodeSessionKeys(enc []byte) ([]byte, error)
+	GenerateSessionKeys() ([]byte, error)
 	GetReadProofAt(block common.Hash, keys [][]byte) (common.Hash, [][]byte, error)
+	DryRun(ext types.Extrinsic, bhash *common.Hash) ([]byte, error)
+	TraceBlock(hash common.Hash, filter rtstorage.TraceFilter) (*rtstorage.Trace, error)
+	ProfileBlock(hash common.Hash) (runtime.Profile, error)
 }
 
 //go:generate mockery --name RPCAPI --structname RPCAPI --case underscore --keeptree
@@ -123,6 +132,8 @@ type SystemAPI interface {
 	Properties() map[string]interface{}
 	ChainType() string
 	ChainName() string
+	AddLogFilter(directives string) error
+	ResetLogFilter()
 }
 
 //go:generate mockery --name BlockFinalityAPI --structname BlockFinalityAPI --case underscore --keeptree
@@ -5,6 +5,7 @@
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -59,8 +60,8 @@ type ExtrinsicOrHash struct {
 // RemoveExtrinsicsResponse is a array of hash used to Remove extrinsics
 type RemoveExtrinsicsResponse []common.Hash
 
-// KeyRotateResponse is a byte array used to rotate
-type KeyRotateResponse []byte
+// KeyRotateResponse is the hex encoded session keys returned by author_rotateKeys
+type KeyRotateResponse string
 
 // HasSessionKeyResponse is the response to the RPC call author_hasSessionKeys
 type HasSessionKeyResponse bool
@@ -199,6 +200,12 @@ func (am *AuthorModule) RemoveExtrinsic(r *http.Request, _ *ExtrinsicOrHashReque
 
 // RotateKeys Generate new session keys and returns the corresponding public keys
 func (am *AuthorModule) RotateKeys(r *http.Request, req *EmptyRequest, res *KeyRotateResponse) error {
+	keys, err := am.coreAPI.GenerateSessionKeys()
+	if err != nil {
+		return fmt.Errorf("cannot generate session keys: %w", err)
+	}
+
+	*res = KeyRotateResponse(common.BytesToHex(keys))
 	return nil
 }
 
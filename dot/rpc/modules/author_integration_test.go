@@ -394,7 +394,7 @@ func TestAuthorModule_HasKey_Integration(t *testing.T) {
 			pub:     kr.Alice().Public().Hex(),
 			keytype: "xxxx",
 			hasKey:  false,
-			waitErr: keystore.ErrInvalidKeystoreName,
+			waitErr: errors.New("unknown key type: xxxx"),
 		},
 	}
 
@@ -225,6 +225,54 @@ type args struct {
 	}
 }
 
+func TestAuthorModule_RotateKeys(t *testing.T) {
+	t.Parallel()
+
+	testKeys := []byte{1, 2, 3, 4}
+
+	errMockCoreAPI := &mocks.CoreAPI{}
+	errMockCoreAPI.On("GenerateSessionKeys").Return(nil, errors.New("some error"))
+
+	mockCoreAPI := &mocks.CoreAPI{}
+	mockCoreAPI.On("GenerateSessionKeys").Return(testKeys, nil)
+
+	tests := []struct {
+		name    string
+		coreAPI CoreAPI
+		expErr  error
+		wantRes KeyRotateResponse
+	}{
+		{
+			name:    "GenerateSessionKeys error",
+			coreAPI: errMockCoreAPI,
+			expErr:  errors.New("cannot generate session keys: some error"),
+		},
+		{
+			name:    "happy path",
+			coreAPI: mockCoreAPI,
+			wantRes: KeyRotateResponse("0x01020304"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			am := &AuthorModule{
+				coreAPI: tt.coreAPI,
+			}
+			var res KeyRotateResponse
+			err := am.RotateKeys(nil, nil, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRes, res)
+		})
+	}
+}
+
 func TestAuthorModule_PendingExtrinsics(t *testing.T) {
 	emptyMockTransactionStateAPI := &mocks.TransactionStateAPI{}
 	emptyMockTransactionStateAPI.On("Pending").Return([]*transaction.ValidTransaction{})
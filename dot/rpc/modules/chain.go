@@ -30,6 +30,11 @@ type ChainFinalizedHeadRequest struct {
 	SetID uint64
 }
 
+// ChainIndexedTransactionRequest holds the content hash of indexed transaction data
+type ChainIndexedTransactionRequest struct {
+	Hash common.Hash
+}
+
 // ChainBlockHeaderResponse struct
 type ChainBlockHeaderResponse struct {
 	ParentHash     string                 `json:"parentHash"`
@@ -58,6 +63,9 @@ type ChainBlockResponse struct {
 // ChainHashResponse interface to handle response
 type ChainHashResponse interface{}
 
+// ChainIndexedTransactionResponse is the hex encoded indexed transaction data
+type ChainIndexedTransactionResponse string
+
 // ChainModule is an RPC module providing access to storage API points.
 type ChainModule struct {
 	blockAPI BlockAPI
@@ -139,6 +147,19 @@ func (cm *ChainModule) GetFinalizedHeadByRound(
 	return nil
 }
 
+// GetIndexedTransaction returns the transaction data indexed with the given content hash
+// by the runtime, which is kept as long as a block indexing or renewing it is retained.
+func (cm *ChainModule) GetIndexedTransaction(
+	_ *http.Request, req *ChainIndexedTransactionRequest, res *ChainIndexedTransactionResponse) error {
+	data, err := cm.blockAPI.GetIndexedTransaction(req.Hash)
+	if err != nil {
+		return err
+	}
+
+	*res = ChainIndexedTransactionResponse(common.BytesToHex(data))
+	return nil
+}
+
 //GetHeader Get header of a relay chain block. If no block hash is provided, the latest block header will be returned.
 func (cm *ChainModule) GetHeader(r *http.Request, req *ChainHashRequest, res *ChainBlockHeaderResponse) error {
 	hash := cm.hashLookup(req)
@@ -350,6 +350,48 @@ type args struct {
 	}
 }
 
+func TestChainModule_GetIndexedTransaction(t *testing.T) {
+	testHash := common.NewHash([]byte{0x01, 0x02})
+	mockBlockAPI := new(mocks.BlockAPI)
+	mockBlockAPI.On("GetIndexedTransaction", testHash).Return([]byte{1, 2, 3}, nil)
+
+	mockBlockAPIErr := new(mocks.BlockAPI)
+	mockBlockAPIErr.On("GetIndexedTransaction", testHash).Return(nil, errors.New("GetIndexedTransaction Error"))
+
+	tests := []struct {
+		name     string
+		blockAPI BlockAPI
+		expErr   error
+		exp      ChainIndexedTransactionResponse
+	}{
+		{
+			name:     "happy path",
+			blockAPI: mockBlockAPI,
+			exp:      ChainIndexedTransactionResponse("0x010203"),
+		},
+		{
+			name:     "error case",
+			blockAPI: mockBlockAPIErr,
+			expErr:   errors.New("GetIndexedTransaction Error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &ChainModule{
+				blockAPI: tt.blockAPI,
+			}
+			var res ChainIndexedTransactionResponse
+			err := cm.GetIndexedTransaction(nil, &ChainIndexedTransactionRequest{Hash: testHash}, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
 func TestChainModule_GetHeader(t *testing.T) {
 	emptyHeader := types.NewEmptyHeader()
 	testHash := common.NewHash([]byte{0x01, 0x02})
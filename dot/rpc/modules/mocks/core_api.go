@@ -12,6 +12,8 @@
 
 	runtime "github.com/ChainSafe/gossamer/lib/runtime"
 
+	storage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
 	types "github.com/ChainSafe/gossamer/dot/types"
 )
 
@@ -43,6 +45,52 @@ func (_m *CoreAPI) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	return r0, r1
 }
 
+// DryRun provides a mock function with given fields: ext, bhash
+func (_m *CoreAPI) DryRun(ext types.Extrinsic, bhash *common.Hash) ([]byte, error) {
+	ret := _m.Called(ext, bhash)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(types.Extrinsic, *common.Hash) []byte); ok {
+		r0 = rf(ext, bhash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(types.Extrinsic, *common.Hash) error); ok {
+		r1 = rf(ext, bhash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateSessionKeys provides a mock function with given fields:
+func (_m *CoreAPI) GenerateSessionKeys() ([]byte, error) {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetadata provides a mock function with given fields: bhash
 func (_m *CoreAPI) GetMetadata(bhash *common.Hash) ([]byte, error) {
 	ret := _m.Called(bhash)
@@ -170,6 +218,29 @@ func (_m *CoreAPI) InsertKey(kp crypto.Keypair, keystoreType string) error {
 	return r0
 }
 
+// ProfileBlock provides a mock function with given fields: hash
+func (_m *CoreAPI) ProfileBlock(hash common.Hash) (runtime.Profile, error) {
+	ret := _m.Called(hash)
+
+	var r0 runtime.Profile
+	if rf, ok := ret.Get(0).(func(common.Hash) runtime.Profile); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(runtime.Profile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(common.Hash) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryStorage provides a mock function with given fields: from, to, keys
 func (_m *CoreAPI) QueryStorage(from common.Hash, to common.Hash, keys ...string) (map[common.Hash]core.QueryKeyValueChanges, error) {
 	_va := make([]interface{}, len(keys))
@@ -199,3 +270,26 @@ func (_m *CoreAPI) QueryStorage(from common.Hash, to common.Hash, keys ...string
 
 	return r0, r1
 }
+
+// TraceBlock provides a mock function with given fields: hash, filter
+func (_m *CoreAPI) TraceBlock(hash common.Hash, filter storage.TraceFilter) (*storage.Trace, error) {
+	ret := _m.Called(hash, filter)
+
+	var r0 *storage.Trace
+	if rf, ok := ret.Get(0).(func(common.Hash, storage.TraceFilter) *storage.Trace); ok {
+		r0 = rf(hash, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Trace)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(common.Hash, storage.TraceFilter) error); ok {
+		r1 = rf(hash, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
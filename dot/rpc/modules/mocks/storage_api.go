@@ -8,6 +8,8 @@
 
 	state "github.com/ChainSafe/gossamer/dot/state"
 
+	storage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
 	trie "github.com/ChainSafe/gossamer/lib/trie"
 )
 
@@ -16,6 +18,20 @@ type StorageAPI struct {
 	mock.Mock
 }
 
+// Diff provides a mock function with given fields: fromRoot, toRoot, fn
+func (_m *StorageAPI) Diff(fromRoot common.Hash, toRoot common.Hash, fn func(trie.Change) error) error {
+	ret := _m.Called(fromRoot, toRoot, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(common.Hash, common.Hash, func(trie.Change) error) error); ok {
+		r0 = rf(fromRoot, toRoot, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Entries provides a mock function with given fields: root
 func (_m *StorageAPI) Entries(root *common.Hash) (map[string][]byte, error) {
 	ret := _m.Called(root)
@@ -182,6 +198,29 @@ func (_m *StorageAPI) RegisterStorageObserver(observer state.Observer) {
 	_m.Called(observer)
 }
 
+// TrieState provides a mock function with given fields: root
+func (_m *StorageAPI) TrieState(root *common.Hash) (*storage.TrieState, error) {
+	ret := _m.Called(root)
+
+	var r0 *storage.TrieState
+	if rf, ok := ret.Get(0).(func(*common.Hash) *storage.TrieState); ok {
+		r0 = rf(root)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.TrieState)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*common.Hash) error); ok {
+		r1 = rf(root)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UnregisterStorageObserver provides a mock function with given fields: observer
 func (_m *StorageAPI) UnregisterStorageObserver(observer state.Observer) {
 	_m.Called(observer)
@@ -9,6 +9,20 @@ type SystemAPI struct {
 	mock.Mock
 }
 
+// AddLogFilter provides a mock function with given fields: directives
+func (_m *SystemAPI) AddLogFilter(directives string) error {
+	ret := _m.Called(directives)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(directives)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ChainName provides a mock function with given fields:
 func (_m *SystemAPI) ChainName() string {
 	ret := _m.Called()
@@ -53,6 +67,11 @@ func (_m *SystemAPI) Properties() map[string]interface{} {
 	return r0
 }
 
+// ResetLogFilter provides a mock function with given fields:
+func (_m *SystemAPI) ResetLogFilter() {
+	_m.Called()
+}
+
 // SystemName provides a mock function with given fields:
 func (_m *SystemAPI) SystemName() string {
 	ret := _m.Called()
@@ -26,13 +26,15 @@ type PaymentQueryInfoResponse struct {
 
 // PaymentModule holds all the RPC implementation of polkadot payment rpc api
 type PaymentModule struct {
-	blockAPI BlockAPI
+	blockAPI   BlockAPI
+	storageAPI StorageAPI
 }
 
 // NewPaymentModule returns a pointer to PaymentModule
-func NewPaymentModule(blockAPI BlockAPI) *PaymentModule {
+func NewPaymentModule(blockAPI BlockAPI, storageAPI StorageAPI) *PaymentModule {
 	return &PaymentModule{
-		blockAPI: blockAPI,
+		blockAPI:   blockAPI,
+		storageAPI: storageAPI,
 	}
 }
 
@@ -45,16 +47,34 @@ func (p *PaymentModule) QueryInfo(_ *http.Request, req *PaymentQueryInfoRequest,
 		hash = *req.Hash
 	}
 
-	r, err := p.blockAPI.GetRuntime(&hash)
+	ext, err := common.HexToBytes(req.Ext)
 	if err != nil {
 		return err
 	}
 
-	ext, err := common.HexToBytes(req.Ext)
+	stateRoot, err := p.storageAPI.GetStateRootFromBlock(&hash)
+	if err != nil {
+		return err
+	}
+
+	ts, err := p.storageAPI.TrieState(stateRoot)
 	if err != nil {
 		return err
 	}
 
+	pool, err := p.blockAPI.GetRuntimePool(&hash)
+	if err != nil {
+		return err
+	}
+
+	r, err := pool.Checkout()
+	if err != nil {
+		return err
+	}
+	defer pool.Checkin(r)
+
+	r.SetContextStorage(ts)
+
 	encQueryInfo, err := r.PaymentQueryInfo(ext)
 	if err != nil {
 		return err
@@ -15,7 +15,7 @@
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
-	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
 )
 
@@ -37,15 +37,18 @@ func TestPaymentQueryInfo(t *testing.T) {
 		}
 
 		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", mock.AnythingOfType("*storage.TrieState"))
 		runtimeMock.On("PaymentQueryInfo", mock.AnythingOfType("[]uint8")).Return(mockedQueryInfo, nil)
 
 		blockAPIMock := new(mocks.BlockAPI)
 		blockAPIMock.On("BestBlockHash").Return(bestBlockHash)
 
-		blockAPIMock.On("GetRuntime", mock.AnythingOfType("*common.Hash")).Return(runtimeMock, nil)
+		blockAPIMock.On("GetRuntimePool", mock.AnythingOfType("*common.Hash")).
+			Return(runtime.NewPool(runtimeMock, 1), nil)
 
 		mod := &PaymentModule{
-			blockAPI: blockAPIMock,
+			blockAPI:   blockAPIMock,
+			storageAPI: state.Storage,
 		}
 
 		var req PaymentQueryInfoRequest
@@ -60,7 +63,7 @@ func TestPaymentQueryInfo(t *testing.T) {
 
 		// should be called because req.Hash is nil
 		blockAPIMock.AssertCalled(t, "BestBlockHash")
-		blockAPIMock.AssertCalled(t, "GetRuntime", mock.AnythingOfType("*common.Hash"))
+		blockAPIMock.AssertCalled(t, "GetRuntimePool", mock.AnythingOfType("*common.Hash"))
 		runtimeMock.AssertCalled(t, "PaymentQueryInfo", mock.AnythingOfType("[]uint8"))
 	})
 
@@ -68,11 +71,12 @@ func TestPaymentQueryInfo(t *testing.T) {
 		blockAPIMock := new(mocks.BlockAPI)
 		blockAPIMock.On("BestBlockHash").Return(bestBlockHash)
 
-		blockAPIMock.On("GetRuntime", mock.AnythingOfType("*common.Hash")).
+		blockAPIMock.On("GetRuntimePool", mock.AnythingOfType("*common.Hash")).
 			Return(nil, errors.New("mocked problems"))
 
 		mod := &PaymentModule{
-			blockAPI: blockAPIMock,
+			blockAPI:   blockAPIMock,
+			storageAPI: state.Storage,
 		}
 
 		var req PaymentQueryInfoRequest
@@ -86,24 +90,26 @@ func TestPaymentQueryInfo(t *testing.T) {
 		require.Equal(t, res, PaymentQueryInfoResponse{})
 
 		blockAPIMock.AssertCalled(t, "BestBlockHash")
-		blockAPIMock.AssertCalled(t, "GetRuntime", mock.AnythingOfType("*common.Hash"))
+		blockAPIMock.AssertCalled(t, "GetRuntimePool", mock.AnythingOfType("*common.Hash"))
 	})
 
 	t.Run("When PaymentQueryInfo returns error", func(t *testing.T) {
 		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", mock.AnythingOfType("*storage.TrieState"))
 		runtimeMock.On("PaymentQueryInfo", mock.AnythingOfType("[]uint8")).Return(nil, errors.New("mocked error"))
 
 		blockAPIMock := new(mocks.BlockAPI)
-		blockAPIMock.On("GetRuntime", mock.AnythingOfType("*common.Hash")).Return(runtimeMock, nil)
+		blockAPIMock.On("GetRuntimePool", mock.AnythingOfType("*common.Hash")).
+			Return(runtime.NewPool(runtimeMock, 1), nil)
 
 		mod := &PaymentModule{
-			blockAPI: blockAPIMock,
+			blockAPI:   blockAPIMock,
+			storageAPI: state.Storage,
 		}
 
-		mockedHash := common.NewHash([]byte{0x01, 0x02})
 		var req PaymentQueryInfoRequest
 		req.Ext = "0x0000"
-		req.Hash = &mockedHash
+		req.Hash = &bestBlockHash
 
 		var res PaymentQueryInfoResponse
 		err := mod.QueryInfo(nil, &req, &res)
@@ -113,25 +119,27 @@ func TestPaymentQueryInfo(t *testing.T) {
 
 		// should be called because req.Hash is nil
 		blockAPIMock.AssertNotCalled(t, "BestBlockHash")
-		blockAPIMock.AssertCalled(t, "GetRuntime", mock.AnythingOfType("*common.Hash"))
+		blockAPIMock.AssertCalled(t, "GetRuntimePool", mock.AnythingOfType("*common.Hash"))
 		runtimeMock.AssertCalled(t, "PaymentQueryInfo", mock.AnythingOfType("[]uint8"))
 	})
 
 	t.Run("When PaymentQueryInfo returns a nil info", func(t *testing.T) {
 		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", mock.AnythingOfType("*storage.TrieState"))
 		runtimeMock.On("PaymentQueryInfo", mock.AnythingOfType("[]uint8")).Return(nil, nil)
 
 		blockAPIMock := new(mocks.BlockAPI)
-		blockAPIMock.On("GetRuntime", mock.AnythingOfType("*common.Hash")).Return(runtimeMock, nil)
+		blockAPIMock.On("GetRuntimePool", mock.AnythingOfType("*common.Hash")).
+			Return(runtime.NewPool(runtimeMock, 1), nil)
 
 		mod := &PaymentModule{
-			blockAPI: blockAPIMock,
+			blockAPI:   blockAPIMock,
+			storageAPI: state.Storage,
 		}
 
-		mockedHash := common.NewHash([]byte{0x01, 0x02})
 		var req PaymentQueryInfoRequest
 		req.Ext = "0x0020"
-		req.Hash = &mockedHash
+		req.Hash = &bestBlockHash
 
 		var res PaymentQueryInfoResponse
 		err := mod.QueryInfo(nil, &req, &res)
@@ -141,7 +149,7 @@ func TestPaymentQueryInfo(t *testing.T) {
 
 		// should be called because req.Hash is nil
 		blockAPIMock.AssertNotCalled(t, "BestBlockHash")
-		blockAPIMock.AssertCalled(t, "GetRuntime", mock.AnythingOfType("*common.Hash"))
+		blockAPIMock.AssertCalled(t, "GetRuntimePool", mock.AnythingOfType("*common.Hash"))
 		runtimeMock.AssertCalled(t, "PaymentQueryInfo", mock.AnythingOfType("[]uint8"))
 	})
 }
@@ -12,7 +12,9 @@
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,13 @@ func TestPaymentModule_QueryInfo(t *testing.T) {
 	u, err := scale.NewUint128(new(big.Int).SetBytes([]byte{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6}))
 	require.NoError(t, err)
 
+	stateRoot := common.Hash{0x03}
+	trieState, err := rtstorage.NewTrieState(nil)
+	require.NoError(t, err)
+	storageAPIMock := new(mocks.StorageAPI)
+	storageAPIMock.On("GetStateRootFromBlock", &testHash).Return(&stateRoot, nil)
+	storageAPIMock.On("TrieState", &stateRoot).Return(trieState, nil)
+
 	runtimeMock := new(mocksruntime.Instance)
 	runtimeMock2 := new(mocksruntime.Instance)
 	runtimeErrorMock := new(mocksruntime.Instance)
@@ -34,14 +43,17 @@ func TestPaymentModule_QueryInfo(t *testing.T) {
 	blockErrorAPIMock2 := new(mocks.BlockAPI)
 
 	blockAPIMock.On("BestBlockHash").Return(testHash, nil)
-	blockAPIMock.On("GetRuntime", &testHash).Return(runtimeMock, nil)
+	blockAPIMock.On("GetRuntimePool", &testHash).Return(runtime.NewPool(runtimeMock, 1), nil)
 
-	blockAPIMock2.On("GetRuntime", &testHash).Return(runtimeMock2, nil)
+	blockAPIMock2.On("GetRuntimePool", &testHash).Return(runtime.NewPool(runtimeMock2, 1), nil)
 
-	blockErrorAPIMock1.On("GetRuntime", &testHash).Return(runtimeErrorMock, nil)
+	blockErrorAPIMock1.On("GetRuntimePool", &testHash).Return(runtime.NewPool(runtimeErrorMock, 1), nil)
 
-	blockErrorAPIMock2.On("GetRuntime", &testHash).Return(nil, errors.New("GetRuntime error"))
+	blockErrorAPIMock2.On("GetRuntimePool", &testHash).Return(nil, errors.New("GetRuntimePool error"))
 
+	runtimeMock.On("SetContextStorage", trieState)
+	runtimeMock2.On("SetContextStorage", trieState)
+	runtimeErrorMock.On("SetContextStorage", trieState)
 	runtimeMock.On("PaymentQueryInfo", common.MustHexToBytes("0x0000")).Return(nil, nil)
 	runtimeMock2.On("PaymentQueryInfo", common.MustHexToBytes("0x0000")).Return(&types.TransactionPaymentQueryInfo{
 		Weight:     uint64(21),
@@ -51,9 +63,10 @@ func TestPaymentModule_QueryInfo(t *testing.T) {
 	runtimeErrorMock.On("PaymentQueryInfo", common.MustHexToBytes("0x0000")).
 		Return(nil, errors.New("PaymentQueryInfo error"))
 
-	paymentModule := NewPaymentModule(blockAPIMock)
+	paymentModule := NewPaymentModule(blockAPIMock, storageAPIMock)
 	type fields struct {
-		blockAPI BlockAPI
+		blockAPI   BlockAPI
+		storageAPI StorageAPI
 	}
 	type args struct {
 		in0 *http.Request
@@ -70,6 +83,7 @@ type args struct {
 			name: "Nil Query Info",
 			fields: fields{
 				paymentModule.blockAPI,
+				paymentModule.storageAPI,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -83,6 +97,7 @@ type args struct {
 			name: "Not Nil Query Info",
 			fields: fields{
 				blockAPIMock2,
+				storageAPIMock,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -102,6 +117,7 @@ type args struct {
 			name: "Nil Hash",
 			fields: fields{
 				paymentModule.blockAPI,
+				paymentModule.storageAPI,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -114,6 +130,7 @@ type args struct {
 			name: "Invalid Ext",
 			fields: fields{
 				paymentModule.blockAPI,
+				paymentModule.storageAPI,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -126,6 +143,7 @@ type args struct {
 			name: "PaymentQueryInfo error",
 			fields: fields{
 				blockErrorAPIMock1,
+				storageAPIMock,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -136,9 +154,10 @@ type args struct {
 			expErr: errors.New("PaymentQueryInfo error"),
 		},
 		{
-			name: "GetRuntime error",
+			name: "GetRuntimePool error",
 			fields: fields{
 				blockErrorAPIMock2,
+				storageAPIMock,
 			},
 			args: args{
 				req: &PaymentQueryInfoRequest{
@@ -146,13 +165,14 @@ type args struct {
 					Hash: &testHash,
 				},
 			},
-			expErr: errors.New("GetRuntime error"),
+			expErr: errors.New("GetRuntimePool error"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &PaymentModule{
-				blockAPI: tt.fields.blockAPI,
+				blockAPI:   tt.fields.blockAPI,
+				storageAPI: tt.fields.storageAPI,
 			}
 			res := PaymentQueryInfoResponse{}
 			err := p.QueryInfo(tt.args.in0, tt.args.req, &res)
@@ -12,6 +12,9 @@
 	UnsafeMethods = []string{
 		"system_addReservedPeer",
 		"system_removeReservedPeer",
+		"system_dryRun",
+		"system_addLogFilter",
+		"system_resetLogFilter",
 		"author_submitExtrinsic",
 		"author_removeExtrinsic",
 		"author_insertKey",
@@ -19,6 +22,9 @@
 		"state_getPairs",
 		"state_getKeysPaged",
 		"state_queryStorage",
+		"state_getStorageDiff",
+		"state_traceBlock",
+		"state_profileBlock",
 	}
 
 	// AliasesMethods is a map that links the original methods to their aliases
@@ -12,6 +12,8 @@
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
@@ -77,6 +79,29 @@ type StateStorageQueryRangeRequest struct {
 	EndBlock   common.Hash `json:"block"`
 }
 
+// StateStorageDiffRequest holds the fields of the state_getStorageDiff rpc call.
+// If To is not given, the state of From is compared with the state of the best block.
+type StateStorageDiffRequest struct {
+	From common.Hash  `json:"from" validate:"required"`
+	To   *common.Hash `json:"to"`
+}
+
+// StateTraceBlockRequest holds the fields of the state_traceBlock rpc call.
+// Targets, StorageKeys and Methods are comma separated lists of runtime log
+// target prefixes, hex encoded storage key prefixes and storage operations
+// to record. Values are only recorded if storage key prefixes are given.
+type StateTraceBlockRequest struct {
+	Block       common.Hash `validate:"required"`
+	Targets     *string
+	StorageKeys *string
+	Methods     *string
+}
+
+// StateProfileBlockRequest holds the fields of the state_profileBlock rpc call.
+type StateProfileBlockRequest struct {
+	Block common.Hash `validate:"required"`
+}
+
 // StateStorageKeysQuery field to store storage keys
 type StateStorageKeysQuery [][]byte
 
@@ -125,6 +150,43 @@ type StorageChangeSetResponse struct {
 	Changes [][]string   `json:"changes"`
 }
 
+// StorageDiff is a storage key whose value differs between the states of two blocks.
+// ChildStorageKey is the key of the child trie, without the child storage key
+// prefix, for the keys of child tries. Values are absent if the key was added or removed.
+type StorageDiff struct {
+	ChildStorageKey string  `json:"childStorageKey,omitempty"`
+	Key             string  `json:"key"`
+	Type            string  `json:"type"`
+	OldValue        *string `json:"oldValue"`
+	NewValue        *string `json:"newValue"`
+}
+
+// StateStorageDiffResponse is the response of the state_getStorageDiff rpc call
+type StateStorageDiffResponse []StorageDiff
+
+// BlockTrace holds the tracing spans entered and the storage accesses made by the execution of a block
+type BlockTrace struct {
+	BlockHash      common.Hash            `json:"blockHash"`
+	TracingTargets string                 `json:"tracingTargets"`
+	StorageKeys    string                 `json:"storageKeys"`
+	Methods        string                 `json:"methods"`
+	Spans          []rtstorage.TraceSpan  `json:"spans"`
+	Events         []rtstorage.TraceEvent `json:"events"`
+}
+
+// StateTraceBlockResponse is the response of the state_traceBlock rpc call
+type StateTraceBlockResponse struct {
+	BlockTrace BlockTrace `json:"blockTrace"`
+}
+
+// StateProfileBlockResponse is the response of the state_profileBlock rpc call, holding
+// the host function calls made by the runtime by runtime entrypoint and host function.
+// The durations are in nanoseconds.
+type StateProfileBlockResponse struct {
+	BlockHash   common.Hash     `json:"blockHash"`
+	Entrypoints runtime.Profile `json:"entrypoints"`
+}
+
 // KeyValueOption struct holds json fields
 type KeyValueOption []byte
 
@@ -435,6 +497,52 @@ func (sm *StateModule) QueryStorage(
 	return nil
 }
 
+// GetStorageDiff returns the storage keys added, modified and removed between the states
+// of the two blocks given, including the keys of child tries, in key order.
+func (sm *StateModule) GetStorageDiff(
+	_ *http.Request, req *StateStorageDiffRequest, res *StateStorageDiffResponse) error {
+	fromRoot, err := sm.storageAPI.GetStateRootFromBlock(&req.From)
+	if err != nil {
+		return fmt.Errorf("cannot get state root of block %s: %w", req.From, err)
+	}
+
+	toRoot, err := sm.storageAPI.GetStateRootFromBlock(req.To)
+	if err != nil {
+		return fmt.Errorf("cannot get state root of block to: %w", err)
+	}
+
+	diffs := StateStorageDiffResponse{}
+	err = sm.storageAPI.Diff(*fromRoot, *toRoot, func(change trie.Change) error {
+		diff := StorageDiff{
+			Key:  common.BytesToHex(change.Key),
+			Type: change.Type.String(),
+		}
+
+		if change.KeyToChild != nil {
+			diff.ChildStorageKey = common.BytesToHex(change.KeyToChild)
+		}
+
+		if change.OldValue != nil {
+			oldValue := common.BytesToHex(change.OldValue)
+			diff.OldValue = &oldValue
+		}
+
+		if change.NewValue != nil {
+			newValue := common.BytesToHex(change.NewValue)
+			diff.NewValue = &newValue
+		}
+
+		diffs = append(diffs, diff)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot diff states: %w", err)
+	}
+
+	*res = diffs
+	return nil
+}
+
 // SubscribeRuntimeVersion initialised a runtime version subscription and returns the current version
 // See dot/rpc/subscription
 func (sm *StateModule) SubscribeRuntimeVersion(
@@ -460,3 +568,78 @@ func ConvertAPIs(in []runtime.APIItem) []interface{} {
 	}
 	return ret
 }
+
+// TraceBlock re-executes the given block and returns the tracing spans entered and the storage
+// accesses made by the runtime matching the requested targets, storage keys and methods.
+func (sm *StateModule) TraceBlock(
+	_ *http.Request, req *StateTraceBlockRequest, res *StateTraceBlockResponse) error {
+	var targets, storageKeys, methods string
+	if req.Targets != nil {
+		targets = *req.Targets
+	}
+	if req.StorageKeys != nil {
+		storageKeys = *req.StorageKeys
+	}
+	if req.Methods != nil {
+		methods = *req.Methods
+	}
+
+	filter := rtstorage.TraceFilter{
+		Targets:    splitCommaSeparated(targets),
+		Operations: splitCommaSeparated(methods),
+	}
+
+	for _, hexKey := range splitCommaSeparated(storageKeys) {
+		key, err := common.HexToBytes(hexKey)
+		if err != nil {
+			return fmt.Errorf("cannot convert hex storage key %s to bytes: %w", hexKey, err)
+		}
+		filter.KeyPrefixes = append(filter.KeyPrefixes, key)
+	}
+	filter.WithValues = len(filter.KeyPrefixes) > 0
+
+	trace, err := sm.coreAPI.TraceBlock(req.Block, filter)
+	if err != nil {
+		return err
+	}
+
+	*res = StateTraceBlockResponse{
+		BlockTrace: BlockTrace{
+			BlockHash:      req.Block,
+			TracingTargets: targets,
+			StorageKeys:    storageKeys,
+			Methods:        methods,
+			Spans:          trace.Spans,
+			Events:         trace.Events,
+		},
+	}
+
+	return nil
+}
+
+// ProfileBlock re-executes the given block and returns the number of calls, the
+// time spent and the bytes transferred in the host functions called by the runtime.
+func (sm *StateModule) ProfileBlock(
+	_ *http.Request, req *StateProfileBlockRequest, res *StateProfileBlockResponse) error {
+	profile, err := sm.coreAPI.ProfileBlock(req.Block)
+	if err != nil {
+		return err
+	}
+
+	*res = StateProfileBlockResponse{
+		BlockHash:   req.Block,
+		Entrypoints: profile,
+	}
+
+	return nil
+}
+
+func splitCommaSeparated(s string) (values []string) {
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
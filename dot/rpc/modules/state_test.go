@@ -19,15 +19,19 @@
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
 	testdata "github.com/ChainSafe/gossamer/dot/rpc/modules/test_data"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestStateModuleGetPairs(t *testing.T) {
@@ -887,3 +891,190 @@ type args struct {
 		})
 	}
 }
+
+func TestStateModule_TraceBlock(t *testing.T) {
+	hash := common.Hash{1}
+	trace := &rtstorage.Trace{
+		Spans: []rtstorage.TraceSpan{
+			{ID: 1, Name: "on_initialize", Target: "runtime::system"},
+		},
+		Events: []rtstorage.TraceEvent{
+			{Operation: rtstorage.OperationGet, Key: "0x1111", Value: "0x01", Target: "runtime::system", SpanID: 1},
+		},
+	}
+
+	mockCoreAPI := new(mocks.CoreAPI)
+	mockCoreAPI.On("TraceBlock", hash, rtstorage.TraceFilter{
+		Targets:     []string{"runtime::system", "pallet"},
+		KeyPrefixes: [][]byte{{0x11, 0x11}, {0x22}},
+		Operations:  []string{"get"},
+		WithValues:  true,
+	}).Return(trace, nil)
+	mockCoreAPI.On("TraceBlock", hash, rtstorage.TraceFilter{}).Return(nil, errors.New("TraceBlock Error"))
+
+	targets := "runtime::system, pallet"
+	storageKeys := "0x1111,0x22"
+	badStorageKeys := "1111"
+	methods := "get"
+
+	tests := []struct {
+		name   string
+		req    *StateTraceBlockRequest
+		expErr error
+		exp    StateTraceBlockResponse
+	}{
+		{
+			name: "OK Case",
+			req: &StateTraceBlockRequest{
+				Block:       hash,
+				Targets:     &targets,
+				StorageKeys: &storageKeys,
+				Methods:     &methods,
+			},
+			exp: StateTraceBlockResponse{
+				BlockTrace: BlockTrace{
+					BlockHash:      hash,
+					TracingTargets: targets,
+					StorageKeys:    storageKeys,
+					Methods:        methods,
+					Spans:          trace.Spans,
+					Events:         trace.Events,
+				},
+			},
+		},
+		{
+			name:   "TraceBlock Error",
+			req:    &StateTraceBlockRequest{Block: hash},
+			expErr: errors.New("TraceBlock Error"),
+		},
+		{
+			name:   "Invalid storage key Error",
+			req:    &StateTraceBlockRequest{Block: hash, StorageKeys: &badStorageKeys},
+			expErr: errors.New("cannot convert hex storage key 1111 to bytes: could not byteify non 0x prefixed string: 1111"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateModule(nil, nil, mockCoreAPI)
+			res := StateTraceBlockResponse{}
+			err := sm.TraceBlock(nil, tt.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestStateModule_ProfileBlock(t *testing.T) {
+	hash := common.Hash{1}
+	errorHash := common.Hash{2}
+	profile := runtime.Profile{
+		runtime.CoreExecuteBlock: {
+			"ext_storage_get_version_1": {Calls: 2, Duration: time.Millisecond, Bytes: 64},
+		},
+	}
+
+	mockCoreAPI := new(mocks.CoreAPI)
+	mockCoreAPI.On("ProfileBlock", hash).Return(profile, nil)
+	mockCoreAPI.On("ProfileBlock", errorHash).Return(nil, errors.New("ProfileBlock Error"))
+
+	tests := []struct {
+		name   string
+		req    *StateProfileBlockRequest
+		expErr error
+		exp    StateProfileBlockResponse
+	}{
+		{
+			name: "OK Case",
+			req:  &StateProfileBlockRequest{Block: hash},
+			exp: StateProfileBlockResponse{
+				BlockHash:   hash,
+				Entrypoints: profile,
+			},
+		},
+		{
+			name:   "ProfileBlock Error",
+			req:    &StateProfileBlockRequest{Block: errorHash},
+			expErr: errors.New("ProfileBlock Error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateModule(nil, nil, mockCoreAPI)
+			res := StateProfileBlockResponse{}
+			err := sm.ProfileBlock(nil, tt.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestStateModule_GetStorageDiff(t *testing.T) {
+	fromHash, toHash := common.Hash{1}, common.Hash{2}
+	fromRoot, toRoot := common.Hash{3}, common.Hash{4}
+	unknownHash := common.Hash{5}
+
+	changes := []trie.Change{
+		{Key: []byte{1}, Type: trie.Added, NewValue: []byte{2}},
+		{KeyToChild: []byte{3}, Key: []byte{4}, Type: trie.Modified, OldValue: []byte{5}, NewValue: []byte{6}},
+		{Key: []byte{7}, Type: trie.Removed, OldValue: []byte{8}},
+	}
+
+	mockStorageAPI := new(mocks.StorageAPI)
+	mockStorageAPI.On("GetStateRootFromBlock", &fromHash).Return(&fromRoot, nil)
+	mockStorageAPI.On("GetStateRootFromBlock", &toHash).Return(&toRoot, nil)
+	mockStorageAPI.On("GetStateRootFromBlock", &unknownHash).Return(nil, errors.New("not found"))
+	mockStorageAPI.On("Diff", fromRoot, toRoot, mock.AnythingOfType("func(trie.Change) error")).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func(trie.Change) error)
+			for _, change := range changes {
+				_ = fn(change)
+			}
+		}).Return(nil)
+
+	value := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		req    *StateStorageDiffRequest
+		expErr error
+		exp    StateStorageDiffResponse
+	}{
+		{
+			name: "OK Case",
+			req:  &StateStorageDiffRequest{From: fromHash, To: &toHash},
+			exp: StateStorageDiffResponse{
+				{Key: "0x01", Type: "added", NewValue: value("0x02")},
+				{ChildStorageKey: "0x03", Key: "0x04", Type: "modified", OldValue: value("0x05"), NewValue: value("0x06")},
+				{Key: "0x07", Type: "removed", OldValue: value("0x08")},
+			},
+		},
+		{
+			name: "Unknown block Error",
+			req:  &StateStorageDiffRequest{From: unknownHash, To: &toHash},
+			expErr: errors.New("cannot get state root of block " +
+				"0x0500000000000000000000000000000000000000000000000000000000000000: not found"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateModule(nil, mockStorageAPI, nil)
+			res := StateStorageDiffResponse{}
+			err := sm.GetStorageDiff(nil, tt.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
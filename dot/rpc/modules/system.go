@@ -10,6 +10,7 @@
 	"net/http"
 	"strings"
 
+	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -59,6 +60,12 @@ type StringRequest struct {
 	String string
 }
 
+// DryRunRequest holds the fields of the system_dryRun rpc call
+type DryRunRequest struct {
+	Extrinsic string `validate:"required"`
+	Bhash     *common.Hash
+}
+
 // SyncStateResponse is the struct to return on the system_syncState rpc call
 type SyncStateResponse struct {
 	CurrentBlock  uint32 `json:"currentBlock"`
@@ -289,3 +296,37 @@ func (sm *SystemModule) RemoveReservedPeer(r *http.Request, req *StringRequest,
 
 	return sm.networkAPI.RemoveReservedPeers(req.String)
 }
+
+// DryRun applies the hex encoded extrinsic on top of the state of the given block,
+// or of the best block if none is given, without storing any state change.
+// It returns the hex encoded SCALE ApplyExtrinsicResult.
+func (sm *SystemModule) DryRun(r *http.Request, req *DryRunRequest, res *string) error {
+	extBytes, err := common.HexToBytes(req.Extrinsic)
+	if err != nil {
+		return err
+	}
+
+	result, err := sm.coreAPI.DryRun(types.Extrinsic(extBytes), req.Bhash)
+	if err != nil {
+		return err
+	}
+
+	*res = common.BytesToHex(result)
+	return nil
+}
+
+// AddLogFilter adds the comma separated log directives, such as
+// `sync=trace,runtime::system=debug`, to the node log filter.
+func (sm *SystemModule) AddLogFilter(r *http.Request, req *StringRequest, res *[]byte) error {
+	if strings.TrimSpace(req.String) == "" {
+		return errors.New("cannot add an empty log filter")
+	}
+
+	return sm.systemAPI.AddLogFilter(req.String)
+}
+
+// ResetLogFilter resets the node loggers to their configured levels.
+func (sm *SystemModule) ResetLogFilter(r *http.Request, req *EmptyRequest, res *[]byte) error {
+	sm.systemAPI.ResetLogFilter()
+	return nil
+}
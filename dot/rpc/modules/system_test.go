@@ -605,3 +605,125 @@ type args struct {
 		})
 	}
 }
+
+func TestSystemModule_DryRun(t *testing.T) {
+	blockHash := common.Hash{1}
+
+	mockCoreAPI := new(mocks.CoreAPI)
+	mockCoreAPI.On("DryRun", types.Extrinsic{1, 2}, (*common.Hash)(nil)).Return([]byte{0, 0}, nil)
+	mockCoreAPI.On("DryRun", types.Extrinsic{1, 2}, &blockHash).Return(nil, errors.New("dry run error"))
+
+	type args struct {
+		r   *http.Request
+		req *DryRunRequest
+	}
+	tests := []struct {
+		name      string
+		sysModule *SystemModule
+		args      args
+		expErr    error
+		exp       string
+	}{
+		{
+			name:      "OK",
+			sysModule: NewSystemModule(nil, nil, mockCoreAPI, nil, nil, nil, nil),
+			args: args{
+				req: &DryRunRequest{Extrinsic: "0x0102"},
+			},
+			exp: "0x0000",
+		},
+		{
+			name:      "DryRun Error",
+			sysModule: NewSystemModule(nil, nil, mockCoreAPI, nil, nil, nil, nil),
+			args: args{
+				req: &DryRunRequest{Extrinsic: "0x0102", Bhash: &blockHash},
+			},
+			expErr: errors.New("dry run error"),
+		},
+		{
+			name:      "Invalid extrinsic Error",
+			sysModule: NewSystemModule(nil, nil, mockCoreAPI, nil, nil, nil, nil),
+			args: args{
+				req: &DryRunRequest{Extrinsic: "0102"},
+			},
+			expErr: errors.New("could not byteify non 0x prefixed string: 0102"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := tt.sysModule
+			res := ""
+			err := sm.DryRun(tt.args.r, tt.args.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestSystemModule_AddLogFilter(t *testing.T) {
+	mockSystemAPI := new(mocks.SystemAPI)
+	mockSystemAPI.On("AddLogFilter", "sync=trace").Return(nil)
+	mockSystemAPI.On("AddLogFilter", "sync=loud").Return(errors.New("cannot parse log directives"))
+
+	type args struct {
+		r   *http.Request
+		req *StringRequest
+	}
+	tests := []struct {
+		name      string
+		sysModule *SystemModule
+		args      args
+		expErr    error
+	}{
+		{
+			name:      "OK",
+			sysModule: NewSystemModule(nil, mockSystemAPI, nil, nil, nil, nil, nil),
+			args: args{
+				req: &StringRequest{"sync=trace"},
+			},
+		},
+		{
+			name:      "AddLogFilter Error",
+			sysModule: NewSystemModule(nil, mockSystemAPI, nil, nil, nil, nil, nil),
+			args: args{
+				req: &StringRequest{"sync=loud"},
+			},
+			expErr: errors.New("cannot parse log directives"),
+		},
+		{
+			name:      "Empty StringRequest Error",
+			sysModule: NewSystemModule(nil, mockSystemAPI, nil, nil, nil, nil, nil),
+			args: args{
+				req: &StringRequest{" "},
+			},
+			expErr: errors.New("cannot add an empty log filter"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := tt.sysModule
+			res := []byte(nil)
+			err := sm.AddLogFilter(tt.args.r, tt.args.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSystemModule_ResetLogFilter(t *testing.T) {
+	mockSystemAPI := new(mocks.SystemAPI)
+	mockSystemAPI.On("ResetLogFilter").Return()
+
+	sm := NewSystemModule(nil, mockSystemAPI, nil, nil, nil, nil, nil)
+	res := []byte(nil)
+	err := sm.ResetLogFilter(nil, &EmptyRequest{}, &res)
+	assert.NoError(t, err)
+	mockSystemAPI.AssertCalled(t, "ResetLogFilter")
+}
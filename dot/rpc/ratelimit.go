@@ -0,0 +1,240 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	defaultMethodCost = 1
+	// bucketsCleanupInterval is the minimum time between two sweeps of
+	// the idle token buckets.
+	bucketsCleanupInterval = time.Minute
+
+	httpListener = "http"
+	wsListener   = "ws"
+
+	rejectReasonDenied      = "denied"
+	rejectReasonRateLimited = "rate_limited"
+)
+
+var (
+	errMethodNotAllowed = errors.New("rpc method not allowed on this listener")
+	errRateLimited      = errors.New("rpc rate limit exceeded")
+)
+
+// defaultMethodCosts are the token costs of the methods known to be
+// expensive to serve, any other method costs defaultMethodCost.
+var defaultMethodCosts = map[string]uint32{
+	"state_getKeysPaged":   10,
+	"state_getPairs":       10,
+	"state_queryStorage":   20,
+	"state_queryStorageAt": 5,
+	"state_getStorageDiff": 20,
+}
+
+var rejectedCallsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gossamer_rpc",
+	Name:      "rejected_calls_total",
+	Help:      "total number of rpc calls rejected by a listener policy",
+}, []string{"listener", "reason"})
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// rateLimiter is a token bucket rate limiter keyed by client IP.
+// Each call consumes the cost of its method from the bucket of the client.
+type rateLimiter struct {
+	rate  float64 // tokens refilled per second
+	burst float64 // bucket capacity
+	costs map[string]uint32
+
+	mutex       sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// newRateLimiter returns a rate limiter refilling rate tokens per second up
+// to burst tokens for each client. Costs override the default method costs.
+// It returns nil if rate is not strictly positive, which disables rate limiting.
+func newRateLimiter(rate float64, burst uint32, costs map[string]uint32) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst == 0 {
+		burst = uint32(rate)
+		if burst == 0 {
+			burst = 1
+		}
+	}
+
+	methodCosts := make(map[string]uint32, len(defaultMethodCosts)+len(costs))
+	for method, cost := range defaultMethodCosts {
+		methodCosts[method] = cost
+	}
+	for method, cost := range costs {
+		methodCosts[method] = cost
+	}
+
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		costs:   methodCosts,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+func (r *rateLimiter) cost(method string) float64 {
+	cost, ok := r.costs[method]
+	if !ok {
+		cost = defaultMethodCost
+	}
+
+	// a call costing more than the bucket capacity would never be served
+	if float64(cost) > r.burst {
+		return r.burst
+	}
+	return float64(cost)
+}
+
+// allow returns true if the client with the given IP address has
+// enough tokens left to call the given method, and consumes them.
+func (r *rateLimiter) allow(ip, method string) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	r.cleanup(now)
+
+	bucket, ok := r.buckets[ip]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:     r.burst,
+			lastRefill: now,
+		}
+		r.buckets[ip] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens += elapsed * r.rate
+	if bucket.tokens > r.burst {
+		bucket.tokens = r.burst
+	}
+	bucket.lastRefill = now
+
+	cost := r.cost(method)
+	if bucket.tokens < cost {
+		return false
+	}
+
+	bucket.tokens -= cost
+	return true
+}
+
+// cleanup removes the buckets which would be full by now,
+// since they are equivalent to a missing bucket.
+// It must be called with the mutex locked.
+func (r *rateLimiter) cleanup(now time.Time) {
+	if now.Sub(r.lastCleanup) < bucketsCleanupInterval {
+		return
+	}
+	r.lastCleanup = now
+
+	for ip, bucket := range r.buckets {
+		refilled := bucket.tokens + now.Sub(bucket.lastRefill).Seconds()*r.rate
+		if refilled >= r.burst {
+			delete(r.buckets, ip)
+		}
+	}
+}
+
+// methodFilter is an allow/deny list of rpc methods.
+// Entries are method names such as `state_getKeysPaged`, or
+// module wildcards such as `state_*`.
+type methodFilter struct {
+	allowed []string
+	denied  []string
+}
+
+func newMethodFilter(allowed, denied []string) *methodFilter {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+
+	return &methodFilter{
+		allowed: allowed,
+		denied:  denied,
+	}
+}
+
+// permits returns true if the method is not in the deny list and,
+// if an allow list is set, is in the allow list.
+func (f *methodFilter) permits(method string) bool {
+	if f == nil {
+		return true
+	}
+
+	if matchesAny(method, f.denied) {
+		return false
+	}
+
+	return len(f.allowed) == 0 || matchesAny(method, f.allowed)
+}
+
+func matchesAny(method string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(method, prefix) {
+				return true
+			}
+			continue
+		}
+
+		if method == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerPolicy groups the method filter of a listener with the
+// rate limiter shared by all listeners.
+type listenerPolicy struct {
+	listener string
+	filter   *methodFilter
+	limiter  *rateLimiter
+}
+
+// check returns an error if the client with the given IP address
+// cannot call the given method on the listener.
+func (p *listenerPolicy) check(ip, method string) error {
+	if !p.filter.permits(method) {
+		rejectedCallsCounter.WithLabelValues(p.listener, rejectReasonDenied).Inc()
+		return fmt.Errorf("%w: %s", errMethodNotAllowed, method)
+	}
+
+	if !p.limiter.allow(ip, method) {
+		rejectedCallsCounter.WithLabelValues(p.listener, rejectReasonRateLimited).Inc()
+		return fmt.Errorf("%w: %s", errRateLimited, method)
+	}
+
+	return nil
+}
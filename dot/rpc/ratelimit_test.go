@@ -0,0 +1,148 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(0, 10, nil)
+	assert.Nil(t, limiter)
+
+	limiter = newRateLimiter(0.5, 0, map[string]uint32{
+		"state_getKeysPaged":     3,
+		"author_submitExtrinsic": 2,
+	})
+	require.NotNil(t, limiter)
+	assert.Equal(t, float64(1), limiter.burst)
+	assert.Equal(t, uint32(3), limiter.costs["state_getKeysPaged"])
+	assert.Equal(t, uint32(2), limiter.costs["author_submitExtrinsic"])
+	assert.Equal(t, defaultMethodCosts["state_queryStorage"], limiter.costs["state_queryStorage"])
+}
+
+func Test_rateLimiter_allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(1, 10, nil)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, limiter.allow("1.1.1.1", "system_name"))
+	}
+	assert.False(t, limiter.allow("1.1.1.1", "system_name"))
+
+	// other clients have their own bucket
+	assert.True(t, limiter.allow("2.2.2.2", "state_getKeysPaged"))
+	assert.False(t, limiter.allow("2.2.2.2", "system_name"))
+
+	now = now.Add(2 * time.Second)
+	assert.True(t, limiter.allow("1.1.1.1", "system_name"))
+	assert.True(t, limiter.allow("1.1.1.1", "system_name"))
+	assert.False(t, limiter.allow("1.1.1.1", "system_name"))
+
+	// costs above the burst are capped to the burst
+	assert.False(t, limiter.allow("2.2.2.2", "state_queryStorage"))
+	now = now.Add(10 * time.Second)
+	assert.True(t, limiter.allow("2.2.2.2", "state_queryStorage"))
+
+	var nilLimiter *rateLimiter
+	assert.True(t, nilLimiter.allow("1.1.1.1", "system_name"))
+}
+
+func Test_rateLimiter_cleanup(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(1, 10, nil)
+	limiter.now = func() time.Time { return now }
+	limiter.lastCleanup = now
+
+	limiter.allow("1.1.1.1", "state_getKeysPaged")
+	limiter.allow("2.2.2.2", "system_name")
+	require.Len(t, limiter.buckets, 2)
+
+	now = now.Add(5 * time.Second)
+	limiter.cleanup(now)
+	assert.Len(t, limiter.buckets, 2)
+
+	now = now.Add(bucketsCleanupInterval)
+	limiter.cleanup(now)
+	assert.Empty(t, limiter.buckets)
+}
+
+func Test_methodFilter_permits(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		allowed  []string
+		denied   []string
+		method   string
+		permited bool
+	}{
+		"no lists": {
+			method:   "state_getKeysPaged",
+			permited: true,
+		},
+		"denied method": {
+			denied: []string{"state_getKeysPaged"},
+			method: "state_getKeysPaged",
+		},
+		"denied module": {
+			denied: []string{"state_*"},
+			method: "state_getKeysPaged",
+		},
+		"not in allow list": {
+			allowed: []string{"chain_*", "system_name"},
+			method:  "state_getKeysPaged",
+		},
+		"in allow list": {
+			allowed:  []string{"chain_*", "system_name"},
+			method:   "system_name",
+			permited: true,
+		},
+		"deny list takes precedence": {
+			allowed: []string{"chain_*"},
+			denied:  []string{"chain_getBlock"},
+			method:  "chain_getBlock",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := newMethodFilter(testCase.allowed, testCase.denied)
+			permited := filter.permits(testCase.method)
+			assert.Equal(t, testCase.permited, permited)
+		})
+	}
+}
+
+func Test_listenerPolicy_check(t *testing.T) {
+	t.Parallel()
+
+	policy := &listenerPolicy{
+		listener: httpListener,
+		filter:   newMethodFilter(nil, []string{"author_*"}),
+		limiter:  newRateLimiter(1, 1, nil),
+	}
+
+	err := policy.check("1.1.1.1", "author_submitExtrinsic")
+	assert.ErrorIs(t, err, errMethodNotAllowed)
+
+	err = policy.check("1.1.1.1", "system_name")
+	assert.NoError(t, err)
+
+	err = policy.check("1.1.1.1", "system_name")
+	assert.ErrorIs(t, err, errRateLimited)
+}
@@ -22,6 +22,9 @@ type Params struct {
 // InvalidRequestMessage error message for invalid request parameters
 const InvalidRequestMessage = "Invalid request"
 
+// RequestRejectedCode error code returned when a call is rejected by the listener policy
+const RequestRejectedCode = -32005
+
 func newSubcriptionBaseResponseJSON() BaseResponseJSON {
 	return BaseResponseJSON{
 		Jsonrpc: "2.0",
@@ -26,6 +26,10 @@ type httpclient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// ForwardedCallHeader is the HTTP header set on the websocket calls
+// forwarded to the HTTP listener of the node.
+const ForwardedCallHeader = "X-Gossamer-Ws-Forwarded"
+
 var errCannotReadFromWebsocket = errors.New("cannot read message from websocket")
 var errCannotUnmarshalMessage = errors.New("cannot unmarshal webasocket message data")
 var logger = log.NewFromGlobal(log.AddContext("pkg", "rpc/subscription"))
@@ -43,6 +47,12 @@ type WSConn struct {
 	TxStateAPI    modules.TransactionStateAPI
 	RPCHost       string
 	HTTP          httpclient
+	// CallFilter, if set, is called for every method received and the
+	// call is rejected if it returns an error.
+	CallFilter func(method string) error
+	// ForwardedCallSecret is set in the forwarded call header of the calls
+	// forwarded to the HTTP listener, to authenticate them as coming from the node.
+	ForwardedCallSecret string
 }
 
 // readWebsocketMessage will read and parse the message data to a string->interface{} data
@@ -87,6 +97,14 @@ func (c *WSConn) HandleComm() {
 		logger.Debugf("ws method %s called with params %v", method, params)
 
 		if !strings.Contains(method, "_unsubscribe") && !strings.Contains(method, "_unwatch") {
+			if c.CallFilter != nil {
+				if err = c.CallFilter(method); err != nil {
+					logger.Debugf("ws method %s rejected: %s", method, err)
+					c.safeSendError(reqid, big.NewInt(RequestRejectedCode), err.Error())
+					continue
+				}
+			}
+
 			setupListener := c.getSetupListener(method)
 
 			if setupListener == nil {
@@ -411,6 +429,9 @@ func (c *WSConn) prepareRequest(b []byte) (*http.Request, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json;")
+	if c.ForwardedCallSecret != "" {
+		req.Header.Set(ForwardedCallHeader, c.ForwardedCallSecret)
+	}
 	return req, nil
 }
 
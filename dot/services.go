@@ -6,6 +6,7 @@
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/ChainSafe/chaindb"
@@ -31,7 +32,9 @@
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/runtime/life"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
 	"github.com/ChainSafe/gossamer/lib/utils"
 )
 
@@ -55,15 +58,25 @@ func newInMemoryDB() (chaindb.Database, error) {
 func (nodeBuilder) createStateService(cfg *Config) (*state.Service, error) {
 	logger.Debug("creating state service...")
 
+	newInstance, err := newInstanceFunc(cfg.Core.WasmInterpreter)
+	if err != nil {
+		return nil, err
+	}
+
 	config := state.Config{
 		Path:     cfg.Global.BasePath,
 		LogLevel: cfg.Log.StateLvl,
 		Metrics:  metrics.NewIntervalConfig(cfg.Global.PublishMetrics),
+
+		CacheSize:       cfg.State.CacheSize,
+		BlocksPruning:   cfg.State.BlocksPruning,
+		RuntimePoolSize: cfg.State.RuntimePoolSize,
+		NewInstance:     newInstance,
 	}
 
 	stateSrvc := state.NewService(config)
 
-	err := stateSrvc.SetupBase()
+	err = stateSrvc.SetupBase()
 	if err != nil {
 		return nil, fmt.Errorf("cannot setup base: %w", err)
 	}
@@ -132,47 +145,102 @@ func createRuntime(cfg *Config, ns runtime.NodeStorage, st *state.Service,
 		return nil, err
 	}
 
-	var rt runtime.Instance
-	switch cfg.Core.WasmInterpreter {
+	rt, err := newRuntimeInstance(cfg, ns, ks, net, code, ts, codeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := rt.Version()
+	if err != nil {
+		rt.Stop()
+		return nil, fmt.Errorf("cannot get runtime version: %w", err)
+	}
+
+	if err = runtime.CheckRequiredAPIs(version); err != nil {
+		rt.Stop()
+		return nil, err
+	}
+
+	st.Block.StoreRuntime(st.Block.BestBlockHash(), rt)
+	return rt, nil
+}
+
+// setRuntimeLogTargets applies the configured log directives to the runtime log
+// target loggers only, so they do not change the levels of the node packages
+// sharing a name with a runtime log target, such as grandpa.
+func setRuntimeLogTargets(runtimeTargets string) error {
+	directives, err := log.ParseDirectives(runtimeTargets)
+	if err != nil {
+		return fmt.Errorf("cannot parse runtime log targets: %w", err)
+	}
+
+	for i := range directives {
+		directives[i].TargetOnly = true
+	}
+
+	log.SetDefaultFilter(directives...)
+	return nil
+}
+
+// runtimeCacheDir is the directory of the base path where
+// the compiled runtime modules are stored.
+const runtimeCacheDir = "runtime-cache"
+
+// newInstanceFunc returns the function creating the runtime
+// instances with the given wasm interpreter.
+func newInstanceFunc(interpreter string) (runtime.NewInstanceFunc, error) {
+	switch interpreter {
 	case wasmer.Name:
-		rtCfg := &wasmer.Config{
-			Imports: wasmer.ImportsNodeRuntime,
-		}
-		rtCfg.Storage = ts
-		rtCfg.Keystore = ks
-		rtCfg.LogLvl = cfg.Log.RuntimeLvl
-		rtCfg.NodeStorage = ns
-		rtCfg.Network = net
-		rtCfg.Role = cfg.Core.Roles
-		rtCfg.CodeHash = codeHash
-
-		// create runtime executor
-		rt, err = wasmer.NewInstance(code, rtCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create runtime executor: %s", err)
-		}
+		return wasmer.NewRuntimeInstance, nil
 	case life.Name:
-		rtCfg := &life.Config{
-			Resolver: new(life.Resolver),
-		}
-		rtCfg.Storage = ts
-		rtCfg.Keystore = ks
-		rtCfg.LogLvl = cfg.Log.RuntimeLvl
-		rtCfg.NodeStorage = ns
-		rtCfg.Network = net
-		rtCfg.Role = cfg.Core.Roles
-		rtCfg.CodeHash = codeHash
-
-		// create runtime executor
-		rt, err = life.NewInstance(code, rtCfg)
+		return life.NewRuntimeInstance, nil
+	case wazero.Name:
+		return wazero.NewRuntimeInstance, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrWasmInterpreterName, interpreter)
+	}
+}
+
+// newRuntimeInstance creates a runtime instance of the given code with the
+// interpreter of the configuration, using the given trie state as storage.
+func newRuntimeInstance(cfg *Config, ns runtime.NodeStorage, ks *keystore.GlobalKeystore,
+	net *network.Service, code []byte, ts *rtstorage.TrieState, codeHash common.Hash) (
+	rt runtime.Instance, err error) {
+	err = setRuntimeLogTargets(cfg.Log.RuntimeTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	newInstance, err := newInstanceFunc(cfg.Core.WasmInterpreter)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Core.WasmInterpreter == wasmer.Name && cfg.Global.BasePath != "" {
+		err = wasmer.SetModuleCacheDir(filepath.Join(cfg.Global.BasePath, runtimeCacheDir))
 		if err != nil {
-			return nil, fmt.Errorf("failed to create runtime executor: %s", err)
+			return nil, fmt.Errorf("cannot set runtime module cache directory: %w", err)
 		}
-	default:
-		return nil, fmt.Errorf("%w: %s", ErrWasmInterpreterName, cfg.Core.WasmInterpreter)
 	}
 
-	st.Block.StoreRuntime(st.Block.BestBlockHash(), rt)
+	runtime.SetProfiling(cfg.Global.RuntimeProfiling)
+
+	rtCfg := runtime.InstanceConfig{
+		Storage:     ts,
+		Keystore:    ks,
+		LogLvl:      cfg.Log.RuntimeLvl,
+		NodeStorage: ns,
+		Network:     net,
+		Role:        cfg.Core.Roles,
+		CodeHash:    codeHash,
+	}
+
+	// create runtime executor
+	rt, err = newInstance(code, rtCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runtime executor: %s", err)
+	}
+
 	return rt, nil
 }
 
@@ -268,6 +336,14 @@ func (nodeBuilder) createCoreService(cfg *Config, ks *keystore.GlobalKeystore,
 		CodeSubstitutedState: st.Base,
 	}
 
+	coreConfig.NewInstance, err = newInstanceFunc(cfg.Core.WasmInterpreter)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Core.WasmInterpreter == wasmer.Name {
+		coreConfig.Precompile = wasmer.PrecompileModule
+	}
+
 	// create new core service
 	coreSrvc, err := core.NewService(coreConfig)
 	if err != nil {
@@ -376,6 +452,13 @@ func (nodeBuilder) createRPCService(params rpcServiceSettings) (*rpc.HTTPServer,
 		WSUnsafeExternal:    params.config.RPC.WSUnsafeExternal,
 		WSPort:              params.config.RPC.WSPort,
 		Modules:             params.config.RPC.Modules,
+		RateLimit:           params.config.RPC.RateLimit,
+		RateLimitBurst:      params.config.RPC.RateLimitBurst,
+		MethodCosts:         params.config.RPC.MethodCosts,
+		RPCAllowedMethods:   params.config.RPC.AllowedMethods,
+		RPCDeniedMethods:    params.config.RPC.DeniedMethods,
+		WSAllowedMethods:    params.config.RPC.WSAllowedMethods,
+		WSDeniedMethods:     params.config.RPC.WSDeniedMethods,
 	}
 
 	return rpc.NewHTTPServer(rpcConfig), nil
@@ -464,6 +547,7 @@ func (nodeBuilder) newSyncService(cfg *Config, st *state.Service, fg sync.Finali
 		MaxPeers:           cfg.Network.MaxPeers,
 		SlotDuration:       slotDuration,
 		Telemetry:          telemetryMailer,
+		TimestampDrift:     cfg.Core.TimestampDrift,
 	}
 
 	return sync.NewService(syncCfg)
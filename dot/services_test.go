@@ -518,6 +518,18 @@ type args struct {
 	}
 }
 
+func Test_setRuntimeLogTargets(t *testing.T) {
+	t.Parallel()
+
+	err := setRuntimeLogTargets("")
+	require.NoError(t, err)
+
+	err = setRuntimeLogTargets("runtime::system=debug=trace")
+	assert.ErrorIs(t, err, log.ErrDirectiveMalformed)
+	assert.EqualError(t, err, "cannot parse runtime log targets: "+
+		"log directive is malformed: runtime::system=debug=trace")
+}
+
 func Test_nodeBuilder_newSyncService(t *testing.T) {
 	t.Parallel()
 	finalityGadget := &grandpa.Service{}
@@ -0,0 +1,58 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// ExportSnapshot writes a snapshot of the state of the block with the given number to the
+// writer. The state of the highest finalised block is exported if the number is nil.
+func ExportSnapshot(cfg *Config, blockNumber *uint, w io.Writer) error {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if err := stateSrvc.Stop(); err != nil {
+			logger.Errorf("cannot stop state service: %s", err)
+		}
+	}()
+
+	var hash common.Hash
+	if blockNumber == nil {
+		hash, err = stateSrvc.Block.GetHighestFinalisedHash()
+		if err != nil {
+			return fmt.Errorf("cannot get highest finalised hash: %w", err)
+		}
+	} else {
+		hash, err = stateSrvc.Block.GetHashByNumber(*blockNumber)
+		if err != nil {
+			return fmt.Errorf("cannot get hash of block %d: %w", *blockNumber, err)
+		}
+	}
+
+	return stateSrvc.ExportSnapshot(hash, w)
+}
+
+// ImportSnapshot imports the state snapshot read from the reader to the database
+// with the given path, and sets the head of the chain to the block of the snapshot.
+func ImportSnapshot(basepath string, r io.Reader) error {
+	config := state.Config{
+		Path:     basepath,
+		LogLevel: log.Info,
+	}
+	srv := state.NewService(config)
+	return srv.ImportSnapshot(r)
+}
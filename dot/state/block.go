@@ -12,6 +12,7 @@
 	"time"
 
 	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
@@ -59,6 +60,16 @@ type BlockState struct {
 	lastFinalised     common.Hash
 	unfinalisedBlocks *hashToBlockMap
 	tries             *Tries
+	blocksPruning     pruner.BlocksConfig
+	statePruner       pruner.Pruner
+	runtimePools      *runtimePools
+	// newInstance creates the runtime instances of
+	// runtime code changes with the wasm interpreter of the node.
+	newInstance runtime.NewInstanceFunc
+
+	// indexedTransactions holds the data indexed by the runtime, keyed by content hash
+	indexedTransactions     chaindb.Database
+	indexedTransactionsLock sync.Mutex
 
 	// block notifiers
 	imported                       map[chan *types.Block]struct{}
@@ -77,8 +88,13 @@ func NewBlockState(db chaindb.Database, trs *Tries, telemetry telemetry.Client)
 		dbPath:                     db.Path(),
 		baseState:                  NewBaseState(db),
 		db:                         chaindb.NewTable(db, blockPrefix),
+		indexedTransactions:        chaindb.NewTable(db, indexedTransactionsPrefix),
 		unfinalisedBlocks:          newHashToBlockMap(),
 		tries:                      trs,
+		statePruner:                &pruner.ArchiveNode{},
+		blocksPruning:              pruner.BlocksConfig{Mode: pruner.DefaultBlocksMode},
+		runtimePools:               newRuntimePools(runtime.DefaultPoolSize),
+		newInstance:                wasmer.NewRuntimeInstance,
 		imported:                   make(map[chan *types.Block]struct{}),
 		finalised:                  make(map[chan *types.FinalisationInfo]struct{}),
 		runtimeUpdateSubscriptions: make(map[uint32]chan<- runtime.Version),
@@ -110,8 +126,13 @@ func NewBlockStateFromGenesis(db chaindb.Database, trs *Tries, header *types.Hea
 		bt:                         blocktree.NewBlockTreeFromRoot(header),
 		baseState:                  NewBaseState(db),
 		db:                         chaindb.NewTable(db, blockPrefix),
+		indexedTransactions:        chaindb.NewTable(db, indexedTransactionsPrefix),
 		unfinalisedBlocks:          newHashToBlockMap(),
 		tries:                      trs,
+		statePruner:                &pruner.ArchiveNode{},
+		blocksPruning:              pruner.BlocksConfig{Mode: pruner.DefaultBlocksMode},
+		runtimePools:               newRuntimePools(runtime.DefaultPoolSize),
+		newInstance:                wasmer.NewRuntimeInstance,
 		imported:                   make(map[chan *types.Block]struct{}),
 		finalised:                  make(map[chan *types.FinalisationInfo]struct{}),
 		runtimeUpdateSubscriptions: make(map[uint32]chan<- runtime.Version),
@@ -386,6 +407,7 @@ func (bs *BlockState) AddBlockWithArrivalTime(block *types.Block, arrivalTime ti
 	}
 
 	bs.unfinalisedBlocks.store(block)
+	bs.pinBestStateRoot()
 	go bs.notifyImported(block)
 	return nil
 }
@@ -402,7 +424,26 @@ func (bs *BlockState) AddBlockToBlockTree(block *types.Block) error {
 	}
 
 	bs.unfinalisedBlocks.store(block)
-	return bs.bt.AddBlock(&block.Header, arrivalTime)
+	err = bs.bt.AddBlock(&block.Header, arrivalTime)
+	if err != nil {
+		return err
+	}
+
+	bs.pinBestStateRoot()
+	return nil
+}
+
+// pinBestStateRoot keeps the trie of the best block in memory
+// until another block becomes the best block.
+// It must be called with the block state lock held.
+func (bs *BlockState) pinBestStateRoot() {
+	header, err := bs.GetHeader(bs.BestBlockHash())
+	if err != nil {
+		logger.Debugf("cannot pin best block state root: %s", err)
+		return
+	}
+
+	bs.tries.pinBest(header.StateRoot)
 }
 
 // GetAllBlocksAtNumber returns all unfinalised blocks with the given number
@@ -577,18 +618,28 @@ func (bs *BlockState) HandleRuntimeChanges(newState *rtstorage.TrieState,
 	}
 
 	codeHash := rt.GetCodeHash()
-	if bytes.Equal(codeHash[:], currCodeHash[:]) {
+	codeChanged := !bytes.Equal(codeHash[:], currCodeHash[:])
+	heapPages := runtime.HeapPages(newState)
+	if !codeChanged && rt.GetHeapPages() == heapPages {
 		bs.StoreRuntime(bHash, rt)
 		return nil
 	}
 
-	logger.Infof("🔄 detected runtime code change, upgrading with block %s from previous code hash %s to new code hash %s...", //nolint:lll
-		bHash, codeHash, currCodeHash)
 	code := newState.LoadCode()
 	if len(code) == 0 {
 		return errors.New("new :code is empty")
 	}
 
+	if !codeChanged {
+		// the memory of an instance cannot be resized, so the runtime is re-instantiated.
+		logger.Infof("🔄 detected runtime heap pages change with block %s from %d to %d heap pages...",
+			bHash, rt.GetHeapPages(), heapPages)
+		return bs.storeNewRuntime(bHash, rt, code, newState, currCodeHash)
+	}
+
+	logger.Infof("🔄 detected runtime code change, upgrading with block %s from previous code hash %s to new code hash %s...", //nolint:lll
+		bHash, codeHash, currCodeHash)
+
 	codeSubBlockHash := bs.baseState.LoadCodeSubstitutedBlockHash()
 
 	if !codeSubBlockHash.Equal(common.Hash{}) {
@@ -610,38 +661,64 @@ func (bs *BlockState) HandleRuntimeChanges(newState *rtstorage.TrieState,
 			bHash, codeHash, previousVersion.SpecVersion(), currCodeHash, newVersion.SpecVersion())
 	}
 
-	rtCfg := &wasmer.Config{
-		Imports: wasmer.ImportsNodeRuntime,
+	err = bs.storeNewRuntime(bHash, rt, code, newState, currCodeHash)
+	if err != nil {
+		return err
+	}
+
+	err = bs.baseState.StoreCodeSubstitutedBlockHash(common.Hash{})
+	if err != nil {
+		return fmt.Errorf("failed to update code substituted block hash: %w", err)
+	}
+
+	newVersion, err := rt.Version()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve runtime version: %w", err)
 	}
+	go bs.notifyRuntimeUpdated(newVersion)
+	return nil
+}
 
-	rtCfg.Storage = newState
-	rtCfg.Keystore = rt.Keystore()
-	rtCfg.NodeStorage = rt.NodeStorage()
-	rtCfg.Network = rt.NetworkService()
-	rtCfg.CodeHash = currCodeHash
+// storeNewRuntime creates a runtime instance of the given code, with the services of the
+// given runtime and the heap pages of the given state, and stores it for the block hash
+// if it implements a supported version of the runtime APIs required by the node.
+func (bs *BlockState) storeNewRuntime(bHash common.Hash, rt runtime.Instance, code []byte,
+	newState *rtstorage.TrieState, codeHash common.Hash) error {
+	rtCfg := runtime.InstanceConfig{
+		Storage:     newState,
+		Keystore:    rt.Keystore(),
+		NodeStorage: rt.NodeStorage(),
+		Network:     rt.NetworkService(),
+		CodeHash:    codeHash,
+	}
 
 	if rt.Validator() {
 		rtCfg.Role = 4
 	}
 
-	instance, err := wasmer.NewInstance(code, rtCfg)
+	instance, err := bs.newInstance(code, rtCfg)
 	if err != nil {
 		return err
 	}
 
+	if err = checkRuntimeAPIs(instance); err != nil {
+		instance.Stop()
+		return err
+	}
+
 	bs.StoreRuntime(bHash, instance)
+	return nil
+}
 
-	err = bs.baseState.StoreCodeSubstitutedBlockHash(common.Hash{})
+// checkRuntimeAPIs returns an error if the runtime does not implement
+// a supported version of the runtime APIs required by the node.
+func checkRuntimeAPIs(rt runtime.Instance) error {
+	version, err := rt.Version()
 	if err != nil {
-		return fmt.Errorf("failed to update code substituted block hash: %w", err)
+		return fmt.Errorf("cannot get runtime version: %w", err)
 	}
 
-	newVersion, err := rt.Version()
-	if err != nil {
-		return fmt.Errorf("failed to retrieve runtime version: %w", err)
-	}
-	go bs.notifyRuntimeUpdated(newVersion)
-	return nil
+	return runtime.CheckRequiredAPIs(version)
 }
 
 // GetRuntime gets the runtime for the corresponding block hash.
@@ -657,6 +734,33 @@ func (bs *BlockState) GetRuntime(hash *common.Hash) (runtime.Instance, error) {
 	return bs.bt.GetBlockRuntime(*hash)
 }
 
+// GetRuntimePool gets the pool of instances of the runtime for the corresponding
+// block hash, or for the best block if the hash is nil. The instances checked out
+// from the pool are for the exclusive use of the caller until they are checked in.
+func (bs *BlockState) GetRuntimePool(hash *common.Hash) (*runtime.Pool, error) {
+	rt, err := bs.GetRuntime(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return bs.runtimePools.get(rt), nil
+}
+
+// pruneRuntimePools stops the runtime pools of the runtimes
+// which are not used by any block of the block tree anymore.
+func (bs *BlockState) pruneRuntimePools() {
+	inUse := make(map[runtimePoolKey]struct{})
+	for _, hash := range bs.bt.GetAllBlocks() {
+		rt, err := bs.bt.GetBlockRuntime(hash)
+		if err != nil {
+			continue
+		}
+		inUse[newRuntimePoolKey(rt)] = struct{}{}
+	}
+
+	bs.runtimePools.prune(inUse)
+}
+
 // StoreRuntime stores the runtime for corresponding block hash.
 func (bs *BlockState) StoreRuntime(hash common.Hash, rt runtime.Instance) {
 	bs.bt.StoreRuntime(hash, rt)
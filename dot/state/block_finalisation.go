@@ -123,10 +123,22 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		return fmt.Errorf("cannot finalise unknown block %s", hash)
 	}
 
+	header, err := bs.GetHeader(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get finalised header, hash: %s, error: %s", hash, err)
+	}
+
+	// keep the finalised state trie in memory
+	bs.tries.pinFinalised(header.StateRoot)
+
 	if err := bs.handleFinalisedBlock(hash); err != nil {
 		return fmt.Errorf("failed to set finalised subchain in db on finalisation: %w", err)
 	}
 
+	if err := bs.statePruner.Canonicalise(hash, int64(header.Number)); err != nil {
+		return fmt.Errorf("failed to canonicalise state journal on finalisation: %w", err)
+	}
+
 	if err := bs.db.Put(finalisedHashKey(round, setID), hash[:]); err != nil {
 		return fmt.Errorf("failed to set finalised hash key: %w", err)
 	}
@@ -140,17 +152,31 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 	}
 
 	pruned := bs.bt.Prune(hash)
+	prunedBlocks := make([]*types.Block, 0, len(pruned))
 	for _, hash := range pruned {
+		block := bs.unfinalisedBlocks.getBlock(hash)
 		blockHeader := bs.unfinalisedBlocks.delete(hash)
 		if blockHeader == nil {
 			continue
 		}
 
-		bs.tries.delete(blockHeader.StateRoot)
+		prunedBlocks = append(prunedBlocks, block)
 
 		logger.Tracef("pruned block number %d with hash %s", blockHeader.Number, hash)
 	}
 
+	bs.pruneRuntimePools()
+
+	if err := bs.handlePrunedBlocks(prunedBlocks); err != nil {
+		return fmt.Errorf("failed to handle pruned blocks: %w", err)
+	}
+
+	if err := bs.pruneFinalisedBodies(header.Number, maxPrunedBodiesPerFinalisation); err != nil {
+		return fmt.Errorf("failed to prune finalised block bodies: %w", err)
+	}
+
+	bs.pinBestStateRoot()
+
 	// if nothing was previously finalised, set the first slot of the network to the
 	// slot number of block 1, which is now being set as final
 	if bs.lastFinalised.Equal(bs.genesisHash) && !hash.Equal(bs.genesisHash) {
@@ -159,11 +185,6 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		}
 	}
 
-	header, err := bs.GetHeader(hash)
-	if err != nil {
-		return fmt.Errorf("failed to get finalised header, hash: %s, error: %s", hash, err)
-	}
-
 	bs.telemetry.SendMessage(
 		telemetry.NewNotifyFinalized(
 			header.Hash(),
@@ -171,33 +192,10 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		),
 	)
 
-	if !bs.lastFinalised.Equal(hash) {
-		defer func(lastFinalised common.Hash) {
-			err := bs.deleteFromTries(lastFinalised)
-			if err != nil {
-				logger.Debugf("%v", err)
-			}
-		}(bs.lastFinalised)
-	}
-
 	bs.lastFinalised = hash
 	return nil
 }
 
-func (bs *BlockState) deleteFromTries(lastFinalised common.Hash) error {
-	lastFinalisedHeader, err := bs.GetHeader(lastFinalised)
-	if err != nil {
-		return fmt.Errorf("unable to retrieve header for last finalised block, hash: %s, err: %s", bs.lastFinalised, err)
-	}
-	stateRootTrie := bs.tries.get(lastFinalisedHeader.StateRoot)
-	if stateRootTrie != nil {
-		bs.tries.delete(lastFinalisedHeader.StateRoot)
-	} else {
-		return fmt.Errorf("unable to find trie with stateroot hash: %s", lastFinalisedHeader.StateRoot)
-	}
-	return nil
-}
-
 func (bs *BlockState) handleFinalisedBlock(curr common.Hash) error {
 	if curr.Equal(bs.lastFinalised) {
 		return nil
@@ -251,14 +249,12 @@ func (bs *BlockState) handleFinalisedBlock(curr common.Hash) error {
 			return err
 		}
 
-		// delete from the unfinalisedBlockMap and delete reference to in-memory trie
+		// delete from the unfinalisedBlockMap
 		blockHeader := bs.unfinalisedBlocks.delete(hash)
 		if blockHeader == nil {
 			continue
 		}
 
-		bs.tries.delete(blockHeader.StateRoot)
-
 		logger.Tracef("cleaned out finalised block from memory; block number %d with hash %s", blockHeader.Number, hash)
 	}
 	return batch.Flush()
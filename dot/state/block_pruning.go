@@ -0,0 +1,167 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// lastPrunedBodyKey is the key of the number of the last
+// finalised block whose body was pruned.
+var lastPrunedBodyKey = []byte("last_pruned_body")
+
+// maxPrunedBodiesPerFinalisation is the maximum number of finalised block
+// bodies pruned on each finalisation, so enabling the pruning on an existing
+// database prunes the old bodies over several finalisations.
+const maxPrunedBodiesPerFinalisation = 512
+
+// handlePrunedBlocks handles the blocks of the forks pruned from the block tree on
+// finalisation. They are written to the database in archive mode, and their data
+// possibly written to the database, such as their justification and indexed
+// transactions, is deleted in the archive canonical and keep finalised modes.
+func (bs *BlockState) handlePrunedBlocks(blocks []*types.Block) error {
+	switch bs.blocksPruning.Mode {
+	case pruner.BlocksArchive, pruner.BlocksArchiveCanonical, pruner.BlocksKeepFinalised:
+	default:
+		return fmt.Errorf("unknown block pruning mode: %q", bs.blocksPruning.Mode)
+	}
+
+	batch := bs.db.NewBatch()
+
+	var deleted []common.Hash
+	for _, block := range blocks {
+		hash := block.Header.Hash()
+
+		if bs.blocksPruning.Mode == pruner.BlocksArchive {
+			encodedHeader, err := scale.Marshal(block.Header)
+			if err != nil {
+				return fmt.Errorf("cannot encode header of pruned block %s: %w", hash, err)
+			}
+
+			encodedBody, err := scale.Marshal(block.Body)
+			if err != nil {
+				return fmt.Errorf("cannot encode body of pruned block %s: %w", hash, err)
+			}
+
+			if err = batch.Put(headerKey(hash), encodedHeader); err != nil {
+				return err
+			}
+
+			if err = batch.Put(blockBodyKey(hash), encodedBody); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		keys := [][]byte{
+			headerKey(hash),
+			blockBodyKey(hash),
+			arrivalTimeKey(hash),
+			prefixKey(hash, receiptPrefix),
+			prefixKey(hash, messageQueuePrefix),
+			prefixKey(hash, justificationPrefix),
+		}
+		for _, key := range keys {
+			if err := batch.Del(key); err != nil {
+				return err
+			}
+		}
+		deleted = append(deleted, hash)
+	}
+
+	if err := bs.releaseIndexedTransactions(deleted); err != nil {
+		return fmt.Errorf("cannot release indexed transactions of pruned blocks: %w", err)
+	}
+
+	return batch.Flush()
+}
+
+// pruneFinalisedBodies deletes the bodies, receipts, message queues and indexed transactions
+// of the finalised blocks older than the retained blocks in keep finalised mode.
+// The headers and justifications are kept for GRANDPA and BABE verification.
+// At most maxBodies bodies are pruned, the next bodies being pruned on the next calls
+// from the number of the last block pruned stored in the database.
+func (bs *BlockState) pruneFinalisedBodies(finalisedNumber, maxBodies uint) error {
+	if bs.blocksPruning.Mode != pruner.BlocksKeepFinalised ||
+		finalisedNumber <= bs.blocksPruning.RetainedBlocks {
+		return nil
+	}
+
+	lastPruned, err := bs.getLastPrunedBody()
+	if err != nil {
+		return fmt.Errorf("cannot get last pruned block body: %w", err)
+	}
+
+	pruneUntil := finalisedNumber - bs.blocksPruning.RetainedBlocks
+	if lastPruned >= pruneUntil {
+		return nil
+	}
+
+	if pruneUntil-lastPruned > maxBodies {
+		pruneUntil = lastPruned + maxBodies
+	}
+
+	batch := bs.db.NewBatch()
+
+	// the genesis block body is never pruned
+	pruned := make([]common.Hash, 0, pruneUntil-lastPruned)
+	for number := lastPruned + 1; number <= pruneUntil; number++ {
+		encodedHash, err := bs.db.Get(headerHashKey(uint64(number)))
+		if err != nil {
+			return fmt.Errorf("cannot get hash of finalised block number %d: %w", number, err)
+		}
+		hash := common.NewHash(encodedHash)
+
+		keys := [][]byte{
+			blockBodyKey(hash),
+			prefixKey(hash, receiptPrefix),
+			prefixKey(hash, messageQueuePrefix),
+		}
+		for _, key := range keys {
+			if err := batch.Del(key); err != nil {
+				return err
+			}
+		}
+		pruned = append(pruned, hash)
+	}
+
+	if err = bs.releaseIndexedTransactions(pruned); err != nil {
+		return fmt.Errorf("cannot release indexed transactions of pruned block bodies: %w", err)
+	}
+
+	encodedNumber := make([]byte, 8)
+	binary.LittleEndian.PutUint64(encodedNumber, uint64(pruneUntil))
+	if err = batch.Put(lastPrunedBodyKey, encodedNumber); err != nil {
+		return err
+	}
+
+	if err = batch.Flush(); err != nil {
+		return err
+	}
+
+	logger.Debugf("pruned bodies of finalised blocks %d to %d", lastPruned+1, pruneUntil)
+	return nil
+}
+
+// getLastPrunedBody returns the number of the last finalised
+// block whose body was pruned, or 0 if no body was pruned.
+func (bs *BlockState) getLastPrunedBody() (number uint, err error) {
+	encodedNumber, err := bs.db.Get(lastPrunedBodyKey)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return uint(binary.LittleEndian.Uint64(encodedNumber)), nil
+}
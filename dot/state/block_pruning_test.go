@@ -0,0 +1,170 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockState_pruneFinalisedBodies(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	bs.blocksPruning = pruner.BlocksConfig{
+		Mode:           pruner.BlocksKeepFinalised,
+		RetainedBlocks: 2,
+	}
+
+	chain, _ := AddBlocksToState(t, bs, 5, false)
+	for _, header := range chain {
+		err := bs.SetReceipt(header.Hash(), []byte{1})
+		require.NoError(t, err)
+	}
+
+	err := bs.SetFinalisedHash(chain[4].Hash(), 1, 0)
+	require.NoError(t, err)
+
+	for i, header := range chain {
+		hash := header.Hash()
+		pruned := header.Number <= 3
+
+		has, err := bs.HasBlockBody(hash)
+		require.NoError(t, err)
+		assert.Equal(t, !pruned, has, "body of block %d", i+1)
+
+		has, err = bs.HasReceipt(hash)
+		require.NoError(t, err)
+		assert.Equal(t, !pruned, has, "receipt of block %d", i+1)
+
+		has, err = bs.HasHeader(hash)
+		require.NoError(t, err)
+		assert.True(t, has, "header of block %d", i+1)
+	}
+
+	has, err := bs.HasBlockBody(testGenesisHeader.Hash())
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	lastPruned, err := bs.getLastPrunedBody()
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), lastPruned)
+}
+
+func TestBlockState_pruneFinalisedBodies_bounded(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	chain, _ := AddBlocksToState(t, bs, 5, false)
+
+	// finalise the blocks before enabling the pruning
+	err := bs.SetFinalisedHash(chain[4].Hash(), 1, 0)
+	require.NoError(t, err)
+
+	bs.blocksPruning = pruner.BlocksConfig{
+		Mode:           pruner.BlocksKeepFinalised,
+		RetainedBlocks: 1,
+	}
+
+	const maxBodies = 3
+	for _, expectedLastPruned := range []uint{3, 4, 4} {
+		err = bs.pruneFinalisedBodies(5, maxBodies)
+		require.NoError(t, err)
+
+		lastPruned, err := bs.getLastPrunedBody()
+		require.NoError(t, err)
+		assert.Equal(t, expectedLastPruned, lastPruned)
+
+		for _, header := range chain {
+			has, err := bs.HasBlockBody(header.Hash())
+			require.NoError(t, err)
+			assert.Equal(t, header.Number > expectedLastPruned, has,
+				"body of block %d", header.Number)
+		}
+	}
+}
+
+func TestBlockState_handlePrunedBlocks(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		mode     pruner.BlocksMode
+		forkKept bool
+	}{
+		"archive": {
+			mode:     pruner.BlocksArchive,
+			forkKept: true,
+		},
+		"default": {
+			mode: pruner.DefaultBlocksMode,
+		},
+		"archive canonical": {
+			mode: pruner.BlocksArchiveCanonical,
+		},
+		"keep finalised": {
+			mode: pruner.BlocksKeepFinalised,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+			bs.blocksPruning = pruner.BlocksConfig{
+				Mode:           testCase.mode,
+				RetainedBlocks: 10,
+			}
+
+			chain, _ := AddBlocksToState(t, bs, 2, false)
+
+			preDigest, err := types.NewBabeSecondaryPlainPreDigest(1, 1).ToPreRuntimeDigest()
+			require.NoError(t, err)
+			digest := types.NewDigest()
+			err = digest.Add(*preDigest)
+			require.NoError(t, err)
+			fork := &types.Block{
+				Header: types.Header{
+					ParentHash: testGenesisHeader.Hash(),
+					Number:     1,
+					StateRoot:  trie.EmptyHash,
+					Digest:     digest,
+				},
+				Body: types.Body{},
+			}
+			forkHash := fork.Header.Hash()
+			err = bs.AddBlock(fork)
+			require.NoError(t, err)
+			err = bs.SetJustification(forkHash, []byte{1})
+			require.NoError(t, err)
+
+			err = bs.SetFinalisedHash(chain[1].Hash(), 1, 0)
+			require.NoError(t, err)
+
+			has, err := bs.HasHeader(forkHash)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.forkKept, has)
+
+			has, err = bs.HasBlockBody(forkHash)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.forkKept, has)
+
+			has, err = bs.HasJustification(forkHash)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.forkKept, has)
+
+			for _, header := range chain {
+				has, err = bs.HasBlockBody(header.Hash())
+				require.NoError(t, err)
+				assert.True(t, has)
+			}
+		})
+	}
+}
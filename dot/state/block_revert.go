@@ -0,0 +1,163 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// revert removes the blocks with a number greater than the number of the given header,
+// so the best block becomes the highest block remaining. If the header is below the
+// highest finalised block, the finalised blocks above it are removed from the database
+// and it is set as the highest finalised block in the given GRANDPA set.
+// It returns the hashes of the removed blocks.
+func (bs *BlockState) revert(header *types.Header, setID uint64) (reverted []common.Hash, err error) {
+	bs.Lock()
+	defer bs.Unlock()
+
+	finalised, err := bs.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get highest finalised header: %w", err)
+	}
+
+	if header.Number >= finalised.Number {
+		reverted, err = bs.bt.Revert(header.Number)
+		if err != nil {
+			return nil, fmt.Errorf("cannot revert blocktree: %w", err)
+		}
+	} else {
+		for _, hash := range bs.bt.GetAllBlocks() {
+			if !hash.Equal(bs.lastFinalised) {
+				reverted = append(reverted, hash)
+			}
+		}
+
+		finalisedReverted, err := bs.revertFinalised(header, finalised, setID)
+		if err != nil {
+			return nil, err
+		}
+		reverted = append(reverted, finalisedReverted...)
+
+		bs.bt = blocktree.NewBlockTreeFromRoot(header)
+		bs.lastFinalised = header.Hash()
+		bs.tries.pinFinalised(header.StateRoot)
+	}
+
+	for _, hash := range reverted {
+		blockHeader := bs.unfinalisedBlocks.delete(hash)
+		if blockHeader == nil {
+			continue
+		}
+
+		logger.Tracef("reverted block number %d with hash %s", blockHeader.Number, hash)
+	}
+
+	if err = bs.releaseIndexedTransactions(reverted); err != nil {
+		return nil, fmt.Errorf("cannot release indexed transactions of reverted blocks: %w", err)
+	}
+
+	bs.pinBestStateRoot()
+	return reverted, nil
+}
+
+// revertFinalised deletes the finalised blocks above the given header from the database,
+// and sets the header as the highest finalised block in the first round of the given
+// GRANDPA set. The finalised hashes of the rounds after it are deleted, so the rounds
+// are finalised again. It returns the hashes of the deleted blocks.
+func (bs *BlockState) revertFinalised(header, finalised *types.Header, setID uint64) (
+	reverted []common.Hash, err error) {
+	batch := bs.db.NewBatch()
+
+	for number := finalised.Number; number > header.Number; number-- {
+		encodedHash, err := bs.db.Get(headerHashKey(uint64(number)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot get hash of finalised block %d: %w", number, err)
+		}
+		hash := common.NewHash(encodedHash)
+
+		keys := [][]byte{
+			headerHashKey(uint64(number)),
+			headerKey(hash),
+			blockBodyKey(hash),
+			arrivalTimeKey(hash),
+			prefixKey(hash, receiptPrefix),
+			prefixKey(hash, messageQueuePrefix),
+			prefixKey(hash, justificationPrefix),
+		}
+		for _, key := range keys {
+			if err = batch.Del(key); err != nil {
+				return nil, err
+			}
+		}
+
+		reverted = append(reverted, hash)
+	}
+
+	highestRound, highestSetID, err := bs.GetHighestRoundAndSetID()
+	if err != nil {
+		return nil, err
+	}
+
+	for id := highestSetID; id >= setID; id-- {
+		if err = bs.deleteFinalisedRounds(batch, id, id == setID, highestRound, id == highestSetID); err != nil {
+			return nil, err
+		}
+
+		if id == 0 {
+			break
+		}
+	}
+
+	hash := header.Hash()
+	if err = batch.Put(finalisedHashKey(0, setID), hash[:]); err != nil {
+		return nil, err
+	}
+
+	if err = batch.Put(highestRoundAndSetIDKey, roundAndSetIDToBytes(0, setID)); err != nil {
+		return nil, err
+	}
+
+	if err = batch.Flush(); err != nil {
+		return nil, fmt.Errorf("cannot write reverted finalised blocks: %w", err)
+	}
+
+	return reverted, nil
+}
+
+// deleteFinalisedRounds adds to the batch the deletion of the finalised hashes of the rounds
+// of the given set, starting from round one if keepRoundZero is true and from round
+// zero otherwise. The rounds of the highest set are deleted up to the highest round, and
+// the rounds of the other sets up to the first round without a finalised hash.
+func (bs *BlockState) deleteFinalisedRounds(batch chaindb.Batch, setID uint64, keepRoundZero bool,
+	highestRound uint64, isHighestSet bool) error {
+	var round uint64
+	if keepRoundZero {
+		round = 1
+	}
+
+	for ; ; round++ {
+		key := finalisedHashKey(round, setID)
+		has, err := bs.db.Has(key)
+		if err != nil {
+			return fmt.Errorf("cannot check finalised hash of round %d in set %d: %w", round, setID, err)
+		}
+
+		if !has {
+			// the round zero of a set may not be finalised
+			if (isHighestSet && round < highestRound) || round == 0 {
+				continue
+			}
+			return nil
+		}
+
+		if err = batch.Del(key); err != nil {
+			return err
+		}
+	}
+}
@@ -9,6 +9,10 @@
 
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/golang/mock/gomock"
@@ -545,3 +549,43 @@ func TestNumberIsFinalised(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, fin)
 }
+
+func TestBlockState_storeNewRuntime(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	genesisHash := testGenesisHeader.Hash()
+
+	ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, err)
+
+	rt := new(mocksruntime.Instance)
+	rt.On("Keystore").Return(keystore.NewGlobalKeystore())
+	rt.On("NodeStorage").Return(runtime.NodeStorage{})
+	rt.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+	rt.On("Validator").Return(true)
+
+	apiItems := make([]runtime.APIItem, len(runtime.RequiredAPIs))
+	for i, api := range runtime.RequiredAPIs {
+		apiItems[i] = runtime.APIItem{Name: runtime.APIID(api.Name), Ver: api.MinVersion}
+	}
+	instance := new(mocksruntime.Instance)
+	instance.On("Version").Return(runtime.NewVersionData([]byte("node"), nil, 0, 1, 0, apiItems, 0), nil)
+
+	code := []byte{1, 2, 3}
+	codeHash := common.Hash{4}
+	bs.newInstance = func(instanceCode []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+		require.Equal(t, code, instanceCode)
+		require.Equal(t, ts, cfg.Storage)
+		require.Equal(t, codeHash, cfg.CodeHash)
+		require.Equal(t, byte(4), cfg.Role)
+		return instance, nil
+	}
+
+	err = bs.storeNewRuntime(genesisHash, rt, code, ts, codeHash)
+	require.NoError(t, err)
+
+	stored, err := bs.GetRuntime(&genesisHash)
+	require.NoError(t, err)
+	require.Same(t, instance, stored)
+}
@@ -497,6 +497,85 @@ func (s *EpochState) SetFirstSlot(slot uint64) error {
 	return s.baseState.storeFirstSlot(slot)
 }
 
+// revert sets the current epoch to the epoch of the given header, which is the new best
+// block, and removes the BABE epoch and config data announced by the reverted blocks.
+// The data of the next epoch is kept, since it is announced by the first block of the
+// current epoch.
+func (s *EpochState) revert(header *types.Header, reverted []common.Hash) error {
+	var epoch uint64
+	if header.Number > 0 {
+		var err error
+		epoch, err = s.GetEpochForBlock(header)
+		if err != nil {
+			return fmt.Errorf("cannot get epoch for block %d: %w", header.Number, err)
+		}
+	}
+
+	if err := s.SetCurrentEpoch(epoch); err != nil {
+		return fmt.Errorf("cannot set current epoch: %w", err)
+	}
+
+	s.nextEpochDataLock.Lock()
+	for _, atEpoch := range s.nextEpochData {
+		for _, hash := range reverted {
+			delete(atEpoch, hash)
+		}
+	}
+	s.nextEpochDataLock.Unlock()
+
+	s.nextConfigDataLock.Lock()
+	for _, atEpoch := range s.nextConfigData {
+		for _, hash := range reverted {
+			delete(atEpoch, hash)
+		}
+	}
+	s.nextConfigDataLock.Unlock()
+
+	nextEpoch := epoch + 1
+	for e := nextEpoch + 1; ; e++ {
+		has, err := s.db.Has(epochDataKey(e))
+		if err != nil {
+			return fmt.Errorf("cannot check epoch data for epoch %d: %w", e, err)
+		} else if !has {
+			break
+		}
+
+		if err = s.db.Del(epochDataKey(e)); err != nil {
+			return fmt.Errorf("cannot delete epoch data for epoch %d: %w", e, err)
+		}
+	}
+
+	encodedLatest, err := s.db.Get(latestConfigDataKey)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get latest config data epoch: %w", err)
+	}
+
+	latest := binary.LittleEndian.Uint64(encodedLatest)
+	if latest <= nextEpoch {
+		return nil
+	}
+
+	for ; latest > nextEpoch; latest-- {
+		if err = s.db.Del(configDataKey(latest)); err != nil {
+			return fmt.Errorf("cannot delete config data for epoch %d: %w", latest, err)
+		}
+	}
+
+	// config data is only set for the epochs where it changes
+	for ; latest > 0; latest-- {
+		has, err := s.db.Has(configDataKey(latest))
+		if err != nil {
+			return fmt.Errorf("cannot check config data for epoch %d: %w", latest, err)
+		} else if has {
+			break
+		}
+	}
+
+	return s.setLatestConfigData(latest)
+}
+
 // SkipVerify returns whether verification for the given header should be skipped or not.
 // Only used in the case of imported state.
 func (s *EpochState) SkipVerify(header *types.Header) (bool, error) {
@@ -233,6 +233,39 @@ func (s *GrandpaState) GetSetIDByBlockNumber(num uint) (uint64, error) {
 	}
 }
 
+// revert sets the current set ID to the given set ID, which is the set of the block with
+// the given number, and removes the authority set changes scheduled after this block.
+func (s *GrandpaState) revert(setID uint64, number uint) error {
+	currSetID, err := s.GetCurrentSetID()
+	if err != nil {
+		return fmt.Errorf("cannot get current set ID: %w", err)
+	}
+
+	// the change to the next set ID may be scheduled but not enacted yet
+	for id := currSetID + 1; id > setID; id-- {
+		changeNumber, err := s.GetSetIDChange(id)
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("cannot get set ID change of set %d: %w", id, err)
+		}
+
+		if changeNumber <= number {
+			continue
+		}
+
+		if err = s.db.Del(setIDChangeKey(id)); err != nil {
+			return fmt.Errorf("cannot delete set ID change of set %d: %w", id, err)
+		}
+
+		if err = s.db.Del(authoritiesKey(id)); err != nil {
+			return fmt.Errorf("cannot delete authorities of set %d: %w", id, err)
+		}
+	}
+
+	return s.setCurrentSetID(setID)
+}
+
 // SetNextPause sets the next grandpa pause at the given block number
 func (s *GrandpaState) SetNextPause(number uint) error {
 	value := common.UintToBytes(number)
@@ -15,8 +15,8 @@
 
 func newTriesEmpty() *Tries {
 	return &Tries{
-		rootToTrie:    make(map[common.Hash]*trie.Trie),
-		triesGauge:    triesGauge,
+		pinned:        make(map[common.Hash]*trie.Trie),
+		nodeCache:     newNodeCache(DefaultNodeCacheSize),
 		setCounter:    setCounter,
 		deleteCounter: deleteCounter,
 	}
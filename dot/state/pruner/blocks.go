@@ -0,0 +1,70 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// BlocksArchive keeps all the blocks, including the blocks
+	// of the forks pruned on finalisation.
+	BlocksArchive = BlocksMode("archive")
+	// BlocksArchiveCanonical keeps all the finalised blocks and deletes
+	// the blocks of the forks pruned on finalisation.
+	BlocksArchiveCanonical = BlocksMode("archive-canonical")
+	// BlocksKeepFinalised only keeps the bodies of the last finalised
+	// blocks and deletes the blocks of the forks pruned on finalisation.
+	// Headers and justifications of all finalised blocks are kept for
+	// GRANDPA and BABE verification.
+	BlocksKeepFinalised = BlocksMode("keep-finalised")
+)
+
+// DefaultBlocksMode is the block pruning mode used if none is set,
+// keeping all the finalised blocks and deleting the pruned forks.
+const DefaultBlocksMode = BlocksArchiveCanonical
+
+// BlocksMode is the pruning mode of the finalised blocks.
+type BlocksMode string
+
+// BlocksConfig holds the block pruning mode and retained finalised blocks.
+type BlocksConfig struct {
+	Mode BlocksMode
+	// RetainedBlocks is the number of last finalised blocks
+	// whose bodies are kept in BlocksKeepFinalised mode.
+	RetainedBlocks uint
+}
+
+// ErrBlocksPruningMalformed is returned when a block pruning value is malformed.
+var ErrBlocksPruningMalformed = errors.New("block pruning value is malformed")
+
+// ParseBlocksConfig parses the block pruning value given, which is
+// either archive, archive-canonical or a number of finalised blocks to keep.
+func ParseBlocksConfig(s string) (config BlocksConfig, err error) {
+	switch BlocksMode(s) {
+	case BlocksArchive, BlocksArchiveCanonical:
+		return BlocksConfig{Mode: BlocksMode(s)}, nil
+	}
+
+	retainedBlocks, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || retainedBlocks == 0 {
+		return config, fmt.Errorf("%w: %q must be %s, %s or a strictly positive number of blocks",
+			ErrBlocksPruningMalformed, s, BlocksArchive, BlocksArchiveCanonical)
+	}
+
+	return BlocksConfig{
+		Mode:           BlocksKeepFinalised,
+		RetainedBlocks: uint(retainedBlocks),
+	}, nil
+}
+
+// String returns the block pruning value as parsed by ParseBlocksConfig.
+func (c BlocksConfig) String() string {
+	if c.Mode == BlocksKeepFinalised {
+		return strconv.FormatUint(uint64(c.RetainedBlocks), 10)
+	}
+	return string(c.Mode)
+}
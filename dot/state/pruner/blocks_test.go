@@ -0,0 +1,66 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBlocksConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		s          string
+		config     BlocksConfig
+		errWrapped error
+		errMessage string
+	}{
+		"archive": {
+			s:      "archive",
+			config: BlocksConfig{Mode: BlocksArchive},
+		},
+		"archive canonical": {
+			s:      "archive-canonical",
+			config: BlocksConfig{Mode: BlocksArchiveCanonical},
+		},
+		"keep finalised": {
+			s: "256",
+			config: BlocksConfig{
+				Mode:           BlocksKeepFinalised,
+				RetainedBlocks: 256,
+			},
+		},
+		"zero blocks": {
+			s:          "0",
+			errWrapped: ErrBlocksPruningMalformed,
+			errMessage: "block pruning value is malformed: " +
+				`"0" must be archive, archive-canonical or a strictly positive number of blocks`,
+		},
+		"unknown mode": {
+			s:          "full",
+			errWrapped: ErrBlocksPruningMalformed,
+			errMessage: "block pruning value is malformed: " +
+				`"full" must be archive, archive-canonical or a strictly positive number of blocks`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := ParseBlocksConfig(testCase.s)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.Equal(t, testCase.s, config.String())
+			}
+			assert.Equal(t, testCase.config, config)
+		})
+	}
+}
@@ -4,8 +4,10 @@
 package pruner
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -52,7 +54,8 @@ type Config struct {
 // Pruner is implemented by FullNode and ArchiveNode.
 type Pruner interface {
 	StoreJournalRecord(deletedHashesSet, insertedHashesSet map[common.Hash]struct{},
-		blockHash common.Hash, blockNum int64) error
+		blockHash, parentHash common.Hash, blockNum int64) error
+	Canonicalise(blockHash common.Hash, blockNum int64) error
 }
 
 // ArchiveNode is a no-op since we don't prune nodes in archive mode.
@@ -60,7 +63,12 @@ type ArchiveNode struct{
 
 // StoreJournalRecord for archive node doesn't do anything.
 func (a *ArchiveNode) StoreJournalRecord(_, _ map[common.Hash]struct{},
-	_ common.Hash, _ int64) error {
+	_, _ common.Hash, _ int64) error {
+	return nil
+}
+
+// Canonicalise for archive node doesn't do anything.
+func (a *ArchiveNode) Canonicalise(_ common.Hash, _ int64) error {
 	return nil
 }
 
@@ -71,13 +79,23 @@ type deathRecord struct {
 
 type deathRow []*deathRecord
 
-// FullNode stores state trie diff and allows online state trie pruning
+// FullNode stores state trie diff and allows online state trie pruning.
+// Journal records of non canonical blocks are kept until a block at their
+// number is finalised: the records of the canonical chain are then added
+// to the death list, and the nodes inserted by the discarded forks are
+// deleted if no other non canonical block references them.
+// The journal is stored in the database, so pruning resumes after a restart.
 type FullNode struct {
 	logger     log.LeveledLogger
 	deathList  []deathRow
 	storageDB  chaindb.Database
 	journalDB  chaindb.Database
 	deathIndex map[common.Hash]int64 // Mapping from deleted key hash to block number.
+	// nonCanonical are the journal records of the non canonical blocks.
+	nonCanonical map[common.Hash]*journalRecord
+	// refs counts the non canonical journal records referencing each
+	// node created by a non canonical block.
+	refs map[common.Hash]uint
 	// pendingNumber is the block number to be pruned.
 	// Initial value is set to 1 and is incremented after every block pruning.
 	pendingNumber int64
@@ -86,33 +104,44 @@ type FullNode struct {
 }
 
 type journalRecord struct {
-	// blockHash of the block corresponding to journal record
-	blockHash common.Hash
+	// BlockNumber and BlockHash of the block corresponding to journal record
+	BlockNumber int64
+	BlockHash   common.Hash
+	ParentHash  common.Hash
 	// Hash of keys that are inserted into state trie of the block
-	insertedHashesSet map[common.Hash]struct{}
+	InsertedHashes []common.Hash
 	// Hash of keys that are deleted from state trie of the block
-	deletedHashesSet map[common.Hash]struct{}
+	DeletedHashes []common.Hash
+	// Hash of the inserted keys which were not part of the canonical
+	// state when inserted, and are deleted if the block is discarded
+	CreatedHashes []common.Hash
+	// Canonical is true once the block is finalised
+	Canonical bool
 }
 
 type journalKey struct {
-	blockNum  int64
-	blockHash common.Hash
+	BlockNum  int64
+	BlockHash common.Hash
 }
 
-func newJournalRecord(hash common.Hash, insertedHashesSet,
-	deletedHashesSet map[common.Hash]struct{}) *journalRecord {
-	return &journalRecord{
-		blockHash:         hash,
-		insertedHashesSet: insertedHashesSet,
-		deletedHashesSet:  deletedHashesSet,
+// NewFullNode creates a Pruner for full node.
+func NewFullNode(db, storageDB chaindb.Database, retainBlocks int64, l log.LeveledLogger) (Pruner, error) {
+	p, err := newFullNode(db, storageDB, retainBlocks, l)
+	if err != nil {
+		return nil, err
 	}
+
+	go p.start()
+
+	return p, nil
 }
 
-// NewFullNode creates a Pruner for full node.
-func NewFullNode(db, storageDB chaindb.Database, retainBlocks int64, l log.LeveledLogger) (Pruner, error) {
+func newFullNode(db, storageDB chaindb.Database, retainBlocks int64, l log.LeveledLogger) (*FullNode, error) {
 	p := &FullNode{
 		deathList:    make([]deathRow, 0),
 		deathIndex:   make(map[common.Hash]int64),
+		nonCanonical: make(map[common.Hash]*journalRecord),
+		refs:         make(map[common.Hash]uint),
 		storageDB:    storageDB,
 		journalDB:    chaindb.NewTable(db, journalPrefix),
 		retainBlocks: retainBlocks,
@@ -129,61 +158,236 @@ func NewFullNode(db, storageDB chaindb.Database, retainBlocks int64, l log.Level
 
 	p.pendingNumber = blockNum
 
-	err = p.loadDeathList()
+	err = p.loadJournal()
 	if err != nil {
 		return nil, err
 	}
 
-	go p.start()
-
 	return p, nil
 }
 
-// StoreJournalRecord stores journal record into DB and add deathRow into deathList
+// StoreJournalRecord stores the journal record of a non canonical block into
+// the DB. It must be called before the inserted keys are written to the DB.
 func (p *FullNode) StoreJournalRecord(deletedHashesSet, insertedHashesSet map[common.Hash]struct{},
-	blockHash common.Hash, blockNum int64) error {
-	jr := newJournalRecord(blockHash, insertedHashesSet, deletedHashesSet)
+	blockHash, parentHash common.Hash, blockNum int64) error {
+	if blockNum == 0 {
+		return nil
+	}
 
-	key := &journalKey{blockNum, blockHash}
-	err := p.storeJournal(key, jr)
+	p.Lock()
+	defer p.Unlock()
+
+	if _, has := p.nonCanonical[blockHash]; has {
+		return nil
+	}
+
+	createdHashesSet := make(map[common.Hash]struct{})
+	for k := range insertedHashesSet {
+		created := p.refs[k] > 0
+		if !created {
+			_, created = p.deathIndex[k]
+		}
+		if !created {
+			has, err := p.storageDB.Has(k.ToBytes())
+			if err != nil {
+				return fmt.Errorf("failed to check if key %s is in storage: %w", k, err)
+			}
+			created = !has
+		}
+
+		if created {
+			createdHashesSet[k] = struct{}{}
+		}
+	}
+
+	jr := &journalRecord{
+		BlockNumber:    blockNum,
+		BlockHash:      blockHash,
+		ParentHash:     parentHash,
+		InsertedHashes: hashesFromSet(insertedHashesSet),
+		DeletedHashes:  hashesFromSet(deletedHashesSet),
+		CreatedHashes:  hashesFromSet(createdHashesSet),
+	}
+
+	err := p.storeJournal(p.journalDB, jr)
 	if err != nil {
 		return fmt.Errorf("failed to store journal record for %d: %w", blockNum, err)
 	}
 
+	p.addNonCanonical(jr)
 	p.logger.Debugf("journal record stored for block number %d", blockNum)
-	p.addDeathRow(jr, blockNum)
 	return nil
 }
 
-func (p *FullNode) addDeathRow(jr *journalRecord, blockNum int64) {
-	if blockNum == 0 {
-		return
-	}
-
+// Canonicalise is called when the block with the given hash and number is finalised.
+// The journal records of the block and of its non canonical ancestors are added to
+// the death list, and the records of the blocks on other forks are discarded.
+// It can safely be called again for the same block, for example after a restart.
+func (p *FullNode) Canonicalise(blockHash common.Hash, blockNum int64) error {
 	p.Lock()
 	defer p.Unlock()
 
-	// The block is already pruned.
-	if blockNum < p.pendingNumber {
-		return
+	canonical := make(map[common.Hash]struct{})
+	for hash := blockHash; ; {
+		jr, has := p.nonCanonical[hash]
+		if !has {
+			break
+		}
+		canonical[hash] = struct{}{}
+		hash = jr.ParentHash
+	}
+
+	var canonicalised, discarded []*journalRecord
+	for hash, jr := range p.nonCanonical {
+		_, isCanonical := canonical[hash]
+		switch {
+		case isCanonical:
+			canonicalised = append(canonicalised, jr)
+		case jr.BlockNumber <= blockNum || !p.descendsFrom(jr, blockHash, blockNum):
+			discarded = append(discarded, jr)
+		}
 	}
 
-	p.processInsertedKeys(jr.insertedHashesSet, jr.blockHash)
+	sort.Slice(canonicalised, func(i, j int) bool {
+		return canonicalised[i].BlockNumber < canonicalised[j].BlockNumber
+	})
+
+	// delete the nodes created by discarded blocks before their journal
+	// records, so they are deleted again if we crash in between.
+	sdbBatch := p.storageDB.NewBatch()
+	for _, jr := range discarded {
+		delete(p.nonCanonical, jr.BlockHash)
+		for _, k := range jr.CreatedHashes {
+			if p.dereference(k) {
+				continue
+			}
+			if _, pending := p.deathIndex[k]; pending {
+				continue
+			}
+
+			err := sdbBatch.Del(k.ToBytes())
+			if err != nil {
+				return fmt.Errorf("failed to delete key %s of discarded block %s: %w", k, jr.BlockHash, err)
+			}
+		}
+	}
+
+	if err := sdbBatch.Flush(); err != nil {
+		return fmt.Errorf("failed to delete keys of discarded blocks: %w", err)
+	}
+
+	jdbBatch := p.journalDB.NewBatch()
+	for _, jr := range discarded {
+		err := p.deleteJournalRecord(jdbBatch, &journalKey{jr.BlockNumber, jr.BlockHash})
+		if err != nil {
+			return fmt.Errorf("failed to delete journal record of discarded block %s: %w", jr.BlockHash, err)
+		}
+	}
+
+	for _, jr := range canonicalised {
+		delete(p.nonCanonical, jr.BlockHash)
+		for _, k := range jr.CreatedHashes {
+			p.dereference(k)
+			// the node is now part of the canonical state
+			delete(p.refs, k)
+		}
+
+		if jr.BlockNumber < p.pendingNumber {
+			// the block number is already pruned
+			err := p.deleteJournalRecord(jdbBatch, &journalKey{jr.BlockNumber, jr.BlockHash})
+			if err != nil {
+				return fmt.Errorf("failed to delete journal record of pruned block %s: %w", jr.BlockHash, err)
+			}
+			continue
+		}
+
+		jr.Canonical = true
+		err := p.storeJournal(jdbBatch, jr)
+		if err != nil {
+			return fmt.Errorf("failed to store canonical journal record for %d: %w", jr.BlockNumber, err)
+		}
+
+		p.addDeathRow(jr)
+	}
+
+	if err := jdbBatch.Flush(); err != nil {
+		return fmt.Errorf("failed to flush journal records: %w", err)
+	}
+
+	p.logger.Debugf("canonicalised %d and discarded %d journal records for block number %d",
+		len(canonicalised), len(discarded), blockNum)
+	return nil
+}
+
+// descendsFrom returns false if the journal record given is for a block which
+// is not a descendant of the block with the given hash and number.
+// It returns true if the record ancestry cannot be determined.
+// It must be called with the lock held.
+func (p *FullNode) descendsFrom(jr *journalRecord, blockHash common.Hash, blockNum int64) bool {
+	for jr.BlockNumber > blockNum+1 {
+		parent, has := p.nonCanonical[jr.ParentHash]
+		if !has {
+			return true
+		}
+		jr = parent
+	}
+
+	return jr.ParentHash == blockHash
+}
+
+// addNonCanonical must be called with the lock held.
+func (p *FullNode) addNonCanonical(jr *journalRecord) {
+	p.nonCanonical[jr.BlockHash] = jr
+	for _, k := range jr.CreatedHashes {
+		p.refs[k]++
+	}
+}
+
+// dereference decrements the references count of the key given
+// and returns true if the key is still referenced.
+// It must be called with the lock held.
+func (p *FullNode) dereference(k common.Hash) (referenced bool) {
+	count, has := p.refs[k]
+	if !has {
+		// the key belongs to the canonical state
+		return true
+	}
+
+	if count <= 1 {
+		delete(p.refs, k)
+		return false
+	}
+
+	p.refs[k] = count - 1
+	return true
+}
+
+// addDeathRow must be called with the lock held.
+func (p *FullNode) addDeathRow(jr *journalRecord) {
+	insertedHashesSet := make(map[common.Hash]struct{}, len(jr.InsertedHashes))
+	for _, k := range jr.InsertedHashes {
+		insertedHashesSet[k] = struct{}{}
+	}
+
+	p.processInsertedKeys(insertedHashesSet)
 
 	// add deleted keys from journal to death index
-	deletedKeys := make(map[common.Hash]int64, len(jr.deletedHashesSet))
-	for k := range jr.deletedHashesSet {
-		p.deathIndex[k] = blockNum
-		deletedKeys[k] = blockNum
+	deletedKeys := make(map[common.Hash]int64, len(jr.DeletedHashes))
+	for _, k := range jr.DeletedHashes {
+		if _, reinserted := insertedHashesSet[k]; reinserted {
+			continue
+		}
+		p.deathIndex[k] = jr.BlockNumber
+		deletedKeys[k] = jr.BlockNumber
 	}
 
-	blockIndex := blockNum - p.pendingNumber
+	blockIndex := jr.BlockNumber - p.pendingNumber
 	for idx := blockIndex - int64(len(p.deathList)); idx >= 0; idx-- {
 		p.deathList = append(p.deathList, deathRow{})
 	}
 
 	record := &deathRecord{
-		blockHash:   jr.blockHash,
+		blockHash:   jr.BlockHash,
 		deletedKeys: deletedKeys,
 	}
 
@@ -191,8 +395,8 @@ func (p *FullNode) addDeathRow(jr *journalRecord, blockNum int64) {
 	p.deathList[blockIndex] = append(p.deathList[blockIndex], record)
 }
 
-// Remove re-inserted keys
-func (p *FullNode) processInsertedKeys(insertedHashesSet map[common.Hash]struct{}, blockHash common.Hash) {
+// Remove re-inserted keys from the death list.
+func (p *FullNode) processInsertedKeys(insertedHashesSet map[common.Hash]struct{}) {
 	for k := range insertedHashesSet {
 		num, ok := p.deathIndex[k]
 		if !ok {
@@ -200,9 +404,7 @@ func (p *FullNode) processInsertedKeys(insertedHashesSet map[common.Hash]struct{
 		}
 		records := p.deathList[num-p.pendingNumber]
 		for _, v := range records {
-			if v.blockHash == blockHash {
-				delete(v.deletedKeys, k)
-			}
+			delete(v.deletedKeys, k)
 		}
 		delete(p.deathIndex, k)
 	}
@@ -211,89 +413,90 @@ func (p *FullNode) processInsertedKeys(insertedHashesSet map[common.Hash]struct{
 func (p *FullNode) start() {
 	p.logger.Debug("pruning started")
 
-	var canPrune bool
-	checkPruning := func() {
-		p.Lock()
-		defer p.Unlock()
-		if int64(len(p.deathList)) <= p.retainBlocks {
-			canPrune = false
-			return
+	for {
+		canPrune := p.pruneNext()
+		// Don't sleep if we have data to prune.
+		if !canPrune {
+			time.Sleep(pruneInterval)
 		}
-		canPrune = true
-
-		// pop first element from death list
-		row := p.deathList[0]
-		blockNum := p.pendingNumber
+	}
+}
 
-		p.logger.Debugf("pruning block number %d", blockNum)
+// pruneNext prunes the oldest row of the death list if more than
+// retainBlocks rows are pending, and returns true if it did.
+func (p *FullNode) pruneNext() (pruned bool) {
+	p.Lock()
+	defer p.Unlock()
+	if int64(len(p.deathList)) <= p.retainBlocks {
+		return false
+	}
 
-		sdbBatch := p.storageDB.NewBatch()
-		for _, record := range row {
-			err := p.deleteKeys(sdbBatch, record.deletedKeys)
-			if err != nil {
-				p.logger.Warnf("failed to prune keys for block number %d: %s", blockNum, err)
-				sdbBatch.Reset()
-				return
-			}
+	// pop first element from death list
+	row := p.deathList[0]
+	blockNum := p.pendingNumber
 
-			for k := range record.deletedKeys {
-				delete(p.deathIndex, k)
-			}
-		}
+	p.logger.Debugf("pruning block number %d", blockNum)
 
-		if err := sdbBatch.Flush(); err != nil {
+	sdbBatch := p.storageDB.NewBatch()
+	for _, record := range row {
+		err := p.deleteKeys(sdbBatch, record.deletedKeys)
+		if err != nil {
 			p.logger.Warnf("failed to prune keys for block number %d: %s", blockNum, err)
-			return
+			sdbBatch.Reset()
+			return false
 		}
 
-		err := p.storeLastPrunedIndex(blockNum)
-		if err != nil {
-			p.logger.Warnf("failed to store last pruned index for block number %d: %s", blockNum, err)
-			return
+		for k := range record.deletedKeys {
+			delete(p.deathIndex, k)
 		}
+	}
 
-		p.deathList = p.deathList[1:]
-		p.pendingNumber++
+	if err := sdbBatch.Flush(); err != nil {
+		p.logger.Warnf("failed to prune keys for block number %d: %s", blockNum, err)
+		return false
+	}
 
-		jdbBatch := p.journalDB.NewBatch()
-		for _, record := range row {
-			jk := &journalKey{blockNum, record.blockHash}
-			err = p.deleteJournalRecord(jdbBatch, jk)
-			if err != nil {
-				p.logger.Warnf("failed to delete journal record for block number %d: %s", blockNum, err)
-				jdbBatch.Reset()
-				return
-			}
-		}
+	err := p.storeLastPrunedIndex(blockNum)
+	if err != nil {
+		p.logger.Warnf("failed to store last pruned index for block number %d: %s", blockNum, err)
+		return false
+	}
 
-		if err = jdbBatch.Flush(); err != nil {
-			p.logger.Warnf("failed to flush delete journal record for block number %d: %s", blockNum, err)
-			return
+	p.deathList = p.deathList[1:]
+	p.pendingNumber++
+
+	jdbBatch := p.journalDB.NewBatch()
+	for _, record := range row {
+		jk := &journalKey{blockNum, record.blockHash}
+		err = p.deleteJournalRecord(jdbBatch, jk)
+		if err != nil {
+			p.logger.Warnf("failed to delete journal record for block number %d: %s", blockNum, err)
+			jdbBatch.Reset()
+			return true
 		}
-		p.logger.Debugf("pruned block number %d", blockNum)
 	}
 
-	for {
-		checkPruning()
-		// Don't sleep if we have data to prune.
-		if !canPrune {
-			time.Sleep(pruneInterval)
-		}
+	if err = jdbBatch.Flush(); err != nil {
+		p.logger.Warnf("failed to flush delete journal record for block number %d: %s", blockNum, err)
+		return true
 	}
+	p.logger.Debugf("pruned block number %d", blockNum)
+	return true
 }
 
-func (p *FullNode) storeJournal(key *journalKey, jr *journalRecord) error {
-	encKey, err := scale.Marshal(*key)
+func (p *FullNode) storeJournal(db chaindb.Writer, jr *journalRecord) error {
+	key := journalKey{jr.BlockNumber, jr.BlockHash}
+	encKey, err := scale.Marshal(key)
 	if err != nil {
-		return fmt.Errorf("failed to encode journal key block num %d: %w", key.blockNum, err)
+		return fmt.Errorf("failed to encode journal key block num %d: %w", key.BlockNum, err)
 	}
 
 	encRecord, err := scale.Marshal(*jr)
 	if err != nil {
-		return fmt.Errorf("failed to encode journal record block num %d: %w", key.blockNum, err)
+		return fmt.Errorf("failed to encode journal record block num %d: %w", key.BlockNum, err)
 	}
 
-	err = p.journalDB.Put(encKey, encRecord)
+	err = db.Put(encKey, encRecord)
 	if err != nil {
 		return err
 	}
@@ -301,29 +504,55 @@ func (p *FullNode) storeJournal(key *journalKey, jr *journalRecord) error {
 	return nil
 }
 
-// loadDeathList loads deathList and deathIndex from journalRecord.
-func (p *FullNode) loadDeathList() error {
+// loadJournal loads the non canonical records, the deathList and deathIndex from
+// the journal records. Canonical records are added to the death list by increasing
+// block number, as they were when canonicalised.
+func (p *FullNode) loadJournal() error {
 	itr := p.journalDB.NewIterator()
 	defer itr.Release()
 
+	var records []*journalRecord
 	for itr.Next() {
+		if string(itr.Key()) == lastPrunedKey {
+			continue
+		}
+
 		key := &journalKey{}
 		err := scale.Unmarshal(itr.Key(), key)
 		if err != nil {
 			return fmt.Errorf("failed to decode journal key %w", err)
 		}
 
-		val := itr.Value()
-
 		jr := &journalRecord{}
-		err = scale.Unmarshal(val, jr)
+		err = scale.Unmarshal(itr.Value(), jr)
 		if err != nil {
-			return fmt.Errorf("failed to decode journal record block num %d : %w", key.blockNum, err)
+			return fmt.Errorf("failed to decode journal record block num %d : %w", key.BlockNum, err)
 		}
 
-		p.addDeathRow(jr, key.blockNum)
+		records = append(records, jr)
 	}
-	return nil
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].BlockNumber < records[j].BlockNumber
+	})
+
+	jdbBatch := p.journalDB.NewBatch()
+	for _, jr := range records {
+		switch {
+		case !jr.Canonical:
+			p.addNonCanonical(jr)
+		case jr.BlockNumber < p.pendingNumber:
+			// we stopped after pruning the block but before deleting its record
+			err := p.deleteJournalRecord(jdbBatch, &journalKey{jr.BlockNumber, jr.BlockHash})
+			if err != nil {
+				return err
+			}
+		default:
+			p.addDeathRow(jr)
+		}
+	}
+
+	return jdbBatch.Flush()
 }
 
 func (p *FullNode) deleteJournalRecord(b chaindb.Batch, key *journalKey) error {
@@ -373,8 +602,14 @@ func (p *FullNode) getLastPrunedIndex() (int64, error) {
 	return blockNum, nil
 }
 
+// deleteKeys deletes the keys given, except the keys
+// still referenced by non canonical blocks.
 func (p *FullNode) deleteKeys(b chaindb.Batch, nodesHash map[common.Hash]int64) error {
 	for k := range nodesHash {
+		if p.refs[k] > 0 {
+			continue
+		}
+
 		err := b.Del(k.ToBytes())
 		if err != nil {
 			return err
@@ -383,3 +618,21 @@ func (p *FullNode) deleteKeys(b chaindb.Batch, nodesHash map[common.Hash]int64)
 
 	return nil
 }
+
+// hashesFromSet returns the hashes of the set given sorted, so journal
+// records are encoded deterministically.
+func hashesFromSet(set map[common.Hash]struct{}) (hashes []common.Hash) {
+	if len(set) == 0 {
+		return nil
+	}
+
+	hashes = make([]common.Hash, 0, len(set))
+	for k := range set {
+		hashes = append(hashes, k)
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	return hashes
+}
@@ -0,0 +1,282 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) chaindb.Database {
+	t.Helper()
+
+	db, err := chaindb.NewBadgerDB(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	// the chaindb table iterator panics on an empty database
+	err = db.Put([]byte("not empty"), []byte{1})
+	require.NoError(t, err)
+
+	return db
+}
+
+func newTestFullNode(t *testing.T, db chaindb.Database, retainBlocks int64) *FullNode {
+	t.Helper()
+
+	logger := log.New(log.SetWriter(io.Discard))
+	p, err := newFullNode(db, chaindb.NewTable(db, "storage"), retainBlocks, logger)
+	require.NoError(t, err)
+	return p
+}
+
+type testBlock struct {
+	number   int64
+	hash     common.Hash
+	parent   common.Hash
+	inserted []common.Hash
+	deleted  []common.Hash
+}
+
+// storeTestBlock stores the journal record of the block and
+// writes its inserted keys, as the storage state does.
+func storeTestBlock(t *testing.T, p *FullNode, block testBlock) {
+	t.Helper()
+
+	insertedHashesSet := make(map[common.Hash]struct{}, len(block.inserted))
+	for _, k := range block.inserted {
+		insertedHashesSet[k] = struct{}{}
+	}
+
+	deletedHashesSet := make(map[common.Hash]struct{}, len(block.deleted))
+	for _, k := range block.deleted {
+		deletedHashesSet[k] = struct{}{}
+	}
+
+	err := p.StoreJournalRecord(deletedHashesSet, insertedHashesSet,
+		block.hash, block.parent, block.number)
+	require.NoError(t, err)
+
+	for _, k := range block.inserted {
+		err = p.storageDB.Put(k.ToBytes(), []byte{1})
+		require.NoError(t, err)
+	}
+}
+
+func assertKeys(t *testing.T, storageDB chaindb.Database, keys []common.Hash, expected bool) {
+	t.Helper()
+
+	for _, k := range keys {
+		has, err := storageDB.Has(k.ToBytes())
+		require.NoError(t, err)
+		assert.Equal(t, expected, has, "key %s", k)
+	}
+}
+
+// newTestForks stores the blocks of two forks of the given depth from the genesis
+// block, each block inserting its own key. The key shared is inserted by both forks
+// and the key canonical is already part of the canonical state.
+func newTestForks(t *testing.T, p *FullNode, depth int64) (forkA, forkB []testBlock,
+	shared, canonical common.Hash) {
+	t.Helper()
+
+	genesisHash := common.Hash{0xff}
+	shared = common.Hash{0xfe}
+	canonical = common.Hash{0xfd}
+
+	err := p.storageDB.Put(canonical.ToBytes(), []byte{1})
+	require.NoError(t, err)
+
+	parentA, parentB := genesisHash, genesisHash
+	for number := int64(1); number <= depth; number++ {
+		blockA := testBlock{
+			number:   number,
+			hash:     common.Hash{0xa, byte(number)},
+			parent:   parentA,
+			inserted: []common.Hash{{0xa, byte(number), 1}},
+		}
+		blockB := testBlock{
+			number:   number,
+			hash:     common.Hash{0xb, byte(number)},
+			parent:   parentB,
+			inserted: []common.Hash{{0xb, byte(number), 1}},
+		}
+
+		switch number {
+		case 2:
+			blockB.inserted = append(blockB.inserted, canonical)
+		case 3:
+			blockB.inserted = append(blockB.inserted, shared)
+		case 5:
+			blockA.inserted = append(blockA.inserted, shared)
+		}
+
+		storeTestBlock(t, p, blockA)
+		storeTestBlock(t, p, blockB)
+
+		forkA = append(forkA, blockA)
+		forkB = append(forkB, blockB)
+		parentA, parentB = blockA.hash, blockB.hash
+	}
+
+	return forkA, forkB, shared, canonical
+}
+
+func insertedKeys(blocks []testBlock) (keys []common.Hash) {
+	for _, block := range blocks {
+		keys = append(keys, block.inserted[0])
+	}
+	return keys
+}
+
+func Test_FullNode_Canonicalise(t *testing.T) {
+	t.Parallel()
+
+	const depth = 10
+
+	testCases := map[string]struct {
+		// finalisedIndex is the index of the finalised block in the canonical fork
+		finalisedIndex  int
+		forkACanonical  bool
+		nonCanonicalLen int
+	}{
+		"finalise inside fork A": {
+			finalisedIndex:  7,
+			forkACanonical:  true,
+			nonCanonicalLen: 2,
+		},
+		"deep reorg to fork B tip": {
+			finalisedIndex: depth - 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := newTestDB(t)
+			p := newTestFullNode(t, db, depth)
+			forkA, forkB, shared, canonical := newTestForks(t, p, depth)
+
+			canonicalFork, discardedFork := forkB, forkA
+			if testCase.forkACanonical {
+				canonicalFork, discardedFork = forkA, forkB
+			}
+			finalised := canonicalFork[testCase.finalisedIndex]
+
+			err := p.Canonicalise(finalised.hash, finalised.number)
+			require.NoError(t, err)
+
+			assertKeys(t, p.storageDB, insertedKeys(canonicalFork), true)
+			assertKeys(t, p.storageDB, insertedKeys(discardedFork), false)
+			assertKeys(t, p.storageDB, []common.Hash{shared, canonical}, true)
+
+			assert.Len(t, p.nonCanonical, testCase.nonCanonicalLen)
+			// each pending block references the key it created
+			assert.Len(t, p.refs, testCase.nonCanonicalLen)
+			assert.Len(t, p.deathList, testCase.finalisedIndex+1)
+
+			// canonicalising again is a no-op
+			err = p.Canonicalise(finalised.hash, finalised.number)
+			require.NoError(t, err)
+			assert.Len(t, p.nonCanonical, testCase.nonCanonicalLen)
+			assert.Len(t, p.deathList, testCase.finalisedIndex+1)
+		})
+	}
+}
+
+func Test_FullNode_Canonicalise_sharedKeyOfPendingFork(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	p := newTestFullNode(t, db, 10)
+	forkA, forkB, shared, _ := newTestForks(t, p, 5)
+
+	// fork B block 3 and fork A block 5 share a key, fork A block 5 is
+	// not yet finalised so the key is still referenced once finalising
+	// fork A block 4 discards fork B.
+	err := p.Canonicalise(forkA[3].hash, forkA[3].number)
+	require.NoError(t, err)
+
+	assertKeys(t, p.storageDB, insertedKeys(forkB), false)
+	assertKeys(t, p.storageDB, []common.Hash{shared}, true)
+	assert.Equal(t, map[common.Hash]uint{
+		forkA[4].inserted[0]: 1,
+		shared:               1,
+	}, p.refs)
+}
+
+func Test_FullNode_resume(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	p := newTestFullNode(t, db, 10)
+	forkA, _, _, _ := newTestForks(t, p, 6)
+
+	err := p.Canonicalise(forkA[3].hash, forkA[3].number)
+	require.NoError(t, err)
+
+	resumed := newTestFullNode(t, db, 10)
+
+	assert.Equal(t, p.nonCanonical, resumed.nonCanonical)
+	assert.Equal(t, p.refs, resumed.refs)
+	assert.Equal(t, p.deathList, resumed.deathList)
+	assert.Equal(t, p.deathIndex, resumed.deathIndex)
+	assert.Equal(t, p.pendingNumber, resumed.pendingNumber)
+}
+
+func Test_FullNode_pruneNext(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	p := newTestFullNode(t, db, 2)
+
+	x, y, z := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	blocks := []testBlock{
+		{number: 1, hash: common.Hash{0x1}, inserted: []common.Hash{x, y}},
+		// x is deleted and never re-inserted
+		{number: 2, hash: common.Hash{0x2}, parent: common.Hash{0x1},
+			deleted: []common.Hash{x}, inserted: []common.Hash{z}},
+		// y is deleted and re-inserted by the next block
+		{number: 3, hash: common.Hash{0x3}, parent: common.Hash{0x2}, deleted: []common.Hash{y}},
+		{number: 4, hash: common.Hash{0x4}, parent: common.Hash{0x3}, inserted: []common.Hash{y}},
+	}
+	for _, block := range blocks {
+		storeTestBlock(t, p, block)
+	}
+
+	err := p.Canonicalise(blocks[3].hash, blocks[3].number)
+	require.NoError(t, err)
+
+	assert.True(t, p.pruneNext())
+	assertKeys(t, p.storageDB, []common.Hash{x, y, z}, true)
+
+	assert.True(t, p.pruneNext())
+	assertKeys(t, p.storageDB, []common.Hash{x}, false)
+	assertKeys(t, p.storageDB, []common.Hash{y, z}, true)
+
+	assert.False(t, p.pruneNext())
+	assert.Equal(t, int64(3), p.pendingNumber)
+
+	lastPruned, err := p.getLastPrunedIndex()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), lastPruned)
+
+	// the journal records of pruned blocks are deleted
+	resumed := newTestFullNode(t, db, 2)
+	assert.Len(t, resumed.deathList, 2)
+	assert.Empty(t, resumed.deathIndex)
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRevertService returns a service with a chain of 6 blocks and
+// a fork of 2 blocks from the block 4, where the block 2 is finalised.
+func newTestRevertService(t *testing.T) (serv *Service, chain []*types.Header) {
+	t.Helper()
+
+	blockState := newTestBlockState(t, nil, newTriesEmpty())
+	epochState, err := NewEpochStateFromGenesis(NewInMemoryDB(t), blockState, genesisBABEConfig)
+	require.NoError(t, err)
+	grandpaState, err := NewGrandpaStateFromGenesis(NewInMemoryDB(t), testAuths)
+	require.NoError(t, err)
+
+	const withBranches = false
+	chain, _ = AddBlocksToState(t, blockState, 6, withBranches)
+
+	parentHash := chain[3].Hash()
+	for number := uint(5); number <= 6; number++ {
+		digest := types.NewDigest()
+		prd, err := types.NewBabeSecondaryPlainPreDigest(0, uint64(number+100)).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		err = digest.Add(*prd)
+		require.NoError(t, err)
+
+		block := &types.Block{
+			Header: types.Header{
+				ParentHash: parentHash,
+				Number:     number,
+				StateRoot:  trie.EmptyHash,
+				Digest:     digest,
+			},
+			Body: types.Body{},
+		}
+		err = blockState.AddBlock(block)
+		require.NoError(t, err)
+		parentHash = block.Header.Hash()
+	}
+
+	err = blockState.SetFinalisedHash(chain[1].Hash(), 1, 0)
+	require.NoError(t, err)
+
+	serv = &Service{
+		Block:   blockState,
+		Epoch:   epochState,
+		Grandpa: grandpaState,
+	}
+	return serv, chain
+}
+
+func TestService_Revert(t *testing.T) {
+	t.Parallel()
+
+	serv, chain := newTestRevertService(t)
+
+	// authority set change scheduled by a reverted block
+	err := serv.Grandpa.SetNextChange(testAuths, 5)
+	require.NoError(t, err)
+	// epoch data announced by a reverted block
+	err = serv.Epoch.SetEpochData(2, &types.EpochData{})
+	require.NoError(t, err)
+
+	const force = false
+	best, err := serv.Revert(2, force)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), best.Number)
+	assert.Equal(t, chain[3].Hash(), serv.Block.BestBlockHash())
+
+	for _, header := range chain[4:] {
+		has, err := serv.Block.HasHeader(header.Hash())
+		require.NoError(t, err)
+		assert.False(t, has)
+	}
+
+	leaves := serv.Block.Leaves()
+	assert.Len(t, leaves, 1)
+	for _, leaf := range leaves {
+		header, err := serv.Block.GetHeader(leaf)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, header.Number, uint(4))
+	}
+
+	finalisedHash, err := serv.Block.GetHighestFinalisedHash()
+	require.NoError(t, err)
+	assert.Equal(t, chain[1].Hash(), finalisedHash)
+
+	_, err = serv.Grandpa.GetSetIDChange(1)
+	assert.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+	setID, err := serv.Grandpa.GetCurrentSetID()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), setID)
+
+	has, err := serv.Epoch.db.Has(epochDataKey(2))
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestService_Revert_finalised(t *testing.T) {
+	t.Parallel()
+
+	serv, _ := newTestRevertService(t)
+
+	const force = false
+	_, err := serv.Revert(5, force)
+	assert.ErrorIs(t, err, ErrRevertFinalised)
+
+	best, err := serv.Block.BestBlockHeader()
+	require.NoError(t, err)
+	assert.Equal(t, uint(6), best.Number)
+}
+
+func TestService_Revert_force(t *testing.T) {
+	t.Parallel()
+
+	serv, chain := newTestRevertService(t)
+
+	const force = true
+	best, err := serv.Revert(5, force)
+	require.NoError(t, err)
+	assert.Equal(t, chain[0].Hash(), best.Hash())
+	assert.Equal(t, chain[0].Hash(), serv.Block.BestBlockHash())
+
+	finalised, err := serv.Block.GetHighestFinalisedHeader()
+	require.NoError(t, err)
+	assert.Equal(t, chain[0].Hash(), finalised.Hash())
+
+	round, setID, err := serv.Block.GetHighestRoundAndSetID()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), round)
+	assert.Equal(t, uint64(0), setID)
+
+	has, err := serv.Block.HasFinalisedBlock(1, 0)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = serv.Block.GetHashByNumber(2)
+	assert.Error(t, err)
+	has, err = serv.Block.HasHeader(chain[1].Hash())
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	// blocks can be imported again from the new best block
+	err = serv.Block.AddBlock(&types.Block{
+		Header: *chain[1],
+		Body:   types.Body{},
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// runtimePoolKey identifies the runtime instances which are interchangeable,
+// which are the instances of the same code with the same heap pages.
+type runtimePoolKey struct {
+	codeHash  common.Hash
+	heapPages uint64
+}
+
+func newRuntimePoolKey(instance runtime.Instance) runtimePoolKey {
+	return runtimePoolKey{
+		codeHash:  instance.GetCodeHash(),
+		heapPages: instance.GetHeapPages(),
+	}
+}
+
+// runtimePools implements a thread safe map of runtime code hashes and heap
+// pages to the pools of instances of the runtimes in use by the block tree.
+type runtimePools struct {
+	mutex sync.Mutex
+	size  uint
+	pools map[runtimePoolKey]*runtime.Pool
+}
+
+func newRuntimePools(size uint) *runtimePools {
+	return &runtimePools{
+		size:  size,
+		pools: make(map[runtimePoolKey]*runtime.Pool),
+	}
+}
+
+// get returns the pool of instances of the code and heap pages of
+// the runtime instance given, and creates it from the instance if needed.
+func (r *runtimePools) get(instance runtime.Instance) *runtime.Pool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := newRuntimePoolKey(instance)
+	pool, ok := r.pools[key]
+	if !ok {
+		pool = runtime.NewPool(instance, r.size)
+		r.pools[key] = pool
+	}
+	return pool
+}
+
+// prune stops and removes the pools of the runtimes
+// which are not in the set of runtimes in use given.
+func (r *runtimePools) prune(inUse map[runtimePoolKey]struct{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, pool := range r.pools {
+		if _, ok := inUse[key]; ok {
+			continue
+		}
+		pool.Stop()
+		delete(r.pools, key)
+	}
+}
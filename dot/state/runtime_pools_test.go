@@ -0,0 +1,76 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newRuntimePools(t *testing.T) {
+	t.Parallel()
+
+	pools := newRuntimePools(2)
+
+	expected := &runtimePools{
+		size:  2,
+		pools: make(map[runtimePoolKey]*runtime.Pool),
+	}
+	assert.Equal(t, expected, pools)
+}
+
+func Test_runtimePools_get(t *testing.T) {
+	t.Parallel()
+
+	first := new(mocksruntime.Instance)
+	first.On("GetCodeHash").Return(common.Hash{1})
+	first.On("GetHeapPages").Return(uint64(1))
+	sameCode := new(mocksruntime.Instance)
+	sameCode.On("GetCodeHash").Return(common.Hash{1})
+	sameCode.On("GetHeapPages").Return(uint64(1))
+	otherCode := new(mocksruntime.Instance)
+	otherCode.On("GetCodeHash").Return(common.Hash{2})
+	otherCode.On("GetHeapPages").Return(uint64(1))
+	otherHeapPages := new(mocksruntime.Instance)
+	otherHeapPages.On("GetCodeHash").Return(common.Hash{1})
+	otherHeapPages.On("GetHeapPages").Return(uint64(2))
+
+	pools := newRuntimePools(1)
+
+	pool := pools.get(first)
+	assert.Same(t, pool, pools.get(sameCode))
+	assert.NotSame(t, pool, pools.get(otherCode))
+	assert.NotSame(t, pool, pools.get(otherHeapPages))
+
+	instance, err := pool.Checkout()
+	assert.NoError(t, err)
+	assert.Same(t, first, instance)
+}
+
+func Test_runtimePools_prune(t *testing.T) {
+	t.Parallel()
+
+	inUse := new(mocksruntime.Instance)
+	inUse.On("GetCodeHash").Return(common.Hash{1})
+	inUse.On("GetHeapPages").Return(uint64(1))
+	unused := new(mocksruntime.Instance)
+	unused.On("GetCodeHash").Return(common.Hash{2})
+	unused.On("GetHeapPages").Return(uint64(1))
+
+	pools := newRuntimePools(1)
+	inUsePool := pools.get(inUse)
+	pools.get(unused)
+
+	inUseKey := runtimePoolKey{codeHash: common.Hash{1}, heapPages: 1}
+	pools.prune(map[runtimePoolKey]struct{}{inUseKey: {}})
+
+	expected := map[runtimePoolKey]*runtime.Pool{
+		inUseKey: inUsePool,
+	}
+	assert.Equal(t, expected, pools.pools)
+}
@@ -4,6 +4,7 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -12,7 +13,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/internal/metrics"
-	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/lib/utils"
 
@@ -23,6 +24,14 @@
 	log.AddContext("pkg", "state"),
 )
 
+var (
+	// ErrRevertFinalised is returned when reverting blocks would revert finalised blocks.
+	ErrRevertFinalised = errors.New("cannot revert finalised blocks")
+
+	errRevertPastGenesis = errors.New("cannot revert past the genesis block")
+	errStatePruned       = errors.New("state is pruned")
+)
+
 // Service is the struct that holds storage, block and network states
 type Service struct {
 	dbPath      string
@@ -39,6 +48,14 @@ type Service struct {
 
 	PrunerCfg pruner.Config
 	Telemetry telemetry.Client
+	// CacheSize is the maximum size in bytes of the trie node cache.
+	CacheSize uint64
+	// BlocksPruning is the pruning mode of the finalised blocks.
+	BlocksPruning pruner.BlocksConfig
+	// RuntimePoolSize is the maximum number of instances of each runtime pool.
+	RuntimePoolSize uint
+	// NewInstance creates the runtime instances of runtime code changes.
+	NewInstance runtime.NewInstanceFunc
 
 	// Below are for testing only.
 	BabeThresholdNumerator   uint64
@@ -52,12 +69,29 @@ type Config struct {
 	PrunerCfg pruner.Config
 	Telemetry telemetry.Client
 	Metrics   metrics.IntervalConfig
+	// CacheSize is the maximum size in bytes of the trie node
+	// cache, DefaultNodeCacheSize is used if it is zero.
+	CacheSize uint64
+	// BlocksPruning is the pruning mode of the finalised blocks,
+	// pruner.DefaultBlocksMode is used if its mode is not set.
+	BlocksPruning pruner.BlocksConfig
+	// RuntimePoolSize is the maximum number of instances of each
+	// runtime pool, runtime.DefaultPoolSize is used if it is zero.
+	RuntimePoolSize uint
+	// NewInstance creates the runtime instances of runtime code
+	// changes, wasmer.NewRuntimeInstance is used if it is nil.
+	NewInstance runtime.NewInstanceFunc
 }
 
 // NewService create a new instance of Service
 func NewService(config Config) *Service {
 	logger.Patch(log.SetLevel(config.LogLevel))
 
+	blocksPruning := config.BlocksPruning
+	if blocksPruning.Mode == "" {
+		blocksPruning.Mode = pruner.DefaultBlocksMode
+	}
+
 	return &Service{
 		dbPath:    config.Path,
 		logLvl:    config.LogLevel,
@@ -68,6 +102,11 @@ func NewService(config Config) *Service {
 		closeCh:   make(chan interface{}),
 		PrunerCfg: config.PrunerCfg,
 		Telemetry: config.Telemetry,
+		CacheSize: config.CacheSize,
+
+		BlocksPruning:   blocksPruning,
+		RuntimePoolSize: config.RuntimePoolSize,
+		NewInstance:     config.NewInstance,
 	}
 }
 
@@ -118,11 +157,20 @@ func (s *Service) Start() error {
 		return fmt.Errorf("cannot create tries: %w", err)
 	}
 
+	if s.CacheSize != 0 {
+		tries.SetNodeCacheSize(s.CacheSize)
+	}
+
 	// create block state
 	s.Block, err = NewBlockState(s.db, tries, s.Telemetry)
 	if err != nil {
 		return fmt.Errorf("failed to create block state: %w", err)
 	}
+	s.Block.blocksPruning = s.BlocksPruning
+	s.Block.runtimePools = newRuntimePools(s.RuntimePoolSize)
+	if s.NewInstance != nil {
+		s.Block.newInstance = s.NewInstance
+	}
 
 	// retrieve latest header
 	bestHeader, err := s.Block.GetHighestFinalisedHeader()
@@ -144,8 +192,12 @@ func (s *Service) Start() error {
 		return fmt.Errorf("failed to create storage state: %w", err)
 	}
 
-	// load current storage state trie into memory
-	_, err = s.Storage.LoadFromDB(stateRoot)
+	// the chain starts from the finalised block, keep its trie in memory
+	tries.pinFinalised(stateRoot)
+	tries.pinBest(stateRoot)
+
+	// load current storage state trie root node into memory
+	_, err = s.Storage.loadTrie(&stateRoot)
 	if err != nil {
 		return fmt.Errorf("failed to load storage trie from database: %w", err)
 	}
@@ -172,8 +224,7 @@ func (s *Service) Start() error {
 	return nil
 }
 
-// Rewind rewinds the chain to the given block number.
-// If the given number of blocks is greater than the chain height, it will rewind to genesis.
+// Rewind rewinds the chain to the given block number, reverting finalised blocks if needed.
 func (s *Service) Rewind(toBlock uint) error {
 	num, _ := s.Block.BestBlockNumber()
 	if toBlock > num {
@@ -181,72 +232,99 @@ func (s *Service) Rewind(toBlock uint) error {
 	}
 
 	logger.Infof(
-		"rewinding state from current height %s to desired height %d...",
+		"rewinding state from current height %d to desired height %d...",
 		num, toBlock)
 
-	root, err := s.Block.GetBlockByNumber(toBlock)
+	const force = true
+	header, err := s.revertTo(toBlock, force)
 	if err != nil {
 		return err
 	}
 
-	s.Block.bt = blocktree.NewBlockTreeFromRoot(&root.Header)
+	logger.Infof(
+		"rewinding state for new height %d and best block hash %s...",
+		header.Number, header.Hash())
+	return nil
+}
 
-	header, err := s.Block.BestBlockHeader()
+// Revert removes the given number of blocks from the head of the best chain, and the blocks
+// of the other forks above the new best block number. The BABE epoch and the GRANDPA authority
+// set are restored to the ones of the new best block, and the changes scheduled by the removed
+// blocks are removed. Finalised blocks are only reverted if force is true.
+// It returns the header of the new best block.
+func (s *Service) Revert(blocks uint, force bool) (*types.Header, error) {
+	best, err := s.Block.BestBlockHeader()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get best block header: %w", err)
 	}
 
-	s.Block.lastFinalised = header.Hash()
-	logger.Infof(
-		"rewinding state for new height %s and best block hash %s...",
-		header.Number, header.Hash())
+	if blocks > best.Number {
+		return nil, fmt.Errorf("%w: cannot revert %d blocks from best block number %d",
+			errRevertPastGenesis, blocks, best.Number)
+	}
 
-	epoch, err := s.Epoch.GetEpochForBlock(header)
+	return s.revertTo(best.Number-blocks, force)
+}
+
+func (s *Service) revertTo(number uint, force bool) (*types.Header, error) {
+	finalised, err := s.Block.GetHighestFinalisedHeader()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get highest finalised header: %w", err)
 	}
 
-	err = s.Epoch.SetCurrentEpoch(epoch)
+	revertFinalised := number < finalised.Number
+	if revertFinalised && !force {
+		return nil, fmt.Errorf("%w: block number %d is below the highest finalised block number %d",
+			ErrRevertFinalised, number, finalised.Number)
+	}
+
+	header, err := s.Block.GetHeaderByNumber(number)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get header of block %d: %w", number, err)
 	}
 
-	s.Block.lastFinalised = header.Hash()
+	if revertFinalised && header.StateRoot != trie.EmptyHash {
+		has, err := s.Storage.db.Has(header.StateRoot.ToBytes())
+		if err != nil {
+			return nil, fmt.Errorf("cannot check state of block %d: %w", number, err)
+		} else if !has {
+			return nil, fmt.Errorf("%w: state root %s of block %d", errStatePruned, header.StateRoot, number)
+		}
+	}
 
-	// TODO: this is broken, it needs to set the latest finalised header after
-	// rewinding to some block number, but there is no reverse lookup function
-	// for block -> (round, setID) where it was finalised (#1859)
-	err = s.Block.SetFinalisedHash(header.Hash(), 0, 0)
+	setID, err := s.Grandpa.GetSetIDByBlockNumber(number)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get set ID of block %d: %w", number, err)
 	}
 
-	// update the current grandpa set ID
-	prevSetID, err := s.Grandpa.GetCurrentSetID()
+	reverted, err := s.Block.revert(header, setID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot revert blocks: %w", err)
 	}
 
-	newSetID, err := s.Grandpa.GetSetIDByBlockNumber(header.Number)
+	best, err := s.Block.BestBlockHeader()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get best block header: %w", err)
 	}
 
-	err = s.Grandpa.setCurrentSetID(newSetID)
-	if err != nil {
-		return err
+	if err = s.Epoch.revert(best, reverted); err != nil {
+		return nil, fmt.Errorf("cannot revert epoch state: %w", err)
 	}
 
-	// remove previously set grandpa changes, need to go up to prevSetID+1 in case of a scheduled change
-	for i := newSetID + 1; i <= prevSetID+1; i++ {
-		err = s.Grandpa.db.Del(setIDChangeKey(i))
-		if err != nil {
-			return err
+	if err = s.Grandpa.revert(setID, number); err != nil {
+		return nil, fmt.Errorf("cannot revert grandpa state: %w", err)
+	}
+
+	if revertFinalised {
+		// the rounds after the new finalised block are finalised again
+		if err = s.Grandpa.SetLatestRound(0); err != nil {
+			return nil, fmt.Errorf("cannot set latest grandpa round: %w", err)
 		}
 	}
 
-	//return s.Base.StoreBestBlockHash(newHead)
-	return nil
+	logger.Infof("reverted %d blocks, best block is now number %d with hash %s",
+		len(reverted), best.Number, best.Hash())
+	return best, nil
 }
 
 // Stop closes each state database
@@ -270,6 +348,22 @@ func (s *Service) Stop() error {
 // Import imports the given state corresponding to the given header and sets the head of the chain
 // to it. Additionally, it uses the first slot to correctly set the epoch number of the block.
 func (s *Service) Import(header *types.Header, t *trie.Trie, firstSlot uint64) error {
+	root := t.MustHash()
+	if root != header.StateRoot {
+		return fmt.Errorf("trie state root does not equal header state root")
+	}
+
+	return s.importState(header, firstSlot, func(storageDB chaindb.Database) error {
+		logger.Info("importing storage trie from base path " +
+			s.dbPath + " with root " + root.String() + "...")
+		return t.Store(storageDB)
+	})
+}
+
+// importState stores the state trie with the given store function, and only then sets
+// the head of the chain to the given header, using the first slot to set its epoch number.
+func (s *Service) importState(header *types.Header, firstSlot uint64,
+	storeTrie func(storageDB chaindb.Database) error) error {
 	var err error
 	// initialise database using data directory
 	if !s.isMemDB {
@@ -287,6 +381,10 @@ func (s *Service) Import(header *types.Header, t *trie.Trie, firstSlot uint64) e
 		db: chaindb.NewTable(s.db, storagePrefix),
 	}
 
+	if err = storeTrie(storage.db); err != nil {
+		return err
+	}
+
 	epoch, err := NewEpochState(s.db, block)
 	if err != nil {
 		return err
@@ -314,18 +412,6 @@ func (s *Service) Import(header *types.Header, t *trie.Trie, firstSlot uint64) e
 		return err
 	}
 
-	root := t.MustHash()
-	if root != header.StateRoot {
-		return fmt.Errorf("trie state root does not equal header state root")
-	}
-
-	logger.Info("importing storage trie from base path " +
-		s.dbPath + " with root " + root.String() + "...")
-
-	if err := t.Store(storage.db); err != nil {
-		return err
-	}
-
 	hash := header.Hash()
 	if err := block.SetHeader(header); err != nil {
 		return err
@@ -341,7 +427,7 @@ func (s *Service) Import(header *types.Header, t *trie.Trie, firstSlot uint64) e
 
 	logger.Debugf(
 		"Import best block hash %s with latest state root %s",
-		hash, root)
+		hash, header.StateRoot)
 	if err := s.db.Flush(); err != nil {
 		return err
 	}
@@ -0,0 +1,274 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"golang.org/x/crypto/blake2b"
+)
+
+// snapshotMagic is written at the start of a state snapshot,
+// followed by the version of the snapshot format.
+var snapshotMagic = []byte("gssmrsnp")
+
+const snapshotVersion byte = 1
+
+var (
+	// ErrSnapshotMalformed is returned when a state snapshot cannot be read.
+	ErrSnapshotMalformed = errors.New("state snapshot is malformed")
+	// ErrSnapshotChecksum is returned when the checksum of a state snapshot does not match its content.
+	ErrSnapshotChecksum = errors.New("state snapshot checksum mismatch")
+)
+
+// snapshotWriter writes length prefixed records to a state snapshot
+// and keeps the checksum of all the bytes written.
+type snapshotWriter struct {
+	w      *bufio.Writer
+	hasher hash.Hash
+	out    io.Writer
+}
+
+func newSnapshotWriter(w io.Writer) (*snapshotWriter, error) {
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	return &snapshotWriter{
+		w:      bw,
+		hasher: hasher,
+		out:    io.MultiWriter(bw, hasher),
+	}, nil
+}
+
+func (sw *snapshotWriter) write(b []byte) error {
+	_, err := sw.out.Write(b)
+	return err
+}
+
+func (sw *snapshotWriter) writeRecord(record []byte) error {
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(record)))
+	if err := sw.write(length); err != nil {
+		return err
+	}
+	return sw.write(record)
+}
+
+// close writes the end of records marker and the checksum, and flushes the writer.
+func (sw *snapshotWriter) close() error {
+	if err := sw.writeRecord(nil); err != nil {
+		return err
+	}
+
+	if _, err := sw.w.Write(sw.hasher.Sum(nil)); err != nil {
+		return err
+	}
+
+	return sw.w.Flush()
+}
+
+// snapshotReader reads length prefixed records from a state
+// snapshot and keeps the checksum of all the bytes read.
+type snapshotReader struct {
+	r      *bufio.Reader
+	hasher hash.Hash
+	in     io.Reader
+}
+
+func newSnapshotReader(r io.Reader) (*snapshotReader, error) {
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	return &snapshotReader{
+		r:      br,
+		hasher: hasher,
+		in:     io.TeeReader(br, hasher),
+	}, nil
+}
+
+func (sr *snapshotReader) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(sr.in, b); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotMalformed, err)
+	}
+	return b, nil
+}
+
+// readRecord returns the next record, or nil at the end of records marker.
+func (sr *snapshotReader) readRecord() ([]byte, error) {
+	length, err := sr.read(4)
+	if err != nil {
+		return nil, err
+	}
+
+	if binary.LittleEndian.Uint32(length) == 0 {
+		return nil, nil
+	}
+
+	return sr.read(int(binary.LittleEndian.Uint32(length)))
+}
+
+// verifyChecksum reads the checksum following the end of records
+// marker and verifies it matches the checksum of the bytes read.
+func (sr *snapshotReader) verifyChecksum() error {
+	expected := sr.hasher.Sum(nil)
+
+	checksum := make([]byte, len(expected))
+	if _, err := io.ReadFull(sr.r, checksum); err != nil {
+		return fmt.Errorf("%w: cannot read checksum: %s", ErrSnapshotMalformed, err)
+	}
+
+	if !bytes.Equal(checksum, expected) {
+		return fmt.Errorf("%w: expected 0x%x but got 0x%x", ErrSnapshotChecksum, expected, checksum)
+	}
+
+	return nil
+}
+
+// ExportSnapshot writes a snapshot of the state of the block with the given hash to the
+// writer. The snapshot contains the block header, the first BABE slot of the chain, the
+// encoded nodes of the state trie and of its child tries, and a blake2b checksum of all
+// the preceding bytes. The trie nodes are read from the database as they are written,
+// so the state is never entirely kept in memory.
+func (s *Service) ExportSnapshot(hash common.Hash, w io.Writer) error {
+	header, err := s.Block.GetHeader(hash)
+	if err != nil {
+		return fmt.Errorf("cannot get header: %w", err)
+	}
+
+	firstSlot, err := s.Base.loadFirstSlot()
+	if err != nil {
+		return fmt.Errorf("cannot load first slot: %w", err)
+	}
+
+	sw, err := newSnapshotWriter(w)
+	if err != nil {
+		return err
+	}
+
+	if err = sw.write(append(snapshotMagic, snapshotVersion)); err != nil {
+		return err
+	}
+
+	encodedHeader, err := scale.Marshal(*header)
+	if err != nil {
+		return fmt.Errorf("cannot encode header: %w", err)
+	}
+
+	if err = sw.writeRecord(encodedHeader); err != nil {
+		return err
+	}
+
+	encodedFirstSlot := make([]byte, 8)
+	binary.LittleEndian.PutUint64(encodedFirstSlot, firstSlot)
+	if err = sw.write(encodedFirstSlot); err != nil {
+		return err
+	}
+
+	var nodes uint
+	lazyTrie := trie.NewLazyTrie(s.Storage.db, nil, header.StateRoot)
+	err = lazyTrie.WalkNodes(func(encodedNode []byte) error {
+		nodes++
+		return sw.writeRecord(encodedNode)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot write state trie nodes: %w", err)
+	}
+
+	if err = sw.close(); err != nil {
+		return err
+	}
+
+	logger.Infof("exported snapshot of state root %s of block number %d with hash %s with %d trie nodes",
+		header.StateRoot, header.Number, hash, nodes)
+	return nil
+}
+
+// ImportSnapshot imports the state snapshot read from the reader and sets the head of the
+// chain to the block of the snapshot. The trie nodes are written to the database as they
+// are read, and the head is only set once all the nodes of the state root of the block
+// header are loaded and the checksum of the snapshot is verified.
+func (s *Service) ImportSnapshot(r io.Reader) error {
+	sr, err := newSnapshotReader(r)
+	if err != nil {
+		return err
+	}
+
+	prefix, err := sr.read(len(snapshotMagic) + 1)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(prefix[:len(snapshotMagic)], snapshotMagic) {
+		return fmt.Errorf("%w: unknown prefix 0x%x", ErrSnapshotMalformed, prefix)
+	}
+
+	if version := prefix[len(snapshotMagic)]; version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrSnapshotMalformed, version)
+	}
+
+	encodedHeader, err := sr.readRecord()
+	if err != nil {
+		return err
+	}
+
+	header := types.NewEmptyHeader()
+	if err = scale.Unmarshal(encodedHeader, header); err != nil {
+		return fmt.Errorf("%w: cannot decode header: %s", ErrSnapshotMalformed, err)
+	}
+
+	encodedFirstSlot, err := sr.read(8)
+	if err != nil {
+		return err
+	}
+	firstSlot := binary.LittleEndian.Uint64(encodedFirstSlot)
+
+	return s.importState(header, firstSlot, func(storageDB chaindb.Database) error {
+		logger.Infof("importing snapshot of state root %s of block number %d...",
+			header.StateRoot, header.Number)
+
+		loader := trie.NewNodesLoader(storageDB, header.StateRoot)
+		for {
+			encodedNode, err := sr.readRecord()
+			if err != nil {
+				return err
+			} else if encodedNode == nil {
+				break
+			}
+
+			if err = loader.Load(encodedNode); err != nil {
+				return fmt.Errorf("cannot load trie node: %w", err)
+			}
+		}
+
+		nodes, err := loader.Done()
+		if err != nil {
+			return fmt.Errorf("cannot load state trie: %w", err)
+		}
+
+		if err = sr.verifyChecksum(); err != nil {
+			return err
+		}
+
+		logger.Infof("imported %d trie nodes of state root %s", nodes, header.StateRoot)
+		return nil
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSnapshot returns a snapshot of a state with a child trie
+// and the trie and header of the state.
+func newTestSnapshot(t *testing.T) (snapshot []byte, tr *trie.Trie, header *types.Header) {
+	t.Helper()
+
+	tr = trie.NewEmptyTrie()
+	for _, key := range []string{"asdf", "ghjk", "qwerty", "uiopl", "zxcv", "bnm"} {
+		tr.Put([]byte(key), []byte(key+key+key+key+key+key+key+key))
+	}
+
+	childTrie := trie.NewEmptyTrie()
+	childTrie.Put([]byte("child_key"), []byte("child_value"))
+	err := tr.PutChild([]byte("child"), childTrie)
+	require.NoError(t, err)
+
+	digest := types.NewDigest()
+	prd, err := types.NewBabeSecondaryPlainPreDigest(0, 177).ToPreRuntimeDigest()
+	require.NoError(t, err)
+	err = digest.Add(*prd)
+	require.NoError(t, err)
+	header = &types.Header{
+		ParentHash: testGenesisHeader.Hash(),
+		Number:     1,
+		StateRoot:  tr.MustHash(),
+		Digest:     digest,
+	}
+
+	storage := newTestStorageState(t, newTriesEmpty())
+	err = tr.Store(storage.db)
+	require.NoError(t, err)
+	err = storage.blockState.SetHeader(header)
+	require.NoError(t, err)
+
+	db := NewInMemoryDB(t)
+	serv := &Service{
+		Base:    NewBaseState(db),
+		Block:   storage.blockState,
+		Storage: storage,
+	}
+	err = serv.Base.storeFirstSlot(100)
+	require.NoError(t, err)
+
+	buffer := bytes.NewBuffer(nil)
+	err = serv.ExportSnapshot(header.Hash(), buffer)
+	require.NoError(t, err)
+
+	return buffer.Bytes(), tr, header
+}
+
+func newTestSnapshotImportService(t *testing.T) *Service {
+	t.Helper()
+
+	serv := newTestMemDBService(t)
+	serv.db = NewInMemoryDB(t)
+
+	// the snapshot is imported into an initialised database
+	base := NewBaseState(serv.db)
+	err := base.storeEpochLength(200)
+	require.NoError(t, err)
+	err = base.storeSkipToEpoch(0)
+	require.NoError(t, err)
+	blockDB := chaindb.NewTable(serv.db, blockPrefix)
+	err = blockDB.Put(highestRoundAndSetIDKey, roundAndSetIDToBytes(0, 0))
+	require.NoError(t, err)
+
+	return serv
+}
+
+func TestService_ImportSnapshot(t *testing.T) {
+	t.Parallel()
+
+	snapshot, tr, header := newTestSnapshot(t)
+
+	serv := newTestSnapshotImportService(t)
+	err := serv.ImportSnapshot(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+
+	storageDB := chaindb.NewTable(serv.db, storagePrefix)
+	imported, err := trie.NewLazyTrie(storageDB, nil, header.StateRoot).Trie()
+	require.NoError(t, err)
+	assert.Equal(t, tr.Entries(), imported.Entries())
+
+	value, err := imported.GetFromChild([]byte("child"), []byte("child_key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("child_value"), value)
+
+	blockDB := chaindb.NewTable(serv.db, blockPrefix)
+	finalisedHash, err := blockDB.Get(finalisedHashKey(0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, header.Hash().ToBytes(), finalisedHash)
+
+	firstSlot, err := serv.Base.loadFirstSlot()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), firstSlot)
+}
+
+func TestService_ImportSnapshot_errors(t *testing.T) {
+	t.Parallel()
+
+	snapshot, _, _ := newTestSnapshot(t)
+
+	corrupted := make([]byte, len(snapshot))
+	copy(corrupted, snapshot)
+	// corrupt the checksum
+	corrupted[len(corrupted)-1]++
+
+	wrongVersion := make([]byte, len(snapshot))
+	copy(wrongVersion, snapshot)
+	wrongVersion[len(snapshotMagic)] = snapshotVersion + 1
+
+	testCases := map[string]struct {
+		snapshot   []byte
+		errWrapped error
+	}{
+		"checksum mismatch": {
+			snapshot:   corrupted,
+			errWrapped: ErrSnapshotChecksum,
+		},
+		"unsupported version": {
+			snapshot:   wrongVersion,
+			errWrapped: ErrSnapshotMalformed,
+		},
+		"truncated": {
+			snapshot:   snapshot[:len(snapshot)/2],
+			errWrapped: ErrSnapshotMalformed,
+		},
+		"missing checksum": {
+			snapshot:   snapshot[:len(snapshot)-32],
+			errWrapped: ErrSnapshotMalformed,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			serv := newTestSnapshotImportService(t)
+			err := serv.ImportSnapshot(bytes.NewReader(testCase.snapshot))
+			assert.ErrorIs(t, err, testCase.errWrapped)
+
+			// the head is not set
+			blockDB := chaindb.NewTable(serv.db, blockPrefix)
+			has, err := blockDB.Has(finalisedHashKey(0, 0))
+			require.NoError(t, err)
+			assert.False(t, has)
+		})
+	}
+}
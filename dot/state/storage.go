@@ -62,6 +62,24 @@ func NewStorageState(db chaindb.Database, blockState *BlockState,
 		p = &pruner.ArchiveNode{}
 	}
 
+	if blockState != nil {
+		// canonicalise the state journal on finalisation
+		blockState.statePruner = p
+	}
+
+	if blockState != nil && onlinePruner.Mode == pruner.Full {
+		// canonicalise with the last finalised block in case we stopped before.
+		finalised, err := blockState.GetHighestFinalisedHeader()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get highest finalised header: %w", err)
+		}
+
+		err = p.Canonicalise(finalised.Hash(), int64(finalised.Number))
+		if err != nil {
+			return nil, fmt.Errorf("cannot canonicalise state journal: %w", err)
+		}
+	}
+
 	return &StorageState{
 		blockState:   blockState,
 		tries:        tries,
@@ -73,7 +91,10 @@ func NewStorageState(db chaindb.Database, blockState *BlockState,
 
 // StoreTrie stores the given trie in the StorageState and writes it to the database
 func (s *StorageState) StoreTrie(ts *rtstorage.TrieState, header *types.Header) error {
-	root := ts.MustRoot()
+	root, err := ts.Root()
+	if err != nil {
+		return fmt.Errorf("cannot compute state root: %w", err)
+	}
 
 	s.tries.softSet(root, ts.Trie())
 
@@ -88,7 +109,8 @@ func (s *StorageState) StoreTrie(ts *rtstorage.TrieState, header *types.Header)
 		}
 
 		deletedNodeHashes := ts.GetDeletedNodeHashes()
-		err = s.pruner.StoreJournalRecord(deletedNodeHashes, insertedNodeHashes, header.Hash(), int64(header.Number))
+		err = s.pruner.StoreJournalRecord(deletedNodeHashes, insertedNodeHashes,
+			header.Hash(), header.ParentHash, int64(header.Number))
 		if err != nil {
 			return err
 		}
@@ -116,16 +138,12 @@ func (s *StorageState) TrieState(root *common.Hash) (*rtstorage.TrieState, error
 		root = &sr
 	}
 
-	t := s.tries.get(*root)
-	if t == nil {
-		var err error
-		t, err = s.LoadFromDB(*root)
-		if err != nil {
-			return nil, err
-		}
+	t, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
 
-		s.tries.softSet(*root, t)
-	} else if t.MustHash() != *root {
+	if t.MustHash() != *root {
 		panic("trie does not have expected root")
 	}
 
@@ -165,14 +183,38 @@ func (s *StorageState) loadTrie(root *common.Hash) (*trie.Trie, error) {
 		return t, nil
 	}
 
-	tr, err := s.LoadFromDB(*root)
+	// only the tries of the best and finalised blocks are kept in
+	// memory, other tries resolve their nodes from the database
+	// through the node cache as they are traversed.
+	tr, err := s.tries.lazy(s.db, *root).Trie()
 	if err != nil {
 		return nil, fmt.Errorf("trie does not exist at root %s: %w", *root, err)
 	}
 
+	s.tries.softSet(*root, tr)
 	return tr, nil
 }
 
+// lazyTrie returns a read only trie with the given state root, or the best
+// block state root if root is nil. The trie is the in-memory trie if it is
+// cached, and otherwise resolves its nodes from the database as needed.
+func (s *StorageState) lazyTrie(root *common.Hash) (tr readOnlyTrie, err error) {
+	if root == nil {
+		sr, err := s.blockState.BestBlockStateRoot()
+		if err != nil {
+			return nil, err
+		}
+		root = &sr
+	}
+
+	t := s.tries.get(*root)
+	if t != nil {
+		return inMemoryTrie{t}, nil
+	}
+
+	return s.tries.lazy(s.db, *root), nil
+}
+
 // ExistsStorage check if the key exists in the storage trie with the given storage hash
 // If no hash is provided, the current chain head is used
 func (s *StorageState) ExistsStorage(root *common.Hash, key []byte) (bool, error) {
@@ -194,10 +236,10 @@ func (s *StorageState) GetStorage(root *common.Hash, key []byte) ([]byte, error)
 	t := s.tries.get(*root)
 	if t != nil {
 		val := t.Get(key)
-		return val, nil
+		return val, t.Err()
 	}
 
-	return trie.GetFromDB(s.db, *root, key)
+	return s.tries.lazy(s.db, *root).Get(key)
 }
 
 // GetStorageByBlockHash returns the value at the given key at the given block hash
@@ -251,18 +293,27 @@ func (s *StorageState) Entries(root *common.Hash) (map[string][]byte, error) {
 		return nil, err
 	}
 
-	return tr.Entries(), nil
+	return tr.Entries(), tr.Err()
 }
 
 // GetKeysWithPrefix returns all that match the given prefix for the given hash
 // (or best block state root if hash is nil) in lexicographic order
 func (s *StorageState) GetKeysWithPrefix(root *common.Hash, prefix []byte) ([][]byte, error) {
-	tr, err := s.loadTrie(root)
+	tr, err := s.lazyTrie(root)
 	if err != nil {
 		return nil, err
 	}
 
-	return tr.GetKeysWithPrefix(prefix), nil
+	return tr.GetKeysWithPrefix(prefix)
+}
+
+// Diff calls the given function with each key whose value differs between the
+// state tries with the given roots, including the keys of their child tries, in
+// key order. Only the trie nodes on the path to changed keys are read from the database.
+func (s *StorageState) Diff(fromRoot, toRoot common.Hash, fn func(change trie.Change) error) error {
+	from := s.tries.lazy(s.db, fromRoot)
+	to := s.tries.lazy(s.db, toRoot)
+	return from.Diff(to, fn)
 }
 
 // GetStorageChild returns a child trie, if it exists
@@ -277,7 +328,7 @@ func (s *StorageState) GetStorageChild(root *common.Hash, keyToChild []byte) (*t
 
 // GetStorageFromChild get a value from a child trie
 func (s *StorageState) GetStorageFromChild(root *common.Hash, keyToChild, key []byte) ([]byte, error) {
-	tr, err := s.loadTrie(root)
+	tr, err := s.lazyTrie(root)
 	if err != nil {
 		return nil, err
 	}
@@ -304,3 +355,18 @@ func (s *StorageState) LoadCodeHash(hash *common.Hash) (common.Hash, error) {
 func (s *StorageState) GenerateTrieProof(stateRoot common.Hash, keys [][]byte) ([][]byte, error) {
 	return trie.GenerateProof(stateRoot[:], keys, s.db)
 }
+
+// readOnlyTrie is implemented by both in-memory and lazy tries.
+type readOnlyTrie interface {
+	GetKeysWithPrefix(prefix []byte) ([][]byte, error)
+	GetFromChild(keyToChild, key []byte) ([]byte, error)
+}
+
+// inMemoryTrie adapts a trie to the readOnlyTrie interface.
+type inMemoryTrie struct {
+	*trie.Trie
+}
+
+func (t inMemoryTrie) GetKeysWithPrefix(prefix []byte) ([][]byte, error) {
+	return t.Trie.GetKeysWithPrefix(prefix), t.Err()
+}
@@ -100,7 +100,6 @@ func TestStorage_TrieState(t *testing.T) {
 	time.Sleep(time.Millisecond * 100)
 
 	// get trie from db
-	storage.blockState.tries.delete(root)
 	ts3, err := storage.TrieState(&root)
 	require.NoError(t, err)
 	require.Equal(t, ts.Trie().MustHash(), ts3.Trie().MustHash())
@@ -131,21 +130,15 @@ func TestStorage_LoadFromDB(t *testing.T) {
 	err = storage.StoreTrie(ts, nil)
 	require.NoError(t, err)
 
-	// Clear trie from cache and fetch data from disk.
-	storage.blockState.tries.delete(root)
-
+	// Fetch data from disk.
 	data, err := storage.GetStorage(&root, trieKV[0].key)
 	require.NoError(t, err)
 	require.Equal(t, trieKV[0].value, data)
 
-	storage.blockState.tries.delete(root)
-
 	prefixKeys, err := storage.GetKeysWithPrefix(&root, []byte("ke"))
 	require.NoError(t, err)
 	require.Equal(t, 2, len(prefixKeys))
 
-	storage.blockState.tries.delete(root)
-
 	entries, err := storage.Entries(&root)
 	require.NoError(t, err)
 	require.Equal(t, 3, len(entries))
@@ -162,7 +155,46 @@ func TestStorage_StoreTrie_NotSyncing(t *testing.T) {
 
 	err = storage.StoreTrie(ts, nil)
 	require.NoError(t, err)
-	require.Equal(t, 2, storage.blockState.tries.len())
+	// the trie is not pinned so it is resolved from the database
+	root := ts.MustRoot()
+	require.Nil(t, storage.blockState.tries.get(root))
+	storedValue, err := storage.GetStorage(&root, key)
+	require.NoError(t, err)
+	require.Equal(t, value, storedValue)
+}
+
+func TestStorage_Diff(t *testing.T) {
+	storage := newTestStorageState(t, newTriesEmpty())
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+
+	ts.Set([]byte("unchanged"), []byte("value"))
+	ts.Set([]byte("modified"), []byte("old"))
+	ts.Set([]byte("removed"), []byte("value"))
+	err = storage.StoreTrie(ts, nil)
+	require.NoError(t, err)
+	fromRoot := ts.MustRoot()
+
+	ts.Set([]byte("modified"), []byte("new"))
+	ts.Delete([]byte("removed"))
+	ts.Set([]byte("added"), []byte("value"))
+	err = storage.StoreTrie(ts, nil)
+	require.NoError(t, err)
+	toRoot := ts.MustRoot()
+
+	var changes []trie.Change
+	err = storage.Diff(fromRoot, toRoot, func(change trie.Change) error {
+		changes = append(changes, change)
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []trie.Change{
+		{Key: []byte("added"), Type: trie.Added, NewValue: []byte("value")},
+		{Key: []byte("modified"), Type: trie.Modified, OldValue: []byte("old"), NewValue: []byte("new")},
+		{Key: []byte("removed"), Type: trie.Removed, OldValue: []byte("value")},
+	}
+	require.Equal(t, expected, changes)
 }
 
 func TestGetStorageChildAndGetStorageFromChild(t *testing.T) {
@@ -208,12 +240,7 @@ func TestGetStorageChildAndGetStorageFromChild(t *testing.T) {
 	rootHash, err := genTrie.Hash()
 	require.NoError(t, err)
 
-	_, err = storage.GetStorageChild(&rootHash, []byte("keyToChild"))
-	require.NoError(t, err)
-
-	// Clear trie from cache and fetch data from disk.
-	storage.blockState.tries.delete(rootHash)
-
+	// Fetch data from disk.
 	_, err = storage.GetStorageChild(&rootHash, []byte("keyToChild"))
 	require.NoError(t, err)
 
@@ -0,0 +1,281 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+)
+
+const indexedTransactionsPrefix = "indexedtx"
+
+// blockTransactionIndexPrefix + hash -> indexed transactions of the block
+var blockTransactionIndexPrefix = []byte("tix")
+
+// IndexedTransaction is an extrinsic of a block whose data is indexed by content hash.
+type IndexedTransaction struct {
+	// Extrinsic is the index of the extrinsic in the block body.
+	Extrinsic uint32
+	// Hash is the content hash of the indexed data.
+	Hash common.Hash
+	// Offset is the offset of the indexed data in the encoded extrinsic.
+	Offset uint32
+	// Size is the size of the indexed data.
+	Size uint32
+	// Renewed is true if the data was indexed by an earlier block
+	// and renewed by the extrinsic, in which case the offset is zero.
+	Renewed bool
+}
+
+// indexedData is the data indexed with a content hash, referenced by the
+// blocks having indexed or renewed it, and deleted when no longer referenced.
+type indexedData struct {
+	References uint32
+	Data       []byte
+}
+
+// AddIndexedTransactions stores the data of the extrinsics of the block indexed by the given
+// operations, recorded by the runtime during its execution. The data is referenced by the block,
+// and kept until the block body is pruned or the block is reverted. Operations referring to a
+// missing extrinsic or renewing unknown data are ignored. It is a no-op if the block already
+// has indexed transactions stored.
+func (bs *BlockState) AddIndexedTransactions(block *types.Block, operations []rtstorage.IndexOperation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	bs.indexedTransactionsLock.Lock()
+	defer bs.indexedTransactionsLock.Unlock()
+
+	blockHash := block.Header.Hash()
+	has, err := bs.db.Has(prefixKey(blockHash, blockTransactionIndexPrefix))
+	if err != nil {
+		return fmt.Errorf("cannot check indexed transactions of block %s: %w", blockHash, err)
+	} else if has {
+		return nil
+	}
+
+	var indexed []IndexedTransaction
+	added := make(map[common.Hash]*indexedData)
+	for _, operation := range operations {
+		if int(operation.Extrinsic) >= len(block.Body) {
+			logger.Debugf("ignoring index operation for missing extrinsic %d of block %s",
+				operation.Extrinsic, blockHash)
+			continue
+		}
+
+		data, ok := added[operation.Hash]
+		if !ok {
+			data, err = bs.getIndexedData(operation.Hash)
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction := IndexedTransaction{
+			Extrinsic: operation.Extrinsic,
+			Hash:      operation.Hash,
+			Renewed:   operation.Renew,
+		}
+
+		if operation.Renew {
+			if data == nil {
+				logger.Debugf("ignoring renewal of unknown indexed data %s by extrinsic %d of block %s",
+					operation.Hash, operation.Extrinsic, blockHash)
+				continue
+			}
+			transaction.Size = uint32(len(data.Data))
+		} else {
+			extrinsic := block.Body[operation.Extrinsic]
+			if int(operation.Size) > len(extrinsic) {
+				logger.Debugf("ignoring index operation of %d bytes for extrinsic %d of %d bytes of block %s",
+					operation.Size, operation.Extrinsic, len(extrinsic), blockHash)
+				continue
+			}
+
+			transaction.Offset = uint32(len(extrinsic)) - operation.Size
+			transaction.Size = operation.Size
+			if data == nil {
+				data = &indexedData{
+					Data: append([]byte(nil), extrinsic[transaction.Offset:]...),
+				}
+			}
+		}
+
+		data.References++
+		added[operation.Hash] = data
+		indexed = append(indexed, transaction)
+	}
+
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	batch := bs.indexedTransactions.NewBatch()
+	for hash, data := range added {
+		encodedData, err := scale.Marshal(*data)
+		if err != nil {
+			return fmt.Errorf("cannot encode indexed data %s: %w", hash, err)
+		}
+
+		if err = batch.Put(hash.ToBytes(), encodedData); err != nil {
+			return err
+		}
+	}
+
+	if err = batch.Flush(); err != nil {
+		return fmt.Errorf("cannot write indexed data: %w", err)
+	}
+
+	encodedIndexed, err := scale.Marshal(indexed)
+	if err != nil {
+		return fmt.Errorf("cannot encode indexed transactions: %w", err)
+	}
+
+	return bs.db.Put(prefixKey(blockHash, blockTransactionIndexPrefix), encodedIndexed)
+}
+
+// GetIndexedTransactions returns the indexed transactions of the block with the given hash,
+// ordered by extrinsic index, or nil if the block has no indexed transactions.
+func (bs *BlockState) GetIndexedTransactions(blockHash common.Hash) ([]IndexedTransaction, error) {
+	encodedIndexed, err := bs.db.Get(prefixKey(blockHash, blockTransactionIndexPrefix))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var indexed []IndexedTransaction
+	if err = scale.Unmarshal(encodedIndexed, &indexed); err != nil {
+		return nil, fmt.Errorf("cannot decode indexed transactions of block %s: %w", blockHash, err)
+	}
+
+	return indexed, nil
+}
+
+// GetIndexedTransaction returns the data indexed with the given content hash.
+// It returns a chaindb.ErrKeyNotFound error if no data is indexed with the hash.
+func (bs *BlockState) GetIndexedTransaction(hash common.Hash) ([]byte, error) {
+	data, err := bs.getIndexedData(hash)
+	if err != nil {
+		return nil, err
+	} else if data == nil {
+		return nil, fmt.Errorf("%w: no data indexed with hash %s", chaindb.ErrKeyNotFound, hash)
+	}
+
+	return data.Data, nil
+}
+
+// GetBlockIndexedBody returns the data indexed by the extrinsics of the block with
+// the given hash, ordered by extrinsic index, for the block responses requesting
+// the indexed body. It returns nil if the block has no indexed transactions.
+func (bs *BlockState) GetBlockIndexedBody(blockHash common.Hash) ([][]byte, error) {
+	indexed, err := bs.GetIndexedTransactions(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(indexed) == 0 {
+		return nil, nil
+	}
+
+	indexedBody := make([][]byte, len(indexed))
+	for i, transaction := range indexed {
+		indexedBody[i], err = bs.GetIndexedTransaction(transaction.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return indexedBody, nil
+}
+
+// releaseIndexedTransactions removes the references of the blocks with the given
+// hashes to their indexed data, and deletes the data no longer referenced.
+func (bs *BlockState) releaseIndexedTransactions(blockHashes []common.Hash) error {
+	bs.indexedTransactionsLock.Lock()
+	defer bs.indexedTransactionsLock.Unlock()
+
+	released := make(map[common.Hash]uint32)
+	blockBatch := bs.db.NewBatch()
+	for _, blockHash := range blockHashes {
+		indexed, err := bs.GetIndexedTransactions(blockHash)
+		if err != nil {
+			return err
+		}
+
+		if len(indexed) == 0 {
+			continue
+		}
+
+		for _, transaction := range indexed {
+			released[transaction.Hash]++
+		}
+
+		if err = blockBatch.Del(prefixKey(blockHash, blockTransactionIndexPrefix)); err != nil {
+			return err
+		}
+	}
+
+	if len(released) == 0 {
+		return nil
+	}
+
+	batch := bs.indexedTransactions.NewBatch()
+	for hash, references := range released {
+		data, err := bs.getIndexedData(hash)
+		if err != nil {
+			return err
+		} else if data == nil {
+			continue
+		}
+
+		if data.References <= references {
+			if err = batch.Del(hash.ToBytes()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data.References -= references
+		encodedData, err := scale.Marshal(*data)
+		if err != nil {
+			return fmt.Errorf("cannot encode indexed data %s: %w", hash, err)
+		}
+
+		if err = batch.Put(hash.ToBytes(), encodedData); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("cannot write released indexed data: %w", err)
+	}
+
+	return blockBatch.Flush()
+}
+
+// getIndexedData returns the data indexed with the given content hash, or nil if there is none.
+func (bs *BlockState) getIndexedData(hash common.Hash) (*indexedData, error) {
+	encodedData, err := bs.indexedTransactions.Get(hash.ToBytes())
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get indexed data %s: %w", hash, err)
+	}
+
+	data := new(indexedData)
+	if err = scale.Unmarshal(encodedData, data); err != nil {
+		return nil, fmt.Errorf("cannot decode indexed data %s: %w", hash, err)
+	}
+
+	return data, nil
+}
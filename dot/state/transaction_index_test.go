@@ -0,0 +1,82 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+)
+
+func TestBlockState_IndexedTransactions(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+
+	data := []byte{4, 5, 6}
+	hash := common.MustBlake2bHash(data)
+
+	indexingBlock := &types.Block{
+		Header: types.Header{Number: 1},
+		Body:   types.Body{{1}, {1, 2, 3, 4, 5, 6}},
+	}
+	operations := []rtstorage.IndexOperation{
+		{Extrinsic: 1, Hash: hash, Size: 3},
+		// ignored, as the extrinsic is missing
+		{Extrinsic: 2, Hash: common.Hash{1}, Size: 1},
+	}
+	err := bs.AddIndexedTransactions(indexingBlock, operations)
+	require.NoError(t, err)
+
+	// adding the indexed transactions of a block again is a no-op
+	err = bs.AddIndexedTransactions(indexingBlock, operations)
+	require.NoError(t, err)
+
+	renewingBlock := &types.Block{
+		Header: types.Header{Number: 2},
+		Body:   types.Body{{1}},
+	}
+	err = bs.AddIndexedTransactions(renewingBlock, []rtstorage.IndexOperation{
+		{Extrinsic: 0, Hash: hash, Renew: true},
+		// ignored, as no data is indexed with the hash
+		{Extrinsic: 0, Hash: common.Hash{1}, Renew: true},
+	})
+	require.NoError(t, err)
+
+	indexed, err := bs.GetIndexedTransactions(indexingBlock.Header.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, []IndexedTransaction{{Extrinsic: 1, Hash: hash, Offset: 3, Size: 3}}, indexed)
+
+	indexed, err = bs.GetIndexedTransactions(renewingBlock.Header.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, []IndexedTransaction{{Extrinsic: 0, Hash: hash, Size: 3, Renewed: true}}, indexed)
+
+	indexedBody, err := bs.GetBlockIndexedBody(renewingBlock.Header.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{data}, indexedBody)
+
+	// the data is kept while a block references it
+	err = bs.releaseIndexedTransactions([]common.Hash{indexingBlock.Header.Hash()})
+	require.NoError(t, err)
+
+	indexed, err = bs.GetIndexedTransactions(indexingBlock.Header.Hash())
+	require.NoError(t, err)
+	assert.Nil(t, indexed)
+
+	indexedData, err := bs.GetIndexedTransaction(hash)
+	require.NoError(t, err)
+	assert.Equal(t, data, indexedData)
+
+	err = bs.releaseIndexedTransactions([]common.Hash{renewingBlock.Header.Hash()})
+	require.NoError(t, err)
+
+	_, err = bs.GetIndexedTransaction(hash)
+	assert.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+}
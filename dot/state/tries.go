@@ -6,18 +6,18 @@
 import (
 	"sync"
 
+	"github.com/ChainSafe/chaindb"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// DefaultNodeCacheSize is the default maximum size of the
+// state trie node cache, in bytes.
+const DefaultNodeCacheSize = 64 * 1024 * 1024
+
 var (
-	triesGauge = promauto.NewGauge(prometheus.GaugeOpts{
-		Namespace: "gossamer_storage_tries",
-		Name:      "cached_total",
-		Help:      "total number of tries cached in memory",
-	})
 	setCounter = promauto.NewCounter(prometheus.CounterOpts{
 		Namespace: "gossamer_storage_tries",
 		Name:      "set_total",
@@ -28,69 +28,258 @@
 		Name:      "delete_total",
 		Help:      "total number of tries deleted from memory",
 	})
+	nodeCacheHitsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_storage_tries",
+		Name:      "node_cache_hits_total",
+		Help:      "total number of trie nodes found in the node cache",
+	})
+	nodeCacheMissesCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_storage_tries",
+		Name:      "node_cache_misses_total",
+		Help:      "total number of trie nodes not found in the node cache",
+	})
+	nodeCacheEvictionsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_storage_tries",
+		Name:      "node_cache_evictions_total",
+		Help:      "total number of trie nodes evicted from the node cache",
+	})
+	nodeCacheSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_storage_tries",
+		Name:      "node_cache_size_bytes",
+		Help:      "approximate memory size of the trie nodes in the node cache",
+	})
 )
 
-// Tries is a thread safe map of root hash
-// to trie.
+// Tries keeps in memory the tries of the best and finalised state roots.
+// The nodes of the tries stored are written to the database, and tries of
+// other state roots resolve their nodes from the database as they are
+// traversed, through a node cache shared by all the tries, bounded in bytes.
+// The nodes resolved by the tries kept in memory are pinned in the node
+// cache until their root is no longer pinned.
 type Tries struct {
-	rootToTrie    map[common.Hash]*trie.Trie
-	mapMutex      sync.RWMutex
-	triesGauge    prometheus.Gauge
+	// pinned contains the tries of the pinned roots only,
+	// which are the best root and the finalised root.
+	pinned map[common.Hash]*trie.Trie
+	// stored is the trie of the last root set and not pinned, kept
+	// so it can be pinned when its block becomes the best block,
+	// since the trie of a block is stored before its block is added.
+	stored        *trie.Trie
+	storedRoot    common.Hash
+	mutex         sync.RWMutex
+	bestRoot      common.Hash
+	finalisedRoot common.Hash
+	nodeCache     *nodeCache
 	setCounter    prometheus.Counter
 	deleteCounter prometheus.Counter
 }
 
-// NewTries creates a new thread safe map of root hash
-// to trie using the trie given as a first trie.
+// NewTries creates a new thread safe set of tries using the trie
+// given as a first trie, pinned as both the best and finalised trie,
+// with a node cache of DefaultNodeCacheSize bytes.
 func NewTries(t *trie.Trie) (trs *Tries, err error) {
+	root := t.MustHash()
+	nodeCache := newNodeCache(DefaultNodeCacheSize)
+	nodeCache.PinRoot(root)
 	return &Tries{
-		rootToTrie: map[common.Hash]*trie.Trie{
-			t.MustHash(): t,
+		pinned: map[common.Hash]*trie.Trie{
+			root: t.WithNodeCache(nodeCache.pinned(root)),
 		},
-		triesGauge:    triesGauge,
+		bestRoot:      root,
+		finalisedRoot: root,
+		nodeCache:     nodeCache,
 		setCounter:    setCounter,
 		deleteCounter: deleteCounter,
 	}, nil
 }
 
-// softSet sets the given trie at the given root hash
-// in the memory map only if it is not already set.
+// SetNodeCacheSize sets the maximum size in bytes of the node cache.
+func (t *Tries) SetNodeCacheSize(size uint64) {
+	t.nodeCache.Resize(size)
+	t.nodeCache.updateMetrics()
+}
+
+// softSet keeps the given trie in memory if the given root hash
+// is pinned and its trie is not already in memory.
+// Tries of roots not pinned are resolved from the database,
+// except for the trie set last which is kept until another
+// trie is set, in case its root gets pinned.
 func (t *Tries) softSet(root common.Hash, trie *trie.Trie) {
-	t.mapMutex.Lock()
-	defer t.mapMutex.Unlock()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 
-	_, has := t.rootToTrie[root]
-	if has {
+	if !t.isPinned(root) {
+		t.stored = trie
+		t.storedRoot = root
 		return
 	}
 
-	t.triesGauge.Inc()
-	t.setCounter.Inc()
-	t.rootToTrie[root] = trie
+	t.set(root, trie)
 }
 
-func (t *Tries) delete(root common.Hash) {
-	t.mapMutex.Lock()
-	defer t.mapMutex.Unlock()
-	delete(t.rootToTrie, root)
-	// Note we use .Set instead of .Dec in case nothing
-	// was deleted since nothing existed at the hash given.
-	t.triesGauge.Set(float64(len(t.rootToTrie)))
-	t.deleteCounter.Inc()
+// set keeps the given trie of a pinned root in memory
+// if its trie is not already in memory.
+// It must be called with the mutex locked.
+func (t *Tries) set(root common.Hash, trie *trie.Trie) {
+	_, has := t.pinned[root]
+	if has {
+		return
+	}
+
+	t.setCounter.Inc()
+	t.pinned[root] = trie.WithNodeCache(t.nodeCache.pinned(root))
 }
 
 // get retrieves the trie corresponding to the root hash given
-// from the in-memory thread safe map.
+// if it is kept in memory, and returns nil otherwise.
 func (t *Tries) get(root common.Hash) (tr *trie.Trie) {
-	t.mapMutex.RLock()
-	defer t.mapMutex.RUnlock()
-	return t.rootToTrie[root]
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.pinned[root]
 }
 
-// len returns the current numbers of tries
-// stored in the in-memory map.
+// len returns the current numbers of tries kept in memory.
 func (t *Tries) len() int {
-	t.mapMutex.RLock()
-	defer t.mapMutex.RUnlock()
-	return len(t.rootToTrie)
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return len(t.pinned)
+}
+
+// lazy returns a trie with the given root hash resolving
+// its nodes from the database through the node cache.
+func (t *Tries) lazy(db chaindb.Database, root common.Hash) *trie.LazyTrie {
+	return trie.NewLazyTrie(db, t.nodeCache, root)
+}
+
+// pinBest pins the state root of the best block.
+func (t *Tries) pinBest(root common.Hash) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.bestRoot
+	t.bestRoot = root
+	t.pin(root)
+	t.unpin(previous)
+}
+
+// pinFinalised pins the state root of the finalised block.
+func (t *Tries) pinFinalised(root common.Hash) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.finalisedRoot
+	t.finalisedRoot = root
+	t.pin(root)
+	t.unpin(previous)
+}
+
+// isPinned must be called with the mutex locked.
+func (t *Tries) isPinned(root common.Hash) bool {
+	return root == t.bestRoot || root == t.finalisedRoot
+}
+
+// pin pins the nodes of the given pinned root in the node cache,
+// and keeps the trie set last in memory if it has the given root.
+// It must be called with the mutex locked.
+func (t *Tries) pin(root common.Hash) {
+	t.nodeCache.PinRoot(root)
+
+	if t.stored != nil && t.storedRoot == root {
+		t.set(root, t.stored)
+		t.stored = nil
+	}
+}
+
+// unpin removes the trie at the given root from memory
+// and unpins its nodes if the root is no longer pinned.
+// It must be called with the mutex locked.
+func (t *Tries) unpin(root common.Hash) {
+	if t.isPinned(root) {
+		return
+	}
+
+	t.nodeCache.UnpinRoot(root)
+
+	_, has := t.pinned[root]
+	if !has {
+		return
+	}
+
+	delete(t.pinned, root)
+	t.deleteCounter.Inc()
+}
+
+// nodeCache is a trie node cache reporting its usage as metrics.
+type nodeCache struct {
+	*trie.LRUNodeCache
+	evictions uint64
+	mutex     sync.Mutex
+}
+
+func newNodeCache(maxSize uint64) *nodeCache {
+	return &nodeCache{
+		LRUNodeCache: trie.NewLRUNodeCache(maxSize),
+	}
+}
+
+// Get returns the node with the given hash if it is cached.
+func (c *nodeCache) Get(hash common.Hash) (n trie.Node, ok bool) {
+	n, ok = c.LRUNodeCache.Get(hash)
+	countLookup(ok)
+	return n, ok
+}
+
+// Put adds the node with the given hash to the cache.
+func (c *nodeCache) Put(hash common.Hash, n trie.Node) {
+	c.LRUNodeCache.Put(hash, n)
+	// Nodes are only put after being read from the database,
+	// so the cost of updating the metrics is negligible.
+	c.updateMetrics()
+}
+
+// pinned returns a view of the cache pinning
+// the nodes resolved for the given root.
+func (c *nodeCache) pinned(root common.Hash) trie.NodeCache {
+	return &pinnedNodeCache{
+		NodeCache: c.LRUNodeCache.Pinned(root),
+		cache:     c,
+	}
+}
+
+func countLookup(hit bool) {
+	if hit {
+		nodeCacheHitsCounter.Inc()
+	} else {
+		nodeCacheMissesCounter.Inc()
+	}
+}
+
+func (c *nodeCache) updateMetrics() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.Stats()
+	if stats.Evictions > c.evictions {
+		nodeCacheEvictionsCounter.Add(float64(stats.Evictions - c.evictions))
+		c.evictions = stats.Evictions
+	}
+	nodeCacheSizeGauge.Set(float64(stats.Size))
+}
+
+// pinnedNodeCache is a view of the node cache pinning the nodes of a root.
+type pinnedNodeCache struct {
+	trie.NodeCache
+	cache *nodeCache
+}
+
+// Get returns the node with the given hash if it is cached, and pins it.
+func (c *pinnedNodeCache) Get(hash common.Hash) (n trie.Node, ok bool) {
+	n, ok = c.NodeCache.Get(hash)
+	countLookup(ok)
+	return n, ok
+}
+
+// Put adds the node with the given hash to the cache, and pins it.
+func (c *pinnedNodeCache) Put(hash common.Hash, n trie.Node) {
+	c.NodeCache.Put(hash, n)
+	c.cache.updateMetrics()
 }
@@ -19,50 +19,64 @@ func Test_NewTries(t *testing.T) {
 
 	tr := trie.NewEmptyTrie()
 
-	rootToTrie, err := NewTries(tr)
+	tries, err := NewTries(tr)
 	require.NoError(t, err)
 
-	expectedTries := &Tries{
-		rootToTrie: map[common.Hash]*trie.Trie{
-			tr.MustHash(): tr,
-		},
-		triesGauge:    triesGauge,
-		setCounter:    setCounter,
-		deleteCounter: deleteCounter,
+	expectedPinned := map[common.Hash]*trie.Trie{
+		tr.MustHash(): tr,
 	}
-
-	assert.Equal(t, expectedTries, rootToTrie)
+	assert.Equal(t, expectedPinned, tries.pinned)
+	assert.Equal(t, tr.MustHash(), tries.bestRoot)
+	assert.Equal(t, tr.MustHash(), tries.finalisedRoot)
+	assert.Equal(t, setCounter, tries.setCounter)
+	assert.Equal(t, deleteCounter, tries.deleteCounter)
+	require.NotNil(t, tries.nodeCache)
+
+	tries.SetNodeCacheSize(1)
+	tries.nodeCache.Put(common.Hash{1}, &node.Leaf{Key: []byte{1}})
+	assert.Equal(t, 0, tries.nodeCache.Stats().Nodes)
 }
 
-//go:generate mockgen -destination=mock_gauge_test.go -package $GOPACKAGE github.com/prometheus/client_golang/prometheus Gauge
 //go:generate mockgen -destination=mock_counter_test.go -package $GOPACKAGE github.com/prometheus/client_golang/prometheus Counter
 
 func Test_Tries_softSet(t *testing.T) {
 	t.Parallel()
 
 	testCases := map[string]struct {
-		rootToTrie         map[common.Hash]*trie.Trie
-		root               common.Hash
-		trie               *trie.Trie
-		triesGaugeInc      bool
-		expectedRootToTrie map[common.Hash]*trie.Trie
+		pinned         map[common.Hash]*trie.Trie
+		bestRoot       common.Hash
+		root           common.Hash
+		trie           *trie.Trie
+		setCounterInc  bool
+		expectedPinned map[common.Hash]*trie.Trie
+		expectedStored *trie.Trie
 	}{
-		"set new in map": {
-			rootToTrie:    map[common.Hash]*trie.Trie{},
+		"set pinned root": {
+			pinned:        map[common.Hash]*trie.Trie{},
+			bestRoot:      common.Hash{1, 2, 3},
 			root:          common.Hash{1, 2, 3},
 			trie:          trie.NewEmptyTrie(),
-			triesGaugeInc: true,
-			expectedRootToTrie: map[common.Hash]*trie.Trie{
+			setCounterInc: true,
+			expectedPinned: map[common.Hash]*trie.Trie{
 				{1, 2, 3}: trie.NewEmptyTrie(),
 			},
 		},
-		"do not override in map": {
-			rootToTrie: map[common.Hash]*trie.Trie{
+		"do not pin root not pinned": {
+			pinned:         map[common.Hash]*trie.Trie{},
+			bestRoot:       common.Hash{3, 4, 5},
+			root:           common.Hash{1, 2, 3},
+			trie:           trie.NewEmptyTrie(),
+			expectedPinned: map[common.Hash]*trie.Trie{},
+			expectedStored: trie.NewEmptyTrie(),
+		},
+		"do not override pinned root": {
+			pinned: map[common.Hash]*trie.Trie{
 				{1, 2, 3}: {},
 			},
-			root: common.Hash{1, 2, 3},
-			trie: trie.NewEmptyTrie(),
-			expectedRootToTrie: map[common.Hash]*trie.Trie{
+			bestRoot: common.Hash{1, 2, 3},
+			root:     common.Hash{1, 2, 3},
+			trie:     trie.NewEmptyTrie(),
+			expectedPinned: map[common.Hash]*trie.Trie{
 				{1, 2, 3}: {},
 			},
 		},
@@ -74,89 +88,115 @@ func Test_Tries_softSet(t *testing.T) {
 			t.Parallel()
 			ctrl := gomock.NewController(t)
 
-			triesGauge := NewMockGauge(ctrl)
-			if testCase.triesGaugeInc {
-				triesGauge.EXPECT().Inc()
-			}
-
 			setCounter := NewMockCounter(ctrl)
-			if testCase.triesGaugeInc {
+			if testCase.setCounterInc {
 				setCounter.EXPECT().Inc()
 			}
 
 			tries := &Tries{
-				rootToTrie: testCase.rootToTrie,
-				triesGauge: triesGauge,
+				pinned:     testCase.pinned,
+				bestRoot:   testCase.bestRoot,
+				nodeCache:  newNodeCache(DefaultNodeCacheSize),
 				setCounter: setCounter,
 			}
 
 			tries.softSet(testCase.root, testCase.trie)
 
-			assert.Equal(t, testCase.expectedRootToTrie, tries.rootToTrie)
+			assert.Equal(t, testCase.expectedPinned, tries.pinned)
+			assert.Equal(t, testCase.expectedStored, tries.stored)
 		})
 	}
 }
 
-func Test_Tries_delete(t *testing.T) {
+func Test_Tries_pin(t *testing.T) {
 	t.Parallel()
 
-	testCases := map[string]struct {
-		rootToTrie         map[common.Hash]*trie.Trie
-		root               common.Hash
-		deleteCounterInc   bool
-		expectedRootToTrie map[common.Hash]*trie.Trie
-		triesGaugeSet      float64
-	}{
-		"not found": {
-			rootToTrie: map[common.Hash]*trie.Trie{
-				{3, 4, 5}: {},
-			},
-			root:          common.Hash{1, 2, 3},
-			triesGaugeSet: 1,
-			expectedRootToTrie: map[common.Hash]*trie.Trie{
-				{3, 4, 5}: {},
-			},
-			deleteCounterInc: true,
-		},
-		"deleted": {
-			rootToTrie: map[common.Hash]*trie.Trie{
-				{1, 2, 3}: {},
-				{3, 4, 5}: {},
-			},
-			root:          common.Hash{1, 2, 3},
-			triesGaugeSet: 1,
-			expectedRootToTrie: map[common.Hash]*trie.Trie{
-				{3, 4, 5}: {},
-			},
-			deleteCounterInc: true,
-		},
-	}
+	ctrl := gomock.NewController(t)
+	setCounter := NewMockCounter(ctrl)
+	setCounter.EXPECT().Inc().Times(3)
+	deleteCounter := NewMockCounter(ctrl)
 
-	for name, testCase := range testCases {
-		testCase := testCase
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-			ctrl := gomock.NewController(t)
-			triesGauge := NewMockGauge(ctrl)
-			triesGauge.EXPECT().Set(testCase.triesGaugeSet)
+	first, second, third := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	tries, err := NewTries(trie.NewEmptyTrie())
+	require.NoError(t, err)
+	tries.setCounter = setCounter
+	tries.deleteCounter = deleteCounter
+
+	// the initial trie is unpinned once neither best nor finalised
+	tries.pinBest(first)
+	assert.Equal(t, 1, tries.len())
+	deleteCounter.EXPECT().Inc()
+	tries.pinFinalised(first)
+	assert.Equal(t, 0, tries.len())
+
+	tries.softSet(first, trie.NewEmptyTrie())
+	tries.pinBest(second)
+	tries.softSet(second, trie.NewEmptyTrie())
+	// tries of roots not pinned are not kept in memory
+	thirdTrie := trie.NewEmptyTrie()
+	tries.softSet(third, thirdTrie)
+	assert.Equal(t, 2, tries.len())
+	assert.Nil(t, tries.get(third))
+
+	// first is still pinned as the finalised root, and the trie
+	// set last is kept in memory once its root gets pinned
+	deleteCounter.EXPECT().Inc()
+	tries.pinBest(third)
+	assert.NotNil(t, tries.get(first))
+	assert.Nil(t, tries.get(second))
+	assert.Same(t, thirdTrie, tries.get(third))
+
+	deleteCounter.EXPECT().Inc()
+	tries.pinFinalised(third)
+	assert.Nil(t, tries.get(first))
+	assert.Equal(t, 1, tries.len())
+
+	tries.softSet(third, trie.NewEmptyTrie())
+	assert.NotNil(t, tries.get(third))
+}
 
-			deleteCounter := NewMockCounter(ctrl)
-			if testCase.deleteCounterInc {
-				deleteCounter.EXPECT().Inc()
-			}
+func Test_Tries_pin_nodeCache(t *testing.T) {
+	t.Parallel()
 
-			tries := &Tries{
-				rootToTrie:    testCase.rootToTrie,
-				triesGauge:    triesGauge,
-				deleteCounter: deleteCounter,
-			}
+	tr := trie.NewEmptyTrie()
+	for i := 0; i < 100; i++ {
+		tr.Put([]byte{byte(i)}, make([]byte, 40))
+	}
+	db := NewInMemoryDB(t)
+	err := tr.Store(db)
+	require.NoError(t, err)
+	root := tr.MustHash()
 
-			tries.delete(testCase.root)
+	tries, err := NewTries(trie.NewEmptyTrie())
+	require.NoError(t, err)
+	// only the pinned nodes are kept in the cache
+	tries.SetNodeCacheSize(0)
 
-			assert.Equal(t, testCase.expectedRootToTrie, tries.rootToTrie)
-		})
+	lazyTrie, err := tries.lazy(db, root).Trie()
+	require.NoError(t, err)
+	tries.pinBest(root)
+	tries.softSet(root, lazyTrie)
+
+	pinned := tries.get(root)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, make([]byte, 40), pinned.Get([]byte{byte(i)}))
 	}
+	stats := tries.nodeCache.Stats()
+	assert.Greater(t, stats.Nodes, 1)
+	assert.Equal(t, stats.Size, stats.PinnedSize)
+
+	// the nodes read by other tries are not pinned
+	other, err := tries.lazy(db, root).Trie()
+	require.NoError(t, err)
+	other.Get([]byte{1})
+	assert.Equal(t, stats.Nodes, tries.nodeCache.Stats().Nodes)
+
+	tries.pinBest(common.Hash{1})
+	stats = tries.nodeCache.Stats()
+	assert.Equal(t, 0, stats.Nodes)
+	assert.Equal(t, uint64(0), stats.PinnedSize)
 }
+
 func Test_Tries_get(t *testing.T) {
 	t.Parallel()
 
@@ -167,7 +207,7 @@ func Test_Tries_get(t *testing.T) {
 	}{
 		"found in map": {
 			tries: &Tries{
-				rootToTrie: map[common.Hash]*trie.Trie{
+				pinned: map[common.Hash]*trie.Trie{
 					{1, 2, 3}: trie.NewTrie(&node.Leaf{
 						Key: []byte{1, 2, 3},
 					}),
@@ -181,7 +221,7 @@ func Test_Tries_get(t *testing.T) {
 		"not found in map": {
 			// similar to not found in database
 			tries: &Tries{
-				rootToTrie: map[common.Hash]*trie.Trie{},
+				pinned: map[common.Hash]*trie.Trie{},
 			},
 			root: common.Hash{1, 2, 3},
 		},
@@ -208,12 +248,12 @@ func Test_Tries_len(t *testing.T) {
 	}{
 		"empty map": {
 			tries: &Tries{
-				rootToTrie: map[common.Hash]*trie.Trie{},
+				pinned: map[common.Hash]*trie.Trie{},
 			},
 		},
 		"non empty map": {
 			tries: &Tries{
-				rootToTrie: map[common.Hash]*trie.Trie{
+				pinned: map[common.Hash]*trie.Trie{
 					{1, 2, 3}: {},
 				},
 			},
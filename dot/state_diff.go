@@ -0,0 +1,121 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+var errInvalidBlockID = errors.New("invalid block id")
+
+// StateDiff writes the storage keys added, modified and removed between the states of the
+// two given blocks to the writer, including the keys of child tries, in key order. Blocks
+// are given by number or by hex encoded hash, and the best block is used if to is empty.
+func StateDiff(cfg *Config, from, to string, w io.Writer) error {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if err := stateSrvc.Stop(); err != nil {
+			logger.Errorf("cannot stop state service: %s", err)
+		}
+	}()
+
+	fromHash, err := blockHashFromID(stateSrvc.Block, from)
+	if err != nil {
+		return err
+	}
+
+	toHash := stateSrvc.Block.BestBlockHash()
+	if to != "" {
+		toHash, err = blockHashFromID(stateSrvc.Block, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	fromHeader, err := stateSrvc.Block.GetHeader(fromHash)
+	if err != nil {
+		return fmt.Errorf("cannot get header of block %s: %w", fromHash, err)
+	}
+
+	toHeader, err := stateSrvc.Block.GetHeader(toHash)
+	if err != nil {
+		return fmt.Errorf("cannot get header of block %s: %w", toHash, err)
+	}
+
+	logger.Infof("comparing state of block number %d with hash %s to state of block number %d with hash %s...",
+		fromHeader.Number, fromHash, toHeader.Number, toHash)
+
+	var changes uint
+	err = stateSrvc.Storage.Diff(fromHeader.StateRoot, toHeader.StateRoot, func(change trie.Change) error {
+		changes++
+		return writeChange(w, change)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot diff states: %w", err)
+	}
+
+	logger.Infof("found %d changed keys", changes)
+	return nil
+}
+
+// blockHashFromID returns the hash of the block given by hex encoded
+// hash, or of the block of the best chain with the given number.
+func blockHashFromID(bs *state.BlockState, id string) (common.Hash, error) {
+	if strings.HasPrefix(id, "0x") {
+		hash, err := common.HexToHash(id)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%w: %s", errInvalidBlockID, err)
+		}
+		return hash, nil
+	}
+
+	number, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %s", errInvalidBlockID, err)
+	}
+
+	hash, err := bs.GetHashByNumber(uint(number))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("cannot get hash of block %d: %w", number, err)
+	}
+	return hash, nil
+}
+
+// writeChange writes the change to the writer prefixed by + for added keys,
+// - for removed keys and ~ for modified keys. The keys of child tries are
+// prefixed by the key of their child trie.
+func writeChange(w io.Writer, change trie.Change) (err error) {
+	var key string
+	if change.KeyToChild != nil {
+		key = fmt.Sprintf("child 0x%x 0x%x", change.KeyToChild, change.Key)
+	} else {
+		key = fmt.Sprintf("0x%x", change.Key)
+	}
+
+	switch change.Type {
+	case trie.Added:
+		_, err = fmt.Fprintf(w, "+ %s: 0x%x\n", key, change.NewValue)
+	case trie.Removed:
+		_, err = fmt.Fprintf(w, "- %s: 0x%x\n", key, change.OldValue)
+	default:
+		_, err = fmt.Fprintf(w, "~ %s: 0x%x -> 0x%x\n", key, change.OldValue, change.NewValue)
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeChange(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		change trie.Change
+		line   string
+	}{
+		"added": {
+			change: trie.Change{Key: []byte{1}, Type: trie.Added, NewValue: []byte{2}},
+			line:   "+ 0x01: 0x02\n",
+		},
+		"removed": {
+			change: trie.Change{Key: []byte{1}, Type: trie.Removed, OldValue: []byte{2}},
+			line:   "- 0x01: 0x02\n",
+		},
+		"modified child trie key": {
+			change: trie.Change{
+				KeyToChild: []byte{3},
+				Key:        []byte{1},
+				Type:       trie.Modified,
+				OldValue:   []byte{2},
+				NewValue:   []byte{4},
+			},
+			line: "~ child 0x03 0x01: 0x02 -> 0x04\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := bytes.NewBuffer(nil)
+			err := writeChange(buffer, testCase.change)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.line, buffer.String())
+		})
+	}
+}
@@ -8,10 +8,14 @@
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 )
 
 // ChainProcessor processes ready blocks.
@@ -40,12 +44,17 @@ type chainProcessor struct {
 	finalityGadget     FinalityGadget
 	blockImportHandler BlockImportHandler
 	telemetry          telemetry.Client
+
+	// timestampDrift is added to the current time to build the timestamp inherent
+	// the inherents of the blocks are checked with, to tolerate clock drifts.
+	timestampDrift time.Duration
 }
 
 func newChainProcessor(readyBlocks *blockQueue, pendingBlocks DisjointBlockSet,
 	blockState BlockState, storageState StorageState,
 	transactionState TransactionState, babeVerifier BabeVerifier,
-	finalityGadget FinalityGadget, blockImportHandler BlockImportHandler, telemetry telemetry.Client) *chainProcessor {
+	finalityGadget FinalityGadget, blockImportHandler BlockImportHandler, telemetry telemetry.Client,
+	timestampDrift time.Duration) *chainProcessor {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &chainProcessor{
@@ -60,6 +69,7 @@ func newChainProcessor(readyBlocks *blockQueue, pendingBlocks DisjointBlockSet,
 		finalityGadget:     finalityGadget,
 		blockImportHandler: blockImportHandler,
 		telemetry:          telemetry,
+		timestampDrift:     timestampDrift,
 	}
 }
 
@@ -246,6 +256,11 @@ func (s *chainProcessor) handleBlock(block *types.Block) error {
 
 	rt.SetContextStorage(ts)
 
+	err = s.checkInherents(rt, ts, block)
+	if err != nil {
+		return err
+	}
+
 	_, err = rt.ExecuteBlock(block)
 	if err != nil {
 		return fmt.Errorf("failed to execute block %d: %w", block.Header.Number, err)
@@ -266,6 +281,50 @@ func (s *chainProcessor) handleBlock(block *types.Block) error {
 	return nil
 }
 
+// checkInherents checks the inherents of the block with the runtime, which uses the parent
+// state given as storage. The changes made to the state by the check are discarded.
+func (s *chainProcessor) checkInherents(rt runtime.Instance, ts *rtstorage.TrieState, block *types.Block) error {
+	slot, err := types.GetSlotFromHeader(&block.Header)
+	if err != nil {
+		return fmt.Errorf("cannot get slot from header: %w", err)
+	}
+
+	idata, err := types.NewBlockInherentsData(time.Now().Add(s.timestampDrift), slot)
+	if err != nil {
+		return fmt.Errorf("cannot build inherents data: %w", err)
+	}
+
+	ienc, err := idata.Encode()
+	if err != nil {
+		return fmt.Errorf("cannot encode inherents data: %w", err)
+	}
+
+	ts.BeginStorageTransaction()
+	result, err := rt.CheckInherents(block, ienc)
+	ts.RollbackStorageTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to check inherents of block %d: %w", block.Header.Number, err)
+	}
+
+	if result.Okay {
+		return nil
+	}
+
+	inherentErrors := make([]string, len(result.Errors))
+	for i, inherentError := range result.Errors {
+		inherentErrors[i] = inherentError.String()
+	}
+
+	if result.FatalError {
+		return fmt.Errorf("%w: fatal inherent errors for block %d: %s",
+			ErrInvalidBlock, block.Header.Number, strings.Join(inherentErrors, ", "))
+	}
+
+	logger.Debugf("non fatal inherent errors for block %d: %s",
+		block.Header.Number, strings.Join(inherentErrors, ", "))
+	return nil
+}
+
 func (s *chainProcessor) handleJustification(header *types.Header, justification []byte) {
 	if len(justification) == 0 || header == nil {
 		return
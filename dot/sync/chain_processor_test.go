@@ -200,6 +200,35 @@ func TestChainProcessor_ExecuteBlock(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestChainProcessor_checkInherents(t *testing.T) {
+	syncer := newTestSyncer(t)
+	processor := syncer.chainProcessor.(*chainProcessor)
+
+	parent, err := syncer.blockState.(*state.BlockState).BestBlockHeader()
+	require.NoError(t, err)
+
+	rt, err := syncer.blockState.GetRuntime(nil)
+	require.NoError(t, err)
+
+	block := BuildBlock(t, rt, parent, nil)
+
+	parentState, err := processor.storageState.TrieState(&parent.StateRoot)
+	require.NoError(t, err)
+	rt.SetContextStorage(parentState)
+
+	err = processor.checkInherents(rt, parentState, block)
+	require.NoError(t, err)
+
+	root, err := parentState.Root()
+	require.NoError(t, err)
+	require.Equal(t, parent.StateRoot, root)
+
+	// check the block timestamp against the unix epoch, so it is too far in the future.
+	processor.timestampDrift = -time.Duration(time.Now().UnixNano())
+	err = processor.checkInherents(rt, parentState, block)
+	require.ErrorIs(t, err, ErrInvalidBlock)
+}
+
 func TestChainProcessor_HandleJustification(t *testing.T) {
 	syncer := newTestSyncer(t)
 
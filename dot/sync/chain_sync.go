@@ -28,6 +28,13 @@
 const (
 	// maxWorkers is the maximum number of parallel sync workers
 	maxWorkers = 12
+
+	// implausibleHeadDistance is the distance above the sync target from which
+	// a best block reported by a peer is considered implausible
+	implausibleHeadDistance = maxResponseSize * 8
+	// minPeersForPlausibility is the minimum number of peer states needed
+	// to check the plausibility of a best block reported by a peer
+	minPeersForPlausibility = 3
 )
 
 var _ ChainSync = &chainSync{}
@@ -106,6 +113,9 @@ type ChainSync interface {
 
 	// getHighestBlock returns the highest block or an error
 	getHighestBlock() (highestBlock uint, err error)
+
+	// called when a peer disconnects
+	removePeer(p peer.ID)
 }
 
 type chainSync struct {
@@ -127,6 +137,10 @@ type chainSync struct {
 	peerState   map[peer.ID]*peerState
 	ignorePeers map[peer.ID]struct{}
 
+	// tracks the quality of the block responses of our peers,
+	// used to select the peers to sync from
+	peerScores *peerScores
+
 	// current workers that are attempting to obtain blocks
 	workerState *workerState
 
@@ -184,6 +198,7 @@ func newChainSync(cfg *chainSyncConfig) *chainSync {
 		resultQueue:      make(chan *worker, 1024),
 		peerState:        make(map[peer.ID]*peerState),
 		ignorePeers:      make(map[peer.ID]struct{}),
+		peerScores:       newPeerScores(),
 		workerState:      newWorkerState(),
 		readyBlocks:      cfg.readyBlocks,
 		pendingBlocks:    cfg.pendingBlocks,
@@ -294,8 +309,7 @@ func (cs *chainSync) setPeerHead(p peer.ID, hash common.Hash, number uint) error
 		// chain), and also the highest finalised block is higher than that number.
 		// thus the peer is on an invalid chain
 		if fin.Number >= ps.number {
-			// TODO: downscore this peer, or temporarily don't sync from them? (#1399)
-			// perhaps we need another field in `peerState` to mark whether the state is valid or not
+			cs.peerScores.recordImplausibleHead(p)
 			cs.network.ReportPeer(peerset.ReputationChange{
 				Value:  peerset.BadBlockAnnouncementValue,
 				Reason: peerset.BadBlockAnnouncementReason,
@@ -316,6 +330,13 @@ func (cs *chainSync) setPeerHead(p peer.ID, hash common.Hash, number uint) error
 		}
 	}
 
+	if cs.isImplausibleHead(ps.number) {
+		// we still sync from the peer, since removeOutliers prevents it
+		// from moving our sync target, but we prefer other peers.
+		logger.Debugf("peer %s reported implausible best block number %d", p, ps.number)
+		cs.peerScores.recordImplausibleHead(p)
+	}
+
 	// the peer has a higher best block than us, or they are on some fork we are not aware of
 	// add it to the disjoint block set
 	if err = cs.pendingBlocks.addHashAndNumber(ps.hash, ps.number); err != nil {
@@ -327,6 +348,19 @@ func (cs *chainSync) setPeerHead(p peer.ID, hash common.Hash, number uint) error
 	return nil
 }
 
+// isImplausibleHead returns true if the given best block number reported by a peer
+// is too far above the sync target computed from the best blocks of all our peers.
+func (cs *chainSync) isImplausibleHead(number uint) bool {
+	cs.RLock()
+	n := len(cs.peerState)
+	cs.RUnlock()
+	if n < minPeersForPlausibility {
+		return false
+	}
+
+	return number > cs.getTarget()+implausibleHeadDistance
+}
+
 func (cs *chainSync) logSyncSpeed() {
 	t := time.NewTicker(time.Second * 5)
 	defer t.Stop()
@@ -647,9 +681,12 @@ func (cs *chainSync) dispatchWorker(w *worker) {
 		return
 	}
 
+	// keep syncing from the same peer as long as it successfully responds
+	var who peer.ID
 	for _, req := range reqs {
-		// TODO: if we find a good peer, do sync with them, right now it re-selects a peer each time (#1399)
-		if err := cs.doSync(req, w.peersTried); err != nil {
+		var err *workerError
+		who, err = cs.doSync(req, w.peersTried, who)
+		if err != nil {
 			// failed to sync, set worker error and put into result queue
 			w.err = err
 			return
@@ -657,12 +694,16 @@ func (cs *chainSync) dispatchWorker(w *worker) {
 	}
 }
 
-func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[peer.ID]struct{}) *workerError {
+// doSync sends the block request to the given preferred peer if it is still a
+// suitable sync peer, or otherwise to a peer selected using the peer scores.
+// It returns the peer which successfully responded.
+func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[peer.ID]struct{},
+	preferred peer.ID) (who peer.ID, workerErr *workerError) {
 	// determine which peers have the blocks we want to request
 	peers := cs.determineSyncPeers(req, peersTried)
 
 	if len(peers) == 0 {
-		return &workerError{
+		return "", &workerError{
 			err: errNoPeers,
 		}
 	}
@@ -670,19 +711,21 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 	// send out request and potentially receive response, error if timeout
 	logger.Tracef("sending out block request: %s", req)
 
-	// TODO: use scoring to determine what peer to try to sync from first (#1399)
-	idx, _ := rand.Int(rand.Reader, big.NewInt(int64(len(peers))))
-	who := peers[idx.Int64()]
-	resp, err := cs.network.DoBlockRequest(who, req)
+	who = cs.selectSyncPeer(peers, preferred)
+	start := time.Now()
+	resp, size, err := cs.network.DoBlockRequest(who, req)
 	if err != nil {
-		return &workerError{
+		cs.recordPeerFailure(who, false)
+		return "", &workerError{
 			err: err,
 			who: who,
 		}
 	}
+	latency := time.Since(start)
 
 	if resp == nil {
-		return &workerError{
+		cs.recordPeerFailure(who, false)
+		return "", &workerError{
 			err: errNilResponse,
 			who: who,
 		}
@@ -695,12 +738,16 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 
 	// perform some pre-validation of response, error if failure
 	if err := cs.validateResponse(req, resp, who); err != nil {
-		return &workerError{
+		if isInvalidResponseError(err) {
+			cs.recordPeerFailure(who, true)
+		}
+		return "", &workerError{
 			err: err,
 			who: who,
 		}
 	}
 
+	cs.peerScores.recordSuccess(who, latency, size)
 	logger.Trace("success! placing block response data in ready queue")
 
 	// response was validated! place into ready block queue
@@ -709,7 +756,67 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 		cs.handleReadyBlock(bd)
 	}
 
-	return nil
+	return who, nil
+}
+
+// selectSyncPeer returns the preferred peer if it is in the given peers,
+// or otherwise a random peer where peers with a higher score are more likely to be picked.
+func (cs *chainSync) selectSyncPeer(peers []peer.ID, preferred peer.ID) peer.ID {
+	weights := make([]int64, len(peers))
+	var total int64
+	for i, p := range peers {
+		if p == preferred {
+			return p
+		}
+
+		// square the score so the best peers are clearly favoured,
+		// and add one so a peer with a zero score can still be picked.
+		score := cs.peerScores.value(p)
+		weights[i] = int64(score*score) + 1
+		total += weights[i]
+	}
+
+	n, _ := rand.Int(rand.Reader, big.NewInt(total))
+	pick := n.Int64()
+	for i, weight := range weights {
+		if pick < weight {
+			return peers[i]
+		}
+		pick -= weight
+	}
+	return peers[len(peers)-1]
+}
+
+// recordPeerFailure records a failed block request to the peer, and reports the peer
+// if it sent an invalid response or if it got excluded from syncing.
+func (cs *chainSync) recordPeerFailure(who peer.ID, invalidResponse bool) {
+	excluded := cs.peerScores.recordFailure(who, invalidResponse)
+
+	if invalidResponse {
+		cs.network.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadBlockResponseValue,
+			Reason: peerset.BadBlockResponseReason,
+		}, who)
+	}
+
+	if excluded {
+		logger.Debugf("temporarily excluding peer %s from syncing after repeated failures", who)
+		cs.network.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.RepeatedSyncFailuresValue,
+			Reason: peerset.RepeatedSyncFailuresReason,
+		}, who)
+	}
+}
+
+// isInvalidResponseError returns true if the block response validation
+// error is due to the peer sending an invalid response.
+func isInvalidResponseError(err error) bool {
+	return errors.Is(err, errEmptyBlockData) ||
+		errors.Is(err, errNilBlockData) ||
+		errors.Is(err, errNilHeaderInResponse) ||
+		errors.Is(err, errNilBodyInResponse) ||
+		errors.Is(err, errResponseIsNotChain) ||
+		errors.Is(err, errUnknownBlockForJustification)
 }
 
 func (cs *chainSync) handleReadyBlock(bd *types.BlockData) {
@@ -762,7 +869,8 @@ func (cs *chainSync) handleReadyBlock(bd *types.BlockData) {
 	}
 }
 
-// determineSyncPeers returns a list of peers that likely have the blocks in the given block request.
+// determineSyncPeers returns a list of peers that likely have the blocks in the given block request,
+// sorted by decreasing score. Peers temporarily excluded because of repeated failures are left out.
 func (cs *chainSync) determineSyncPeers(req *network.BlockRequestMessage, peersTried map[peer.ID]struct{}) []peer.ID {
 	var start uint32
 	if req.StartingBlock.IsUint32() {
@@ -794,6 +902,10 @@ func (cs *chainSync) determineSyncPeers(req *network.BlockRequestMessage, peersT
 			continue
 		}
 
+		if cs.peerScores.isExcluded(p) {
+			continue
+		}
+
 		// if peer definitely doesn't have any blocks we want in the request,
 		// don't request from them
 		if start > 0 && uint32(state.number) < start {
@@ -803,6 +915,7 @@ func (cs *chainSync) determineSyncPeers(req *network.BlockRequestMessage, peersT
 		peers = append(peers, p)
 	}
 
+	cs.peerScores.sortByScore(peers)
 	return peers
 }
 
@@ -963,6 +1076,12 @@ func (cs *chainSync) getHighestBlock() (highestBlock uint, err error) {
 	return highestBlock, nil
 }
 
+// removePeer forgets the sync score of the given disconnected peer,
+// except for its exclusion state.
+func (cs *chainSync) removePeer(p peer.ID) {
+	cs.peerScores.remove(p)
+}
+
 func workerToRequests(w *worker) ([]*network.BlockRequestMessage, error) {
 	diff := int(*w.targetNumber) - int(*w.startNumber)
 	if diff < 0 && w.direction != network.Descending {
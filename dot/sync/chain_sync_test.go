@@ -10,6 +10,7 @@
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	syncmocks "github.com/ChainSafe/gossamer/dot/sync/mocks"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
@@ -41,7 +42,7 @@ func newTestChainSync(t *testing.T) (*chainSync, *blockQueue) {
 
 	net := new(syncmocks.Network)
 	net.On("DoBlockRequest", mock.AnythingOfType("peer.ID"),
-		mock.AnythingOfType("*network.BlockRequestMessage")).Return(nil, nil)
+		mock.AnythingOfType("*network.BlockRequestMessage")).Return(nil, 0, nil)
 	net.On("ReportPeer", mock.AnythingOfType("peerset.ReputationChange"), mock.AnythingOfType("peer.ID"))
 
 	readyBlocks := newBlockQueue(maxResponseSize)
@@ -632,7 +633,7 @@ func TestChainSync_doSync(t *testing.T) {
 		Max:           &max,
 	}
 
-	workerErr := cs.doSync(req, make(map[peer.ID]struct{}))
+	_, workerErr := cs.doSync(req, make(map[peer.ID]struct{}), "")
 	require.NotNil(t, workerErr)
 	require.Equal(t, errNoPeers, workerErr.err)
 
@@ -640,7 +641,7 @@ func TestChainSync_doSync(t *testing.T) {
 		number: 100,
 	}
 
-	workerErr = cs.doSync(req, make(map[peer.ID]struct{}))
+	_, workerErr = cs.doSync(req, make(map[peer.ID]struct{}), "")
 	require.NotNil(t, workerErr)
 	require.Equal(t, errNilResponse, workerErr.err)
 
@@ -659,9 +660,9 @@ func TestChainSync_doSync(t *testing.T) {
 	cs.network = new(syncmocks.Network)
 	cs.network.(*syncmocks.Network).On("DoBlockRequest",
 		mock.AnythingOfType("peer.ID"),
-		mock.AnythingOfType("*network.BlockRequestMessage")).Return(resp, nil)
+		mock.AnythingOfType("*network.BlockRequestMessage")).Return(resp, 0, nil)
 
-	workerErr = cs.doSync(req, make(map[peer.ID]struct{}))
+	_, workerErr = cs.doSync(req, make(map[peer.ID]struct{}), "")
 	require.Nil(t, workerErr)
 	bd := readyBlocks.pop()
 	require.NotNil(t, bd)
@@ -695,8 +696,8 @@ func TestChainSync_doSync(t *testing.T) {
 	cs.network = new(syncmocks.Network)
 	cs.network.(*syncmocks.Network).On("DoBlockRequest",
 		mock.AnythingOfType("peer.ID"),
-		mock.AnythingOfType("*network.BlockRequestMessage")).Return(resp, nil)
-	workerErr = cs.doSync(req, make(map[peer.ID]struct{}))
+		mock.AnythingOfType("*network.BlockRequestMessage")).Return(resp, 0, nil)
+	_, workerErr = cs.doSync(req, make(map[peer.ID]struct{}), "")
 	require.Nil(t, workerErr)
 
 	bd = readyBlocks.pop()
@@ -813,6 +814,90 @@ func TestChainSync_determineSyncPeers(t *testing.T) {
 	require.Equal(t, []peer.ID{testPeerB}, peers)
 }
 
+func TestChainSync_determineSyncPeers_scores(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	req := &network.BlockRequestMessage{}
+	testPeerA := peer.ID("a")
+	testPeerB := peer.ID("b")
+	testPeerC := peer.ID("c")
+	cs.peerState[testPeerA] = &peerState{number: 129}
+	cs.peerState[testPeerB] = &peerState{number: 129}
+	cs.peerState[testPeerC] = &peerState{number: 129}
+
+	// peers are sorted by decreasing score
+	cs.peerScores.recordSuccess(testPeerB, time.Millisecond, bestThroughput)
+	cs.peerScores.recordFailure(testPeerA, true)
+	peers := cs.determineSyncPeers(req, map[peer.ID]struct{}{})
+	require.Equal(t, []peer.ID{testPeerB, testPeerC, testPeerA}, peers)
+
+	// excluded peers are left out
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		cs.peerScores.recordFailure(testPeerC, false)
+	}
+	peers = cs.determineSyncPeers(req, map[peer.ID]struct{}{})
+	require.Equal(t, []peer.ID{testPeerB, testPeerA}, peers)
+}
+
+func TestChainSync_selectSyncPeer(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	testPeerA := peer.ID("a")
+	testPeerB := peer.ID("b")
+	peers := []peer.ID{testPeerA, testPeerB}
+
+	who := cs.selectSyncPeer(peers, testPeerB)
+	require.Equal(t, testPeerB, who)
+
+	who = cs.selectSyncPeer(peers, peer.ID("c"))
+	require.Contains(t, peers, who)
+
+	who = cs.selectSyncPeer([]peer.ID{testPeerA}, "")
+	require.Equal(t, testPeerA, who)
+}
+
+func TestChainSync_doSync_scores(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	max := uint32(1)
+	req := &network.BlockRequestMessage{
+		RequestedData: bootstrapRequestData,
+		StartingBlock: *variadic.MustNewUint32OrHash(1),
+		Direction:     network.Ascending,
+		Max:           &max,
+	}
+	testPeer := peer.ID("noot")
+	cs.peerState[testPeer] = &peerState{
+		number: 100,
+	}
+
+	// empty responses are invalid and get the peer reported
+	cs.network = new(syncmocks.Network)
+	cs.network.(*syncmocks.Network).On("DoBlockRequest", testPeer, req).
+		Return(&network.BlockResponseMessage{}, 0, nil)
+	cs.network.(*syncmocks.Network).On("ReportPeer", peerset.ReputationChange{
+		Value:  peerset.BadBlockResponseValue,
+		Reason: peerset.BadBlockResponseReason,
+	}, testPeer)
+	cs.network.(*syncmocks.Network).On("ReportPeer", peerset.ReputationChange{
+		Value:  peerset.RepeatedSyncFailuresValue,
+		Reason: peerset.RepeatedSyncFailuresReason,
+	}, testPeer)
+
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		_, workerErr := cs.doSync(req, map[peer.ID]struct{}{}, "")
+		require.NotNil(t, workerErr)
+		require.Equal(t, errEmptyBlockData, workerErr.err)
+	}
+
+	require.True(t, cs.peerScores.isExcluded(testPeer))
+	cs.network.(*syncmocks.Network).AssertNumberOfCalls(t, "ReportPeer", maxConsecutiveFailures+1)
+
+	_, workerErr := cs.doSync(req, map[peer.ID]struct{}{}, "")
+	require.NotNil(t, workerErr)
+	require.Equal(t, errNoPeers, workerErr.err)
+}
+
 func TestChainSync_highestBlock(t *testing.T) {
 	type input struct {
 		peerState map[peer.ID]*peerState
@@ -34,6 +34,7 @@ type BlockState interface {
 	GetReceipt(common.Hash) ([]byte, error)
 	GetMessageQueue(common.Hash) ([]byte, error)
 	GetJustification(common.Hash) ([]byte, error)
+	GetBlockIndexedBody(common.Hash) ([][]byte, error)
 	SetJustification(hash common.Hash, data []byte) error
 	SetFinalisedHash(hash common.Hash, round, setID uint64) error
 	AddBlockToBlockTree(block *types.Block) error
@@ -92,9 +93,10 @@ type BlockImportHandler interface {
 // Network is the interface for the network
 type Network interface {
 	// DoBlockRequest sends a request to the given peer.
-	// If a response is received within a certain time period,
-	// it is returned, otherwise an error is returned.
-	DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (*network.BlockResponseMessage, error)
+	// If a response is received within a certain time period, it is returned
+	// with the size in bytes of the encoded response, otherwise an error is returned.
+	DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (
+		resp *network.BlockResponseMessage, size int, err error)
 
 	// Peers returns a list of currently connected peers
 	Peers() []common.PeerInfo
@@ -415,5 +415,12 @@ func (s *Service) getBlockData(hash common.Hash, requestedData byte) (*types.Blo
 		}
 	}
 
+	if (requestedData&network.RequestedDataIndexedBody)>>5 == 1 {
+		retData, err := s.blockState.GetBlockIndexedBody(hash)
+		if err == nil && retData != nil {
+			blockData.IndexedBody = &retData
+		}
+	}
+
 	return blockData, nil
 }
@@ -8,7 +8,9 @@
 
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/state"
+	syncmocks "github.com/ChainSafe/gossamer/dot/sync/mocks"
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/common/variadic"
 	"github.com/ChainSafe/gossamer/lib/trie"
 
@@ -590,3 +592,23 @@ func TestService_CreateBlockResponse_Fields(t *testing.T) {
 		})
 	}
 }
+
+func TestService_getBlockData_IndexedBody(t *testing.T) {
+	t.Parallel()
+
+	hash := common.Hash{1}
+	indexedBody := [][]byte{{1, 2}, {3}}
+	blockState := new(syncmocks.BlockState)
+	blockState.On("GetBlockIndexedBody", hash).Return(indexedBody, nil)
+
+	s := &Service{blockState: blockState}
+	blockData, err := s.getBlockData(hash, network.RequestedDataIndexedBody)
+	require.NoError(t, err)
+
+	expected := &types.BlockData{
+		Hash:        hash,
+		IndexedBody: &indexedBody,
+	}
+	require.Equal(t, expected, blockData)
+	blockState.AssertExpectations(t)
+}
@@ -51,6 +51,18 @@ func (mr *MockChainSyncMockRecorder) getHighestBlock() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getHighestBlock", reflect.TypeOf((*MockChainSync)(nil).getHighestBlock))
 }
 
+// removePeer mocks base method.
+func (m *MockChainSync) removePeer(arg0 peer.ID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "removePeer", arg0)
+}
+
+// removePeer indicates an expected call of removePeer.
+func (mr *MockChainSyncMockRecorder) removePeer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "removePeer", reflect.TypeOf((*MockChainSync)(nil).removePeer), arg0)
+}
+
 // setBlockAnnounce mocks base method.
 func (m *MockChainSync) setBlockAnnounce(arg0 peer.ID, arg1 *types.Header) error {
 	m.ctrl.T.Helper()
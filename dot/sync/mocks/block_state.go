@@ -210,6 +210,29 @@ func (_m *BlockState) GetBlockByNumber(blockNumber uint) (*types.Block, error) {
 	return r0, r1
 }
 
+// GetBlockIndexedBody provides a mock function with given fields: _a0
+func (_m *BlockState) GetBlockIndexedBody(_a0 common.Hash) ([][]byte, error) {
+	ret := _m.Called(_a0)
+
+	var r0 [][]byte
+	if rf, ok := ret.Get(0).(func(common.Hash) [][]byte); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(common.Hash) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFinalisedNotifierChannel provides a mock function with given fields:
 func (_m *BlockState) GetFinalisedNotifierChannel() chan *types.FinalisationInfo {
 	ret := _m.Called()
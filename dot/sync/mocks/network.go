@@ -19,7 +19,7 @@ type Network struct {
 }
 
 // DoBlockRequest provides a mock function with given fields: to, req
-func (_m *Network) DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (*network.BlockResponseMessage, error) {
+func (_m *Network) DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (*network.BlockResponseMessage, int, error) {
 	ret := _m.Called(to, req)
 
 	var r0 *network.BlockResponseMessage
@@ -31,14 +31,21 @@ func (_m *Network) DoBlockRequest(to peer.ID, req *network.BlockRequestMessage)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(peer.ID, *network.BlockRequestMessage) error); ok {
+	var r1 int
+	if rf, ok := ret.Get(1).(func(peer.ID, *network.BlockRequestMessage) int); ok {
 		r1 = rf(to, req)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(int)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(peer.ID, *network.BlockRequestMessage) error); ok {
+		r2 = rf(to, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // Peers provides a mock function with given fields:
@@ -0,0 +1,285 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// weights of each component of a peer score, adding up to maxPeerScore
+	validityScoreWeight   = 50
+	latencyScoreWeight    = 20
+	throughputScoreWeight = 20
+	headScoreWeight       = 10
+	maxPeerScore          = validityScoreWeight + latencyScoreWeight + throughputScoreWeight + headScoreWeight
+
+	// latencies at or above worstLatency get no latency score
+	worstLatency = 10 * time.Second
+	// throughputs at or above bestThroughput get the full throughput score, in bytes/s
+	bestThroughput = 1 << 20
+
+	// movingAverageWeight is the weight of a new sample in the latency and throughput moving averages
+	movingAverageWeight = 0.2
+
+	// maxConsecutiveFailures is the number of consecutive failed requests
+	// after which a peer is excluded from syncing
+	maxConsecutiveFailures = 3
+	// the exclusion duration doubles each time a peer is excluded again, up to maxExclusionDuration
+	minExclusionDuration = 30 * time.Second
+	maxExclusionDuration = 10 * time.Minute
+	// exclusionStateTTL is the duration the exclusion state of a disconnected
+	// peer is kept for, so a peer cannot reset it by reconnecting
+	exclusionStateTTL = time.Hour
+)
+
+var (
+	peerScoreGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "peer_score",
+		Help:      "sync score of the peer, between 0 and 100",
+	}, []string{"peer"})
+	peerLatencyGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "peer_latency_seconds",
+		Help:      "moving average of the block response latency of the peer",
+	}, []string{"peer"})
+	peerThroughputGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "peer_throughput_bytes_per_second",
+		Help:      "moving average of the block response throughput of the peer",
+	}, []string{"peer"})
+	peerExclusionsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "peer_exclusions_total",
+		Help:      "number of times the peer was temporarily excluded from syncing",
+	}, []string{"peer"})
+)
+
+// peerScore tracks the quality of the block responses of a peer
+type peerScore struct {
+	// latency and throughput are moving averages, they are zero
+	// until a first block response is received
+	latency    time.Duration
+	throughput float64 // bytes per second
+
+	validResponses      uint
+	invalidResponses    uint
+	consecutiveFailures uint
+	implausibleHeads    uint
+
+	exclusions    uint
+	excludedUntil time.Time
+
+	// disconnectedAt is the time the peer disconnected at,
+	// it is zero while the peer is connected
+	disconnectedAt time.Time
+}
+
+// value returns the score of the peer between 0 and maxPeerScore, higher is better.
+// A peer without any latency or throughput sample gets half of their weights,
+// so new peers get a chance to be selected.
+func (s *peerScore) value() float64 {
+	total := float64(s.validResponses + s.invalidResponses + s.consecutiveFailures)
+	score := validityScoreWeight * float64(s.validResponses+1) / (total + 1)
+
+	if s.latency == 0 {
+		score += latencyScoreWeight / 2
+	} else if s.latency < worstLatency {
+		score += latencyScoreWeight * (1 - float64(s.latency)/float64(worstLatency))
+	}
+
+	if s.throughput == 0 {
+		score += throughputScoreWeight / 2
+	} else if s.throughput < bestThroughput {
+		score += throughputScoreWeight * s.throughput / bestThroughput
+	} else {
+		score += throughputScoreWeight
+	}
+
+	score += headScoreWeight / float64(s.implausibleHeads+1)
+	return score
+}
+
+// peerScores keeps the sync scores of our peers
+type peerScores struct {
+	sync.Mutex
+	scores map[peer.ID]*peerScore
+	now    func() time.Time
+}
+
+func newPeerScores() *peerScores {
+	return &peerScores{
+		scores: make(map[peer.ID]*peerScore),
+		now:    time.Now,
+	}
+}
+
+// get returns the score of the given peer, creating it if needed.
+// It must be called with the mutex locked.
+func (ps *peerScores) get(who peer.ID) *peerScore {
+	score, has := ps.scores[who]
+	if !has {
+		score = &peerScore{}
+		ps.scores[who] = score
+	}
+	score.disconnectedAt = time.Time{}
+	return score
+}
+
+// recordSuccess records a valid block response of the given size
+// received from the peer after the given latency.
+func (ps *peerScores) recordSuccess(who peer.ID, latency time.Duration, size int) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	score := ps.get(who)
+	score.validResponses++
+	score.consecutiveFailures = 0
+
+	score.latency = time.Duration(movingAverage(float64(score.latency), float64(latency)))
+
+	if latency > 0 {
+		throughput := float64(size) / latency.Seconds()
+		score.throughput = movingAverage(score.throughput, throughput)
+	}
+
+	ps.updateMetrics(who, score)
+}
+
+// recordFailure records a failed block request to the peer, or an invalid
+// block response received from the peer if invalidResponse is true.
+// The peer is temporarily excluded after maxConsecutiveFailures failures.
+// It returns true if the peer got excluded.
+func (ps *peerScores) recordFailure(who peer.ID, invalidResponse bool) (excluded bool) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	score := ps.get(who)
+	if invalidResponse {
+		score.invalidResponses++
+	}
+	score.consecutiveFailures++
+
+	if score.consecutiveFailures >= maxConsecutiveFailures {
+		exclusionDuration := maxExclusionDuration
+		if score.exclusions < 16 && minExclusionDuration<<score.exclusions < maxExclusionDuration {
+			exclusionDuration = minExclusionDuration << score.exclusions
+		}
+		score.excludedUntil = ps.now().Add(exclusionDuration)
+		score.exclusions++
+		// the failures are kept in the validity score
+		score.invalidResponses += score.consecutiveFailures
+		score.consecutiveFailures = 0
+		peerExclusionsCounter.WithLabelValues(who.String()).Inc()
+		excluded = true
+	}
+
+	ps.updateMetrics(who, score)
+	return excluded
+}
+
+// recordImplausibleHead records a best block reported by
+// the peer which is on an invalid chain or too far ahead.
+func (ps *peerScores) recordImplausibleHead(who peer.ID) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	score := ps.get(who)
+	score.implausibleHeads++
+	ps.updateMetrics(who, score)
+}
+
+// isExcluded returns true if the peer is temporarily excluded from syncing.
+func (ps *peerScores) isExcluded(who peer.ID) bool {
+	ps.Lock()
+	defer ps.Unlock()
+
+	score, has := ps.scores[who]
+	return has && ps.now().Before(score.excludedUntil)
+}
+
+// value returns the score of the given peer.
+func (ps *peerScores) value(who peer.ID) float64 {
+	ps.Lock()
+	defer ps.Unlock()
+
+	score, has := ps.scores[who]
+	if !has {
+		return (&peerScore{}).value()
+	}
+	return score.value()
+}
+
+// sortByScore sorts the given peers by decreasing score.
+func (ps *peerScores) sortByScore(peers []peer.ID) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	values := make(map[peer.ID]float64, len(peers))
+	for _, who := range peers {
+		score, has := ps.scores[who]
+		if !has {
+			score = &peerScore{}
+		}
+		values[who] = score.value()
+	}
+
+	sort.SliceStable(peers, func(i, j int) bool {
+		return values[peers[i]] > values[peers[j]]
+	})
+}
+
+// remove removes the score and the metrics of the given disconnected peer.
+// The exclusion state of the peer is kept for exclusionStateTTL,
+// after which it is removed by a later call to remove.
+func (ps *peerScores) remove(who peer.ID) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	now := ps.now()
+	for id, score := range ps.scores {
+		if !score.disconnectedAt.IsZero() && now.Sub(score.disconnectedAt) >= exclusionStateTTL {
+			delete(ps.scores, id)
+		}
+	}
+
+	score, has := ps.scores[who]
+	if has && score.exclusions > 0 {
+		ps.scores[who] = &peerScore{
+			exclusions:     score.exclusions,
+			excludedUntil:  score.excludedUntil,
+			disconnectedAt: now,
+		}
+	} else {
+		delete(ps.scores, who)
+	}
+
+	label := who.String()
+	peerScoreGauge.DeleteLabelValues(label)
+	peerLatencyGauge.DeleteLabelValues(label)
+	peerThroughputGauge.DeleteLabelValues(label)
+	peerExclusionsCounter.DeleteLabelValues(label)
+}
+
+// updateMetrics must be called with the mutex locked.
+func (ps *peerScores) updateMetrics(who peer.ID, score *peerScore) {
+	label := who.String()
+	peerScoreGauge.WithLabelValues(label).Set(score.value())
+	peerLatencyGauge.WithLabelValues(label).Set(score.latency.Seconds())
+	peerThroughputGauge.WithLabelValues(label).Set(score.throughput)
+}
+
+func movingAverage(average, sample float64) float64 {
+	if average == 0 {
+		return sample
+	}
+	return (1-movingAverageWeight)*average + movingAverageWeight*sample
+}
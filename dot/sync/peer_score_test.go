@@ -0,0 +1,216 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_peerScore_value(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		score peerScore
+		value float64
+	}{
+		"new peer": {
+			value: 80,
+		},
+		"perfect peer": {
+			score: peerScore{
+				latency:        time.Nanosecond,
+				throughput:     2 * bestThroughput,
+				validResponses: 9,
+			},
+			value: 99.999999998,
+		},
+		"slow peer": {
+			score: peerScore{
+				latency:        worstLatency / 2,
+				throughput:     bestThroughput / 4,
+				validResponses: 1,
+			},
+			value: 75,
+		},
+		"failing peer": {
+			score: peerScore{
+				invalidResponses:    2,
+				consecutiveFailures: 1,
+			},
+			value: 42.5,
+		},
+		"implausible heads": {
+			score: peerScore{
+				implausibleHeads: 4,
+			},
+			value: 72,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value := testCase.score.value()
+
+			assert.InDelta(t, testCase.value, value, 1e-6)
+		})
+	}
+}
+
+func Test_peerScores_recordSuccess(t *testing.T) {
+	t.Parallel()
+
+	scores := newPeerScores()
+	who := peer.ID("a")
+
+	scores.recordSuccess(who, time.Second, 1000)
+	score := scores.scores[who]
+	assert.Equal(t, time.Second, score.latency)
+	assert.Equal(t, float64(1000), score.throughput)
+	assert.Equal(t, uint(1), score.validResponses)
+
+	scores.recordSuccess(who, 2*time.Second, 1000)
+	assert.Equal(t, 1200*time.Millisecond, score.latency)
+	assert.InDelta(t, 900, score.throughput, 1e-9)
+	assert.Equal(t, uint(2), score.validResponses)
+}
+
+func Test_peerScores_recordFailure(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	scores := newPeerScores()
+	scores.now = func() time.Time { return now }
+	who := peer.ID("a")
+
+	for i := 0; i < maxConsecutiveFailures-1; i++ {
+		excluded := scores.recordFailure(who, false)
+		assert.False(t, excluded)
+	}
+
+	// a success resets the consecutive failures
+	scores.recordSuccess(who, time.Second, 1000)
+	for i := 0; i < maxConsecutiveFailures-1; i++ {
+		excluded := scores.recordFailure(who, i == 0)
+		assert.False(t, excluded)
+	}
+	assert.False(t, scores.isExcluded(who))
+
+	excluded := scores.recordFailure(who, false)
+	assert.True(t, excluded)
+	assert.True(t, scores.isExcluded(who))
+	score := scores.scores[who]
+	assert.Equal(t, uint(0), score.consecutiveFailures)
+	assert.Equal(t, uint(1+maxConsecutiveFailures), score.invalidResponses)
+
+	now = now.Add(minExclusionDuration)
+	assert.False(t, scores.isExcluded(who))
+
+	// the exclusion duration doubles each time
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		scores.recordFailure(who, false)
+	}
+	now = now.Add(minExclusionDuration)
+	assert.True(t, scores.isExcluded(who))
+	now = now.Add(minExclusionDuration)
+	assert.False(t, scores.isExcluded(who))
+
+	// the exclusion duration is capped
+	score.exclusions = 30
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		scores.recordFailure(who, false)
+	}
+	assert.Equal(t, now.Add(maxExclusionDuration), score.excludedUntil)
+}
+
+func Test_peerScores_sortByScore(t *testing.T) {
+	t.Parallel()
+
+	scores := newPeerScores()
+	good, unknown, bad := peer.ID("good"), peer.ID("unknown"), peer.ID("bad")
+
+	scores.recordSuccess(good, time.Millisecond, bestThroughput)
+	scores.recordFailure(bad, true)
+	scores.recordImplausibleHead(bad)
+
+	peers := []peer.ID{bad, unknown, good}
+	scores.sortByScore(peers)
+	require.Equal(t, []peer.ID{good, unknown, bad}, peers)
+}
+
+func Test_peerScores_remove(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	scores := newPeerScores()
+	scores.now = func() time.Time { return now }
+	excluded, other := peer.ID("excluded"), peer.ID("other")
+
+	scores.recordSuccess(excluded, time.Second, 1000)
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		scores.recordFailure(excluded, false)
+	}
+	scores.recordSuccess(other, time.Second, 1000)
+
+	scores.remove(other)
+	assert.NotContains(t, scores.scores, other)
+
+	// the exclusion state is kept beyond the disconnection
+	scores.remove(excluded)
+	expected := &peerScore{
+		exclusions:     1,
+		excludedUntil:  now.Add(minExclusionDuration),
+		disconnectedAt: now,
+	}
+	assert.Equal(t, expected, scores.scores[excluded])
+	assert.True(t, scores.isExcluded(excluded))
+
+	// the metrics of the peers are already deleted
+	for _, who := range []peer.ID{excluded, other} {
+		label := who.String()
+		assert.False(t, peerScoreGauge.DeleteLabelValues(label))
+		assert.False(t, peerLatencyGauge.DeleteLabelValues(label))
+		assert.False(t, peerThroughputGauge.DeleteLabelValues(label))
+		assert.False(t, peerExclusionsCounter.DeleteLabelValues(label))
+	}
+
+	// the exclusion state expires after exclusionStateTTL
+	now = now.Add(exclusionStateTTL)
+	scores.remove(other)
+	assert.NotContains(t, scores.scores, excluded)
+}
+
+func Test_peerScores_remove_reconnect(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	scores := newPeerScores()
+	scores.now = func() time.Time { return now }
+	who := peer.ID("reconnected")
+
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		scores.recordFailure(who, false)
+	}
+	scores.remove(who)
+
+	// the exclusion state does not expire while the peer is connected
+	scores.recordImplausibleHead(who)
+	assert.True(t, scores.scores[who].disconnectedAt.IsZero())
+	now = now.Add(exclusionStateTTL)
+	scores.remove(peer.ID("other"))
+	require.Contains(t, scores.scores, who)
+
+	// the exclusion duration keeps doubling
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		scores.recordFailure(who, false)
+	}
+	assert.Equal(t, now.Add(2*minExclusionDuration), scores.scores[who].excludedUntil)
+}
@@ -37,6 +37,9 @@ type Config struct {
 	MinPeers, MaxPeers int
 	SlotDuration       time.Duration
 	Telemetry          telemetry.Client
+	// TimestampDrift is added to the current time the timestamps of the imported
+	// blocks are checked against, on top of the drift tolerated by the runtime.
+	TimestampDrift time.Duration
 }
 
 // NewService returns a new *sync.Service
@@ -87,7 +90,7 @@ func NewService(cfg *Config) (*Service, error) {
 	chainSync := newChainSync(csCfg)
 	chainProcessor := newChainProcessor(readyBlocks, pendingBlocks,
 		cfg.BlockState, cfg.StorageState, cfg.TransactionState,
-		cfg.BabeVerifier, cfg.FinalityGadget, cfg.BlockImportHandler, cfg.Telemetry)
+		cfg.BabeVerifier, cfg.FinalityGadget, cfg.BlockImportHandler, cfg.Telemetry, cfg.TimestampDrift)
 
 	return &Service{
 		blockState:     cfg.BlockState,
@@ -130,6 +133,11 @@ func (s *Service) HandleBlockAnnounce(from peer.ID, msg *network.BlockAnnounceMe
 	return s.chainSync.setBlockAnnounce(from, header)
 }
 
+// HandlePeerDisconnect notifies the `chainSync` module that the given peer disconnected.
+func (s *Service) HandlePeerDisconnect(from peer.ID) {
+	s.chainSync.removePeer(from)
+}
+
 // IsSynced exposes the synced state
 func (s *Service) IsSynced() bool {
 	return s.chainSync.syncState() == tip
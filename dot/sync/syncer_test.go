@@ -58,7 +58,7 @@ func newMockBabeVerifier() *mocks.BabeVerifier {
 func newMockNetwork() *mocks.Network {
 	m := new(mocks.Network)
 	m.On("DoBlockRequest", mock.AnythingOfType("peer.ID"),
-		mock.AnythingOfType("*network.BlockRequestMessage")).Return(nil, nil)
+		mock.AnythingOfType("*network.BlockRequestMessage")).Return(nil, 0, nil)
 	return m
 }
 
@@ -14,10 +14,25 @@
 	"github.com/stretchr/testify/require"
 )
 
+// pastSlots is the number of slots before the current slot
+// at which the first block built by BuildBlock is authored.
+const pastSlots = 1024
+
 // BuildBlock ...
 func BuildBlock(t *testing.T, instance runtime.Instance, parent *types.Header, ext types.Extrinsic) *types.Block {
+	babeConfig, err := instance.BabeConfiguration()
+	require.NoError(t, err)
+
+	// the runtime checks the timestamp inherent against the slot of the block,
+	// so the blocks are authored at consecutive slots starting from a slot far
+	// enough in the past for the timestamps of the chain not to be in the future.
+	slot := uint64(time.Now().UnixMilli())/babeConfig.SlotDuration - pastSlots
+	if parentSlot, err := types.GetSlotFromHeader(parent); err == nil {
+		slot = parentSlot + 1
+	}
+
 	digest := types.NewDigest()
-	prd, err := types.NewBabeSecondaryPlainPreDigest(0, 1).ToPreRuntimeDigest()
+	prd, err := types.NewBabeSecondaryPlainPreDigest(0, slot).ToPreRuntimeDigest()
 	require.NoError(t, err)
 	err = digest.Add(*prd)
 	require.NoError(t, err)
@@ -31,10 +46,10 @@ func BuildBlock(t *testing.T, instance runtime.Instance, parent *types.Header, e
 	require.NoError(t, err)
 
 	idata := types.NewInherentsData()
-	err = idata.SetInt64Inherent(types.Timstap0, uint64(time.Now().Unix()))
+	err = idata.SetInt64Inherent(types.Timstap0, slot*babeConfig.SlotDuration)
 	require.NoError(t, err)
 
-	err = idata.SetInt64Inherent(types.Babeslot, 1)
+	err = idata.SetInt64Inherent(types.Babeslot, slot)
 	require.NoError(t, err)
 
 	ienc, err := idata.Encode()
@@ -68,7 +83,7 @@ func BuildBlock(t *testing.T, instance runtime.Instance, parent *types.Header, e
 		var ret []byte
 
 		externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, ext...))
-		_, err = instance.ValidateTransaction(externalExt)
+		_, err = instance.ValidateTransaction(externalExt, parent.Hash())
 		require.NoError(t, err)
 
 		ret, err = instance.ApplyExtrinsic(ext)
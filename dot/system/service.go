@@ -4,7 +4,10 @@
 package system
 
 import (
+	"fmt"
+
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 )
 
@@ -47,6 +50,24 @@ func (s *Service) Properties() map[string]interface{} {
 	return s.genesisData.Properties
 }
 
+// AddLogFilter parses the given comma separated log directives, such as
+// `sync=trace,runtime::system=debug`, and applies them to the node loggers.
+func (s *Service) AddLogFilter(directives string) error {
+	parsed, err := log.ParseDirectives(directives)
+	if err != nil {
+		return fmt.Errorf("cannot parse log directives: %w", err)
+	}
+
+	log.AddFilter(parsed...)
+	return nil
+}
+
+// ResetLogFilter removes all the log directives added and
+// restores the node loggers to their configured levels.
+func (s *Service) ResetLogFilter() {
+	log.ResetFilter()
+}
+
 // Start implements Service interface
 func (s *Service) Start() error {
 	return nil
@@ -62,3 +62,16 @@ func newTestService() *Service {
 	}
 	return NewService(sysInfo, genData)
 }
+
+func TestService_AddLogFilter(t *testing.T) {
+	svc := newTestService()
+
+	err := svc.AddLogFilter("sync=loud")
+	require.EqualError(t, err, "cannot parse log directives: cannot parse directive sync=loud: "+
+		"level is not recognised: loud")
+
+	err = svc.AddLogFilter("sync=trace,runtime::system=debug")
+	require.NoError(t, err)
+
+	svc.ResetLogFilter()
+}
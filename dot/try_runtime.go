@@ -0,0 +1,305 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+)
+
+var (
+	// ErrSpecNameChanged is returned when the runtime code tried has another spec name.
+	ErrSpecNameChanged = errors.New("spec name changed")
+	// ErrSpecVersionNotBumped is returned when the runtime code tried
+	// does not have a spec version greater than the current one.
+	ErrSpecVersionNotBumped = errors.New("spec version not bumped")
+)
+
+// tryRuntimeAPI is the name of the runtime API exported by the
+// runtimes built with the try-runtime feature.
+const tryRuntimeAPI = "TryRuntime"
+
+// TryRuntime dry-runs the upgrade of the runtime to the given code on top of the state of the
+// given block, given by number or by hex encoded hash and defaulting to the best block.
+// It swaps in the new code, verifies its spec version is bumped and that it implements the
+// runtime APIs required by the node, and runs the migrations with TryRuntime_on_runtime_upgrade
+// if the new runtime exports it, or otherwise executes the given number of blocks following
+// the block on the new code. The versions, the weight and time of the execution and the
+// storage keys changed are written to the writer, and nothing is stored.
+func TryRuntime(cfg *Config, code []byte, at string, blocks uint, w io.Writer) (err error) {
+	nb := nodeBuilder{}
+	stateSrvc, err := nb.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create state service: %w", err)
+	}
+
+	if err = stateSrvc.Start(); err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		if err := stateSrvc.Stop(); err != nil {
+			logger.Errorf("cannot stop state service: %s", err)
+		}
+	}()
+
+	hash := stateSrvc.Block.BestBlockHash()
+	if at != "" {
+		hash, err = blockHashFromID(stateSrvc.Block, at)
+		if err != nil {
+			return err
+		}
+	}
+
+	header, err := stateSrvc.Block.GetHeader(hash)
+	if err != nil {
+		return fmt.Errorf("cannot get header of block %s: %w", hash, err)
+	}
+
+	ts, err := stateSrvc.Storage.TrieState(&header.StateRoot)
+	if err != nil {
+		return fmt.Errorf("cannot get state of block %s: %w", hash, err)
+	}
+
+	codeHash, err := ts.LoadCodeHash()
+	if err != nil {
+		return fmt.Errorf("cannot load runtime code hash: %w", err)
+	}
+
+	ns, err := nb.createRuntimeStorage(stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot create runtime storage: %w", err)
+	}
+
+	rt, err := newRuntimeInstance(cfg, *ns, keystore.NewGlobalKeystore(), nil, ts.LoadCode(), ts, codeHash)
+	if err != nil {
+		return fmt.Errorf("cannot create runtime: %w", err)
+	}
+	defer rt.Stop()
+
+	currentVersion, err := rt.Version()
+	if err != nil {
+		return fmt.Errorf("cannot get current runtime version: %w", err)
+	}
+
+	// the code is stored as the set_code call would
+	ts.Set(common.CodeKey, code)
+	if err = rt.UpdateRuntimeCode(code); err != nil {
+		return fmt.Errorf("cannot update runtime code: %w", err)
+	}
+
+	newVersion, err := rt.Version()
+	if err != nil {
+		return fmt.Errorf("cannot get new runtime version: %w", err)
+	}
+
+	logger.Infof("trying runtime upgrade from spec version %d to %d on top of block number %d with hash %s...",
+		currentVersion.SpecVersion(), newVersion.SpecVersion(), header.Number, hash)
+
+	if err = checkVersionUpgrade(w, currentVersion, newVersion); err != nil {
+		return err
+	}
+
+	if err = runtime.CheckRequiredAPIs(newVersion); err != nil {
+		return err
+	}
+
+	before := ts.TrieEntries()
+
+	if apiVersion, ok := runtime.APIVersion(newVersion, tryRuntimeAPI); ok {
+		err = tryOnRuntimeUpgrade(w, rt, apiVersion)
+	} else {
+		if blocks == 0 {
+			blocks = 1
+		}
+		logger.Infof("runtime does not export %s, executing %d blocks instead...",
+			runtime.TryRuntimeOnRuntimeUpgrade, blocks)
+		err = tryBlocks(w, stateSrvc.Block, rt, ts, header.Number, blocks)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, "storage keys changed:")
+	if err != nil {
+		return err
+	}
+
+	if err = writeEntriesDiff(w, before, ts.TrieEntries()); err != nil {
+		return fmt.Errorf("cannot write state diff: %w", err)
+	}
+
+	return nil
+}
+
+// checkVersionUpgrade writes the current and new runtime versions to the writer, and returns
+// an error if the spec name changed or if the spec version is not bumped. A transaction version
+// not bumped is reported only, as it only has to be bumped if the extrinsics encoding changed.
+func checkVersionUpgrade(w io.Writer, current, next runtime.Version) error {
+	_, err := fmt.Fprintf(w, "spec name: %s -> %s\nspec version: %d -> %d\ntransaction version: %d -> %d\n",
+		current.SpecName(), next.SpecName(), current.SpecVersion(), next.SpecVersion(),
+		current.TransactionVersion(), next.TransactionVersion())
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current.SpecName(), next.SpecName()) {
+		return fmt.Errorf("%w: from %s to %s", ErrSpecNameChanged, current.SpecName(), next.SpecName())
+	}
+
+	if next.SpecVersion() <= current.SpecVersion() {
+		return fmt.Errorf("%w: from %d to %d", ErrSpecVersionNotBumped, current.SpecVersion(), next.SpecVersion())
+	}
+
+	if next.TransactionVersion() == current.TransactionVersion() {
+		_, err = fmt.Fprintln(w, "transaction version not bumped, extrinsics encoding must be unchanged")
+	}
+	return err
+}
+
+// tryOnRuntimeUpgrade runs the migrations of the runtime with TryRuntime_on_runtime_upgrade
+// and writes their weight and time to the writer. The version 2 of the API runs the checks
+// of the migrations as well.
+func tryOnRuntimeUpgrade(w io.Writer, rt runtime.Instance, apiVersion uint32) error {
+	var args []byte
+	if apiVersion >= 2 {
+		args = []byte{1} // checks
+	}
+
+	start := time.Now()
+	ret, err := rt.Exec(runtime.TryRuntimeOnRuntimeUpgrade, args)
+	if err != nil {
+		return fmt.Errorf("cannot run runtime upgrade: %w", err)
+	}
+	duration := time.Since(start)
+
+	// the call returns the weight of the migrations and the maximum block weight
+	if len(ret) < 16 {
+		_, err = fmt.Fprintf(w, "runtime upgrade executed in %s, returned 0x%x\n", duration, ret)
+		return err
+	}
+
+	weight := binary.LittleEndian.Uint64(ret[:8])
+	maxWeight := binary.LittleEndian.Uint64(ret[8:16])
+	_, err = fmt.Fprintf(w, "runtime upgrade executed in %s with weight %d of maximum block weight %d\n",
+		duration, weight, maxWeight)
+	return err
+}
+
+// blockStateGetter is the block state used by tryBlocks.
+type blockStateGetter interface {
+	GetBlockByNumber(number uint) (*types.Block, error)
+}
+
+// tryBlocks builds again the given number of blocks following the block of the given number on top
+// of the given state with the given runtime, and writes the weight and time of each block to the
+// writer. The blocks are built by applying their extrinsics instead of being executed, since the
+// state root computed by the new runtime is not expected to match the state root of their header.
+func tryBlocks(w io.Writer, blockState blockStateGetter, rt runtime.Instance,
+	ts *rtstorage.TrieState, parentNumber, blocks uint) error {
+	rt.SetContextStorage(ts)
+
+	for number := parentNumber + 1; number <= parentNumber+blocks; number++ {
+		block, err := blockState.GetBlockByNumber(number)
+		if err != nil {
+			return fmt.Errorf("cannot get block %d: %w", number, err)
+		}
+
+		start := time.Now()
+		failed, err := buildBlock(w, rt, block)
+		if err != nil {
+			return fmt.Errorf("cannot build block %d: %w", number, err)
+		}
+		duration := time.Since(start)
+
+		_, err = fmt.Fprintf(w, "block number %d with %d extrinsics (%d failed) executed in %s with weight %d\n",
+			number, len(block.Body), failed, duration, blockWeight(ts))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildBlock initialises the block, applies its extrinsics and finalises it, writing the
+// extrinsics failing to the writer. It returns the number of extrinsics failing.
+func buildBlock(w io.Writer, rt runtime.Instance, block *types.Block) (failed uint, err error) {
+	header, err := headerWithoutSeal(&block.Header)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = rt.InitializeBlock(header); err != nil {
+		return 0, fmt.Errorf("cannot initialise block: %w", err)
+	}
+
+	for i, extrinsic := range block.Body {
+		ret, err := rt.ApplyExtrinsic(extrinsic)
+		if err != nil {
+			return failed, fmt.Errorf("cannot apply extrinsic %d: %w", i, err)
+		}
+
+		// the result is ok if both the transaction validity and dispatch results are ok
+		if len(ret) >= 2 && ret[0] == 0 && ret[1] == 0 {
+			continue
+		}
+
+		failed++
+		_, err = fmt.Fprintf(w, "extrinsic %d of block number %d failed: 0x%x\n", i, block.Header.Number, ret)
+		if err != nil {
+			return failed, err
+		}
+	}
+
+	if _, err = rt.FinalizeBlock(); err != nil {
+		return failed, fmt.Errorf("cannot finalise block: %w", err)
+	}
+
+	return failed, nil
+}
+
+// headerWithoutSeal returns a copy of the header without its seal digest,
+// as the block is built by the runtime before the seal is added.
+func headerWithoutSeal(header *types.Header) (*types.Header, error) {
+	digest := types.NewDigest()
+	for _, item := range header.Digest.Types {
+		if _, ok := item.Value().(types.SealDigest); ok {
+			continue
+		}
+
+		if err := digest.Add(item.Value()); err != nil {
+			return nil, fmt.Errorf("cannot add digest item: %w", err)
+		}
+	}
+
+	return types.NewHeader(header.ParentHash, header.StateRoot, header.ExtrinsicsRoot, header.Number, digest)
+}
+
+// blockWeight returns the weight consumed by the block built last, stored by the System
+// pallet for each dispatch class, or zero if it cannot be decoded.
+func blockWeight(ts *rtstorage.TrieState) (weight uint64) {
+	prefix, _ := common.Twox128Hash([]byte("System"))
+	key, _ := common.Twox128Hash([]byte("BlockWeight"))
+
+	value := ts.Get(append(prefix, key...))
+	if len(value) != 24 {
+		return 0
+	}
+
+	// normal, operational and mandatory weights
+	for i := 0; i < len(value); i += 8 {
+		weight += binary.LittleEndian.Uint64(value[i : i+8])
+	}
+	return weight
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkVersionUpgrade(t *testing.T) {
+	t.Parallel()
+
+	newVersion := func(specName string, specVersion, transactionVersion uint32) runtime.Version {
+		return runtime.NewVersionData([]byte(specName), nil, 0, specVersion, 0, nil, transactionVersion)
+	}
+
+	testCases := map[string]struct {
+		current    runtime.Version
+		next       runtime.Version
+		output     string
+		errWrap    error
+		errMessage string
+	}{
+		"spec and transaction versions bumped": {
+			current: newVersion("node", 1, 1),
+			next:    newVersion("node", 2, 2),
+			output:  "spec name: node -> node\nspec version: 1 -> 2\ntransaction version: 1 -> 2\n",
+		},
+		"transaction version not bumped": {
+			current: newVersion("node", 1, 1),
+			next:    newVersion("node", 2, 1),
+			output: "spec name: node -> node\nspec version: 1 -> 2\ntransaction version: 1 -> 1\n" +
+				"transaction version not bumped, extrinsics encoding must be unchanged\n",
+		},
+		"spec name changed": {
+			current:    newVersion("node", 1, 1),
+			next:       newVersion("other", 2, 1),
+			output:     "spec name: node -> other\nspec version: 1 -> 2\ntransaction version: 1 -> 1\n",
+			errWrap:    ErrSpecNameChanged,
+			errMessage: "spec name changed: from node to other",
+		},
+		"spec version not bumped": {
+			current:    newVersion("node", 2, 1),
+			next:       newVersion("node", 2, 1),
+			output:     "spec name: node -> node\nspec version: 2 -> 2\ntransaction version: 1 -> 1\n",
+			errWrap:    ErrSpecVersionNotBumped,
+			errMessage: "spec version not bumped: from 2 to 2",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := bytes.NewBuffer(nil)
+			err := checkVersionUpgrade(buffer, testCase.current, testCase.next)
+
+			assert.ErrorIs(t, err, testCase.errWrap)
+			if testCase.errWrap != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.output, buffer.String())
+		})
+	}
+}
+
+func Test_tryOnRuntimeUpgrade(t *testing.T) {
+	t.Parallel()
+
+	ret := []byte{
+		1, 0, 0, 0, 0, 0, 0, 0, // weight
+		2, 0, 0, 0, 0, 0, 0, 0, // maximum block weight
+	}
+
+	rt := new(mocks.Instance)
+	rt.On("Exec", runtime.TryRuntimeOnRuntimeUpgrade, []byte(nil)).Return(ret, nil).Once()
+	rt.On("Exec", runtime.TryRuntimeOnRuntimeUpgrade, []byte{1}).Return([]byte{1}, nil).Once()
+
+	buffer := bytes.NewBuffer(nil)
+	err := tryOnRuntimeUpgrade(buffer, rt, 1)
+	require.NoError(t, err)
+	assert.Regexp(t, "^runtime upgrade executed in .+ with weight 1 of maximum block weight 2\n$", buffer.String())
+
+	buffer.Reset()
+	err = tryOnRuntimeUpgrade(buffer, rt, 2)
+	require.NoError(t, err)
+	assert.Regexp(t, "^runtime upgrade executed in .+, returned 0x01\n$", buffer.String())
+
+	rt.AssertExpectations(t)
+}
+
+func Test_buildBlock(t *testing.T) {
+	t.Parallel()
+
+	digest := types.NewDigest()
+	err := digest.Add(types.PreRuntimeDigest{ConsensusEngineID: types.BabeEngineID, Data: []byte{1}})
+	require.NoError(t, err)
+	expectedHeader, err := types.NewHeader(common.Hash{1}, common.Hash{2}, common.Hash{3}, 4, digest)
+	require.NoError(t, err)
+
+	err = digest.Add(types.SealDigest{ConsensusEngineID: types.BabeEngineID, Data: []byte{2}})
+	require.NoError(t, err)
+	header, err := types.NewHeader(common.Hash{1}, common.Hash{2}, common.Hash{3}, 4, digest)
+	require.NoError(t, err)
+
+	block := &types.Block{
+		Header: *header,
+		Body:   types.Body{{1}, {2}},
+	}
+
+	rt := new(mocks.Instance)
+	rt.On("InitializeBlock", expectedHeader).Return(nil)
+	rt.On("ApplyExtrinsic", types.Extrinsic{1}).Return([]byte{0, 0}, nil)
+	rt.On("ApplyExtrinsic", types.Extrinsic{2}).Return([]byte{1, 0, 1}, nil)
+	rt.On("FinalizeBlock").Return(types.NewEmptyHeader(), nil)
+
+	buffer := bytes.NewBuffer(nil)
+	failed, err := buildBlock(buffer, rt, block)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), failed)
+	assert.Equal(t, "extrinsic 1 of block number 4 failed: 0x010001\n", buffer.String())
+
+	rt.AssertExpectations(t)
+}
+
+func Test_blockWeight(t *testing.T) {
+	t.Parallel()
+
+	ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), blockWeight(ts))
+
+	prefix, err := common.Twox128Hash([]byte("System"))
+	require.NoError(t, err)
+	key, err := common.Twox128Hash([]byte("BlockWeight"))
+	require.NoError(t, err)
+
+	ts.Set(append(prefix, key...), []byte{
+		1, 0, 0, 0, 0, 0, 0, 0, // normal
+		2, 0, 0, 0, 0, 0, 0, 0, // operational
+		3, 0, 0, 0, 0, 0, 0, 0, // mandatory
+	})
+	assert.Equal(t, uint64(6), blockWeight(ts))
+}
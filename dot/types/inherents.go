@@ -9,6 +9,7 @@
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ChainSafe/gossamer/pkg/scale"
 )
@@ -43,6 +44,23 @@ func (d *InherentsData) String() string {
 	return str
 }
 
+// NewBlockInherentsData returns the inherents data of a block built or
+// checked at the given time, in the given BABE slot.
+func NewBlockInherentsData(timestamp time.Time, slot uint64) (*InherentsData, error) {
+	idata := NewInherentsData()
+	err := idata.SetInt64Inherent(Timstap0, uint64(timestamp.UnixMilli()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = idata.SetInt64Inherent(Babeslot, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	return idata, nil
+}
+
 // SetInt64Inherent set an inherent of type uint64
 func (d *InherentsData) SetInt64Inherent(key []byte, data uint64) error {
 	if len(key) != 8 {
@@ -91,3 +109,24 @@ func (d *InherentsData) Encode() ([]byte, error) {
 	}
 	return buffer.Bytes(), nil
 }
+
+// InherentError is an error reported by the runtime for an inherent
+type InherentError struct {
+	Identifier [8]byte
+	// Data is the scale-encoded error, specific to the module providing the inherent
+	Data []byte
+}
+
+func (e InherentError) String() string {
+	return fmt.Sprintf("inherent %s: 0x%x", e.Identifier[:], e.Data)
+}
+
+// CheckInherentsResult is the result of the check of the inherents of a block by the runtime
+type CheckInherentsResult struct {
+	// Okay is true if all the inherents are valid
+	Okay bool
+	// FatalError is true if one of the errors is fatal, in which case the block is invalid
+	FatalError bool
+	// Errors are the errors of the inherents, mapped by inherent identifier
+	Errors []InherentError
+}
@@ -110,6 +110,7 @@ type args struct {
 telemetry_urls = []
 retain_blocks = 0
 pruning = ""
+runtime_profiling = false
 
 [log]
 core_lvl = 0
@@ -121,6 +122,7 @@ type args struct {
 runtime_lvl = 0
 block_producer_lvl = 0
 finality_gadget_lvl = 0
+runtime_targets = ""
 
 [init]
 genesis = ""
@@ -136,6 +138,7 @@ type args struct {
 grandpa_authority = false
 wasm_interpreter = ""
 grandpa_interval = 0
+timestamp_drift = 0
 
 [network]
 port = 0
@@ -163,6 +166,12 @@ type args struct {
 w_s_external = false
 w_s_unsafe = false
 w_s_unsafe_external = false
+rate_limit = 0e+00
+rate_limit_burst = 0
+allowed_methods = []
+denied_methods = []
+w_s_allowed_methods = []
+w_s_denied_methods = []
 
 [system]
 system_name = ""
@@ -170,6 +179,12 @@ type args struct {
 
 [state]
 rewind = 0
+cache_size = 0
+runtime_pool_size = 0
+
+[state.blocks_pruning]
+mode = ""
+retained_blocks = 0
 
 [pprof]
 enabled = false
@@ -49,6 +49,7 @@ require (
 require (
 	github.com/prometheus/client_golang v1.12.1
 	github.com/prometheus/client_model v0.2.0
+	github.com/tetratelabs/wazero v1.0.1
 )
 
 require (
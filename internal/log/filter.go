@@ -0,0 +1,242 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDirectiveMalformed = errors.New("log directive is malformed")
+
+// Directive sets the level of the loggers matching its target.
+// An empty target matches all loggers.
+type Directive struct {
+	Target string
+	Level  Level
+	// TargetOnly restricts the directive to the loggers with a
+	// target context, such as the runtime log target loggers.
+	TargetOnly bool
+}
+
+// ParseDirectives parses comma separated directives such as
+// `sync=trace,network=debug,runtime::system=debug`.
+// A directive without target such as `debug` applies to all loggers.
+func ParseDirectives(s string) (directives []Directive, err error) {
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		var target, levelString string
+		parts := strings.Split(field, "=")
+		switch len(parts) {
+		case 1:
+			levelString = parts[0]
+		case 2:
+			target, levelString = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			if target == "" {
+				return nil, fmt.Errorf("%w: %s", ErrDirectiveMalformed, field)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrDirectiveMalformed, field)
+		}
+
+		level, err := ParseLevel(levelString)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse directive %s: %w", field, err)
+		}
+
+		directives = append(directives, Directive{
+			Target: target,
+			Level:  level,
+		})
+	}
+
+	return directives, nil
+}
+
+// matches returns true if the directive target matches the given
+// package or sub-package, such as `rpc` for `rpc/subscription`, or the
+// given runtime target or sub-target, such as `runtime` for `runtime::system`.
+func (d Directive) matches(pkg, target string) bool {
+	if d.TargetOnly && target == "" {
+		return false
+	}
+
+	if d.Target == "" {
+		return true
+	}
+
+	if d.TargetOnly {
+		pkg = ""
+	}
+
+	if pkg != "" && (pkg == d.Target || strings.HasPrefix(pkg, d.Target+"/")) {
+		return true
+	}
+
+	return target != "" && (target == d.Target || strings.HasPrefix(target, d.Target+"::"))
+}
+
+// filter holds the directives applied to a logger tree.
+type filter struct {
+	// defaultDirectives are applied before the directives
+	// added, and are kept when the filter is reset.
+	defaultDirectives []Directive
+	directives        []Directive
+	// root is the logger the filter was added to.
+	root *Logger
+}
+
+func (l *Logger) initFilter() {
+	if l.filter == nil {
+		l.filter = &filter{root: l}
+	}
+}
+
+// AddFilter applies the directives to the logger and its child loggers,
+// including the child loggers created afterwards.
+// When several directives match a logger, the one with the most
+// specific target is used, and the last one added wins on equality.
+func (l *Logger) AddFilter(directives ...Directive) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.initFilter()
+	l.filter.directives = append(l.filter.directives, directives...)
+
+	l.applyFilter(l.filter)
+}
+
+// SetDefaultFilter sets the directives applied to the logger and its child
+// loggers before the directives added, such as the directives configured
+// for the node. They are kept when the filter is reset.
+func (l *Logger) SetDefaultFilter(directives ...Directive) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.initFilter()
+	l.filter.defaultDirectives = directives
+
+	l.applyFilter(l.filter)
+}
+
+// ResetFilter removes all the directives added to the logger and restores the
+// levels of the logger and its child loggers, apart from the default directives.
+func (l *Logger) ResetFilter() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.filter == nil {
+		return
+	}
+
+	l.filter.directives = nil
+	l.applyFilter(l.filter)
+}
+
+// applyFilter sets the filter on the logger and its child loggers,
+// and sets their level according to the filter directives.
+// It must be called with the mutex locked.
+func (l *Logger) applyFilter(f *filter) {
+	l.filter = f
+
+	if l.defaultLevel == nil {
+		l.setDefaultLevel(*l.settings.level)
+	}
+
+	level := l.filteredLevel(*l.defaultLevel)
+	l.patch(SetLevel(level))
+
+	for _, child := range l.childs {
+		child.applyFilter(f)
+	}
+}
+
+// setDefaultLevel sets the level of the logger before the filter
+// directives are applied. It must be called with the mutex locked.
+func (l *Logger) setDefaultLevel(level Level) {
+	l.defaultLevel = &level
+}
+
+// filteredLevel returns the level of the most specific directive
+// matching the logger, or the default level given if none matches.
+func (l *Logger) filteredLevel(defaultLevel Level) (level Level) {
+	pkg := l.contextValue("pkg")
+	target := l.contextValue("target")
+
+	level = defaultLevel
+	matchLength := -1
+	for _, directive := range l.filter.all() {
+		if !directive.matches(pkg, target) || len(directive.Target) < matchLength {
+			continue
+		}
+		level = directive.Level
+		matchLength = len(directive.Target)
+	}
+	return level
+}
+
+// MaxTargetLevel returns the most verbose level the child loggers of the logger
+// with a target context, such as the runtime log target loggers, can have with
+// the current filter directives, including the child loggers created afterwards.
+// The directives targeting the package of another logger are ignored.
+func (l *Logger) MaxTargetLevel() (level Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// the child loggers have the level of the logger
+	// unless a directive matches their target.
+	level = *l.settings.level
+	if l.filter == nil {
+		return level
+	}
+
+	pkg := l.contextValue("pkg")
+	packages := make(map[string]struct{})
+	l.filter.root.addPackages(packages)
+
+	for _, directive := range l.filter.all() {
+		if directive.Level <= level || directive.matches(pkg, "") {
+			// the directives matching the logger also match its child
+			// loggers, and are already reflected in its level.
+			continue
+		}
+
+		if _, ok := packages[directive.Target]; ok && !directive.TargetOnly {
+			continue
+		}
+
+		level = directive.Level
+	}
+	return level
+}
+
+// addPackages adds the package of the logger and of
+// its descendant loggers to the packages set given.
+func (l *Logger) addPackages(packages map[string]struct{}) {
+	packages[l.contextValue("pkg")] = struct{}{}
+	for _, child := range l.childs {
+		child.addPackages(packages)
+	}
+}
+
+func (f *filter) all() []Directive {
+	if len(f.defaultDirectives) == 0 {
+		return f.directives
+	}
+	return append(append([]Directive(nil), f.defaultDirectives...), f.directives...)
+}
+
+func (l *Logger) contextValue(key string) (value string) {
+	for _, kvs := range l.settings.context {
+		if kvs.key == key && len(kvs.values) > 0 {
+			return kvs.values[len(kvs.values)-1]
+		}
+	}
+	return ""
+}
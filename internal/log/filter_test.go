@@ -0,0 +1,257 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseDirectives(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		s          string
+		directives []Directive
+		errWrapped error
+		errMessage string
+	}{
+		"empty string": {},
+		"single level": {
+			s:          "debug",
+			directives: []Directive{{Level: Debug}},
+		},
+		"multiple directives": {
+			s: "sync=trace, network=debug,runtime::system=4",
+			directives: []Directive{
+				{Target: "sync", Level: Trace},
+				{Target: "network", Level: Debug},
+				{Target: "runtime::system", Level: Debug},
+			},
+		},
+		"too many equal signs": {
+			s:          "sync=trace=debug",
+			errWrapped: ErrDirectiveMalformed,
+			errMessage: "log directive is malformed: sync=trace=debug",
+		},
+		"empty target": {
+			s:          "=trace",
+			errWrapped: ErrDirectiveMalformed,
+			errMessage: "log directive is malformed: =trace",
+		},
+		"bad level": {
+			s:          "sync=loud",
+			errWrapped: ErrLevelNotRecognised,
+			errMessage: "cannot parse directive sync=loud: level is not recognised: loud",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			directives, err := ParseDirectives(testCase.s)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.directives, directives)
+		})
+	}
+}
+
+func Test_Directive_matches(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		directive Directive
+		pkg       string
+		target    string
+		matches   bool
+	}{
+		"no target": {
+			pkg:     "sync",
+			matches: true,
+		},
+		"same package": {
+			directive: Directive{Target: "sync"},
+			pkg:       "sync",
+			matches:   true,
+		},
+		"sub package": {
+			directive: Directive{Target: "rpc"},
+			pkg:       "rpc/subscription",
+			matches:   true,
+		},
+		"package prefix only": {
+			directive: Directive{Target: "sync"},
+			pkg:       "syncer",
+		},
+		"same runtime target": {
+			directive: Directive{Target: "runtime::system"},
+			pkg:       "runtime",
+			target:    "runtime::system",
+			matches:   true,
+		},
+		"parent runtime target": {
+			directive: Directive{Target: "runtime"},
+			pkg:       "wasmer",
+			target:    "runtime::system",
+			matches:   true,
+		},
+		"other runtime target": {
+			directive: Directive{Target: "runtime::system"},
+			pkg:       "runtime",
+			target:    "runtime::babe",
+		},
+		"target only without target": {
+			directive: Directive{TargetOnly: true},
+			pkg:       "sync",
+		},
+		"target only with any target": {
+			directive: Directive{TargetOnly: true},
+			pkg:       "runtime",
+			target:    "babe",
+			matches:   true,
+		},
+		"target only package": {
+			directive: Directive{Target: "runtime", TargetOnly: true},
+			pkg:       "runtime",
+			target:    "babe",
+		},
+		"target only runtime target": {
+			directive: Directive{Target: "runtime", TargetOnly: true},
+			pkg:       "runtime",
+			target:    "runtime::system",
+			matches:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			matches := testCase.directive.matches(testCase.pkg, testCase.target)
+
+			assert.Equal(t, testCase.matches, matches)
+		})
+	}
+}
+
+func Test_Logger_AddFilter(t *testing.T) {
+	t.Parallel()
+
+	root := New(SetLevel(Warn))
+	sync := root.New(AddContext("pkg", "sync"))
+	runtime := root.New(AddContext("pkg", "runtime"), SetLevel(Info))
+	system := runtime.New(AddContext("target", "runtime::system"))
+
+	root.AddFilter(Directive{Target: "runtime", Level: Debug})
+	root.AddFilter(Directive{Target: "runtime::system", Level: Trace}, Directive{Target: "sync", Level: Error})
+
+	assert.Equal(t, Warn, *root.settings.level)
+	assert.Equal(t, Error, *sync.settings.level)
+	assert.Equal(t, Debug, *runtime.settings.level)
+	assert.Equal(t, Trace, *system.settings.level)
+
+	// child loggers created after the filter is added are filtered
+	babe := runtime.New(AddContext("target", "runtime::babe"))
+	assert.Equal(t, Debug, *babe.settings.level)
+
+	// patched levels are overridden by the filter
+	runtime.Patch(SetLevel(Critical))
+	assert.Equal(t, Debug, *runtime.settings.level)
+
+	root.ResetFilter()
+
+	assert.Equal(t, Warn, *root.settings.level)
+	assert.Equal(t, Warn, *sync.settings.level)
+	assert.Equal(t, Critical, *runtime.settings.level)
+	assert.Equal(t, Critical, *system.settings.level)
+	assert.Equal(t, Critical, *babe.settings.level)
+}
+
+func Test_Logger_Patch_filter(t *testing.T) {
+	t.Parallel()
+
+	root := New(SetLevel(Info))
+	runtime := root.New(AddContext("pkg", "runtime"))
+	system := runtime.New(AddContext("target", "runtime::system"))
+
+	root.AddFilter(Directive{Target: "runtime::system", Level: Trace})
+
+	// the patched level is the default level of all the descendant loggers
+	root.Patch(SetLevel(Error))
+	assert.Equal(t, Error, *root.settings.level)
+	assert.Equal(t, Error, *runtime.settings.level)
+	assert.Equal(t, Trace, *system.settings.level)
+
+	root.ResetFilter()
+	assert.Equal(t, Error, *system.settings.level)
+}
+
+func Test_Logger_ResetFilter(t *testing.T) {
+	t.Parallel()
+
+	logger := New(SetLevel(Info))
+	logger.ResetFilter()
+	require.Nil(t, logger.filter)
+	assert.Equal(t, Info, *logger.settings.level)
+}
+
+func Test_Logger_SetDefaultFilter(t *testing.T) {
+	t.Parallel()
+
+	root := New(SetLevel(Info))
+	grandpa := root.New(AddContext("pkg", "grandpa"))
+	runtime := root.New(AddContext("pkg", "runtime"))
+	target := runtime.New(AddContext("target", "grandpa"))
+
+	root.SetDefaultFilter(Directive{Target: "grandpa", Level: Debug, TargetOnly: true})
+	assert.Equal(t, Info, *grandpa.settings.level)
+	assert.Equal(t, Info, *runtime.settings.level)
+	assert.Equal(t, Debug, *target.settings.level)
+
+	root.AddFilter(Directive{Target: "grandpa", Level: Warn})
+
+	assert.Equal(t, Warn, *grandpa.settings.level)
+	assert.Equal(t, Info, *runtime.settings.level)
+	assert.Equal(t, Warn, *target.settings.level)
+
+	// the default directives are kept when the filter is reset
+	root.ResetFilter()
+
+	assert.Equal(t, Info, *grandpa.settings.level)
+	assert.Equal(t, Info, *runtime.settings.level)
+	assert.Equal(t, Debug, *target.settings.level)
+}
+
+func Test_Logger_MaxTargetLevel(t *testing.T) {
+	t.Parallel()
+
+	root := New(SetLevel(Info))
+	root.New(AddContext("pkg", "sync"))
+	runtime := root.New(AddContext("pkg", "runtime"), SetLevel(Warn))
+
+	assert.Equal(t, Warn, runtime.MaxTargetLevel())
+
+	// directives targeting other packages are ignored
+	root.AddFilter(Directive{Target: "sync", Level: Trace})
+	assert.Equal(t, Warn, runtime.MaxTargetLevel())
+
+	root.AddFilter(Directive{Target: "runtime::system", Level: Debug})
+	assert.Equal(t, Debug, runtime.MaxTargetLevel())
+
+	root.AddFilter(Directive{Target: "sync", Level: Trace, TargetOnly: true})
+	assert.Equal(t, Trace, runtime.MaxTargetLevel())
+
+	root.ResetFilter()
+	root.AddFilter(Directive{Target: "runtime", Level: Error})
+	assert.Equal(t, Error, runtime.MaxTargetLevel())
+}
@@ -21,3 +21,21 @@ func Patch(options ...Option) {
 func Errorf(s string, args ...interface{}) {
 	globalLogger.Errorf(s, args...)
 }
+
+// AddFilter adds the directives to the global logger filter,
+// and applies them to all the loggers created from it.
+func AddFilter(directives ...Directive) {
+	globalLogger.AddFilter(directives...)
+}
+
+// SetDefaultFilter sets the directives of the global logger filter applied
+// before the directives added and kept when the filter is reset.
+func SetDefaultFilter(directives ...Directive) {
+	globalLogger.SetDefaultFilter(directives...)
+}
+
+// ResetFilter removes all the directives from the global logger
+// filter and restores the levels of the loggers created from it.
+func ResetFilter() {
+	globalLogger.ResetFilter()
+}
@@ -13,6 +13,9 @@ type Logger struct {
 	settings settings
 	mutex    *sync.Mutex // pointer for child loggers
 	childs   []*Logger   // TODO-1946 remove this field
+	filter   *filter     // pointer shared with child loggers once a filter is added
+	// defaultLevel is the level before the filter directives are applied.
+	defaultLevel *Level
 }
 
 // New creates a new logger.
@@ -49,5 +52,22 @@ func (l *Logger) New(options ...Option) *Logger {
 
 	l.childs = append(l.childs, newLogger)
 
+	if l.filter != nil {
+		// the child level defaults to its parent default level and
+		// is then set according to the filter directives.
+		newLogger.setDefaultLevel(*l.defaultLevel)
+		if level := newSettings(options).level; level != nil {
+			newLogger.setDefaultLevel(*level)
+		}
+		newLogger.applyFilter(l.filter)
+	}
+
 	return newLogger
 }
+
+// Level returns the current level of the logger.
+func (l *Logger) Level() Level {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return *l.settings.level
+}
@@ -144,3 +144,13 @@ func Test_Logger_New(t *testing.T) {
 		})
 	}
 }
+
+func Test_Logger_Level(t *testing.T) {
+	t.Parallel()
+
+	logger := New(SetWriter(io.Discard), SetLevel(Debug))
+	assert.Equal(t, Debug, logger.Level())
+
+	child := logger.New(SetLevel(Warn))
+	assert.Equal(t, Warn, child.Level())
+}
@@ -10,9 +10,24 @@ func (l *Logger) Patch(options ...Option) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
+	l.patchTree(options...)
+}
+
+// patchTree patches the logger and its descendant loggers,
+// and sets their level according to the filter directives.
+// It must be called with the mutex locked.
+func (l *Logger) patchTree(options ...Option) {
 	l.patch(options...)
+
+	// a level patched is the new default level, which
+	// may be overridden by the filter directives.
+	if level := newSettings(options).level; level != nil && l.filter != nil {
+		l.setDefaultLevel(*level)
+		l.patch(SetLevel(l.filteredLevel(*level)))
+	}
+
 	for _, child := range l.childs {
-		child.patch(options...)
+		child.patchTree(options...)
 	}
 }
 
@@ -75,6 +75,16 @@ func (l *Leaf) ScaleEncodeHash() (encoding []byte, err error) {
 }
 
 func (l *Leaf) hash(writer io.Writer) (err error) {
+	if !l.Dirty && l.Encoding == nil && len(l.HashDigest) == 32 {
+		// the leaf is a placeholder for a node only referenced by its
+		// hash in the encoding of its parent branch, as set by Decode.
+		_, err = writer.Write(l.HashDigest)
+		if err != nil {
+			return fmt.Errorf("cannot write hash digest of leaf to buffer: %w", err)
+		}
+		return nil
+	}
+
 	encodingBuffer := pools.EncodingBuffers.Get().(*bytes.Buffer)
 	encodingBuffer.Reset()
 	defer pools.EncodingBuffers.Put(encodingBuffer)
@@ -526,11 +526,17 @@ func (b *Service) handleSlot(epoch, slotNum uint64,
 	}
 
 	hash := parent.Hash()
-	rt, err := b.blockState.GetRuntime(&hash)
+	pool, err := b.blockState.GetRuntimePool(&hash)
 	if err != nil {
 		return err
 	}
 
+	rt, err := pool.Checkout()
+	if err != nil {
+		return err
+	}
+	defer pool.Checkin(rt)
+
 	rt.SetContextStorage(ts)
 
 	block, err := b.buildBlock(parent, currentSlot, rt, authorityIndex, preRuntimeDigest)
@@ -238,16 +238,8 @@ func (b *BlockBuilder) buildBlockExtrinsics(slot Slot, rt runtime.Instance) []*t
 }
 
 func buildBlockInherents(slot Slot, rt runtime.Instance) ([][]byte, error) {
-	// Setup inherents: add timstap0
-	idata := types.NewInherentsData()
-	timestamp := uint64(time.Now().UnixMilli())
-	err := idata.SetInt64Inherent(types.Timstap0, timestamp)
-	if err != nil {
-		return nil, err
-	}
-
-	// add babeslot
-	err = idata.SetInt64Inherent(types.Babeslot, slot.number)
+	// Setup inherents: add timstap0 and babeslot
+	idata, err := types.NewBlockInherentsData(time.Now(), slot.number)
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +256,21 @@ func (mr *MockBlockStateMockRecorder) GetRuntime(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuntime", reflect.TypeOf((*MockBlockState)(nil).GetRuntime), arg0)
 }
 
+// GetRuntimePool mocks base method.
+func (m *MockBlockState) GetRuntimePool(arg0 *common.Hash) (*runtime.Pool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuntimePool", arg0)
+	ret0, _ := ret[0].(*runtime.Pool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuntimePool indicates an expected call of GetRuntimePool.
+func (mr *MockBlockStateMockRecorder) GetRuntimePool(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuntimePool", reflect.TypeOf((*MockBlockState)(nil).GetRuntimePool), arg0)
+}
+
 // GetSlotForBlock mocks base method.
 func (m *MockBlockState) GetSlotForBlock(arg0 common.Hash) (uint64, error) {
 	m.ctrl.T.Helper()
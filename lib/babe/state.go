@@ -35,6 +35,7 @@ type BlockState interface {
 	IsDescendantOf(parent, child common.Hash) (bool, error)
 	NumberIsFinalised(blockNumber uint) (bool, error)
 	GetRuntime(*common.Hash) (runtime.Instance, error)
+	GetRuntimePool(*common.Hash) (*runtime.Pool, error)
 	StoreRuntime(common.Hash, runtime.Instance)
 	ImportedBlockNotifierManager
 }
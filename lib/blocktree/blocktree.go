@@ -181,6 +181,31 @@ func (bt *BlockTree) Prune(finalised Hash) (pruned []Hash) {
 	return pruned
 }
 
+// Revert removes all the blocks with a number greater than the given number,
+// and returns the hashes of the removed blocks. The root cannot be removed.
+func (bt *BlockTree) Revert(number uint) (reverted []Hash, err error) {
+	bt.Lock()
+	defer bt.Unlock()
+
+	if number < bt.root.number {
+		return nil, ErrNumLowerThanRoot
+	}
+
+	reverted = bt.root.revert(number, nil)
+
+	bt.leaves = newEmptyLeafMap()
+	for _, leaf := range bt.root.getLeaves(nil) {
+		bt.leaves.store(leaf.hash, leaf)
+	}
+
+	for _, hash := range reverted {
+		bt.runtimes.delete(hash)
+	}
+
+	leavesGauge.Set(float64(len(bt.leaves.nodes())))
+	return reverted, nil
+}
+
 // String utilises github.com/disiqueira/gotree to create a printable tree
 func (bt *BlockTree) String() string {
 	bt.RLock()
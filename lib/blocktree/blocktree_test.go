@@ -442,6 +442,45 @@ func TestBlockTree_Prune(t *testing.T) {
 	}
 }
 
+func TestBlockTree_Revert(t *testing.T) {
+	bt, _ := createTestBlockTree(t, testHeader, 8)
+	copy := bt.DeepCopy()
+
+	reverted, err := bt.Revert(5)
+	require.NoError(t, err)
+
+	for _, hash := range copy.GetAllBlocks() {
+		number := copy.getNode(hash).number
+		if number > 5 {
+			require.Contains(t, reverted, hash)
+			require.Nil(t, bt.getNode(hash))
+		} else {
+			require.NotContains(t, reverted, hash)
+			require.NotNil(t, bt.getNode(hash))
+		}
+	}
+
+	require.NotEqual(t, 0, len(bt.leaves.nodes()))
+	for _, leaf := range bt.leaves.nodes() {
+		require.LessOrEqual(t, leaf.number, uint(5))
+		require.Empty(t, leaf.children)
+	}
+	require.Equal(t, uint(5), bt.getNode(bt.BestBlockHash()).number)
+
+	_, err = bt.Revert(bt.root.number)
+	require.NoError(t, err)
+	require.Equal(t, bt.root.hash, bt.BestBlockHash())
+}
+
+func TestBlockTree_Revert_lowerThanRoot(t *testing.T) {
+	bt, _ := createTestBlockTree(t, testHeader, 4)
+	finalised := bt.root.children[0].children[0]
+	bt.Prune(finalised.hash)
+
+	_, err := bt.Revert(1)
+	require.ErrorIs(t, err, ErrNumLowerThanRoot)
+}
+
 func TestBlockTree_GetHashByNumber(t *testing.T) {
 	bt, _ := createTestBlockTree(t, testHeader, 8)
 	best := bt.BestBlockHash()
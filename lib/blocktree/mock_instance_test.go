@@ -69,15 +69,18 @@ func (mr *MockInstanceMockRecorder) BabeConfiguration() *gomock.Call {
 }
 
 // CheckInherents mocks base method.
-func (m *MockInstance) CheckInherents() {
+func (m *MockInstance) CheckInherents(arg0 *types.Block, arg1 []byte) (*types.CheckInherentsResult, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "CheckInherents")
+	ret := m.ctrl.Call(m, "CheckInherents", arg0, arg1)
+	ret0, _ := ret[0].(*types.CheckInherentsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CheckInherents indicates an expected call of CheckInherents.
-func (mr *MockInstanceMockRecorder) CheckInherents() *gomock.Call {
+func (mr *MockInstanceMockRecorder) CheckInherents(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInherents", reflect.TypeOf((*MockInstance)(nil).CheckInherents))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInherents", reflect.TypeOf((*MockInstance)(nil).CheckInherents), arg0, arg1)
 }
 
 // CheckRuntimeVersion mocks base method.
@@ -156,15 +159,18 @@ func (mr *MockInstanceMockRecorder) FinalizeBlock() *gomock.Call {
 }
 
 // GenerateSessionKeys mocks base method.
-func (m *MockInstance) GenerateSessionKeys() {
+func (m *MockInstance) GenerateSessionKeys(arg0 *[]byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "GenerateSessionKeys")
+	ret := m.ctrl.Call(m, "GenerateSessionKeys", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GenerateSessionKeys indicates an expected call of GenerateSessionKeys.
-func (mr *MockInstanceMockRecorder) GenerateSessionKeys() *gomock.Call {
+func (mr *MockInstanceMockRecorder) GenerateSessionKeys(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSessionKeys", reflect.TypeOf((*MockInstance)(nil).GenerateSessionKeys))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSessionKeys", reflect.TypeOf((*MockInstance)(nil).GenerateSessionKeys), arg0)
 }
 
 // GetCodeHash mocks base method.
@@ -181,6 +187,20 @@ func (mr *MockInstanceMockRecorder) GetCodeHash() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeHash", reflect.TypeOf((*MockInstance)(nil).GetCodeHash))
 }
 
+// GetHeapPages mocks base method.
+func (m *MockInstance) GetHeapPages() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeapPages")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetHeapPages indicates an expected call of GetHeapPages.
+func (mr *MockInstanceMockRecorder) GetHeapPages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeapPages", reflect.TypeOf((*MockInstance)(nil).GetHeapPages))
+}
+
 // GrandpaAuthorities mocks base method.
 func (m *MockInstance) GrandpaAuthorities() ([]types.Authority, error) {
 	m.ctrl.T.Helper()
@@ -360,18 +380,18 @@ func (mr *MockInstanceMockRecorder) UpdateRuntimeCode(arg0 interface{}) *gomock.
 }
 
 // ValidateTransaction mocks base method.
-func (m *MockInstance) ValidateTransaction(arg0 types.Extrinsic) (*transaction.Validity, error) {
+func (m *MockInstance) ValidateTransaction(arg0 types.Extrinsic, arg1 common.Hash) (*transaction.Validity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ValidateTransaction", arg0)
+	ret := m.ctrl.Call(m, "ValidateTransaction", arg0, arg1)
 	ret0, _ := ret[0].(*transaction.Validity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ValidateTransaction indicates an expected call of ValidateTransaction.
-func (mr *MockInstanceMockRecorder) ValidateTransaction(arg0 interface{}) *gomock.Call {
+func (mr *MockInstanceMockRecorder) ValidateTransaction(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTransaction", reflect.TypeOf((*MockInstance)(nil).ValidateTransaction), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTransaction", reflect.TypeOf((*MockInstance)(nil).ValidateTransaction), arg0, arg1)
 }
 
 // Validator mocks base method.
@@ -14,6 +14,19 @@
 	"github.com/stretchr/testify/require"
 )
 
+func TestNewKeypairFromMnenomic(t *testing.T) {
+	mnemonic := "twist sausage october vivid neglect swear crumble hawk beauty fabric egg fragile"
+	kp, err := NewKeypairFromMnenomic(mnemonic, "")
+	require.NoError(t, err)
+
+	again, err := NewKeypairFromMnenomic(mnemonic, "")
+	require.NoError(t, err)
+	require.Equal(t, kp.Public().Encode(), again.Public().Encode())
+
+	_, err = NewKeypairFromMnenomic("not a mnemonic", "")
+	require.Error(t, err)
+}
+
 func TestSignAndVerify(t *testing.T) {
 	kp, err := GenerateKeypair()
 	if err != nil {
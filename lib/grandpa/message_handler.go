@@ -466,18 +466,26 @@ func (h *MessageHandler) verifyJustification(just *SignedVote, round, setID uint
 
 // VerifyBlockJustification verifies the finality justification for a block
 func (s *Service) VerifyBlockJustification(hash common.Hash, justification []byte) error {
+	return VerifyBlockJustification(s.blockState, s.grandpaState, hash, justification)
+}
+
+// VerifyBlockJustification verifies the finality justification for a block against
+// the authorities of the grandpa state and sets the block as finalised. It does not
+// require a running grandpa service, so blocks can be finalised offline.
+func VerifyBlockJustification(blockState BlockState, grandpaState GrandpaState,
+	hash common.Hash, justification []byte) error {
 	fj := Justification{}
 	err := scale.Unmarshal(justification, &fj)
 	if err != nil {
 		return err
 	}
 
-	setID, err := s.grandpaState.GetSetIDByBlockNumber(uint(fj.Commit.Number))
+	setID, err := grandpaState.GetSetIDByBlockNumber(uint(fj.Commit.Number))
 	if err != nil {
 		return fmt.Errorf("cannot get set ID from block number: %w", err)
 	}
 
-	has, err := s.blockState.HasFinalisedBlock(fj.Round, setID)
+	has, err := blockState.HasFinalisedBlock(fj.Round, setID)
 	if err != nil {
 		return err
 	}
@@ -486,7 +494,7 @@ func (s *Service) VerifyBlockJustification(hash common.Hash, justification []byt
 		return fmt.Errorf("already have finalised block with setID=%d and round=%d", setID, fj.Round)
 	}
 
-	auths, err := s.grandpaState.GetAuthorities(setID)
+	auths, err := grandpaState.GetAuthorities(setID)
 	if err != nil {
 		return fmt.Errorf("cannot get authorities for set ID: %w", err)
 	}
@@ -517,7 +525,7 @@ func (s *Service) VerifyBlockJustification(hash common.Hash, justification []byt
 		}
 
 		// check if vote was for descendant of committed block
-		isDescendant, err := s.blockState.IsDescendantOf(hash, just.Vote.Hash)
+		isDescendant, err := blockState.IsDescendantOf(hash, just.Vote.Hash)
 		if err != nil {
 			return err
 		}
@@ -562,7 +570,7 @@ func (s *Service) VerifyBlockJustification(hash common.Hash, justification []byt
 		return ErrMinVotesNotMet
 	}
 
-	err = s.blockState.SetFinalisedHash(hash, fj.Round, setID)
+	err = blockState.SetFinalisedHash(hash, fj.Round, setID)
 	if err != nil {
 		return err
 	}
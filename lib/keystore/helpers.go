@@ -57,6 +57,13 @@ func DecodeKeyPairFromHex(keystr []byte, keytype crypto.KeyType) (kp crypto.Keyp
 		kp, err = sr25519.NewKeypairFromSeed(keystr)
 	case crypto.Ed25519Type:
 		kp, err = ed25519.NewKeypairFromSeed(keystr)
+	case crypto.Secp256k1Type:
+		var priv *secp256k1.PrivateKey
+		priv, err = secp256k1.NewPrivateKey(keystr)
+		if err != nil {
+			return nil, err
+		}
+		kp, err = secp256k1.NewKeypairFromPrivate(priv)
 	default:
 		return nil, errors.New("cannot decode key: invalid key type")
 	}
@@ -64,6 +71,21 @@ func DecodeKeyPairFromHex(keystr []byte, keytype crypto.KeyType) (kp crypto.Keyp
 	return kp, err
 }
 
+// PublicKeysOfType returns the public keys of the keystore of the given crypto
+// scheme, since the keystores of some key types hold keys of any scheme.
+func PublicKeysOfType(ks Keystore, typ crypto.KeyType) (keys []crypto.PublicKey) {
+	if ks.Type() == typ {
+		return ks.PublicKeys()
+	}
+
+	for _, kp := range ks.Keypairs() {
+		if kp.Type() == typ {
+			keys = append(keys, kp.Public())
+		}
+	}
+	return keys
+}
+
 // GenerateKeypair create a new keypair with the corresponding type and saves
 // it to basepath/keystore/[public key].key in json format encrypted using the
 // specified password and returns the resulting filepath of the new key
@@ -290,22 +312,14 @@ func UnlockKeys(ks Keystore, dir, unlock, password string) error {
 // DetermineKeyType takes string as defined in https://github.com/w3f/PSPs/blob/psp-rpc-api/psp-002.md#Key-types
 //  and returns the crypto.KeyType
 func DetermineKeyType(t string) crypto.KeyType {
-	switch t {
-	case "babe":
-		return crypto.Sr25519Type
-	case "gran":
-		return crypto.Ed25519Type
-	case "acco":
-		return crypto.Sr25519Type
-	case "aura":
-		return crypto.Sr25519Type
-	case "imon":
-		return crypto.Sr25519Type
-	case "audi":
-		return crypto.Sr25519Type
-	case "dumy":
+	switch Name(t) {
+	case AccoName, DumyName:
 		return crypto.Sr25519Type
 	}
+
+	if typ, ok := keyTypeSchemes[Name(t)]; ok {
+		return typ
+	}
 	return crypto.UnknownType
 }
 
@@ -324,6 +338,10 @@ func HasKey(pubKeyStr, keyType string, keystore Keystore) (bool, error) {
 		pubKey, err = sr25519.NewPublicKey(keyBytes)
 	case crypto.Ed25519Type:
 		pubKey, err = ed25519.NewPublicKey(keyBytes)
+	case crypto.Secp256k1Type:
+		secpPubKey := new(secp256k1.PublicKey)
+		err = secpPubKey.Decode(keyBytes)
+		pubKey = secpPubKey
 	default:
 		err = fmt.Errorf("unknown key type: %s", keyType)
 	}
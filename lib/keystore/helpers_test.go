@@ -43,6 +43,9 @@ func TestLoadKeystore(t *testing.T) {
 	{testType: "aura", expectedType: crypto.Sr25519Type},
 	{testType: "imon", expectedType: crypto.Sr25519Type},
 	{testType: "audi", expectedType: crypto.Sr25519Type},
+	{testType: "para", expectedType: crypto.Sr25519Type},
+	{testType: "asgn", expectedType: crypto.Sr25519Type},
+	{testType: "beef", expectedType: crypto.Secp256k1Type},
 	{testType: "dumy", expectedType: crypto.Sr25519Type},
 	{testType: "xxxx", expectedType: crypto.UnknownType},
 }
@@ -4,10 +4,16 @@
 package keystore
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/utils"
 )
 
 var (
@@ -26,8 +32,33 @@
 	ImonName Name = "imon"
 	AudiName Name = "audi"
 	DumyName Name = "dumy"
+	ParaName Name = "para"
+	AsgnName Name = "asgn"
+	BeefName Name = "beef"
 )
 
+// keyTypeSchemes are the crypto schemes of the keys of the known key types.
+// The keys of the accounts and of the unknown key types can be of any scheme.
+var keyTypeSchemes = map[Name]crypto.KeyType{
+	BabeName: crypto.Sr25519Type,
+	GranName: crypto.Ed25519Type,
+	AuraName: crypto.Sr25519Type,
+	ImonName: crypto.Sr25519Type,
+	AudiName: crypto.Sr25519Type,
+	ParaName: crypto.Sr25519Type,
+	AsgnName: crypto.Sr25519Type,
+	BeefName: crypto.Secp256k1Type,
+}
+
+// NewKeystore returns a new keystore for the key type of the given name, holding the keys
+// of the crypto scheme of the key type, or the keys of any scheme if the key type is unknown.
+func NewKeystore(name Name) Keystore {
+	if typ, ok := keyTypeSchemes[name]; ok {
+		return NewBasicKeystore(name, typ)
+	}
+	return NewGenericKeystore(name)
+}
+
 // Keystore provides key management functionality
 type Keystore interface {
 	Name() Name
@@ -40,7 +71,9 @@ type Keystore interface {
 	Size() int
 }
 
-// GlobalKeystore defines the various keystores used by the node
+// GlobalKeystore defines the various keystores used by the node.
+// The keystores of the key types without a field, such as the key
+// types of custom pallets, are created when first requested.
 type GlobalKeystore struct {
 	Babe Keystore
 	Gran Keystore
@@ -49,23 +82,36 @@ type GlobalKeystore struct {
 	Imon Keystore
 	Audi Keystore
 	Dumy Keystore
+
+	keystores map[Name]Keystore
+	// basepath is the base path of the node the generated keys are
+	// stored under, encrypted with password, if it is not empty.
+	basepath string
+	password []byte
+	mutex    sync.Mutex
 }
 
 // NewGlobalKeystore returns a new GlobalKeystore
 func NewGlobalKeystore() *GlobalKeystore {
 	return &GlobalKeystore{
-		Babe: NewBasicKeystore(BabeName, crypto.Sr25519Type),
-		Gran: NewBasicKeystore(GranName, crypto.Ed25519Type),
-		Acco: NewGenericKeystore(AccoName), // TODO: which type is used? can an account be either type? (#1872)
-		Aura: NewBasicKeystore(AuraName, crypto.Sr25519Type),
-		Imon: NewBasicKeystore(ImonName, crypto.Sr25519Type),
-		Audi: NewBasicKeystore(AudiName, crypto.Sr25519Type),
+		Babe: NewKeystore(BabeName),
+		Gran: NewKeystore(GranName),
+		// accounts can be of any crypto scheme
+		Acco: NewGenericKeystore(AccoName),
+		Aura: NewKeystore(AuraName),
+		Imon: NewKeystore(ImonName),
+		Audi: NewKeystore(AudiName),
 		Dumy: NewGenericKeystore(DumyName),
 	}
 }
 
-// GetKeystore returns a keystore given its name
+// GetKeystore returns the keystore of the key type of the given 4 bytes name,
+// creating it with NewKeystore if the key type has no keystore yet.
 func (k *GlobalKeystore) GetKeystore(name []byte) (Keystore, error) {
+	if len(name) != 4 {
+		return nil, fmt.Errorf("%w: %q is not 4 bytes long", ErrInvalidKeystoreName, name)
+	}
+
 	nameStr := Name(name)
 	switch nameStr {
 	case BabeName:
@@ -82,7 +128,139 @@ func (k *GlobalKeystore) GetKeystore(name []byte) (Keystore, error) {
 		return k.Audi, nil
 	case DumyName:
 		return k.Dumy, nil
-	default:
-		return nil, ErrInvalidKeystoreName
 	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	ks, ok := k.keystores[nameStr]
+	if !ok {
+		if k.keystores == nil {
+			k.keystores = make(map[Name]Keystore)
+		}
+		ks = NewKeystore(nameStr)
+		k.keystores[nameStr] = ks
+	}
+	return ks, nil
+}
+
+// SetBasepath sets the base path of the node the keys generated by InsertGenerated are
+// stored under, in the directory of their key type in the keystore directory and
+// encrypted with the given password, so they can be loaded by LoadGenerated when the
+// node restarts. They are not in the keystore directory itself, so the indices of
+// the keys given to --unlock are not changed.
+func (k *GlobalKeystore) SetBasepath(basepath string, password []byte) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.basepath = basepath
+	k.password = password
+}
+
+// InsertGenerated inserts the given generated keypair into the given keystore,
+// and stores it under the base path of the node if one is set.
+func (k *GlobalKeystore) InsertGenerated(ks Keystore, kp crypto.Keypair) error {
+	err := ks.Insert(kp)
+	if err != nil {
+		return err
+	}
+
+	k.mutex.Lock()
+	basepath, password := k.basepath, k.password
+	k.mutex.Unlock()
+
+	if basepath == "" {
+		return nil
+	}
+
+	dir, err := generatedKeysDir(basepath, ks.Name())
+	if err != nil {
+		return fmt.Errorf("cannot store generated key: %w", err)
+	}
+
+	fp := filepath.Join(dir, hex.EncodeToString(kp.Public().Encode())+".key")
+	err = EncryptAndWriteToFile(fp, kp.Private(), password)
+	if err != nil {
+		return fmt.Errorf("cannot store generated key: %w", err)
+	}
+	return nil
+}
+
+// LoadGenerated inserts the keys stored by InsertGenerated under the base path
+// of the node into the keystores of their key type.
+func (k *GlobalKeystore) LoadGenerated() error {
+	k.mutex.Lock()
+	basepath, password := k.basepath, k.password
+	k.mutex.Unlock()
+
+	if basepath == "" {
+		return nil
+	}
+
+	keystoreDir, err := utils.KeystoreDir(basepath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(keystoreDir)
+	if err != nil {
+		return fmt.Errorf("cannot read keystore directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || len(entry.Name()) != 4 {
+			continue
+		}
+
+		ks, err := k.GetKeystore([]byte(entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Join(keystoreDir, entry.Name())
+		keyFiles, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("cannot read generated keys directory: %w", err)
+		}
+
+		for _, keyFile := range keyFiles {
+			if keyFile.IsDir() || filepath.Ext(keyFile.Name()) != ".key" {
+				continue
+			}
+
+			priv, err := ReadFromFileAndDecrypt(filepath.Join(dir, keyFile.Name()), password)
+			if err != nil {
+				return fmt.Errorf("cannot decrypt generated key file %s/%s: %w", entry.Name(), keyFile.Name(), err)
+			}
+
+			kp, err := PrivateKeyToKeypair(priv)
+			if err != nil {
+				return fmt.Errorf("cannot create keypair of generated key file %s/%s: %w",
+					entry.Name(), keyFile.Name(), err)
+			}
+
+			err = ks.Insert(kp)
+			if err != nil {
+				return fmt.Errorf("cannot insert generated key of file %s/%s: %w", entry.Name(), keyFile.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// generatedKeysDir returns the directory of the generated keys of
+// the given key type under the given base path, creating it if needed.
+func generatedKeysDir(basepath string, name Name) (string, error) {
+	keystoreDir, err := utils.KeystoreDir(basepath)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(keystoreDir, string(name))
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("cannot create generated keys directory: %w", err)
+	}
+	return dir, nil
 }
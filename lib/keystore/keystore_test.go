@@ -0,0 +1,199 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package keystore
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeystore(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		name    Name
+		keyType crypto.KeyType
+	}{
+		"sr25519 key type": {name: ParaName, keyType: crypto.Sr25519Type},
+		"ed25519 key type": {name: GranName, keyType: crypto.Ed25519Type},
+		"ecdsa key type":   {name: BeefName, keyType: crypto.Secp256k1Type},
+		"unknown key type": {name: "xxxx", keyType: crypto.UnknownType},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ks := NewKeystore(testCase.name)
+
+			assert.Equal(t, testCase.name, ks.Name())
+			assert.Equal(t, testCase.keyType, ks.Type())
+		})
+	}
+}
+
+func TestGlobalKeystore_GetKeystore(t *testing.T) {
+	t.Parallel()
+
+	gks := NewGlobalKeystore()
+
+	ks, err := gks.GetKeystore([]byte(BabeName))
+	require.NoError(t, err)
+	assert.Equal(t, gks.Babe, ks)
+
+	ks, err = gks.GetKeystore([]byte(AsgnName))
+	require.NoError(t, err)
+	assert.Equal(t, AsgnName, ks.Name())
+	assert.Equal(t, crypto.Sr25519Type, ks.Type())
+
+	kp, err := sr25519.GenerateKeypair()
+	require.NoError(t, err)
+	err = ks.Insert(kp)
+	require.NoError(t, err)
+
+	ks, err = gks.GetKeystore([]byte(AsgnName))
+	require.NoError(t, err)
+	assert.Equal(t, 1, ks.Size())
+
+	ks, err = gks.GetKeystore([]byte("xxxx"))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.UnknownType, ks.Type())
+
+	_, err = gks.GetKeystore([]byte("invalid"))
+	assert.ErrorIs(t, err, ErrInvalidKeystoreName)
+	assert.EqualError(t, err, `invalid keystore name: "invalid" is not 4 bytes long`)
+}
+
+func TestGlobalKeystore_InsertGenerated(t *testing.T) {
+	t.Parallel()
+
+	gks := NewGlobalKeystore()
+	ks, err := gks.GetKeystore([]byte(BeefName))
+	require.NoError(t, err)
+
+	// without base path, the generated keys are only kept in memory.
+	kp, err := secp256k1.GenerateKeypair()
+	require.NoError(t, err)
+	err = gks.InsertGenerated(ks, kp)
+	require.NoError(t, err)
+	assert.Equal(t, kp, ks.GetKeypair(kp.Public()))
+
+	basepath := t.TempDir()
+	password := []byte("password")
+	gks.SetBasepath(basepath, password)
+
+	kp, err = secp256k1.GenerateKeypair()
+	require.NoError(t, err)
+	err = gks.InsertGenerated(ks, kp)
+	require.NoError(t, err)
+	assert.Equal(t, kp, ks.GetKeypair(kp.Public()))
+
+	files, err := os.ReadDir(filepath.Join(basepath, "keystore", "beef"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, hex.EncodeToString(kp.Public().Encode())+".key", files[0].Name())
+
+	priv, err := ReadFromFileAndDecrypt(filepath.Join(basepath, "keystore", "beef", files[0].Name()), password)
+	require.NoError(t, err)
+	assert.Equal(t, kp.Private(), priv)
+
+	// the generated keys are not part of the keys indexed by --unlock.
+	keyFiles, err := utils.KeystoreFiles(basepath)
+	require.NoError(t, err)
+	assert.Empty(t, keyFiles)
+
+	// a keypair of the wrong crypto scheme is neither inserted nor stored.
+	edKp, err := ed25519.GenerateKeypair()
+	require.NoError(t, err)
+	err = gks.InsertGenerated(ks, edKp)
+	assert.Error(t, err)
+
+	files, err = os.ReadDir(filepath.Join(basepath, "keystore", "beef"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestGlobalKeystore_LoadGenerated(t *testing.T) {
+	t.Parallel()
+
+	basepath := t.TempDir()
+	password := []byte("password")
+
+	gks := NewGlobalKeystore()
+	gks.SetBasepath(basepath, password)
+
+	babeKp, err := sr25519.GenerateKeypair()
+	require.NoError(t, err)
+	err = gks.InsertGenerated(gks.Babe, babeKp)
+	require.NoError(t, err)
+
+	beefKs, err := gks.GetKeystore([]byte(BeefName))
+	require.NoError(t, err)
+	beefKp, err := secp256k1.GenerateKeypair()
+	require.NoError(t, err)
+	err = gks.InsertGenerated(beefKs, beefKp)
+	require.NoError(t, err)
+
+	// the keys of the key type directories are loaded into their keystores.
+	restarted := NewGlobalKeystore()
+	restarted.SetBasepath(basepath, password)
+	err = restarted.LoadGenerated()
+	require.NoError(t, err)
+
+	assert.Equal(t, babeKp.Public(), restarted.Babe.GetKeypair(babeKp.Public()).Public())
+	assert.Zero(t, restarted.Gran.Size())
+	restartedBeefKs, err := restarted.GetKeystore([]byte(BeefName))
+	require.NoError(t, err)
+	assert.Equal(t, beefKp, restartedBeefKs.GetKeypair(beefKp.Public()))
+
+	// without base path, no key is loaded.
+	unset := NewGlobalKeystore()
+	err = unset.LoadGenerated()
+	require.NoError(t, err)
+	assert.Zero(t, unset.Babe.Size())
+
+	// the keys cannot be loaded with another password.
+	wrongPassword := NewGlobalKeystore()
+	wrongPassword.SetBasepath(basepath, []byte("wrong"))
+	err = wrongPassword.LoadGenerated()
+	assert.ErrorContains(t, err, "cannot decrypt generated key file")
+}
+
+func TestPublicKeysOfType(t *testing.T) {
+	t.Parallel()
+
+	srKp, err := sr25519.GenerateKeypair()
+	require.NoError(t, err)
+	edKp, err := ed25519.GenerateKeypair()
+	require.NoError(t, err)
+	secpKp, err := secp256k1.GenerateKeypair()
+	require.NoError(t, err)
+
+	ks := NewGenericKeystore("test")
+	for _, kp := range []crypto.Keypair{srKp, edKp, secpKp} {
+		err = ks.Insert(kp)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []crypto.PublicKey{srKp.Public()}, PublicKeysOfType(ks, crypto.Sr25519Type))
+	assert.Equal(t, []crypto.PublicKey{edKp.Public()}, PublicKeysOfType(ks, crypto.Ed25519Type))
+	assert.Equal(t, []crypto.PublicKey{secpKp.Public()}, PublicKeysOfType(ks, crypto.Secp256k1Type))
+
+	basicKs := NewBasicKeystore("test", crypto.Sr25519Type)
+	err = basicKs.Insert(srKp)
+	require.NoError(t, err)
+
+	assert.Equal(t, []crypto.PublicKey{srKp.Public()}, PublicKeysOfType(basicKs, crypto.Sr25519Type))
+}
@@ -33,6 +33,10 @@ type FreeingBumpHeapAllocator struct {
 	maxHeapSize uint32
 	ptrOffset   uint32
 	totalSize   uint32
+	// usedSize is the size of the heap written by the allocations
+	// since the allocator creation or its last reset. It is not
+	// decreased by Clear, so Reset can zero all the heap written.
+	usedSize uint32
 }
 
 // NewAllocator Creates a new allocation heap which follows a freeing-bump strategy.
@@ -169,9 +173,28 @@ func (fbha *FreeingBumpHeapAllocator) Clear() {
 	}
 }
 
+// Reset frees all allocated memory like Clear, and zeroes the heap
+// written by the allocations, restoring the heap to its initial state.
+func (fbha *FreeingBumpHeapAllocator) Reset() {
+	heap := fbha.heap.Data()[fbha.ptrOffset:]
+	if int(fbha.usedSize) < len(heap) {
+		heap = heap[:fbha.usedSize]
+	}
+
+	for i := range heap {
+		heap[i] = 0
+	}
+
+	fbha.usedSize = 0
+	fbha.Clear()
+}
+
 func (fbha *FreeingBumpHeapAllocator) bump(qty uint32) uint32 {
 	res := fbha.bumper
 	fbha.bumper += qty
+	if fbha.bumper > fbha.usedSize {
+		fbha.usedSize = fbha.bumper
+	}
 	return res
 }
 
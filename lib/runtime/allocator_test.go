@@ -322,6 +322,36 @@ func compareState(allocator FreeingBumpHeapAllocator, state allocatorState,
 	}
 }
 
+// test that resetting the allocator zeroes the heap written since its creation,
+// including the allocations already freed by clearing the allocator
+func TestShouldZeroHeapOnReset(t *testing.T) {
+	mem := newMemoryMock(1 << 16)
+	const ptrOffset = 16
+	fbha := NewAllocator(mem, ptrOffset)
+
+	ptr, err := fbha.Allocate(8)
+	require.NoError(t, err)
+	copy(mem.Data()[ptr:], []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	fbha.Clear()
+
+	ptr, err = fbha.Allocate(16)
+	require.NoError(t, err)
+	copy(mem.Data()[ptr:], []byte{9, 10, 11})
+
+	// the memory before the pointer offset is not part of the heap
+	mem.Data()[ptrOffset-1] = 1
+
+	fbha.Reset()
+
+	expected := make([]byte, 1<<16)
+	expected[ptrOffset-1] = 1
+	require.Equal(t, expected, mem.Data())
+	require.Equal(t, uint32(0), fbha.bumper)
+	require.Equal(t, uint32(0), fbha.totalSize)
+	require.Equal(t, uint32(0), fbha.usedSize)
+	require.Equal(t, [HeadsQty]uint32{}, fbha.heads)
+}
+
 // test that allocator should grow memory if the allocation request is larger than current size
 func TestShouldGrowMemory(t *testing.T) {
 	mem := newMemoryMock(1 << 16)
@@ -0,0 +1,132 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+var (
+	// ErrAPIMissing is returned when the runtime does not implement a runtime API required by the node.
+	ErrAPIMissing = errors.New("runtime API not implemented")
+	// ErrAPIVersionUnsupported is returned when the runtime implements
+	// a version of a runtime API the node does not support.
+	ErrAPIVersionUnsupported = errors.New("runtime API version not supported")
+	errEmptyExtrinsic        = errors.New("extrinsic is empty")
+	errAPINotRequired        = errors.New("runtime API is not required")
+)
+
+// Names of the runtime APIs called by the node.
+const (
+	CoreAPI                   = "Core"
+	MetadataAPI               = "Metadata"
+	BlockBuilderAPI           = "BlockBuilder"
+	TaggedTransactionQueueAPI = "TaggedTransactionQueue"
+	BabeAPI                   = "BabeApi"
+	GrandpaAPI                = "GrandpaApi"
+	SessionKeysAPI            = "SessionKeys"
+	TransactionPaymentAPI     = "TransactionPaymentApi"
+)
+
+// RequiredAPI is a runtime API called by the node, with the range of its versions the node supports.
+type RequiredAPI struct {
+	Name       string
+	MinVersion uint32
+	MaxVersion uint32
+	// Optional is true if the node can run without the runtime API,
+	// such as the APIs only called by RPC methods.
+	Optional bool
+}
+
+// RequiredAPIs are the runtime APIs called by the node. A runtime code is rejected
+// if it does not implement one of them, or implements an unsupported version of it.
+// The minimum versions are the ones of the kusama genesis runtime, spec version 1020.
+var RequiredAPIs = []RequiredAPI{
+	{Name: CoreAPI, MinVersion: 2, MaxVersion: 4},
+	{Name: MetadataAPI, MinVersion: 1, MaxVersion: 1},
+	{Name: BlockBuilderAPI, MinVersion: 4, MaxVersion: 5},
+	{Name: TaggedTransactionQueueAPI, MinVersion: 1, MaxVersion: 3},
+	{Name: BabeAPI, MinVersion: 1, MaxVersion: 2},
+	{Name: GrandpaAPI, MinVersion: 2, MaxVersion: 3},
+	{Name: SessionKeysAPI, MinVersion: 1, MaxVersion: 1},
+	{Name: TransactionPaymentAPI, MinVersion: 1, MaxVersion: 1, Optional: true},
+}
+
+// Check returns an error if the runtime of the given version
+// does not implement a supported version of the runtime API.
+func (r RequiredAPI) Check(version Version) error {
+	apiVersion, ok := APIVersion(version, r.Name)
+	if !ok {
+		if r.Optional {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrAPIMissing, r.Name)
+	}
+
+	if apiVersion < r.MinVersion || apiVersion > r.MaxVersion {
+		return fmt.Errorf("%w: %s version %d is not between versions %d and %d",
+			ErrAPIVersionUnsupported, r.Name, apiVersion, r.MinVersion, r.MaxVersion)
+	}
+
+	return nil
+}
+
+// CheckRequiredAPIs returns an error if the runtime of the given
+// version does not implement a supported version of the required APIs.
+func CheckRequiredAPIs(version Version) error {
+	for _, api := range RequiredAPIs {
+		if err := api.Check(version); err != nil {
+			return fmt.Errorf("runtime %s version %d: %w", version.SpecName(), version.SpecVersion(), err)
+		}
+	}
+	return nil
+}
+
+// requiredAPI returns the required runtime API with the given name.
+func requiredAPI(name string) (RequiredAPI, error) {
+	for _, api := range RequiredAPIs {
+		if api.Name == name {
+			return api, nil
+		}
+	}
+	return RequiredAPI{}, fmt.Errorf("%w: %s", errAPINotRequired, name)
+}
+
+// ValidateTransactionArgs returns the arguments of TaggedTransactionQueue_validate_transaction for
+// the version of the API implemented by the runtime of the given version, from the extrinsic
+// prefixed with its transaction source and the hash of the block the extrinsic is validated at.
+// The version 1 of the API takes the extrinsic only, the version 2 takes the transaction source
+// as well, and the version 3 takes the block hash as well.
+func ValidateTransactionArgs(version Version, e types.Extrinsic, blockHash common.Hash) ([]byte, error) {
+	api, err := requiredAPI(TaggedTransactionQueueAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.Check(version); err != nil {
+		return nil, err
+	}
+
+	if len(e) == 0 {
+		return nil, errEmptyExtrinsic
+	}
+
+	apiVersion, _ := APIVersion(version, TaggedTransactionQueueAPI)
+	switch apiVersion {
+	case 1:
+		return e[1:], nil
+	case 2:
+		return e, nil
+	case 3:
+		args := make([]byte, 0, len(e)+len(blockHash))
+		args = append(args, e...)
+		return append(args, blockHash[:]...), nil
+	default:
+		return nil, fmt.Errorf("%w: %s version %d", ErrAPIVersionUnsupported, TaggedTransactionQueueAPI, apiVersion)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAPIVersion(apis map[string]uint32) Version {
+	apiItems := make([]APIItem, 0, len(apis))
+	for name, version := range apis {
+		apiItems = append(apiItems, APIItem{Name: APIID(name), Ver: version})
+	}
+	return NewVersionData([]byte("node"), nil, 0, 1, 0, apiItems, 0)
+}
+
+func TestRequiredAPI_Check(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		api        RequiredAPI
+		version    Version
+		errWrapped error
+		errMessage string
+	}{
+		"supported version": {
+			api:     RequiredAPI{Name: CoreAPI, MinVersion: 3, MaxVersion: 4},
+			version: newTestAPIVersion(map[string]uint32{CoreAPI: 4}),
+		},
+		"missing": {
+			api:        RequiredAPI{Name: CoreAPI, MinVersion: 3, MaxVersion: 4},
+			version:    newTestAPIVersion(map[string]uint32{MetadataAPI: 1}),
+			errWrapped: ErrAPIMissing,
+			errMessage: "runtime API not implemented: Core",
+		},
+		"optional missing": {
+			api:     RequiredAPI{Name: TransactionPaymentAPI, MinVersion: 1, MaxVersion: 1, Optional: true},
+			version: newTestAPIVersion(nil),
+		},
+		"version too old": {
+			api:        RequiredAPI{Name: CoreAPI, MinVersion: 3, MaxVersion: 4},
+			version:    newTestAPIVersion(map[string]uint32{CoreAPI: 2}),
+			errWrapped: ErrAPIVersionUnsupported,
+			errMessage: "runtime API version not supported: Core version 2 is not between versions 3 and 4",
+		},
+		"optional version too new": {
+			api:        RequiredAPI{Name: TransactionPaymentAPI, MinVersion: 1, MaxVersion: 1, Optional: true},
+			version:    newTestAPIVersion(map[string]uint32{TransactionPaymentAPI: 2}),
+			errWrapped: ErrAPIVersionUnsupported,
+			errMessage: "runtime API version not supported: " +
+				"TransactionPaymentApi version 2 is not between versions 1 and 1",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.api.Check(testCase.version)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+func TestCheckRequiredAPIs(t *testing.T) {
+	t.Parallel()
+
+	apis := make(map[string]uint32, len(RequiredAPIs))
+	for _, api := range RequiredAPIs {
+		if !api.Optional {
+			apis[api.Name] = api.MaxVersion
+		}
+	}
+
+	err := CheckRequiredAPIs(newTestAPIVersion(apis))
+	assert.NoError(t, err)
+
+	for _, api := range RequiredAPIs {
+		if !api.Optional {
+			apis[api.Name] = api.MinVersion
+		}
+	}
+
+	err = CheckRequiredAPIs(newTestAPIVersion(apis))
+	assert.NoError(t, err)
+
+	apis[BabeAPI] = 3
+	err = CheckRequiredAPIs(newTestAPIVersion(apis))
+	assert.ErrorIs(t, err, ErrAPIVersionUnsupported)
+	assert.EqualError(t, err, "runtime node version 1: runtime API version not supported: "+
+		"BabeApi version 3 is not between versions 1 and 2")
+}
+
+func Test_requiredAPI(t *testing.T) {
+	t.Parallel()
+
+	api, err := requiredAPI(BabeAPI)
+	assert.NoError(t, err)
+	assert.Equal(t, BabeAPI, api.Name)
+
+	_, err = requiredAPI("Unknown")
+	assert.ErrorIs(t, err, errAPINotRequired)
+	assert.EqualError(t, err, "runtime API is not required: Unknown")
+}
+
+func TestValidateTransactionArgs(t *testing.T) {
+	t.Parallel()
+
+	extrinsic := types.Extrinsic{byte(types.TxnExternal), 1, 2}
+	blockHash := common.Hash{3}
+
+	testCases := map[string]struct {
+		version    Version
+		extrinsic  types.Extrinsic
+		args       []byte
+		errWrapped error
+		errMessage string
+	}{
+		"version 1": {
+			version:   newTestAPIVersion(map[string]uint32{TaggedTransactionQueueAPI: 1}),
+			extrinsic: extrinsic,
+			args:      []byte{1, 2},
+		},
+		"version 2": {
+			version:   newTestAPIVersion(map[string]uint32{TaggedTransactionQueueAPI: 2}),
+			extrinsic: extrinsic,
+			args:      []byte{byte(types.TxnExternal), 1, 2},
+		},
+		"version 3": {
+			version:   newTestAPIVersion(map[string]uint32{TaggedTransactionQueueAPI: 3}),
+			extrinsic: extrinsic,
+			args:      append([]byte{byte(types.TxnExternal), 1, 2}, blockHash[:]...),
+		},
+		"unsupported version": {
+			version:    newTestAPIVersion(map[string]uint32{TaggedTransactionQueueAPI: 4}),
+			extrinsic:  extrinsic,
+			errWrapped: ErrAPIVersionUnsupported,
+			errMessage: "runtime API version not supported: " +
+				"TaggedTransactionQueue version 4 is not between versions 1 and 3",
+		},
+		"missing": {
+			version:    newTestAPIVersion(nil),
+			extrinsic:  extrinsic,
+			errWrapped: ErrAPIMissing,
+			errMessage: "runtime API not implemented: TaggedTransactionQueue",
+		},
+		"empty extrinsic": {
+			version:    newTestAPIVersion(map[string]uint32{TaggedTransactionQueueAPI: 2}),
+			errWrapped: errEmptyExtrinsic,
+			errMessage: "extrinsic is empty",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			args, err := ValidateTransactionArgs(testCase.version, testCase.extrinsic, blockHash)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.args, args)
+		})
+	}
+}
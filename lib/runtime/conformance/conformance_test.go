@@ -0,0 +1,375 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package conformance
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/life"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend is a runtime implementation under test.
+type backend struct {
+	name        string
+	newInstance runtime.NewInstanceFunc
+	// fullHostAPI is true if the backend implements all the host functions.
+	fullHostAPI bool
+}
+
+var backends = []backend{
+	{
+		name:        wasmer.Name,
+		newInstance: wasmer.NewRuntimeInstance,
+		fullHostAPI: true,
+	},
+	{
+		name:        wazero.Name,
+		newInstance: wazero.NewRuntimeInstance,
+		fullHostAPI: true,
+	},
+	{
+		name:        life.Name,
+		newInstance: life.NewRuntimeInstance,
+	},
+}
+
+// call is a runtime call whose output and resulting state root
+// must be the same on all the backends.
+type call struct {
+	// setup sets the storage before the call, and may be nil.
+	setup    func(t *testing.T, s *storage.TrieState)
+	function string
+	data     []byte
+}
+
+// result is the outcome of a call on a backend.
+type result struct {
+	output []byte
+	err    error
+	root   common.Hash
+}
+
+// runCall runs the call on a new instance of the given backend with the given code.
+func runCall(t *testing.T, b backend, code []byte, c call) result {
+	t.Helper()
+
+	s, err := storage.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, err)
+	if c.setup != nil {
+		c.setup(t, s)
+	}
+
+	cfg := runtime.InstanceConfig{
+		Storage:  s,
+		Keystore: keystore.NewGlobalKeystore(),
+		LogLvl:   log.Critical,
+		NodeStorage: runtime.NodeStorage{
+			LocalStorage:      runtime.NewInMemoryDB(t),
+			PersistentStorage: runtime.NewInMemoryDB(t),
+			BaseDB:            runtime.NewInMemoryDB(t),
+		},
+		Network: new(runtime.TestRuntimeNetwork),
+	}
+
+	instance, err := b.newInstance(code, cfg)
+	require.NoError(t, err, "backend %s", b.name)
+	defer instance.Stop()
+
+	output, err := instance.Exec(c.function, c.data)
+
+	root, rootErr := s.Root()
+	require.NoError(t, rootErr)
+
+	return result{
+		output: output,
+		err:    err,
+		root:   root,
+	}
+}
+
+// assertConformance runs the calls given on all the backends selected and
+// asserts their outputs, errors and resulting state roots are the same.
+func assertConformance(t *testing.T, targetRuntime string, fullHostAPI bool, calls map[string]call) {
+	t.Helper()
+
+	// the test runtimes are not part of the repository and are downloaded
+	// if they are missing, so skip the test if they cannot be downloaded.
+	testRuntimeFilePath, testRuntimeURL := runtime.GetRuntimeVars(targetRuntime)
+	err := runtime.GetRuntimeBlob(testRuntimeFilePath, testRuntimeURL)
+	if err != nil {
+		t.Skipf("test runtime %s is not available: %s", targetRuntime, err)
+	}
+
+	code, err := os.ReadFile(testRuntimeFilePath)
+	require.NoError(t, err)
+
+	assertCodeConformance(t, code, fullHostAPI, calls)
+}
+
+// assertCodeConformance runs the calls given with the code given on all the
+// backends selected and asserts their outputs, errors and resulting state
+// roots are the same.
+func assertCodeConformance(t *testing.T, code []byte, fullHostAPI bool, calls map[string]call) {
+	t.Helper()
+
+	// Subtests are not run in parallel since the life backend
+	// keeps its runtime context in a package variable.
+	for name, c := range calls {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			var reference *result
+			var referenceName string
+			for _, b := range backends {
+				if fullHostAPI && !b.fullHostAPI {
+					continue
+				}
+
+				r := runCall(t, b, code, c)
+				if reference == nil {
+					reference, referenceName = &r, b.name
+					continue
+				}
+
+				assert.Equal(t, reference.output, r.output,
+					"output of %s differs from %s", b.name, referenceName)
+				assert.Equal(t, reference.err == nil, r.err == nil,
+					"error of %s (%v) differs from %s (%v)", b.name, r.err, referenceName, reference.err)
+				assert.Equal(t, reference.root, r.root,
+					"state root of %s differs from %s", b.name, referenceName)
+			}
+		})
+	}
+}
+
+// encode concatenates the SCALE encoding of the values given.
+func encode(t *testing.T, values ...interface{}) (encoded []byte) {
+	t.Helper()
+
+	for _, value := range values {
+		b, err := scale.Marshal(value)
+		require.NoError(t, err)
+		encoded = append(encoded, b...)
+	}
+	return encoded
+}
+
+func Test_RuntimeAPI_NodeRuntime(t *testing.T) {
+	header := types.NewEmptyHeader()
+	header.Number = 1
+	encodedHeader, err := scale.Marshal(*header)
+	require.NoError(t, err)
+
+	calls := map[string]call{
+		"version": {
+			function: runtime.CoreVersion,
+		},
+		"metadata": {
+			function: runtime.Metadata,
+		},
+		"babe configuration": {
+			function: runtime.BabeAPIConfiguration,
+		},
+		"grandpa authorities": {
+			function: runtime.GrandpaAuthorities,
+		},
+		"initialize block": {
+			function: runtime.CoreInitializeBlock,
+			data:     encodedHeader,
+		},
+	}
+
+	assertConformance(t, runtime.NODE_RUNTIME, false, calls)
+}
+
+func Test_HostAPI(t *testing.T) {
+	key, value := []byte("noot"), []byte("washere")
+	childKey := []byte("child")
+
+	setEntries := func(t *testing.T, s *storage.TrieState) {
+		s.Set(key, value)
+		s.Set([]byte("nootnoot"), []byte("washere too"))
+		s.Set([]byte("other"), []byte("value"))
+	}
+
+	setChild := func(t *testing.T, s *storage.TrieState) {
+		err := s.SetChild(childKey, trie.NewEmptyTrie())
+		require.NoError(t, err)
+		err = s.SetChildStorage(childKey, key, value)
+		require.NoError(t, err)
+	}
+
+	limit := make([]byte, 4)
+	binary.LittleEndian.PutUint32(limit, 1)
+
+	calls := map[string]call{
+		"blake2 128": {
+			function: "rtm_ext_hashing_blake2_128_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"blake2 256": {
+			function: "rtm_ext_hashing_blake2_256_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"keccak 256": {
+			function: "rtm_ext_hashing_keccak_256_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"sha2 256": {
+			function: "rtm_ext_hashing_sha2_256_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"twox 64": {
+			function: "rtm_ext_hashing_twox_64_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"twox 128": {
+			function: "rtm_ext_hashing_twox_128_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"storage set": {
+			function: "rtm_ext_storage_set_version_1",
+			data:     encode(t, key, value),
+		},
+		"storage get": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_get_version_1",
+			data:     encode(t, key),
+		},
+		"storage get missing key": {
+			function: "rtm_ext_storage_get_version_1",
+			data:     encode(t, key),
+		},
+		"storage read": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_read_version_1",
+			data:     encode(t, key, uint32(2), uint32(100)),
+		},
+		"storage exists": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_exists_version_1",
+			data:     encode(t, key),
+		},
+		"storage next key": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_next_key_version_1",
+			data:     encode(t, key),
+		},
+		"storage clear": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_clear_version_1",
+			data:     encode(t, key),
+		},
+		"storage clear prefix": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_clear_prefix_version_1",
+			data:     encode(t, []byte("noo")),
+		},
+		"storage clear prefix with limit": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_clear_prefix_version_2",
+			data:     encode(t, []byte("noo"), &limit),
+		},
+		"storage append": {
+			function: "rtm_ext_storage_append_version_1",
+			data:     encode(t, key, encode(t, value)),
+		},
+		"storage root": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_root_version_1",
+		},
+		"child storage set": {
+			setup:    setChild,
+			function: "rtm_ext_default_child_storage_set_version_1",
+			data:     encode(t, childKey, []byte("key"), value),
+		},
+		"child storage get": {
+			setup:    setChild,
+			function: "rtm_ext_default_child_storage_get_version_1",
+			data:     encode(t, childKey, key),
+		},
+		"child storage root": {
+			setup:    setChild,
+			function: "rtm_ext_default_child_storage_root_version_1",
+			data:     encode(t, childKey),
+		},
+		"trie root": {
+			function: "rtm_ext_trie_blake2_256_root_version_1",
+			data: encode(t, []struct{ Key, Value []byte }{
+				{Key: []byte("noot"), Value: []byte("was")},
+				{Key: []byte("here"), Value: []byte("??")},
+			}),
+		},
+		"trie ordered root": {
+			function: "rtm_ext_trie_blake2_256_ordered_root_version_1",
+			data:     encode(t, [][]byte{[]byte("static"), []byte("even-keeled"), []byte("Future-proofed")}),
+		},
+	}
+
+	assertConformance(t, runtime.HOST_API_TEST_RUNTIME, true, calls)
+}
+
+func Test_HostAPI_Testdata(t *testing.T) {
+	// testdata/hostapi.wasm is built from testdata/hostapi.wat and is part of
+	// the repository, so the backends are always compared on these calls.
+	code, err := os.ReadFile(filepath.Join("testdata", "hostapi.wasm"))
+	require.NoError(t, err)
+
+	key, value := []byte("noot"), []byte("washere")
+
+	setEntries := func(t *testing.T, s *storage.TrieState) {
+		s.Set(key, value)
+		s.Set([]byte("nootnoot"), []byte("washere too"))
+		s.Set([]byte("other"), []byte("value"))
+	}
+
+	calls := map[string]call{
+		"blake2 256": {
+			function: "rtm_ext_hashing_blake2_256_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"twox 128": {
+			function: "rtm_ext_hashing_twox_128_version_1",
+			data:     encode(t, []byte("helloworld")),
+		},
+		"storage set": {
+			function: "rtm_ext_storage_set_version_1",
+			data:     encode(t, key, value),
+		},
+		"storage get": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_get_version_1",
+			data:     encode(t, key),
+		},
+		"storage get missing key": {
+			function: "rtm_ext_storage_get_version_1",
+			data:     encode(t, key),
+		},
+		"storage clear prefix": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_clear_prefix_version_1",
+			data:     encode(t, []byte("noo")),
+		},
+		"storage root": {
+			setup:    setEntries,
+			function: "rtm_ext_storage_root_version_1",
+		},
+	}
+
+	assertCodeConformance(t, code, false, calls)
+}
@@ -0,0 +1,6 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package conformance contains the tests running the same runtime calls
+// on all the runtime backends and comparing their outputs.
+package conformance
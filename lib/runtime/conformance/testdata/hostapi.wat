@@ -0,0 +1,59 @@
+;; Minimal runtime calling a few host functions implemented by all the
+;; backends, so their conformance can be tested without downloading a runtime.
+;; Its inputs are SCALE encoded byte arrays shorter than 64 bytes, whose
+;; compact length is a single byte. hostapi.wasm is the binary of this module.
+(module
+  (type $hash (func (param i64) (result i32)))
+  (type $set (func (param i64 i64)))
+  (type $get (func (param i64) (result i64)))
+  (type $clear (func (param i64)))
+  (type $root (func (result i64)))
+  (type $call (func (param i32 i32) (result i64)))
+
+  (import "env" "memory" (memory 2))
+  (import "env" "ext_hashing_blake2_256_version_1" (func $blake2_256 (type $hash)))
+  (import "env" "ext_hashing_twox_128_version_1" (func $twox_128 (type $hash)))
+  (import "env" "ext_storage_set_version_1" (func $storage_set (type $set)))
+  (import "env" "ext_storage_get_version_1" (func $storage_get (type $get)))
+  (import "env" "ext_storage_clear_prefix_version_1" (func $storage_clear_prefix (type $clear)))
+  (import "env" "ext_storage_root_version_1" (func $storage_root (type $root)))
+
+  ;; $bytes returns the pointer-size of the byte array encoded at ptr.
+  (func $bytes (param $ptr i32) (result i64)
+    (i64.or
+      (i64.shl
+        (i64.extend_i32_u (i32.shr_u (i32.load8_u (local.get $ptr)) (i32.const 2)))
+        (i64.const 32))
+      (i64.extend_i32_u (i32.add (local.get $ptr) (i32.const 1)))))
+
+  ;; $next returns the pointer following the byte array encoded at ptr.
+  (func $next (param $ptr i32) (result i32)
+    (i32.add
+      (i32.add (local.get $ptr) (i32.const 1))
+      (i32.shr_u (i32.load8_u (local.get $ptr)) (i32.const 2))))
+
+  (func (export "rtm_ext_hashing_blake2_256_version_1") (type $call)
+    (i64.or
+      (i64.const 0x2000000000)
+      (i64.extend_i32_u (call $blake2_256 (call $bytes (local.get 0))))))
+
+  (func (export "rtm_ext_hashing_twox_128_version_1") (type $call)
+    (i64.or
+      (i64.const 0x1000000000)
+      (i64.extend_i32_u (call $twox_128 (call $bytes (local.get 0))))))
+
+  (func (export "rtm_ext_storage_set_version_1") (type $call)
+    (call $storage_set
+      (call $bytes (local.get 0))
+      (call $bytes (call $next (local.get 0))))
+    (i64.const 0))
+
+  (func (export "rtm_ext_storage_get_version_1") (type $call)
+    (call $storage_get (call $bytes (local.get 0))))
+
+  (func (export "rtm_ext_storage_clear_prefix_version_1") (type $call)
+    (call $storage_clear_prefix (call $bytes (local.get 0)))
+    (i64.const 0))
+
+  (func (export "rtm_ext_storage_root_version_1") (type $call)
+    (call $storage_root)))
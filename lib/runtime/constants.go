@@ -68,10 +68,17 @@
 	BlockBuilderApplyExtrinsic = "BlockBuilder_apply_extrinsic"
 	// BlockBuilderFinalizeBlock is the runtime API call BlockBuilder_finalize_block
 	BlockBuilderFinalizeBlock = "BlockBuilder_finalize_block"
+	// BlockBuilderCheckInherents is the runtime API call BlockBuilder_check_inherents
+	BlockBuilderCheckInherents = "BlockBuilder_check_inherents"
 	// DecodeSessionKeys is the runtime API call SessionKeys_decode_session_keys
 	DecodeSessionKeys = "SessionKeys_decode_session_keys"
+	// SessionKeysGenerateSessionKeys is the runtime API call SessionKeys_generate_session_keys
+	SessionKeysGenerateSessionKeys = "SessionKeys_generate_session_keys"
 	// TransactionPaymentAPIQueryInfo returns information of a given extrinsic
 	TransactionPaymentAPIQueryInfo = "TransactionPaymentApi_query_info"
+	// TryRuntimeOnRuntimeUpgrade is the runtime API call TryRuntime_on_runtime_upgrade,
+	// only exported by the runtimes built with the try-runtime feature
+	TryRuntimeOnRuntimeUpgrade = "TryRuntime_on_runtime_upgrade"
 )
 
 // GrandpaAuthoritiesKey is the location of GRANDPA authority data
@@ -11,6 +11,11 @@
 	"github.com/ChainSafe/gossamer/lib/trie"
 )
 
+// NewInstanceFunc creates a runtime instance of the given code with the given configuration,
+// and is implemented by each wasm interpreter so the node can create instances of the
+// interpreter it is configured with.
+type NewInstanceFunc func(code []byte, cfg InstanceConfig) (Instance, error)
+
 //go:generate mockery --name Instance --structname Instance --case underscore --keeptree
 
 // Instance is the interface a v0.8 runtime instance must implement
@@ -26,25 +31,25 @@ type Instance interface {
 	SetContextStorage(s Storage) // used to set the TrieState before a runtime call
 
 	GetCodeHash() common.Hash
+	GetHeapPages() uint64
 	Version() (Version, error)
 	Metadata() ([]byte, error)
 	BabeConfiguration() (*types.BabeConfiguration, error)
 	GrandpaAuthorities() ([]types.Authority, error)
-	ValidateTransaction(e types.Extrinsic) (*transaction.Validity, error)
+	ValidateTransaction(e types.Extrinsic, blockHash common.Hash) (*transaction.Validity, error)
 	InitializeBlock(header *types.Header) error
 	InherentExtrinsics(data []byte) ([]byte, error)
 	ApplyExtrinsic(data types.Extrinsic) ([]byte, error)
 	FinalizeBlock() (*types.Header, error)
+	CheckInherents(block *types.Block, inherentsData []byte) (*types.CheckInherentsResult, error)
 	ExecuteBlock(block *types.Block) ([]byte, error)
 	DecodeSessionKeys(enc []byte) ([]byte, error)
+	GenerateSessionKeys(seed *[]byte) ([]byte, error)
 	PaymentQueryInfo(ext []byte) (*types.TransactionPaymentQueryInfo, error)
 
-	CheckInherents() // TODO: use this in block verification process (#1873)
-
 	// parameters and return values for these are undefined in the spec
 	RandomSeed()
 	OffchainWorker()
-	GenerateSessionKeys()
 }
 
 // Storage interface
@@ -71,6 +76,33 @@ type Storage interface {
 	LoadCode() []byte
 }
 
+// FallibleStorage is implemented by storages which can fail to load the
+// state accessed by the runtime, such as the storages of tries loaded
+// lazily from the database. Err returns the first failure, if any.
+type FallibleStorage interface {
+	Err() error
+}
+
+// LogTargetTracer is implemented by storages attaching the target of
+// the last runtime log message to the storage accesses they record.
+type LogTargetTracer interface {
+	SetTarget(target string)
+}
+
+// SpanTracer is implemented by storages recording the tracing spans
+// entered by the runtime along with the storage accesses they record.
+type SpanTracer interface {
+	EnterSpan(id, parentID uint64, target, name string)
+	ExitSpan(id uint64)
+}
+
+// TransactionIndexer is implemented by storages recording the
+// transactions indexed by the runtime during the execution of a block.
+type TransactionIndexer interface {
+	IndexTransaction(extrinsic uint32, hash common.Hash, size uint32)
+	RenewTransaction(extrinsic uint32, hash common.Hash)
+}
+
 // BasicNetwork interface for functions used by runtime network state function
 type BasicNetwork interface {
 	NetworkState() common.NetworkState
@@ -9,15 +9,27 @@
 	"strings"
 
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
-// ValidateTransaction runs the extrinsic through runtime function
-// TaggedTransactionQueue_validate_transaction and returns *Validity
-func (in *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validity, error) {
-	ret, err := in.Exec(runtime.TaggedTransactionQueueValidateTransaction, e)
+// ValidateTransaction runs the extrinsic prefixed with its transaction source through runtime
+// function TaggedTransactionQueue_validate_transaction at the block of the given hash, with the
+// arguments of the version of the function implemented by the runtime, and returns *Validity
+func (in *Instance) ValidateTransaction(e types.Extrinsic, blockHash common.Hash) (*transaction.Validity, error) {
+	version, err := in.Version()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runtime version: %w", err)
+	}
+
+	args, err := runtime.ValidateTransactionArgs(version, e, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := in.Exec(runtime.TaggedTransactionQueueValidateTransaction, args)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +142,39 @@ func (in *Instance) FinalizeBlock() (*types.Header, error) {
 
 // ExecuteBlock calls runtime function Core_execute_block
 func (in *Instance) ExecuteBlock(block *types.Block) ([]byte, error) {
+	bdEnc, err := encodeBlockWithoutSeal(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return in.Exec(runtime.CoreExecuteBlock, bdEnc)
+}
+
+// CheckInherents calls runtime API function BlockBuilder_check_inherents
+// with the given block and the given encoded inherents data.
+func (in *Instance) CheckInherents(block *types.Block, inherentsData []byte) (*types.CheckInherentsResult, error) {
+	bdEnc, err := encodeBlockWithoutSeal(block)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := in.Exec(runtime.BlockBuilderCheckInherents, append(bdEnc, inherentsData...))
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(types.CheckInherentsResult)
+	err = scale.Unmarshal(data, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// encodeBlockWithoutSeal encodes the given block without the seal digest,
+// as the block is given to the runtime before the seal is added.
+func encodeBlockWithoutSeal(block *types.Block) ([]byte, error) {
 	// copy block since we're going to modify it
 	b, err := block.DeepCopy()
 	if err != nil {
@@ -151,12 +196,7 @@ func (in *Instance) ExecuteBlock(block *types.Block) ([]byte, error) {
 		}
 	}
 
-	bdEnc, err := b.Encode()
-	if err != nil {
-		return nil, err
-	}
-
-	return in.Exec(runtime.CoreExecuteBlock, bdEnc)
+	return b.Encode()
 }
 
 // DecodeSessionKeys decodes the given public session keys. Returns a list of raw public keys including their key type.
@@ -170,7 +210,27 @@ func (*Instance) PaymentQueryInfo([]byte) (*types.TransactionPaymentQueryInfo, e
 	return nil, errors.New("not implemented yet")
 }
 
-func (in *Instance) CheckInherents()      {} //nolint:revive
-func (in *Instance) RandomSeed()          {} //nolint:revive
-func (in *Instance) OffchainWorker()      {} //nolint:revive
-func (in *Instance) GenerateSessionKeys() {} //nolint:revive
+// GenerateSessionKeys generates a key in the keystore for each key type of the session keys of the runtime,
+// from the given optional seed, and returns the SCALE encoded session keys made of their public keys.
+func (in *Instance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	encSeed, err := scale.Marshal(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := in.Exec(runtime.SessionKeysGenerateSessionKeys, encSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []byte
+	err = scale.Unmarshal(ret, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (in *Instance) RandomSeed()     {} //nolint:revive
+func (in *Instance) OffchainWorker() {} //nolint:revive
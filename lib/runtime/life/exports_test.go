@@ -72,6 +72,23 @@ func TestInstance_Version_NodeRuntime(t *testing.T) {
 	require.Equal(t, expected.TransactionVersion(), version.TransactionVersion())
 }
 
+func TestInstance_Profiler(t *testing.T) {
+	instance := newInstanceFromGenesis(t)
+
+	profiler := runtime.NewProfiler(false)
+	instance.(*Instance).SetProfiler(profiler)
+	defer instance.(*Instance).SetProfiler(nil)
+
+	_, err := instance.Version()
+	require.NoError(t, err)
+
+	hostFunctions := profiler.Profile()[runtime.CoreVersion]
+	require.NotEmpty(t, hostFunctions)
+	for _, hostFunction := range hostFunctions {
+		require.NotZero(t, hostFunction.Calls)
+	}
+}
+
 func TestInstance_BabeConfiguration_NodeRuntime_WithAuthorities(t *testing.T) {
 	instance := newInstanceFromGenesis(t)
 	cfg, err := instance.BabeConfiguration()
@@ -25,12 +25,14 @@
 
 // Check that runtime interfaces are satisfied
 var (
-	_      runtime.Instance = (*Instance)(nil)
-	logger                  = log.NewFromGlobal(
+	_      runtime.Instance           = (*Instance)(nil)
+	_      runtime.ProfilableInstance = (*Instance)(nil)
+	logger                            = log.NewFromGlobal(
 		log.AddContext("pkg", "runtime"),
 		log.AddContext("component", "perlin/life"),
 	)
-	ctx *runtime.Context
+	targetLoggers = runtime.NewTargetLoggers(logger)
+	ctx           *runtime.Context
 )
 
 // Config represents a life configuration
@@ -41,8 +43,9 @@ type Config struct {
 
 // Instance is a runtime life instance
 type Instance struct {
-	vm *exec.VirtualMachine
-	mu sync.Mutex
+	vm        *exec.VirtualMachine
+	mu        sync.Mutex
+	heapPages uint64
 }
 
 // GetCodeHash returns code hash of the runtime
@@ -50,6 +53,11 @@ func (*Instance) GetCodeHash() common.Hash {
 	return common.Hash{}
 }
 
+// GetHeapPages returns the number of heap pages of the memory of the instance
+func (in *Instance) GetHeapPages() uint64 {
+	return in.heapPages
+}
+
 // NewRuntimeFromGenesis creates a runtime instance from the genesis data
 func NewRuntimeFromGenesis(cfg *Config) (runtime.Instance, error) {
 	if cfg.Storage == nil {
@@ -80,6 +88,19 @@ func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
 	return NewInstance(bytes, cfg)
 }
 
+// NewRuntimeInstance instantiates a runtime resolving the node runtime host functions
+// from raw wasm bytecode, and implements runtime.NewInstanceFunc.
+func NewRuntimeInstance(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+	instance, err := NewInstance(code, &Config{
+		InstanceConfig: cfg,
+		Resolver:       new(Resolver),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
 // NewInstance ...
 func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 	if len(code) == 0 {
@@ -88,8 +109,20 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 
 	logger.Patch(log.SetLevel(cfg.LogLvl))
 
+	info, err := runtime.ReadModuleInfo(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read module info: %w", err)
+	}
+
+	heapPages := runtime.HeapPages(cfg.Storage)
+	pages, err := runtime.MemoryPages(info.MemoryPages, heapPages)
+	if err != nil {
+		return nil, err
+	}
+
+	// life creates the imported memory with the default number of pages.
 	vmCfg := exec.VMConfig{
-		DefaultMemoryPages: 23,
+		DefaultMemoryPages: int(pages),
 	}
 
 	instance, err := exec.NewVirtualMachine(code, vmCfg, cfg.Resolver, nil)
@@ -97,12 +130,15 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 		return nil, err
 	}
 
+	if size := int(pages) * runtime.PageSize; len(instance.Memory) < size {
+		instance.Memory = append(instance.Memory, make([]byte, size-len(instance.Memory))...)
+	}
+
 	memory := &Memory{
 		memory: instance.Memory,
 	}
 
-	// TODO: use __heap_base (#1874)
-	allocator := runtime.NewAllocator(memory, 0)
+	allocator := runtime.NewAllocator(memory, info.HeapBase)
 
 	runtimeCtx := &runtime.Context{
 		Storage:     cfg.Storage,
@@ -113,12 +149,14 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 		Network:     cfg.Network,
 		Transaction: cfg.Transaction,
 		SigVerifier: crypto.NewSignatureVerifier(logger),
+		Profiler:    runtime.NewInstanceProfiler(),
 	}
 
 	logger.Debugf("creating new runtime instance with context: %v", runtimeCtx)
 
 	inst := &Instance{
-		vm: instance,
+		vm:        instance,
+		heapPages: heapPages,
 	}
 
 	ctx = runtimeCtx
@@ -162,6 +200,11 @@ func (*Instance) SetContextStorage(s runtime.Storage) {
 	ctx.Storage = s
 }
 
+// SetProfiler implements runtime.ProfilableInstance.
+func (*Instance) SetProfiler(profiler *runtime.Profiler) {
+	ctx.Profiler = profiler
+}
+
 // Exec calls the given function with the given data
 func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 	in.mu.Lock()
@@ -180,7 +223,16 @@ func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("could not find exported function %s", function)
 	}
 
+	if ctx.Profiler != nil {
+		ctx.Profiler.StartEntrypoint(function)
+	}
+
 	ret, err := in.vm.Run(fnc, int64(ptr), int64(len(data)))
+	if storage, ok := ctx.Storage.(runtime.FallibleStorage); ok {
+		if storageErr := storage.Err(); storageErr != nil {
+			return nil, fmt.Errorf("cannot access storage: %w", storageErr)
+		}
+	}
 	if err != nil {
 		fmt.Println(in.vm.StackTrace)
 		return nil, err
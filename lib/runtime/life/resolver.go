@@ -16,6 +16,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -25,8 +26,22 @@
 // Resolver resolves the imports for life
 type Resolver struct{} // TODO: move context inside resolver (#1875)
 
-// ResolveFunc ...
-func (*Resolver) ResolveFunc(module, field string) exec.FunctionImport { //nolint:gocyclo
+// ResolveFunc resolves the given import, recording
+// its calls if the instance has a profiler.
+func (r *Resolver) ResolveFunc(module, field string) exec.FunctionImport {
+	hostFunction := r.resolveFunc(module, field)
+	return func(vm *exec.VirtualMachine) int64 {
+		if ctx.Profiler == nil {
+			return hostFunction(vm)
+		}
+
+		ctx.Profiler.StartHostFunction(field)
+		defer ctx.Profiler.EndHostFunction()
+		return hostFunction(vm)
+	}
+}
+
+func (*Resolver) resolveFunc(module, field string) exec.FunctionImport { //nolint:gocyclo
 	switch module {
 	case "env":
 		switch field {
@@ -110,6 +125,12 @@ func (*Resolver) ResolveFunc(module, field string) exec.FunctionImport { //nolin
 			return ext_crypto_sr25519_verify_version_1
 		case "ext_crypto_secp256k1_ecdsa_recover_version_1":
 			return ext_crypto_secp256k1_ecdsa_recover_version_1
+		case "ext_crypto_ecdsa_generate_version_1":
+			return ext_crypto_ecdsa_generate_version_1
+		case "ext_crypto_ecdsa_public_keys_version_1":
+			return ext_crypto_ecdsa_public_keys_version_1
+		case "ext_crypto_ecdsa_sign_version_1":
+			return ext_crypto_ecdsa_sign_version_1
 		case "ext_hashing_keccak_256_version_1":
 			return ext_hashing_keccak_256_version_1
 		case "ext_hashing_sha2_256_version_1":
@@ -142,21 +163,12 @@ func ext_logging_log_version_1(vm *exec.VirtualMachine) int64 {
 	target := asMemorySlice(vm.Memory, targetData)
 	msg := asMemorySlice(vm.Memory, msgData)
 
-	switch int(level) {
-	case 0:
-		logger.Criticalf("target=%s message=%s", string(target), string(msg))
-	case 1:
-		logger.Warnf("target=%s message=%s", string(target), string(msg))
-	case 2:
-		logger.Infof("target=%s message=%s", string(target), string(msg))
-	case 3:
-		logger.Debugf("target=%s message=%s", string(target), string(msg))
-	case 4:
-		logger.Tracef("target=%s message=%s", string(target), string(msg))
-	default:
-		logger.Errorf("level=%d target=%s message=%s", level, string(target), string(msg))
+	if tracer, ok := ctx.Storage.(runtime.LogTargetTracer); ok {
+		tracer.SetTarget(string(target))
 	}
 
+	targetLoggers.Log(level, string(target), string(msg))
+
 	return 0
 }
 
@@ -787,7 +799,7 @@ func ext_crypto_ed25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 		return ret
 	}
 
-	keys := ks.PublicKeys()
+	keys := keystore.PublicKeysOfType(ks, crypto.Ed25519Type)
 
 	var encodedKeys []byte
 	for _, key := range keys {
@@ -847,7 +859,7 @@ func ext_crypto_ed25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	err = ks.Insert(kp)
+	err = ctx.Keystore.InsertGenerated(ks, kp)
 	if err != nil {
 		logger.Warnf("failed to insert key: %s", err)
 		return 0
@@ -977,7 +989,7 @@ func ext_crypto_sr25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 		return ret
 	}
 
-	keys := ks.PublicKeys()
+	keys := keystore.PublicKeysOfType(ks, crypto.Sr25519Type)
 
 	var encodedKeys []byte
 	for _, key := range keys {
@@ -1036,7 +1048,7 @@ func ext_crypto_sr25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	err = ks.Insert(kp)
+	err = ctx.Keystore.InsertGenerated(ks, kp)
 	if err != nil {
 		logger.Warnf("failed to insert key: %s", err)
 		return 0
@@ -1190,6 +1202,157 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(vm *exec.VirtualMachine) int64
 	return ret
 }
 
+func ext_crypto_ecdsa_generate_version_1(vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	keyTypeID := vm.GetCurrentFrame().Locals[0]
+	seedSpan := vm.GetCurrentFrame().Locals[1]
+	memory := vm.Memory
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(memory, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp crypto.Keypair
+	if seed != nil {
+		kp, err = secp256k1.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = secp256k1.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := ctx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = ctx.Keystore.InsertGenerated(ks, kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(memory, kp.Public().Encode(), 33)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ecdsa keypair with public key: " + kp.Public().Hex())
+	return int64(ret)
+}
+
+func ext_crypto_ecdsa_public_keys_version_1(vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	keyTypeID := vm.GetCurrentFrame().Locals[0]
+	memory := vm.Memory
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := ctx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(memory, []byte{0})
+		return ret
+	}
+
+	if ks.Type() != crypto.Secp256k1Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected ecdsa",
+			id, ks.Type())
+		ret, _ := toWasmMemory(memory, []byte{0})
+		return ret
+	}
+
+	keys := keystore.PublicKeysOfType(ks, crypto.Secp256k1Type)
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(memory, []byte{0})
+		return ret
+	}
+
+	ret, err := toWasmMemory(memory, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(memory, []byte{0})
+		return ret
+	}
+
+	return ret
+}
+
+func ext_crypto_ecdsa_sign_version_1(vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	keyTypeID := vm.GetCurrentFrame().Locals[0]
+	key := vm.GetCurrentFrame().Locals[1]
+	msg := vm.GetCurrentFrame().Locals[2]
+	memory := vm.Memory
+
+	emptyRet, _ := toWasmMemoryOptional(memory, nil)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := ctx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return emptyRet
+	}
+
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(memory[key : key+33])
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return emptyRet
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return emptyRet
+	}
+
+	// the message is signed by its blake2b-256 hash, as the other interpreters do
+	hash, err := common.Blake2bHash(asMemorySlice(memory, msg))
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return emptyRet
+	}
+
+	sig, err := signingKey.Sign(hash[:])
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return emptyRet
+	}
+
+	ret, err := toWasmMemoryFixedSizeOptional(memory, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return emptyRet
+	}
+
+	return ret
+}
+
 func ext_hashing_keccak_256_version_1(vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
@@ -1331,9 +1494,18 @@ type kv struct {
 // Convert 64bit wasm span descriptor to Go memory slice
 func asMemorySlice(memory []byte, span int64) []byte {
 	ptr, size := runtime.Int64ToPointerAndSize(span)
+	profileBytes(int(size))
 	return memory[ptr : ptr+size]
 }
 
+// profileBytes adds the given number of bytes to the ones transferred by the
+// current host function call, if the instance has a profiler.
+func profileBytes(n int) {
+	if ctx.Profiler != nil {
+		ctx.Profiler.AddBytes(n)
+	}
+}
+
 // Copy a byte slice of a fixed size to wasm memory and return resulting pointer
 func toWasmMemorySized(memory, data []byte, size uint32) (uint32, error) {
 	if int(size) != len(data) {
@@ -1347,6 +1519,7 @@ func toWasmMemorySized(memory, data []byte, size uint32) (uint32, error) {
 	}
 
 	copy(memory[out:out+size], data)
+	profileBytes(int(size))
 	return out, nil
 }
 
@@ -1376,6 +1549,7 @@ func toWasmMemory(memory, data []byte) (int64, error) {
 	}
 
 	copy(memory[out:out+size], data)
+	profileBytes(int(size))
 	return runtime.PointerAndSizeToInt64(int32(out), int32(size)), nil
 }
 
@@ -0,0 +1,73 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+)
+
+// TargetLoggers creates and caches the child loggers used for the
+// runtime log targets, so their level can be set with log filters.
+type TargetLoggers struct {
+	parent  *log.Logger
+	mutex   sync.RWMutex
+	loggers map[string]*log.Logger
+}
+
+// NewTargetLoggers returns a new TargetLoggers creating
+// child loggers of the given parent logger.
+func NewTargetLoggers(parent *log.Logger) *TargetLoggers {
+	return &TargetLoggers{
+		parent:  parent,
+		loggers: make(map[string]*log.Logger),
+	}
+}
+
+// Get returns the logger for the given runtime log target.
+func (t *TargetLoggers) Get(target string) *log.Logger {
+	t.mutex.RLock()
+	logger, ok := t.loggers[target]
+	t.mutex.RUnlock()
+	if ok {
+		return logger
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	logger, ok = t.loggers[target]
+	if !ok {
+		logger = t.parent.New(log.AddContext("target", target))
+		t.loggers[target] = logger
+	}
+	return logger
+}
+
+// Log logs the message with the logger of the given runtime log target,
+// the level being the runtime log level, from error (1) to trace (5).
+func (t *TargetLoggers) Log(level int32, target, msg string) {
+	targetLogger := t.Get(target)
+	switch level {
+	case 1:
+		targetLogger.Error(msg)
+	case 2:
+		targetLogger.Warn(msg)
+	case 3:
+		targetLogger.Info(msg)
+	case 4:
+		targetLogger.Debug(msg)
+	case 5:
+		targetLogger.Trace(msg)
+	default:
+		targetLogger.Errorf("level=%d message=%s", level, msg)
+	}
+}
+
+// MaxLevel returns the index of the runtime log level filter, from off to trace,
+// matching the most verbose level a runtime log target logger can have.
+func (t *TargetLoggers) MaxLevel() int32 {
+	return int32(t.parent.MaxTargetLevel())
+}
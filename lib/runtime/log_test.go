@@ -0,0 +1,99 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TargetLoggers_Get(t *testing.T) {
+	t.Parallel()
+
+	loggers := NewTargetLoggers(log.New())
+
+	system := loggers.Get("runtime::system")
+	assert.Same(t, system, loggers.Get("runtime::system"))
+	assert.NotSame(t, system, loggers.Get("runtime::babe"))
+}
+
+func Test_TargetLoggers_Log(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		level     int32
+		logLevel  log.Level
+		expected  string
+		unwritten bool
+	}{
+		"error": {
+			level:    1,
+			logLevel: log.Info,
+			expected: "EROR message",
+		},
+		"warn": {
+			level:    2,
+			logLevel: log.Info,
+			expected: "WARN message",
+		},
+		"info": {
+			level:    3,
+			logLevel: log.Info,
+			expected: "INFO message",
+		},
+		"debug": {
+			level:    4,
+			logLevel: log.Trace,
+			expected: "DBUG message",
+		},
+		"debug filtered": {
+			level:     4,
+			logLevel:  log.Info,
+			unwritten: true,
+		},
+		"trace": {
+			level:    5,
+			logLevel: log.Trace,
+			expected: "TRCE message",
+		},
+		"unknown level": {
+			level:    0,
+			logLevel: log.Info,
+			expected: "EROR level=0 message=message",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := bytes.NewBuffer(nil)
+			loggers := NewTargetLoggers(log.New(log.SetWriter(buffer), log.SetLevel(testCase.logLevel)))
+
+			loggers.Log(testCase.level, "runtime::system", "message")
+
+			if testCase.unwritten {
+				assert.Empty(t, buffer.String())
+				return
+			}
+			assert.Contains(t, buffer.String(), testCase.expected)
+		})
+	}
+}
+
+func Test_TargetLoggers_MaxLevel(t *testing.T) {
+	t.Parallel()
+
+	parent := log.New(log.SetWriter(io.Discard), log.SetLevel(log.Info))
+	loggers := NewTargetLoggers(parent)
+	assert.Equal(t, int32(3), loggers.MaxLevel())
+
+	parent.AddFilter(log.Directive{Target: "runtime::babe", Level: log.Trace, TargetOnly: true})
+	assert.Equal(t, int32(5), loggers.MaxLevel())
+}
@@ -66,9 +66,27 @@ func (_m *Instance) BabeConfiguration() (*types.BabeConfiguration, error) {
 	return r0, r1
 }
 
-// CheckInherents provides a mock function with given fields:
-func (_m *Instance) CheckInherents() {
-	_m.Called()
+// CheckInherents provides a mock function with given fields: block, inherentsData
+func (_m *Instance) CheckInherents(block *types.Block, inherentsData []byte) (*types.CheckInherentsResult, error) {
+	ret := _m.Called(block, inherentsData)
+
+	var r0 *types.CheckInherentsResult
+	if rf, ok := ret.Get(0).(func(*types.Block, []byte) *types.CheckInherentsResult); ok {
+		r0 = rf(block, inherentsData)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.CheckInherentsResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*types.Block, []byte) error); ok {
+		r1 = rf(block, inherentsData)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // CheckRuntimeVersion provides a mock function with given fields: _a0
@@ -186,9 +204,27 @@ func (_m *Instance) FinalizeBlock() (*types.Header, error) {
 	return r0, r1
 }
 
-// GenerateSessionKeys provides a mock function with given fields:
-func (_m *Instance) GenerateSessionKeys() {
-	_m.Called()
+// GenerateSessionKeys provides a mock function with given fields: seed
+func (_m *Instance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	ret := _m.Called(seed)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(*[]byte) []byte); ok {
+		r0 = rf(seed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*[]byte) error); ok {
+		r1 = rf(seed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetCodeHash provides a mock function with given fields:
@@ -207,6 +243,20 @@ func (_m *Instance) GetCodeHash() common.Hash {
 	return r0
 }
 
+// GetHeapPages provides a mock function with given fields:
+func (_m *Instance) GetHeapPages() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
 // GrandpaAuthorities provides a mock function with given fields:
 func (_m *Instance) GrandpaAuthorities() ([]types.Authority, error) {
 	ret := _m.Called()
@@ -393,13 +443,13 @@ func (_m *Instance) UpdateRuntimeCode(_a0 []byte) error {
 	return r0
 }
 
-// ValidateTransaction provides a mock function with given fields: e
-func (_m *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validity, error) {
-	ret := _m.Called(e)
+// ValidateTransaction provides a mock function with given fields: e, blockHash
+func (_m *Instance) ValidateTransaction(e types.Extrinsic, blockHash common.Hash) (*transaction.Validity, error) {
+	ret := _m.Called(e, blockHash)
 
 	var r0 *transaction.Validity
-	if rf, ok := ret.Get(0).(func(types.Extrinsic) *transaction.Validity); ok {
-		r0 = rf(e)
+	if rf, ok := ret.Get(0).(func(types.Extrinsic, common.Hash) *transaction.Validity); ok {
+		r0 = rf(e, blockHash)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*transaction.Validity)
@@ -407,8 +457,8 @@ func (_m *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validit
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(types.Extrinsic) error); ok {
-		r1 = rf(e)
+	if rf, ok := ret.Get(1).(func(types.Extrinsic, common.Hash) error); ok {
+		r1 = rf(e, blockHash)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,358 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// DefaultHeapPages is the number of heap pages of the runtime memory
+// when the state does not contain the :heappages key.
+const DefaultHeapPages = uint64(2048)
+
+// maxMemoryPages is the maximum number of pages of a 32 bits wasm memory.
+const maxMemoryPages = 65536
+
+const (
+	importSectionID = 2
+	memorySectionID = 5
+	globalSectionID = 6
+	exportSectionID = 7
+
+	externalKindFunction = 0x00
+	externalKindTable    = 0x01
+	externalKindMemory   = 0x02
+	externalKindGlobal   = 0x03
+
+	opcodeI32Const = 0x41
+	opcodeEnd      = 0x0b
+
+	heapBaseExport = "__heap_base"
+)
+
+var (
+	wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+	// ErrInvalidModule is returned when the wasm module cannot be read.
+	ErrInvalidModule = errors.New("invalid wasm module")
+	// ErrTooManyHeapPages is returned when the heap pages do not fit in a wasm memory.
+	ErrTooManyHeapPages = errors.New("too many heap pages")
+
+	errUnexpectedEndOfCode = errors.New("unexpected end of wasm code")
+)
+
+// ModuleInfo holds the properties of a runtime wasm module
+// used to set up the memory of its instances.
+type ModuleInfo struct {
+	// HeapBase is the value of the __heap_base global exported by the
+	// module, or DefaultHeapBase if the module does not export it.
+	HeapBase uint32
+	// MemoryPages is the minimum number of pages of the memory of the module.
+	MemoryPages uint32
+	// ImportsMemory is true if the module imports its memory from the host.
+	ImportsMemory bool
+}
+
+// ReadModuleInfo reads the memory limits and the heap base of the given
+// decompressed wasm code, only decoding the sections needed for these.
+func ReadModuleInfo(code []byte) (info ModuleInfo, err error) {
+	if !bytes.HasPrefix(code, wasmHeader) {
+		return info, fmt.Errorf("%w: bad magic number or version", ErrInvalidModule)
+	}
+
+	// global values are indexed with the imported globals first, and
+	// the value of these is unknown, so they are recorded as nil.
+	var globals []*uint32
+	heapBaseIndex := -1
+
+	r := &wasmReader{data: code, offset: len(wasmHeader)}
+	for r.offset < len(r.data) {
+		id, err := r.byte()
+		if err != nil {
+			return info, err
+		}
+
+		size, err := r.u32()
+		if err != nil {
+			return info, err
+		}
+
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return info, fmt.Errorf("cannot read section with id %d: %w", id, err)
+		}
+
+		section := &wasmReader{data: content}
+		switch id {
+		case importSectionID:
+			err = section.imports(&info, &globals)
+		case memorySectionID:
+			err = section.memories(&info)
+		case globalSectionID:
+			err = section.globals(&globals)
+		case exportSectionID:
+			heapBaseIndex, err = section.heapBaseExport()
+		}
+		if err != nil {
+			return info, fmt.Errorf("cannot read section with id %d: %w", id, err)
+		}
+	}
+
+	info.HeapBase = DefaultHeapBase
+	if heapBaseIndex >= 0 && heapBaseIndex < len(globals) && globals[heapBaseIndex] != nil {
+		info.HeapBase = *globals[heapBaseIndex]
+	}
+
+	return info, nil
+}
+
+// HeapPages returns the number of heap pages set in the :heappages key of the
+// given storage, or DefaultHeapPages if the key is not set or not a valid u64.
+func HeapPages(storage Storage) uint64 {
+	if storage == nil {
+		return DefaultHeapPages
+	}
+
+	value := storage.Get(common.HeapPagesKey)
+	if len(value) != 8 {
+		return DefaultHeapPages
+	}
+
+	return binary.LittleEndian.Uint64(value)
+}
+
+// MemoryPages returns the number of pages of the memory of an instance of
+// a module requiring the given memory pages, with the given heap pages.
+func MemoryPages(modulePages uint32, heapPages uint64) (uint32, error) {
+	if heapPages > math.MaxUint32 || uint64(modulePages)+heapPages > maxMemoryPages {
+		return 0, fmt.Errorf("%w: %d heap pages with %d module pages exceed %d pages",
+			ErrTooManyHeapPages, heapPages, modulePages, maxMemoryPages)
+	}
+
+	return modulePages + uint32(heapPages), nil
+}
+
+type wasmReader struct {
+	data   []byte
+	offset int
+}
+
+// imports reads the import section, and records the memory
+// import and the imported globals, whose values are unknown.
+func (r *wasmReader) imports(info *ModuleInfo, globals *[]*uint32) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		for j := 0; j < 2; j++ { // module and field names
+			if _, err = r.name(); err != nil {
+				return err
+			}
+		}
+
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case externalKindFunction:
+			_, err = r.u32()
+		case externalKindTable:
+			if _, err = r.byte(); err == nil {
+				_, err = r.limits()
+			}
+		case externalKindMemory:
+			info.ImportsMemory = true
+			info.MemoryPages, err = r.limits()
+		case externalKindGlobal:
+			_, err = r.bytes(2)
+			*globals = append(*globals, nil)
+		default:
+			err = fmt.Errorf("%w: unknown import kind %d", ErrInvalidModule, kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// memories reads the memory section, which has at most one memory.
+func (r *wasmReader) memories(info *ModuleInfo) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	info.MemoryPages, err = r.limits()
+	return err
+}
+
+// globals reads the global section, and records the values of
+// the globals initialised with a constant 32 bits integer.
+func (r *wasmReader) globals(globals *[]*uint32) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		if _, err = r.bytes(2); err != nil { // value type and mutability
+			return err
+		}
+
+		opcode, err := r.byte()
+		if err != nil {
+			return err
+		}
+
+		var value *uint32
+		if opcode == opcodeI32Const {
+			v, err := r.i32()
+			if err != nil {
+				return err
+			}
+			u := uint32(v)
+			value = &u
+		}
+
+		// skip to the end of the initialisation expression
+		for opcode != opcodeEnd {
+			if opcode, err = r.byte(); err != nil {
+				return err
+			}
+		}
+
+		*globals = append(*globals, value)
+	}
+
+	return nil
+}
+
+// heapBaseExport reads the export section, and returns the index
+// of the __heap_base exported global, or -1 if it is not exported.
+func (r *wasmReader) heapBaseExport() (int, error) {
+	count, err := r.u32()
+	if err != nil {
+		return -1, err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		name, err := r.name()
+		if err != nil {
+			return -1, err
+		}
+
+		kind, err := r.byte()
+		if err != nil {
+			return -1, err
+		}
+
+		index, err := r.u32()
+		if err != nil {
+			return -1, err
+		}
+
+		if kind == externalKindGlobal && string(name) == heapBaseExport {
+			return int(index), nil
+		}
+	}
+
+	return -1, nil
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.offset >= len(r.data) {
+		return 0, errUnexpectedEndOfCode
+	}
+	b := r.data[r.offset]
+	r.offset++
+	return b, nil
+}
+
+func (r *wasmReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.offset+n > len(r.data) {
+		return nil, errUnexpectedEndOfCode
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b, nil
+}
+
+func (r *wasmReader) name() ([]byte, error) {
+	length, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	return r.bytes(int(length))
+}
+
+// u32 reads an unsigned LEB128 encoded 32 bits integer.
+func (r *wasmReader) u32() (value uint32, err error) {
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: integer too large", ErrInvalidModule)
+}
+
+// i32 reads a signed LEB128 encoded 32 bits integer.
+func (r *wasmReader) i32() (value int32, err error) {
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		value |= int32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if shift+7 < 32 && b&0x40 != 0 {
+				value |= -1 << (shift + 7)
+			}
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: integer too large", ErrInvalidModule)
+}
+
+// limits reads the limits of a table or memory, and returns their minimum.
+func (r *wasmReader) limits() (uint32, error) {
+	flag, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+
+	minimum, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+
+	// the lowest bit of the flag indicates a maximum, the second one a shared memory
+	if flag&0x01 != 0 {
+		if _, err = r.u32(); err != nil {
+			return 0, err
+		}
+	}
+
+	return minimum, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wasmModule returns the wasm code made of the given sections.
+func wasmModule(sections ...[]byte) []byte {
+	code := append([]byte(nil), wasmHeader...)
+	for _, section := range sections {
+		code = append(code, section...)
+	}
+	return code
+}
+
+// wasmSection returns the encoding of the section with the given id and content.
+func wasmSection(id byte, content ...byte) []byte {
+	return append([]byte{id, byte(len(content))}, content...)
+}
+
+var (
+	// (import "env" "memory" (memory 20))
+	memoryImportSection = wasmSection(importSectionID,
+		1, 3, 'e', 'n', 'v', 6, 'm', 'e', 'm', 'o', 'r', 'y', externalKindMemory, 0x00, 20)
+	// (import "env" "g" (global i32)) (import "env" "f" (func (type 0)))
+	globalImportSection = wasmSection(importSectionID,
+		2, 3, 'e', 'n', 'v', 1, 'g', externalKindGlobal, 0x7f, 0x00,
+		3, 'e', 'n', 'v', 1, 'f', externalKindFunction, 0)
+	// (memory 17 32)
+	memorySection = wasmSection(memorySectionID, 1, 0x01, 17, 32)
+	// (global (mut i32) (i32.const 1024)) (global i32 (i32.const 1469576))
+	globalSection = wasmSection(globalSectionID,
+		2, 0x7f, 0x01, opcodeI32Const, 0x80, 0x08, opcodeEnd,
+		0x7f, 0x00, opcodeI32Const, 0x88, 0xd9, 0xd9, 0x00, opcodeEnd)
+	// (export "__heap_base" (global 1))
+	heapBaseExportSection = wasmSection(exportSectionID,
+		1, 11, '_', '_', 'h', 'e', 'a', 'p', '_', 'b', 'a', 's', 'e', externalKindGlobal, 1)
+	// (export "__heap_base" (global 0))
+	importedHeapBaseExportSection = wasmSection(exportSectionID,
+		1, 11, '_', '_', 'h', 'e', 'a', 'p', '_', 'b', 'a', 's', 'e', externalKindGlobal, 0)
+)
+
+func Test_ReadModuleInfo(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		code       []byte
+		info       ModuleInfo
+		errWrapped error
+		errMessage string
+	}{
+		"bad header": {
+			code:       []byte{0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+			errWrapped: ErrInvalidModule,
+			errMessage: "invalid wasm module: bad magic number or version",
+		},
+		"empty module": {
+			code: wasmModule(),
+			info: ModuleInfo{HeapBase: DefaultHeapBase},
+		},
+		"imported memory and heap base": {
+			code: wasmModule(memoryImportSection, globalSection, heapBaseExportSection),
+			info: ModuleInfo{
+				HeapBase:      1469576,
+				MemoryPages:   20,
+				ImportsMemory: true,
+			},
+		},
+		"defined memory": {
+			code: wasmModule(memorySection, globalSection, heapBaseExportSection),
+			info: ModuleInfo{
+				HeapBase:    1469576,
+				MemoryPages: 17,
+			},
+		},
+		"imported heap base": {
+			code: wasmModule(globalImportSection, memorySection, globalSection, importedHeapBaseExportSection),
+			info: ModuleInfo{
+				HeapBase:    DefaultHeapBase,
+				MemoryPages: 17,
+			},
+		},
+		"truncated section": {
+			code:       wasmModule(memoryImportSection[:5]),
+			errWrapped: errUnexpectedEndOfCode,
+			errMessage: "cannot read section with id 2: unexpected end of wasm code",
+		},
+		"unknown import kind": {
+			code:       wasmModule(wasmSection(importSectionID, 1, 1, 'a', 1, 'b', 0x04)),
+			errWrapped: ErrInvalidModule,
+			errMessage: "cannot read section with id 2: invalid wasm module: unknown import kind 4",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			info, err := ReadModuleInfo(testCase.code)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.info, info)
+		})
+	}
+}
+
+type heapPagesStorage struct {
+	Storage
+	value []byte
+}
+
+func (s *heapPagesStorage) Get(key []byte) []byte {
+	if string(key) != string(common.HeapPagesKey) {
+		return nil
+	}
+	return s.value
+}
+
+func Test_HeapPages(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		storage   Storage
+		heapPages uint64
+	}{
+		"nil storage": {
+			heapPages: DefaultHeapPages,
+		},
+		"key not set": {
+			storage:   &heapPagesStorage{},
+			heapPages: DefaultHeapPages,
+		},
+		"invalid value": {
+			storage:   &heapPagesStorage{value: []byte{1, 2}},
+			heapPages: DefaultHeapPages,
+		},
+		"key set": {
+			storage:   &heapPagesStorage{value: []byte{0, 1, 0, 0, 0, 0, 0, 0}},
+			heapPages: 256,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			heapPages := HeapPages(testCase.storage)
+
+			assert.Equal(t, testCase.heapPages, heapPages)
+		})
+	}
+}
+
+func Test_MemoryPages(t *testing.T) {
+	t.Parallel()
+
+	pages, err := MemoryPages(20, 2048)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2068), pages)
+
+	_, err = MemoryPages(20, 65536)
+	assert.ErrorIs(t, err, ErrTooManyHeapPages)
+
+	_, err = MemoryPages(0, 1<<32)
+	assert.ErrorIs(t, err, ErrTooManyHeapPages)
+}
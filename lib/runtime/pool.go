@@ -0,0 +1,165 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultPoolSize is the maximum number of instances of a pool if none is configured.
+const DefaultPoolSize = 4
+
+// ErrPoolStopped is returned when checking out an instance from a stopped pool.
+var ErrPoolStopped = errors.New("runtime pool is stopped")
+
+var poolWaitSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: "gossamer_runtime",
+	Name:      "pool_wait_seconds",
+	Help:      "time waited to check out an instance from a runtime pool",
+	Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+})
+
+// PoolableInstance is implemented by the runtime instances which can be pooled.
+type PoolableInstance interface {
+	Instance
+	// Clone returns a new instance of the runtime code of the
+	// instance, with the same configuration and context storage.
+	Clone() (Instance, error)
+	// Reset restores the memory of the instance to its state
+	// right after instantiation and unsets its context storage.
+	Reset()
+}
+
+// Pool is a pool of instances of the same runtime code, so concurrent runtime
+// calls do not wait for each other. An instance is checked out for the exclusive
+// use of the caller, who sets its context storage before the runtime calls and
+// checks it in once done. Instances which do not implement PoolableInstance
+// cannot be pooled, and are shared by all the callers as before.
+type Pool struct {
+	instance Instance
+	size     uint
+	free     chan Instance
+	done     chan struct{}
+
+	mutex     sync.Mutex
+	instances []Instance
+	stopped   bool
+}
+
+// NewPool creates a pool of at most the given number of instances, cloned from the
+// instance given on demand. The instance given is never checked out if it can be
+// cloned, so it can still be used directly. DefaultPoolSize is used if size is zero.
+func NewPool(instance Instance, size uint) *Pool {
+	if size == 0 {
+		size = DefaultPoolSize
+	}
+
+	return &Pool{
+		instance: instance,
+		size:     size,
+		free:     make(chan Instance, size),
+		done:     make(chan struct{}),
+	}
+}
+
+// CodeHash returns the hash of the runtime code of the instances of the pool.
+func (p *Pool) CodeHash() common.Hash {
+	return p.instance.GetCodeHash()
+}
+
+// Checkout returns an instance for the exclusive use of the caller until it is checked in.
+// A new instance is created if none is free and the pool is not full, otherwise it waits
+// for an instance to be checked in.
+func (p *Pool) Checkout() (instance Instance, err error) {
+	poolable, ok := p.instance.(PoolableInstance)
+	if !ok {
+		return p.instance, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		poolWaitSeconds.Observe(time.Since(start).Seconds())
+	}()
+
+	select {
+	case instance = <-p.free:
+		return instance, nil
+	default:
+	}
+
+	p.mutex.Lock()
+	if p.stopped {
+		p.mutex.Unlock()
+		return nil, ErrPoolStopped
+	}
+
+	if uint(len(p.instances)) < p.size {
+		instance, err = poolable.Clone()
+		if err != nil {
+			p.mutex.Unlock()
+			return nil, fmt.Errorf("cannot create runtime instance: %w", err)
+		}
+		p.instances = append(p.instances, instance)
+		p.mutex.Unlock()
+		return instance, nil
+	}
+	p.mutex.Unlock()
+
+	select {
+	case instance = <-p.free:
+		return instance, nil
+	case <-p.done:
+		return nil, ErrPoolStopped
+	}
+}
+
+// Checkin resets the memory and context storage of an instance
+// returned by Checkout, and makes it available to other callers.
+func (p *Pool) Checkin(instance Instance) {
+	poolable, ok := instance.(PoolableInstance)
+	if !ok || instance == p.instance {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		instance.Stop()
+		return
+	}
+
+	poolable.Reset()
+	p.free <- instance
+}
+
+// Stop stops all the instances created by the pool. The instance the pool
+// is created from is not stopped, and the checked out instances are stopped
+// when they are checked in.
+func (p *Pool) Stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	close(p.done)
+
+	for {
+		select {
+		case instance := <-p.free:
+			instance.Stop()
+		default:
+			return
+		}
+	}
+}
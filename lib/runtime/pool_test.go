@@ -0,0 +1,145 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstance struct {
+	Instance
+	mutex    sync.Mutex
+	cloneErr error
+	clones   int
+	resets   int
+	stopped  bool
+}
+
+func (f *fakeInstance) Clone() (Instance, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.cloneErr != nil {
+		return nil, f.cloneErr
+	}
+	f.clones++
+	return &fakeInstance{}, nil
+}
+
+func (f *fakeInstance) Reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.resets++
+}
+
+func (f *fakeInstance) Stop() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.stopped = true
+}
+
+type sharedInstance struct {
+	Instance
+}
+
+func Test_Pool_shared(t *testing.T) {
+	t.Parallel()
+
+	instance := &sharedInstance{}
+	pool := NewPool(instance, 1)
+
+	first, err := pool.Checkout()
+	require.NoError(t, err)
+	second, err := pool.Checkout()
+	require.NoError(t, err)
+
+	assert.Same(t, instance, first)
+	assert.Same(t, instance, second)
+	pool.Checkin(first)
+	pool.Checkin(second)
+}
+
+func Test_Pool_CheckoutCheckin(t *testing.T) {
+	t.Parallel()
+
+	template := &fakeInstance{}
+	pool := NewPool(template, 2)
+
+	first, err := pool.Checkout()
+	require.NoError(t, err)
+	second, err := pool.Checkout()
+	require.NoError(t, err)
+	assert.NotSame(t, template, first)
+	assert.NotSame(t, first, second)
+	assert.Equal(t, 2, template.clones)
+
+	checkedOut := make(chan Instance)
+	go func() {
+		instance, err := pool.Checkout()
+		assert.NoError(t, err)
+		checkedOut <- instance
+	}()
+
+	select {
+	case <-checkedOut:
+		t.Fatal("instance checked out from a full pool")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	pool.Checkin(first)
+	assert.Same(t, first, <-checkedOut)
+	assert.Equal(t, 1, first.(*fakeInstance).resets)
+	assert.Equal(t, 2, template.clones)
+}
+
+func Test_Pool_cloneError(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	pool := NewPool(&fakeInstance{cloneErr: errTest}, 1)
+
+	instance, err := pool.Checkout()
+	assert.ErrorIs(t, err, errTest)
+	assert.Nil(t, instance)
+}
+
+func Test_Pool_Stop(t *testing.T) {
+	t.Parallel()
+
+	template := &fakeInstance{}
+	pool := NewPool(template, 2)
+
+	first, err := pool.Checkout()
+	require.NoError(t, err)
+	second, err := pool.Checkout()
+	require.NoError(t, err)
+	pool.Checkin(first)
+
+	waiting := NewPool(template, 1)
+	_, err = waiting.Checkout()
+	require.NoError(t, err)
+	errs := make(chan error)
+	go func() {
+		_, err := waiting.Checkout()
+		errs <- err
+	}()
+	waiting.Stop()
+	assert.ErrorIs(t, <-errs, ErrPoolStopped)
+
+	pool.Stop()
+	assert.True(t, first.(*fakeInstance).stopped)
+	assert.False(t, second.(*fakeInstance).stopped)
+	assert.False(t, template.stopped)
+
+	pool.Checkin(second)
+	assert.True(t, second.(*fakeInstance).stopped)
+
+	_, err = pool.Checkout()
+	assert.ErrorIs(t, err, ErrPoolStopped)
+}
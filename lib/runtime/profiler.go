@@ -0,0 +1,168 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	hostFunctionSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gossamer_runtime",
+		Name:      "host_function_seconds",
+		Help:      "time spent in the host function calls of the runtime, by runtime entrypoint",
+		Buckets:   prometheus.ExponentialBuckets(0.000001, 4, 10),
+	}, []string{"entrypoint", "function"})
+	hostFunctionBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gossamer_runtime",
+		Name:      "host_function_bytes",
+		Help:      "bytes transferred between the runtime memory and the host function calls, by runtime entrypoint",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"entrypoint", "function"})
+
+	// publishProfiles is set to 1 if the host function calls of
+	// the instances created from now on are profiled as metrics.
+	publishProfiles int32
+)
+
+// SetProfiling sets if the host function calls of the instances created
+// from now on are profiled and published as prometheus metrics.
+func SetProfiling(enabled bool) {
+	var publish int32
+	if enabled {
+		publish = 1
+	}
+	atomic.StoreInt32(&publishProfiles, publish)
+}
+
+// NewInstanceProfiler returns the profiler of a new instance, which publishes
+// its profile as prometheus metrics, or nil if profiling is not enabled.
+func NewInstanceProfiler() *Profiler {
+	if atomic.LoadInt32(&publishProfiles) == 0 {
+		return nil
+	}
+	return NewProfiler(true)
+}
+
+// ProfilableInstance is implemented by the runtime instances
+// which can record the host function calls of their runtime.
+type ProfilableInstance interface {
+	Instance
+	// SetProfiler sets the profiler recording the host function calls of the
+	// runtime, replacing the one publishing metrics if profiling is enabled.
+	SetProfiler(profiler *Profiler)
+}
+
+// HostFunctionProfile holds the calls made by the runtime to a host function.
+type HostFunctionProfile struct {
+	Calls    uint64        `json:"calls"`
+	Duration time.Duration `json:"duration"`
+	// Bytes is the number of bytes of the memory spans
+	// read and written by the host function.
+	Bytes uint64 `json:"bytes"`
+}
+
+// Profile holds the host function calls made by the
+// runtime, by runtime entrypoint and host function name.
+type Profile map[string]map[string]HostFunctionProfile
+
+// Profiler records the host function calls made by the runtime of an instance,
+// by runtime entrypoint. The calls of an instance being sequential, it is only
+// used by the instance and is not safe for concurrent use.
+// Host functions called by other host functions are not recorded separately,
+// their duration and bytes being part of the call made by the runtime.
+type Profiler struct {
+	publish bool
+	profile Profile
+
+	entrypoint   string
+	hostFunction string
+	// depth is the number of nested host function calls in progress.
+	depth int
+	start time.Time
+	bytes uint64
+}
+
+// NewProfiler creates a new profiler, observing the host function
+// calls in the prometheus histograms if publish is true.
+func NewProfiler(publish bool) *Profiler {
+	return &Profiler{
+		publish: publish,
+		profile: make(Profile),
+	}
+}
+
+// StartEntrypoint sets the runtime entrypoint the following host function calls are made by.
+func (p *Profiler) StartEntrypoint(entrypoint string) {
+	p.entrypoint = entrypoint
+}
+
+// StartHostFunction starts recording a call to the given host function,
+// unless it is called by the host function call being recorded.
+func (p *Profiler) StartHostFunction(name string) {
+	p.depth++
+	if p.depth > 1 {
+		return
+	}
+
+	p.hostFunction = name
+	p.bytes = 0
+	p.start = time.Now()
+}
+
+// AddBytes adds the given number of bytes to the ones transferred by the current host function call.
+func (p *Profiler) AddBytes(n int) {
+	if p.depth > 0 {
+		p.bytes += uint64(n)
+	}
+}
+
+// EndHostFunction ends recording the current host function call.
+func (p *Profiler) EndHostFunction() {
+	if p.depth == 0 {
+		return
+	}
+
+	p.depth--
+	if p.depth > 0 {
+		return
+	}
+
+	duration := time.Since(p.start)
+
+	hostFunctions, ok := p.profile[p.entrypoint]
+	if !ok {
+		hostFunctions = make(map[string]HostFunctionProfile)
+		p.profile[p.entrypoint] = hostFunctions
+	}
+
+	hostFunction := hostFunctions[p.hostFunction]
+	hostFunction.Calls++
+	hostFunction.Duration += duration
+	hostFunction.Bytes += p.bytes
+	hostFunctions[p.hostFunction] = hostFunction
+
+	if p.publish {
+		hostFunctionSeconds.WithLabelValues(p.entrypoint, p.hostFunction).Observe(duration.Seconds())
+		hostFunctionBytes.WithLabelValues(p.entrypoint, p.hostFunction).Observe(float64(p.bytes))
+	}
+
+	p.hostFunction = ""
+}
+
+// Profile returns a copy of the host function calls recorded so far.
+func (p *Profiler) Profile() Profile {
+	profile := make(Profile, len(p.profile))
+	for entrypoint, hostFunctions := range p.profile {
+		profile[entrypoint] = make(map[string]HostFunctionProfile, len(hostFunctions))
+		for name, hostFunction := range hostFunctions {
+			profile[entrypoint][name] = hostFunction
+		}
+	}
+	return profile
+}
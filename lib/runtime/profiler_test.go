@@ -0,0 +1,64 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler(t *testing.T) {
+	t.Parallel()
+
+	profiler := NewProfiler(false)
+
+	// bytes transferred outside of a host function call are ignored
+	profiler.AddBytes(100)
+
+	profiler.StartEntrypoint(CoreExecuteBlock)
+	profiler.StartHostFunction("ext_storage_get_version_1")
+	profiler.AddBytes(32)
+	profiler.AddBytes(8)
+	profiler.EndHostFunction()
+	profiler.StartHostFunction("ext_storage_get_version_1")
+	profiler.AddBytes(32)
+	profiler.EndHostFunction()
+
+	profiler.StartEntrypoint(CoreVersion)
+	profiler.StartHostFunction("ext_allocator_malloc_version_1")
+	profiler.EndHostFunction()
+
+	// ending a host function call twice is a no-op
+	profiler.EndHostFunction()
+
+	// a host function called by another host function is part of the outer call
+	profiler.StartHostFunction("ext_storage_root_version_2")
+	profiler.AddBytes(32)
+	profiler.StartHostFunction("ext_storage_root_version_1")
+	profiler.AddBytes(32)
+	profiler.EndHostFunction()
+	profiler.AddBytes(8)
+	profiler.EndHostFunction()
+
+	profile := profiler.Profile()
+	assert.Len(t, profile, 2)
+	assert.Len(t, profile[CoreVersion], 2)
+
+	storageRoot := profile[CoreVersion]["ext_storage_root_version_2"]
+	assert.Equal(t, uint64(1), storageRoot.Calls)
+	assert.Equal(t, uint64(72), storageRoot.Bytes)
+
+	storageGet := profile[CoreExecuteBlock]["ext_storage_get_version_1"]
+	assert.Equal(t, uint64(2), storageGet.Calls)
+	assert.Equal(t, uint64(72), storageGet.Bytes)
+
+	malloc := profile[CoreVersion]["ext_allocator_malloc_version_1"]
+	assert.Equal(t, uint64(1), malloc.Calls)
+	assert.Zero(t, malloc.Bytes)
+
+	// the profile returned is a copy
+	profile[CoreVersion]["ext_allocator_malloc_version_1"] = HostFunctionProfile{}
+	assert.Equal(t, malloc, profiler.Profile()[CoreVersion]["ext_allocator_malloc_version_1"])
+}
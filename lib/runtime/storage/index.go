@@ -0,0 +1,54 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import "github.com/ChainSafe/gossamer/lib/common"
+
+// IndexOperation is a transaction indexing operation requested
+// by the runtime during the execution of a block.
+type IndexOperation struct {
+	// Extrinsic is the index of the extrinsic in the block body.
+	Extrinsic uint32
+	// Hash is the content hash of the indexed data.
+	Hash common.Hash
+	// Size is the size of the indexed data, found at the end
+	// of the encoded extrinsic. It is zero for renewals.
+	Size uint32
+	// Renew is true if the operation renews the data indexed
+	// with the hash by an earlier block.
+	Renew bool
+}
+
+// IndexTransaction records that the last size bytes of the extrinsic at the
+// given index in the block, with the given content hash, are to be indexed.
+func (s *TrieState) IndexTransaction(extrinsic uint32, hash common.Hash, size uint32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.indexOperations = append(s.indexOperations, IndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Size:      size,
+	})
+}
+
+// RenewTransaction records that the data indexed with the given
+// content hash is renewed by the extrinsic at the given index in the block.
+func (s *TrieState) RenewTransaction(extrinsic uint32, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.indexOperations = append(s.indexOperations, IndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Renew:     true,
+	})
+}
+
+// IndexOperations returns the index operations recorded in the order they were requested.
+func (s *TrieState) IndexOperations() []IndexOperation {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	operations := make([]IndexOperation, len(s.indexOperations))
+	copy(operations, s.indexOperations)
+	return operations
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieState_IndexOperations(t *testing.T) {
+	ts := newTestTrieState(t)
+
+	hash := common.Hash{1}
+	ts.IndexTransaction(1, hash, 10)
+
+	ts.BeginStorageTransaction()
+	ts.RenewTransaction(2, hash)
+	ts.RollbackStorageTransaction()
+
+	ts.BeginStorageTransaction()
+	ts.RenewTransaction(3, hash)
+	ts.CommitStorageTransaction()
+
+	expected := []IndexOperation{
+		{Extrinsic: 1, Hash: hash, Size: 10},
+		{Extrinsic: 3, Hash: hash, Renew: true},
+	}
+	require.Equal(t, expected, ts.IndexOperations())
+}
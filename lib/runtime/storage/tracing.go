@@ -0,0 +1,356 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// Storage operations recorded by the TracingStorage.
+const (
+	OperationGet              = "get"
+	OperationSet              = "set"
+	OperationClear            = "clear"
+	OperationClearPrefix      = "clear_prefix"
+	OperationNextKey          = "next_key"
+	OperationChildGet         = "child_get"
+	OperationChildSet         = "child_set"
+	OperationChildClear       = "child_clear"
+	OperationChildClearPrefix = "child_clear_prefix"
+	OperationChildNextKey     = "child_next_key"
+	OperationChildDelete      = "child_delete"
+)
+
+// TraceEvent is a storage access recorded by the TracingStorage.
+type TraceEvent struct {
+	Operation string `json:"operation"`
+	// ChildKey is the key of the child trie for child trie operations.
+	ChildKey string `json:"childKey,omitempty"`
+	Key      string `json:"key"`
+	// Value is only set if the TraceFilter records values.
+	Value string `json:"value,omitempty"`
+	// Target is the target of the last runtime log message
+	// emitted before the storage access.
+	Target string `json:"target,omitempty"`
+	// SpanID is the id of the innermost recorded tracing span
+	// the storage access is made in, if any.
+	SpanID uint64 `json:"spanId,omitempty"`
+}
+
+// TraceSpan is a tracing span of the runtime recorded by the TracingStorage.
+type TraceSpan struct {
+	ID uint64 `json:"id"`
+	// ParentID is the id of the recorded parent span, if any.
+	ParentID uint64 `json:"parentId,omitempty"`
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+}
+
+// Trace holds the tracing spans and storage accesses recorded by the TracingStorage.
+type Trace struct {
+	Spans  []TraceSpan  `json:"spans"`
+	Events []TraceEvent `json:"events"`
+}
+
+// TraceFilter selects the storage accesses recorded by the TracingStorage.
+// An empty field does not filter anything.
+type TraceFilter struct {
+	// Targets are prefixes of the runtime log and tracing span targets to record.
+	Targets []string
+	// KeyPrefixes are prefixes of the storage keys to record.
+	KeyPrefixes [][]byte
+	// Operations are the storage operations to record, such as OperationGet.
+	Operations []string
+	// WithValues records the values read and written.
+	WithValues bool
+}
+
+func (f TraceFilter) matches(operation string, key []byte, target string) bool {
+	if len(f.Operations) > 0 {
+		found := false
+		for _, op := range f.Operations {
+			if op == operation {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.KeyPrefixes) > 0 {
+		found := false
+		for _, prefix := range f.KeyPrefixes {
+			if bytes.HasPrefix(key, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return f.matchesTarget(target)
+}
+
+func (f TraceFilter) matchesTarget(target string) bool {
+	if len(f.Targets) == 0 {
+		return true
+	}
+
+	for _, t := range f.Targets {
+		if strings.HasPrefix(target, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// TracingStorage is a wrapper around a TrieState recording
+// the storage accesses made by a runtime call.
+type TracingStorage struct {
+	*TrieState
+	filter TraceFilter
+
+	mutex  sync.Mutex
+	target string
+	events []TraceEvent
+	spans  []TraceSpan
+	// openSpans holds the ids of the recorded spans entered and not exited yet.
+	openSpans []uint64
+}
+
+// NewTracingStorage returns a new TracingStorage recording the
+// accesses to the given trie state matching the given filter.
+func NewTracingStorage(ts *TrieState, filter TraceFilter) *TracingStorage {
+	return &TracingStorage{
+		TrieState: ts,
+		filter:    filter,
+		events:    []TraceEvent{},
+		spans:     []TraceSpan{},
+	}
+}
+
+// SetTarget sets the runtime log target attached to the
+// storage accesses recorded from now on.
+func (s *TracingStorage) SetTarget(target string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.target = target
+}
+
+// Events returns the storage accesses recorded so far.
+func (s *TracingStorage) Events() []TraceEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := make([]TraceEvent, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
+// EnterSpan records the tracing span entered by the runtime if its target matches the filter.
+// The storage accesses recorded until it is exited are attached to it.
+func (s *TracingStorage) EnterSpan(id, parentID uint64, target, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.filter.matchesTarget(target) {
+		return
+	}
+
+	if !s.isOpen(parentID) {
+		// the parent span is not recorded so the span
+		// is attached to the innermost recorded span.
+		parentID = s.innermostSpan()
+	}
+
+	s.spans = append(s.spans, TraceSpan{
+		ID:       id,
+		ParentID: parentID,
+		Name:     name,
+		Target:   target,
+	})
+	s.openSpans = append(s.openSpans, id)
+}
+
+// ExitSpan exits the recorded tracing span with the given id.
+func (s *TracingStorage) ExitSpan(id uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := len(s.openSpans) - 1; i >= 0; i-- {
+		if s.openSpans[i] == id {
+			s.openSpans = append(s.openSpans[:i], s.openSpans[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *TracingStorage) isOpen(id uint64) bool {
+	for _, openID := range s.openSpans {
+		if openID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TracingStorage) innermostSpan() (id uint64) {
+	if len(s.openSpans) == 0 {
+		return 0
+	}
+	return s.openSpans[len(s.openSpans)-1]
+}
+
+// Trace returns the tracing spans and storage accesses recorded so far.
+func (s *TracingStorage) Trace() *Trace {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	trace := &Trace{
+		Spans:  make([]TraceSpan, len(s.spans)),
+		Events: make([]TraceEvent, len(s.events)),
+	}
+	copy(trace.Spans, s.spans)
+	copy(trace.Events, s.events)
+	return trace
+}
+
+func (s *TracingStorage) record(operation string, keyToChild, key, value []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.filter.matches(operation, key, s.target) {
+		return
+	}
+
+	event := TraceEvent{
+		Operation: operation,
+		Key:       common.BytesToHex(key),
+		Target:    s.target,
+		SpanID:    s.innermostSpan(),
+	}
+
+	if keyToChild != nil {
+		event.ChildKey = common.BytesToHex(keyToChild)
+	}
+
+	if s.filter.WithValues && value != nil {
+		event.Value = common.BytesToHex(value)
+	}
+
+	s.events = append(s.events, event)
+}
+
+// Get returns the value at the given key and records the access.
+func (s *TracingStorage) Get(key []byte) []byte {
+	value := s.TrieState.Get(key)
+	s.record(OperationGet, nil, key, value)
+	return value
+}
+
+// Set sets the value at the given key and records the access.
+func (s *TracingStorage) Set(key, value []byte) {
+	s.TrieState.Set(key, value)
+	s.record(OperationSet, nil, key, value)
+}
+
+// Delete deletes the given key and records the access.
+func (s *TracingStorage) Delete(key []byte) {
+	s.TrieState.Delete(key)
+	s.record(OperationClear, nil, key, nil)
+}
+
+// NextKey returns the next key after the given key and records the access.
+func (s *TracingStorage) NextKey(key []byte) []byte {
+	next := s.TrieState.NextKey(key)
+	s.record(OperationNextKey, nil, key, next)
+	return next
+}
+
+// ClearPrefix deletes the keys with the given prefix and records the access.
+func (s *TracingStorage) ClearPrefix(prefix []byte) error {
+	s.record(OperationClearPrefix, nil, prefix, nil)
+	return s.TrieState.ClearPrefix(prefix)
+}
+
+// ClearPrefixLimit deletes up to limit keys with the given prefix and records the access.
+func (s *TracingStorage) ClearPrefixLimit(prefix []byte, limit uint32) (uint32, bool) {
+	s.record(OperationClearPrefix, nil, prefix, nil)
+	return s.TrieState.ClearPrefixLimit(prefix, limit)
+}
+
+// SetChildStorage sets a value in a child trie and records the access.
+func (s *TracingStorage) SetChildStorage(keyToChild, key, value []byte) error {
+	err := s.TrieState.SetChildStorage(keyToChild, key, value)
+	if err != nil {
+		return err
+	}
+
+	s.record(OperationChildSet, keyToChild, key, value)
+	return nil
+}
+
+// GetChildStorage returns a value from a child trie and records the access.
+func (s *TracingStorage) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	value, err := s.TrieState.GetChildStorage(keyToChild, key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(OperationChildGet, keyToChild, key, value)
+	return value, nil
+}
+
+// ClearChildStorage deletes a key from a child trie and records the access.
+func (s *TracingStorage) ClearChildStorage(keyToChild, key []byte) error {
+	err := s.TrieState.ClearChildStorage(keyToChild, key)
+	if err != nil {
+		return err
+	}
+
+	s.record(OperationChildClear, keyToChild, key, nil)
+	return nil
+}
+
+// ClearPrefixInChild deletes the keys with the given prefix
+// from a child trie and records the access.
+func (s *TracingStorage) ClearPrefixInChild(keyToChild, prefix []byte) error {
+	err := s.TrieState.ClearPrefixInChild(keyToChild, prefix)
+	if err != nil {
+		return err
+	}
+
+	s.record(OperationChildClearPrefix, keyToChild, prefix, nil)
+	return nil
+}
+
+// GetChildNextKey returns the next key after the given key in
+// a child trie and records the access.
+func (s *TracingStorage) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	next, err := s.TrieState.GetChildNextKey(keyToChild, key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(OperationChildNextKey, keyToChild, key, next)
+	return next, nil
+}
+
+// DeleteChild deletes a child trie and records the access.
+func (s *TracingStorage) DeleteChild(keyToChild []byte) {
+	s.record(OperationChildDelete, keyToChild, nil, nil)
+	s.TrieState.DeleteChild(keyToChild)
+}
+
+// DeleteChildLimit deletes up to limit keys from a child trie and records the access.
+func (s *TracingStorage) DeleteChildLimit(keyToChild []byte, limit *[]byte) (uint32, bool, error) {
+	s.record(OperationChildDelete, keyToChild, nil, nil)
+	return s.TrieState.DeleteChildLimit(keyToChild, limit)
+}
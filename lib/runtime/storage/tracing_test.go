@@ -0,0 +1,131 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracingStorage_Events(t *testing.T) {
+	ts := newTestTrieState(t)
+	ts.Set([]byte("balances:alice"), []byte{1})
+
+	tracer := NewTracingStorage(ts, TraceFilter{WithValues: true})
+
+	value := tracer.Get([]byte("balances:alice"))
+	require.Equal(t, []byte{1}, value)
+
+	tracer.SetTarget("runtime::balances")
+	tracer.Set([]byte("balances:bob"), []byte{2})
+	tracer.Delete([]byte("balances:alice"))
+	next := tracer.NextKey([]byte("balances:"))
+	require.Equal(t, []byte("balances:bob"), next)
+
+	err := tracer.SetChild([]byte("child"), trie.NewEmptyTrie())
+	require.NoError(t, err)
+	err = tracer.SetChildStorage([]byte("child"), []byte("key"), []byte{3})
+	require.NoError(t, err)
+
+	// accesses are made on the underlying trie state
+	require.Nil(t, ts.Get([]byte("balances:alice")))
+	require.Equal(t, []byte{2}, ts.Get([]byte("balances:bob")))
+
+	expected := []TraceEvent{
+		{Operation: OperationGet, Key: "0x62616c616e6365733a616c696365", Value: "0x01"},
+		{Operation: OperationSet, Key: "0x62616c616e6365733a626f62", Value: "0x02", Target: "runtime::balances"},
+		{Operation: OperationClear, Key: "0x62616c616e6365733a616c696365", Target: "runtime::balances"},
+		{Operation: OperationNextKey, Key: "0x62616c616e6365733a",
+			Value: "0x62616c616e6365733a626f62", Target: "runtime::balances"},
+		{Operation: OperationChildSet, ChildKey: "0x6368696c64", Key: "0x6b6579",
+			Value: "0x03", Target: "runtime::balances"},
+	}
+	assert.Equal(t, expected, tracer.Events())
+}
+
+func TestTracingStorage_Trace(t *testing.T) {
+	ts := newTestTrieState(t)
+
+	tracer := NewTracingStorage(ts, TraceFilter{Targets: []string{"runtime::"}})
+
+	tracer.EnterSpan(1, 0, "runtime::executive", "execute_block")
+	tracer.EnterSpan(2, 1, "frame_support", "on_initialize")
+	tracer.EnterSpan(3, 2, "runtime::balances", "transfer")
+	tracer.SetTarget("runtime::balances")
+	tracer.Set([]byte("key"), []byte{1})
+	tracer.ExitSpan(3)
+	tracer.ExitSpan(2)
+	tracer.Delete([]byte("key"))
+	tracer.ExitSpan(1)
+	tracer.Get([]byte("key"))
+
+	expected := &Trace{
+		Spans: []TraceSpan{
+			{ID: 1, Name: "execute_block", Target: "runtime::executive"},
+			{ID: 3, ParentID: 1, Name: "transfer", Target: "runtime::balances"},
+		},
+		Events: []TraceEvent{
+			{Operation: OperationSet, Key: "0x6b6579", Target: "runtime::balances", SpanID: 3},
+			{Operation: OperationClear, Key: "0x6b6579", Target: "runtime::balances", SpanID: 1},
+			{Operation: OperationGet, Key: "0x6b6579", Target: "runtime::balances"},
+		},
+	}
+	assert.Equal(t, expected, tracer.Trace())
+}
+
+func TestTraceFilter_matches(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		filter    TraceFilter
+		operation string
+		key       []byte
+		target    string
+		matches   bool
+	}{
+		"empty filter": {
+			operation: OperationGet,
+			key:       []byte{1},
+			matches:   true,
+		},
+		"operation not matching": {
+			filter:    TraceFilter{Operations: []string{OperationSet}},
+			operation: OperationGet,
+		},
+		"key prefix not matching": {
+			filter:    TraceFilter{KeyPrefixes: [][]byte{{2}}},
+			operation: OperationGet,
+			key:       []byte{1, 2},
+		},
+		"target not matching": {
+			filter:    TraceFilter{Targets: []string{"runtime::staking"}},
+			operation: OperationGet,
+			target:    "runtime::balances",
+		},
+		"all matching": {
+			filter: TraceFilter{
+				Operations:  []string{OperationSet, OperationGet},
+				KeyPrefixes: [][]byte{{2}, {1}},
+				Targets:     []string{"runtime"},
+			},
+			operation: OperationGet,
+			key:       []byte{1, 2},
+			target:    "runtime::balances",
+			matches:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			matches := testCase.filter.matches(testCase.operation, testCase.key, testCase.target)
+			assert.Equal(t, testCase.matches, matches)
+		})
+	}
+}
@@ -18,6 +18,12 @@ type TrieState struct {
 	t       *trie.Trie
 	oldTrie *trie.Trie // this is the trie before BeginStorageTransaction is called. set to nil if it isn't called
 	lock    sync.RWMutex
+
+	indexOperations []IndexOperation
+	// oldIndexOperations is the number of index operations when BeginStorageTransaction is called
+	oldIndexOperations int
+	// err is the error of the trie rolled back last, if any
+	err error
 }
 
 // NewTrieState returns a new TrieState with the given trie
@@ -52,6 +58,7 @@ func (s *TrieState) BeginStorageTransaction() {
 	defer s.lock.Unlock()
 	s.oldTrie = s.t
 	s.t = s.t.Snapshot()
+	s.oldIndexOperations = len(s.indexOperations)
 }
 
 // CommitStorageTransaction commits all storage changes made since BeginStorageTransaction was called.
@@ -65,8 +72,12 @@ func (s *TrieState) CommitStorageTransaction() {
 func (s *TrieState) RollbackStorageTransaction() {
 	s.lock.Lock()
 	defer s.lock.Unlock()
+	if err := s.t.Err(); err != nil && s.err == nil {
+		s.err = err
+	}
 	s.t = s.oldTrie
 	s.oldTrie = nil
+	s.indexOperations = s.indexOperations[:s.oldIndexOperations]
 }
 
 // Set sets a key-value pair in the trie
@@ -90,9 +101,25 @@ func (s *TrieState) MustRoot() common.Hash {
 
 // Root returns the trie's root hash
 func (s *TrieState) Root() (common.Hash, error) {
+	err := s.Err()
+	if err != nil {
+		return common.Hash{}, err
+	}
 	return s.t.Hash()
 }
 
+// Err returns the error of the first trie node which could not be loaded
+// from the database, if the trie was loaded lazily from the database.
+// The values read from the trie state since are not reliable.
+func (s *TrieState) Err() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.err != nil {
+		return s.err
+	}
+	return s.t.Err()
+}
+
 // Has returns whether or not a key exists
 func (s *TrieState) Has(key []byte) bool {
 	return s.Get(key) != nil
@@ -122,7 +149,7 @@ func (s *TrieState) ClearPrefix(prefix []byte) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.t.ClearPrefix(prefix)
-	return nil
+	return s.t.Err()
 }
 
 // ClearPrefixLimit deletes key-value pairs from the trie where the key starts with the given prefix till limit reached
@@ -234,7 +261,7 @@ func (s *TrieState) ClearPrefixInChild(keyToChild, prefix []byte) error {
 	}
 
 	child.ClearPrefix(prefix)
-	return nil
+	return child.Err()
 }
 
 // GetChildNextKey returns the next lexicographical larger key from child storage. If it does not exist, it returns nil.
@@ -248,7 +275,7 @@ func (s *TrieState) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
 	if child == nil {
 		return nil, nil
 	}
-	return child.NextKey(key), nil
+	return child.NextKey(key), child.Err()
 }
 
 // GetKeysWithPrefixFromChild ...
@@ -260,7 +287,7 @@ func (s *TrieState) GetKeysWithPrefixFromChild(keyToChild, prefix []byte) ([][]b
 	if child == nil {
 		return nil, nil
 	}
-	return child.GetKeysWithPrefix(prefix), nil
+	return child.GetKeysWithPrefix(prefix), child.Err()
 }
 
 // LoadCode returns the runtime code (located at :code)
@@ -271,6 +298,10 @@ func (s *TrieState) LoadCode() []byte {
 // LoadCodeHash returns the hash of the runtime code (located at :code)
 func (s *TrieState) LoadCodeHash() (common.Hash, error) {
 	code := s.LoadCode()
+	err := s.Err()
+	if err != nil {
+		return common.Hash{}, err
+	}
 	return common.Blake2bHash(code)
 }
 
@@ -12,6 +12,7 @@
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -239,3 +240,40 @@ func TestTrieState_DeleteChildLimit(t *testing.T) {
 		require.Equal(t, test.expectedDelAll, all)
 	}
 }
+
+func TestTrieState_Err(t *testing.T) {
+	tr := trie.NewEmptyTrie()
+	for i := 0; i < 100; i++ {
+		tr.Put([]byte(fmt.Sprintf("key%d", i)), bytes.Repeat([]byte{byte(i)}, 40))
+	}
+
+	db, err := utils.SetupDatabase(t.TempDir(), true)
+	require.NoError(t, err)
+	err = tr.Store(db)
+	require.NoError(t, err)
+
+	// only the root node is in the database of the lazy trie
+	rootHash := tr.MustHash()
+	encodedRoot, err := db.Get(rootHash.ToBytes())
+	require.NoError(t, err)
+	lazyDB, err := utils.SetupDatabase(t.TempDir(), true)
+	require.NoError(t, err)
+	err = lazyDB.Put(rootHash.ToBytes(), encodedRoot)
+	require.NoError(t, err)
+
+	lazyTrie, err := trie.NewLazyTrie(lazyDB, nil, rootHash).Trie()
+	require.NoError(t, err)
+	ts, err := NewTrieState(lazyTrie)
+	require.NoError(t, err)
+	require.NoError(t, ts.Err())
+
+	// the error is kept when the failed transaction is rolled back
+	ts.BeginStorageTransaction()
+	require.Nil(t, ts.Get([]byte("key1")))
+	require.ErrorContains(t, ts.Err(), "cannot resolve trie node")
+	ts.RollbackStorageTransaction()
+	require.ErrorContains(t, ts.Err(), "cannot resolve trie node")
+
+	_, err = ts.Root()
+	require.ErrorContains(t, err, "cannot resolve trie node")
+}
@@ -0,0 +1,315 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+var errUnknownWasmValue = errors.New("unknown wasm value type")
+
+// WasmLevel is the level of a tracing span or event of the runtime.
+type WasmLevel uint8
+
+// Levels of the tracing spans and events of the runtime.
+const (
+	WasmLevelError WasmLevel = iota
+	WasmLevelWarn
+	WasmLevelInfo
+	WasmLevelDebug
+	WasmLevelTrace
+)
+
+func (l WasmLevel) logLevel() log.Level {
+	if l > WasmLevelTrace {
+		return log.Trace
+	}
+	return log.Error + log.Level(l)
+}
+
+// WasmMetadata is the metadata of a tracing span or event of the runtime.
+type WasmMetadata struct {
+	Name       []byte
+	Target     []byte
+	Level      WasmLevel
+	File       []byte
+	Line       uint32
+	ModulePath []byte
+	IsSpan     bool
+	Fields     [][]byte
+}
+
+// WasmField is a field of a tracing span or event of the runtime,
+// with its value formatted as a string. The value is empty if unset.
+type WasmField struct {
+	Name  string
+	Value string
+}
+
+// WasmEntryAttributes are the attributes of a tracing span or event of the runtime.
+type WasmEntryAttributes struct {
+	ParentID *uint64
+	Metadata WasmMetadata
+	Fields   []WasmField
+}
+
+// DecodeWasmEntryAttributes decodes the SCALE encoded attributes of a tracing span or event.
+func DecodeWasmEntryAttributes(data []byte) (attributes WasmEntryAttributes, err error) {
+	decoder := scale.NewDecoder(bytes.NewReader(data))
+
+	err = decoder.Decode(&attributes.ParentID)
+	if err != nil {
+		return attributes, fmt.Errorf("cannot decode parent id: %w", err)
+	}
+
+	err = decoder.Decode(&attributes.Metadata)
+	if err != nil {
+		return attributes, fmt.Errorf("cannot decode metadata: %w", err)
+	}
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return attributes, fmt.Errorf("cannot decode number of fields: %w", err)
+	}
+
+	for i := uint(0); i < length; i++ {
+		field, err := decodeWasmField(decoder)
+		if err != nil {
+			return attributes, fmt.Errorf("cannot decode field %d: %w", i, err)
+		}
+		attributes.Fields = append(attributes.Fields, field)
+	}
+
+	return attributes, nil
+}
+
+// decodeWasmField decodes a field name and its optional value,
+// being a WasmValue enum formatted as a string.
+func decodeWasmField(decoder *scale.Decoder) (field WasmField, err error) {
+	var name []byte
+	err = decoder.Decode(&name)
+	if err != nil {
+		return field, err
+	}
+	field.Name = string(name)
+
+	var isSome bool
+	err = decoder.Decode(&isSome)
+	if err != nil || !isSome {
+		return field, err
+	}
+
+	var index byte
+	err = decoder.Decode(&index)
+	if err != nil {
+		return field, err
+	}
+
+	var value interface{}
+	switch index {
+	case 0:
+		value = new(uint8)
+	case 1:
+		value = new(int8)
+	case 2:
+		value = new(uint32)
+	case 3:
+		value = new(int32)
+	case 4:
+		value = new(int64)
+	case 5:
+		value = new(uint64)
+	case 6:
+		value = new(bool)
+	case 7, 8, 9:
+		value = new([]byte)
+	default:
+		return field, fmt.Errorf("%w: %d", errUnknownWasmValue, index)
+	}
+
+	err = decoder.Decode(value)
+	if err != nil {
+		return field, err
+	}
+
+	switch v := value.(type) {
+	case *[]byte:
+		if index == 9 {
+			// SCALE encoded value
+			field.Value = common.BytesToHex(*v)
+		} else {
+			field.Value = string(*v)
+		}
+	case *uint8:
+		field.Value = fmt.Sprint(*v)
+	case *int8:
+		field.Value = fmt.Sprint(*v)
+	case *uint32:
+		field.Value = fmt.Sprint(*v)
+	case *int32:
+		field.Value = fmt.Sprint(*v)
+	case *int64:
+		field.Value = fmt.Sprint(*v)
+	case *uint64:
+		field.Value = fmt.Sprint(*v)
+	case *bool:
+		field.Value = fmt.Sprint(*v)
+	}
+
+	return field, nil
+}
+
+// message returns the message field of an event followed by its
+// other fields, or the fields of a span, formatted for logging.
+func (a WasmEntryAttributes) message() string {
+	var message string
+	fields := make([]string, 0, len(a.Fields))
+	for _, field := range a.Fields {
+		if field.Name == "message" && message == "" {
+			message = field.Value
+			continue
+		}
+		fields = append(fields, field.Name+"="+field.Value)
+	}
+
+	if message == "" {
+		return strings.Join(fields, " ")
+	}
+	return strings.Join(append([]string{message}, fields...), " ")
+}
+
+type wasmSpan struct {
+	name    string
+	target  string
+	level   log.Level
+	entered time.Time
+}
+
+// WasmTracer handles the tracing spans and events of the runtime of an instance,
+// logging them with the logger of their target. The spans are also recorded in
+// the context storage if it is a SpanTracer. The calls of an instance being
+// sequential, it is not safe for concurrent use.
+type WasmTracer struct {
+	loggers *TargetLoggers
+	lastID  uint64
+	spans   map[uint64]wasmSpan
+	// stack holds the ids of the spans entered and not exited yet,
+	// the last one being the parent of the spans entered next.
+	stack []uint64
+}
+
+// NewWasmTracer returns a new WasmTracer logging with the given target loggers.
+func NewWasmTracer(loggers *TargetLoggers) *WasmTracer {
+	return &WasmTracer{
+		loggers: loggers,
+		spans:   make(map[uint64]wasmSpan),
+	}
+}
+
+// Enabled returns true if the span or event with the given SCALE encoded metadata
+// is logged by the logger of its target or if the spans are recorded by the storage.
+func (t *WasmTracer) Enabled(storage Storage, encodedMetadata []byte) (bool, error) {
+	if _, ok := storage.(SpanTracer); ok {
+		return true, nil
+	}
+
+	var metadata WasmMetadata
+	err := scale.Unmarshal(encodedMetadata, &metadata)
+	if err != nil {
+		return false, fmt.Errorf("cannot decode metadata: %w", err)
+	}
+
+	return t.loggers.Get(string(metadata.Target)).Level() >= metadata.Level.logLevel(), nil
+}
+
+// EnterSpan enters the span with the given SCALE encoded attributes, and returns its id.
+func (t *WasmTracer) EnterSpan(storage Storage, encodedAttributes []byte) (id uint64, err error) {
+	attributes, err := DecodeWasmEntryAttributes(encodedAttributes)
+	if err != nil {
+		return 0, err
+	}
+
+	var parentID uint64
+	if attributes.ParentID != nil {
+		parentID = *attributes.ParentID
+	} else if len(t.stack) > 0 {
+		parentID = t.stack[len(t.stack)-1]
+	}
+
+	t.lastID++
+	id = t.lastID
+	span := wasmSpan{
+		name:    string(attributes.Metadata.Name),
+		target:  string(attributes.Metadata.Target),
+		level:   attributes.Metadata.Level.logLevel(),
+		entered: time.Now(),
+	}
+	t.spans[id] = span
+	t.stack = append(t.stack, id)
+
+	if tracer, ok := storage.(SpanTracer); ok {
+		tracer.EnterSpan(id, parentID, span.target, span.name)
+	}
+
+	t.log(span.target, span.level, "entered span "+span.name+" "+attributes.message())
+	return id, nil
+}
+
+// ExitSpan exits the span with the given id. Unknown ids are ignored.
+func (t *WasmTracer) ExitSpan(storage Storage, id uint64) {
+	span, ok := t.spans[id]
+	if !ok {
+		return
+	}
+	delete(t.spans, id)
+
+	for i := len(t.stack) - 1; i >= 0; i-- {
+		if t.stack[i] == id {
+			t.stack = append(t.stack[:i], t.stack[i+1:]...)
+			break
+		}
+	}
+
+	if tracer, ok := storage.(SpanTracer); ok {
+		tracer.ExitSpan(id)
+	}
+
+	t.log(span.target, span.level, fmt.Sprintf("exited span %s after %s", span.name, time.Since(span.entered)))
+}
+
+// Event logs the event with the given SCALE encoded attributes.
+func (t *WasmTracer) Event(encodedAttributes []byte) error {
+	attributes, err := DecodeWasmEntryAttributes(encodedAttributes)
+	if err != nil {
+		return err
+	}
+
+	t.log(string(attributes.Metadata.Target), attributes.Metadata.Level.logLevel(), attributes.message())
+	return nil
+}
+
+func (t *WasmTracer) log(target string, level log.Level, message string) {
+	targetLogger := t.loggers.Get(target)
+	switch level {
+	case log.Error:
+		targetLogger.Error(message)
+	case log.Warn:
+		targetLogger.Warn(message)
+	case log.Info:
+		targetLogger.Info(message)
+	case log.Debug:
+		targetLogger.Debug(message)
+	default:
+		targetLogger.Trace(message)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encodedField struct {
+	name  string
+	index byte
+	value interface{}
+}
+
+func encodeAttributes(t *testing.T, parentID *uint64, metadata WasmMetadata, fields ...encodedField) []byte {
+	t.Helper()
+
+	encoded, err := scale.Marshal(parentID)
+	require.NoError(t, err)
+
+	encodedMetadata, err := scale.Marshal(metadata)
+	require.NoError(t, err)
+	encoded = append(encoded, encodedMetadata...)
+
+	length, err := scale.Marshal(uint(len(fields)))
+	require.NoError(t, err)
+	encoded = append(encoded, length...)
+
+	for _, field := range fields {
+		name, err := scale.Marshal([]byte(field.name))
+		require.NoError(t, err)
+		encoded = append(encoded, name...)
+
+		if field.value == nil {
+			encoded = append(encoded, 0)
+			continue
+		}
+
+		value, err := scale.Marshal(field.value)
+		require.NoError(t, err)
+		encoded = append(encoded, 1, field.index)
+		encoded = append(encoded, value...)
+	}
+
+	return encoded
+}
+
+func Test_DecodeWasmEntryAttributes(t *testing.T) {
+	t.Parallel()
+
+	parentID := uint64(3)
+	metadata := WasmMetadata{
+		Name:       []byte("apply_extrinsic"),
+		Target:     []byte("frame_executive"),
+		Level:      WasmLevelDebug,
+		File:       []byte("lib.rs"),
+		Line:       12,
+		ModulePath: []byte("frame_executive"),
+		IsSpan:     true,
+		Fields:     [][]byte{[]byte("ext")},
+	}
+
+	encoded := encodeAttributes(t, &parentID, metadata,
+		encodedField{name: "unset"},
+		encodedField{name: "u8", index: 0, value: uint8(1)},
+		encodedField{name: "i32", index: 3, value: int32(-2)},
+		encodedField{name: "bool", index: 6, value: true},
+		encodedField{name: "str", index: 7, value: []byte("hello")},
+		encodedField{name: "encoded", index: 9, value: []byte{0x01, 0x02}},
+	)
+
+	attributes, err := DecodeWasmEntryAttributes(encoded)
+	require.NoError(t, err)
+
+	expected := WasmEntryAttributes{
+		ParentID: &parentID,
+		Metadata: metadata,
+		Fields: []WasmField{
+			{Name: "unset"},
+			{Name: "u8", Value: "1"},
+			{Name: "i32", Value: "-2"},
+			{Name: "bool", Value: "true"},
+			{Name: "str", Value: "hello"},
+			{Name: "encoded", Value: "0x0102"},
+		},
+	}
+	assert.Equal(t, expected, attributes)
+
+	encoded = encodeAttributes(t, nil, metadata, encodedField{name: "bad", index: 10, value: uint8(1)})
+	_, err = DecodeWasmEntryAttributes(encoded)
+	assert.ErrorIs(t, err, errUnknownWasmValue)
+	assert.EqualError(t, err, "cannot decode field 0: unknown wasm value type: 10")
+}
+
+type spanTracerStorage struct {
+	Storage
+	entered [][2]uint64
+	exited  []uint64
+}
+
+func (s *spanTracerStorage) EnterSpan(id, parentID uint64, _, _ string) {
+	s.entered = append(s.entered, [2]uint64{id, parentID})
+}
+
+func (s *spanTracerStorage) ExitSpan(id uint64) {
+	s.exited = append(s.exited, id)
+}
+
+func Test_WasmTracer(t *testing.T) {
+	t.Parallel()
+
+	buffer := bytes.NewBuffer(nil)
+	tracer := NewWasmTracer(NewTargetLoggers(log.New(log.SetWriter(buffer), log.SetLevel(log.Info))))
+
+	infoMetadata := WasmMetadata{Name: []byte("span"), Target: []byte("pallet"), Level: WasmLevelInfo}
+	debugMetadata := WasmMetadata{Name: []byte("event"), Target: []byte("pallet"), Level: WasmLevelDebug}
+
+	encodedMetadata, err := scale.Marshal(infoMetadata)
+	require.NoError(t, err)
+	enabled, err := tracer.Enabled(nil, encodedMetadata)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	encodedMetadata, err = scale.Marshal(debugMetadata)
+	require.NoError(t, err)
+	enabled, err = tracer.Enabled(nil, encodedMetadata)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	storage := &spanTracerStorage{}
+	enabled, err = tracer.Enabled(storage, encodedMetadata)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	outer, err := tracer.EnterSpan(storage, encodeAttributes(t, nil, infoMetadata))
+	require.NoError(t, err)
+	inner, err := tracer.EnterSpan(storage, encodeAttributes(t, nil, infoMetadata))
+	require.NoError(t, err)
+	tracer.ExitSpan(storage, inner)
+	tracer.ExitSpan(storage, outer)
+	tracer.ExitSpan(storage, outer)
+
+	assert.Equal(t, [][2]uint64{{1, 0}, {2, 1}}, storage.entered)
+	assert.Equal(t, []uint64{2, 1}, storage.exited)
+	assert.Contains(t, buffer.String(), "entered span span")
+	assert.Contains(t, buffer.String(), "exited span span after")
+
+	buffer.Reset()
+	err = tracer.Event(encodeAttributes(t, nil, infoMetadata,
+		encodedField{name: "count", index: 2, value: uint32(2)},
+		encodedField{name: "message", index: 8, value: []byte("hello")},
+	))
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "hello count=2")
+}
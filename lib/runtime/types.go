@@ -70,6 +70,10 @@ type Context struct {
 	Transaction     TransactionState
 	SigVerifier     *crypto.SignatureVerifier
 	OffchainHTTPSet *offchain.HTTPSet
+	// Profiler records the host function calls of the runtime if set.
+	Profiler *Profiler
+	// Tracer handles the tracing spans and events of the runtime if set.
+	Tracer *WasmTracer
 }
 
 // NewValidateTransactionError returns an error based on a return value from TaggedTransactionQueueValidateTransaction
@@ -5,6 +5,7 @@
 
 import (
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"golang.org/x/crypto/blake2b"
 )
 
 //go:generate mockery --name Version --structname Version --case underscore --keeptree
@@ -27,6 +28,27 @@ type APIItem struct {
 	Ver  uint32
 }
 
+// APIID returns the id of the runtime API with the given name, such as
+// `Core`, being the 8 bytes blake2b hash of its name.
+func APIID(name string) (id [8]byte) {
+	h, _ := blake2b.New(len(id), nil) // cannot fail for a size between 1 and 64
+	_, _ = h.Write([]byte(name))
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+// APIVersion returns the version of the runtime API with the given name implemented
+// by the runtime of the given version, and false if the runtime does not implement it.
+func APIVersion(version Version, name string) (apiVersion uint32, ok bool) {
+	id := APIID(name)
+	for _, item := range version.APIItems() {
+		if item.Name == id {
+			return item.Ver, true
+		}
+	}
+	return 0, false
+}
+
 // LegacyVersionData is the runtime version info returned by legacy runtimes
 type LegacyVersionData struct {
 	specName         []byte
@@ -57,3 +57,20 @@ func TestLegacyVersionData(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, version, dec)
 }
+
+func TestAPIID(t *testing.T) {
+	require.Equal(t, [8]byte{0xdf, 0x6a, 0xcb, 0x68, 0x99, 0x07, 0x60, 0x9b}, APIID("Core"))
+}
+
+func TestAPIVersion(t *testing.T) {
+	version := NewVersionData(nil, nil, 0, 0, 0, []APIItem{
+		{Name: APIID("Core"), Ver: 3},
+	}, 0)
+
+	apiVersion, ok := APIVersion(version, "Core")
+	require.True(t, ok)
+	require.Equal(t, uint32(3), apiVersion)
+
+	_, ok = APIVersion(version, "TryRuntime")
+	require.False(t, ok)
+}
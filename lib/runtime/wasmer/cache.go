@@ -0,0 +1,388 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+const (
+	wasmerModulePath = "github.com/wasmerio/go-ext-wasm"
+	cacheFileExt     = ".wasmer"
+)
+
+var (
+	// cacheFileMagic starts the files of the compiled modules cache,
+	// it must be changed if the file format changes.
+	cacheFileMagic = []byte("gsmrwc01")
+
+	errCacheFileTooShort    = errors.New("cache file is too short")
+	errCacheFileMagic       = errors.New("cache file has an unknown format")
+	errCacheFileCodeHash    = errors.New("cache file is for another code")
+	errCacheFileChecksum    = errors.New("cache file checksum mismatch")
+	errCacheFileDeserialize = errors.New("cannot deserialize cached module")
+
+	moduleCache = NewModuleCache()
+)
+
+// DefaultModuleCacheSize is the default maximum number
+// of compiled modules kept in memory by a ModuleCache.
+const DefaultModuleCacheSize = 8
+
+// ModuleCache caches the compiled modules of runtime code by code hash, so each code
+// is compiled once for all the instances of the process. If a directory is set, the
+// compiled modules are also stored on disk and reused across restarts. Since compiled
+// modules are specific to the wasmer version, the cache files are keyed by code hash
+// and wasmer version, and the files of other wasmer versions are removed.
+// The least recently used modules are evicted from memory once the cache holds more
+// than its maximum number of modules.
+type ModuleCache struct {
+	mutex      sync.Mutex
+	dir        string
+	version    string
+	maxModules int
+	// list holds the module cache entries, from the most to the least recently used.
+	list    *list.List
+	entries map[common.Hash]*list.Element
+}
+
+// moduleCacheEntry is the compiled module of a code, which is being
+// compiled or loaded from disk until its ready channel is closed.
+type moduleCacheEntry struct {
+	codeHash common.Hash
+	ready    chan struct{}
+	module   wasm.Module
+	err      error
+	// users is the number of callers of Module which did not release the
+	// module yet. An evicted module is only closed once it has no users.
+	users   int
+	evicted bool
+}
+
+// NewModuleCache creates an in memory compiled modules cache
+// keeping up to DefaultModuleCacheSize modules.
+func NewModuleCache() *ModuleCache {
+	return &ModuleCache{
+		version:    engineVersion(),
+		maxModules: DefaultModuleCacheSize,
+		list:       list.New(),
+		entries:    make(map[common.Hash]*list.Element),
+	}
+}
+
+// SetModuleCacheDir sets the directory where the compiled modules
+// of the instances created from now on are stored.
+func SetModuleCacheDir(dir string) error {
+	return moduleCache.SetDir(dir)
+}
+
+// PrecompileModule compiles the given runtime code and stores the compiled
+// module in the cache, so the instances of the code are created faster.
+func PrecompileModule(code []byte) error {
+	code, err := decompressWasm(code)
+	if err != nil {
+		return fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	_, release, err := moduleCache.Module(code)
+	if err != nil {
+		return err
+	}
+	release()
+	return nil
+}
+
+// SetDir sets the directory where the compiled modules are stored. The directory
+// is created if needed, and the cache files of other wasmer versions are removed.
+func (c *ModuleCache) SetDir(dir string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if dir == c.dir {
+		return nil
+	}
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("cannot create module cache directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read module cache directory: %w", err)
+	}
+
+	suffix := "-" + c.version + cacheFileExt
+	for _, entry := range entries {
+		name := entry.Name()
+		stale := strings.HasSuffix(name, cacheFileExt) && !strings.HasSuffix(name, suffix)
+		interrupted := strings.Contains(name, cacheFileExt+".") && strings.HasSuffix(name, ".tmp")
+		if entry.IsDir() || (!stale && !interrupted) {
+			continue
+		}
+
+		logger.Debugf("removing stale compiled module %s", name)
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("cannot remove stale compiled module: %w", err)
+		}
+	}
+
+	c.dir = dir
+	return nil
+}
+
+// Module returns the compiled module of the given decompressed code, from memory,
+// from disk, or compiled and then cached. A cache file failing the integrity checks
+// is removed and replaced. The code is compiled without holding the cache lock, and
+// concurrent calls for the same code wait for the same compilation.
+// The release function returned must be called once the module is no longer used,
+// so an evicted module can be closed.
+func (c *ModuleCache) Module(code []byte) (module wasm.Module, release func(), err error) {
+	if len(code) == 0 {
+		return wasm.Module{}, nil, errors.New("code is empty")
+	}
+
+	codeHash, err := common.Blake2bHash(code)
+	if err != nil {
+		return wasm.Module{}, nil, fmt.Errorf("cannot hash code: %w", err)
+	}
+
+	c.mutex.Lock()
+	element, ok := c.entries[codeHash]
+	if ok {
+		c.list.MoveToFront(element)
+	} else {
+		element = c.list.PushFront(&moduleCacheEntry{
+			codeHash: codeHash,
+			ready:    make(chan struct{}),
+		})
+		c.entries[codeHash] = element
+	}
+	entry := element.Value.(*moduleCacheEntry)
+	entry.users++
+	dir := c.dir
+	c.mutex.Unlock()
+
+	release = func() { c.release(entry) }
+
+	if ok {
+		<-entry.ready
+	} else {
+		entry.module, entry.err = c.compile(dir, codeHash, code)
+
+		c.mutex.Lock()
+		if entry.err != nil {
+			// the compilation is retried by the next call.
+			c.remove(element)
+		}
+		close(entry.ready)
+		c.evict()
+		c.mutex.Unlock()
+	}
+
+	if entry.err != nil {
+		release()
+		return wasm.Module{}, nil, entry.err
+	}
+
+	return entry.module, release, nil
+}
+
+// compile loads the compiled module of the given code from the directory
+// given if it is not empty, or compiles it and stores it in the directory.
+func (c *ModuleCache) compile(dir string, codeHash common.Hash, code []byte) (wasm.Module, error) {
+	var path string
+	if dir != "" {
+		path = c.path(dir, codeHash)
+		module, err := c.load(path, codeHash)
+		if err == nil {
+			return module, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("removing invalid compiled module %s: %s", path, err)
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				logger.Warnf("cannot remove invalid compiled module %s: %s", path, err)
+			}
+		}
+	}
+
+	module, err := wasm.Compile(code)
+	if err != nil {
+		return wasm.Module{}, fmt.Errorf("cannot compile WASM code: %w", err)
+	}
+
+	if path != "" {
+		// the cache file is only an optimisation, so failing to store it is not fatal.
+		err = c.store(dir, path, codeHash, module)
+		if err != nil {
+			logger.Warnf("cannot store compiled module %s: %s", path, err)
+		}
+	}
+
+	return module, nil
+}
+
+// release releases a module returned by Module, and closes
+// it if it was evicted and this was its last user.
+func (c *ModuleCache) release(entry *moduleCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry.users--
+	if entry.evicted && entry.users == 0 && entry.err == nil {
+		entry.module.Close()
+	}
+}
+
+// evict evicts the least recently used compiled modules
+// until the cache holds at most its maximum number of modules.
+// It must be called with the cache mutex locked.
+func (c *ModuleCache) evict() {
+	for element := c.list.Back(); element != nil && c.list.Len() > c.maxModules; {
+		previous := element.Prev()
+		entry := element.Value.(*moduleCacheEntry)
+		select {
+		case <-entry.ready:
+			c.remove(element)
+			entry.evicted = true
+			if entry.users == 0 {
+				entry.module.Close()
+			}
+		default:
+			// modules being compiled are not evicted.
+		}
+		element = previous
+	}
+}
+
+// remove removes the given element from the cache.
+// It must be called with the cache mutex locked.
+func (c *ModuleCache) remove(element *list.Element) {
+	entry := element.Value.(*moduleCacheEntry)
+	c.list.Remove(element)
+	delete(c.entries, entry.codeHash)
+}
+
+func (c *ModuleCache) path(dir string, codeHash common.Hash) string {
+	return filepath.Join(dir, codeHash.String()+"-"+c.version+cacheFileExt)
+}
+
+// load reads, verifies and deserializes the compiled module stored at the given path.
+// A cache file is made of the magic bytes, the hash of the code, the blake2b checksum
+// of the serialized module, and the serialized module.
+func (c *ModuleCache) load(path string, codeHash common.Hash) (wasm.Module, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return wasm.Module{}, err
+	}
+
+	headerLength := len(cacheFileMagic) + 2*common.HashLength
+	if len(data) <= headerLength {
+		return wasm.Module{}, errCacheFileTooShort
+	}
+
+	if !bytes.Equal(data[:len(cacheFileMagic)], cacheFileMagic) {
+		return wasm.Module{}, errCacheFileMagic
+	}
+	data = data[len(cacheFileMagic):]
+
+	if !bytes.Equal(data[:common.HashLength], codeHash[:]) {
+		return wasm.Module{}, errCacheFileCodeHash
+	}
+	data = data[common.HashLength:]
+
+	checksum, serialized := data[:common.HashLength], data[common.HashLength:]
+	actualChecksum, err := common.Blake2bHash(serialized)
+	if err != nil {
+		return wasm.Module{}, fmt.Errorf("cannot hash serialized module: %w", err)
+	}
+
+	if !bytes.Equal(checksum, actualChecksum[:]) {
+		return wasm.Module{}, errCacheFileChecksum
+	}
+
+	module, err := wasm.DeserializeModule(serialized)
+	if err != nil {
+		return wasm.Module{}, fmt.Errorf("%w: %s", errCacheFileDeserialize, err)
+	}
+
+	return module, nil
+}
+
+// store serializes the compiled module to the given path in the given directory. The file
+// is written to a temporary file first, so a partially written cache file is never loaded.
+func (c *ModuleCache) store(dir, path string, codeHash common.Hash, module wasm.Module) error {
+	serialized, err := module.Serialize()
+	if err != nil {
+		return fmt.Errorf("cannot serialize module: %w", err)
+	}
+
+	checksum, err := common.Blake2bHash(serialized)
+	if err != nil {
+		return fmt.Errorf("cannot hash serialized module: %w", err)
+	}
+
+	data := make([]byte, 0, len(cacheFileMagic)+2*common.HashLength+len(serialized))
+	data = append(data, cacheFileMagic...)
+	data = append(data, codeHash[:]...)
+	data = append(data, checksum[:]...)
+	data = append(data, serialized...)
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("cannot write temporary file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close temporary file: %w", err)
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// engineVersion returns the version of the wasmer bindings the node is built
+// with, which identifies the format of the compiled modules.
+func engineVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != wasmerModulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version == "" {
+			break
+		}
+		return strings.NewReplacer("/", "_", "+", "_").Replace(dep.Version)
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// emptyModule is the smallest valid wasm module.
+var emptyModule = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+func Test_ModuleCache_Module(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cache := NewModuleCache()
+	require.NoError(t, cache.SetDir(dir))
+
+	module, release, err := cache.Module(emptyModule)
+	require.NoError(t, err)
+	release()
+
+	cached, release, err := cache.Module(emptyModule)
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, module, cached)
+
+	codeHash := common.MustBlake2bHash(emptyModule)
+	path := filepath.Join(dir, codeHash.String()+"-"+cache.version+cacheFileExt)
+	assert.FileExists(t, path)
+
+	// a new cache, as after a restart, loads the stored module.
+	restarted := NewModuleCache()
+	require.NoError(t, restarted.SetDir(dir))
+	loaded, err := restarted.load(path, codeHash)
+	require.NoError(t, err)
+	_, err = loaded.Instantiate()
+	assert.NoError(t, err)
+
+	_, release, err = restarted.Module(emptyModule)
+	require.NoError(t, err)
+	release()
+}
+
+func Test_ModuleCache_load(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cache := NewModuleCache()
+	require.NoError(t, cache.SetDir(dir))
+
+	_, release, err := cache.Module(emptyModule)
+	require.NoError(t, err)
+	release()
+
+	codeHash := common.MustBlake2bHash(emptyModule)
+	path := cache.path(dir, codeHash)
+	valid, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		corrupt    func(data []byte) []byte
+		codeHash   common.Hash
+		errWrapped error
+	}{
+		"too short": {
+			corrupt:    func(data []byte) []byte { return data[:len(cacheFileMagic)] },
+			codeHash:   codeHash,
+			errWrapped: errCacheFileTooShort,
+		},
+		"bad magic": {
+			corrupt: func(data []byte) []byte {
+				data[0]++
+				return data
+			},
+			codeHash:   codeHash,
+			errWrapped: errCacheFileMagic,
+		},
+		"other code": {
+			corrupt:    func(data []byte) []byte { return data },
+			codeHash:   common.Hash{1},
+			errWrapped: errCacheFileCodeHash,
+		},
+		"corrupted module": {
+			corrupt: func(data []byte) []byte {
+				data[len(data)-1]++
+				return data
+			},
+			codeHash:   codeHash,
+			errWrapped: errCacheFileChecksum,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "module"+cacheFileExt)
+			data := testCase.corrupt(append([]byte(nil), valid...))
+			require.NoError(t, os.WriteFile(path, data, os.ModePerm))
+
+			_, err := cache.load(path, testCase.codeHash)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
+
+func Test_ModuleCache_Module_invalidFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cache := NewModuleCache()
+	require.NoError(t, cache.SetDir(dir))
+
+	codeHash := common.MustBlake2bHash(emptyModule)
+	path := cache.path(dir, codeHash)
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), os.ModePerm))
+
+	_, release, err := cache.Module(emptyModule)
+	require.NoError(t, err)
+	release()
+
+	// the invalid file is replaced by a valid one.
+	_, err = cache.load(path, codeHash)
+	assert.NoError(t, err)
+}
+
+func Test_ModuleCache_Module_concurrent(t *testing.T) {
+	t.Parallel()
+
+	cache := NewModuleCache()
+
+	const callers = 8
+	modules := make(chan wasm.Module, callers)
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			module, release, err := cache.Module(emptyModule)
+			assert.NoError(t, err)
+			release()
+			modules <- module
+		}()
+	}
+	wg.Wait()
+	close(modules)
+
+	// the code is compiled once for all the concurrent callers.
+	first := <-modules
+	for module := range modules {
+		assert.Equal(t, first, module)
+	}
+	assert.Equal(t, 1, cache.list.Len())
+}
+
+func Test_ModuleCache_Module_evict(t *testing.T) {
+	t.Parallel()
+
+	cache := NewModuleCache()
+	cache.maxModules = 2
+
+	// modules differing only by a custom section.
+	codes := make([][]byte, 3)
+	for i := range codes {
+		codes[i] = append(append([]byte(nil), emptyModule...), 0x00, 0x02, 0x01, byte('a'+i))
+	}
+
+	_, release, err := cache.Module(codes[0])
+	require.NoError(t, err)
+	// the first module is used until the end of the test.
+	defer release()
+
+	for _, code := range codes[1:] {
+		_, release, err := cache.Module(code)
+		require.NoError(t, err)
+		release()
+	}
+
+	// the least recently used module is evicted, even though it is still used.
+	require.Equal(t, 2, cache.list.Len())
+	assert.NotContains(t, cache.entries, common.MustBlake2bHash(codes[0]))
+	assert.Contains(t, cache.entries, common.MustBlake2bHash(codes[1]))
+	assert.Contains(t, cache.entries, common.MustBlake2bHash(codes[2]))
+
+	// an evicted module is compiled again when needed.
+	_, release, err = cache.Module(codes[0])
+	require.NoError(t, err)
+	release()
+	assert.Contains(t, cache.entries, common.MustBlake2bHash(codes[0]))
+	assert.NotContains(t, cache.entries, common.MustBlake2bHash(codes[1]))
+}
+
+func Test_ModuleCache_SetDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cache := NewModuleCache()
+
+	current := common.Hash{1}.String() + "-" + cache.version + cacheFileExt
+	otherVersion := common.Hash{1}.String() + "-v0.0.1" + cacheFileExt
+	interrupted := current + ".123.tmp"
+	unrelated := "unrelated"
+	for _, name := range []string{current, otherVersion, interrupted, unrelated} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, os.ModePerm))
+	}
+
+	err := cache.SetDir(dir)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{current, unrelated}, names)
+}
@@ -339,7 +339,7 @@ func TestNodeRuntime_ValidateTransaction(t *testing.T) {
 	extBytes = append([]byte{byte(types.TxnExternal)}, extBytes...)
 
 	runtime.InitializeRuntimeToTest(t, rt, genesisHeader.Hash())
-	_, err = rt.ValidateTransaction(extBytes)
+	_, err = rt.ValidateTransaction(extBytes, genesisHeader.Hash())
 	require.NoError(t, err)
 }
 
@@ -25,6 +25,9 @@
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_version_2(void *context, int32_t a, int32_t b);
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(void *context, int32_t a, int32_t b);
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(void *context, int32_t a, int32_t b);
+// extern int32_t ext_crypto_ecdsa_generate_version_1(void *context, int32_t a, int64_t b);
+// extern int64_t ext_crypto_ecdsa_public_keys_version_1(void *context, int32_t a);
+// extern int64_t ext_crypto_ecdsa_sign_version_1(void *context, int32_t a, int32_t b, int64_t c);
 // extern int32_t ext_crypto_ecdsa_verify_version_2(void *context, int32_t a, int64_t b, int32_t c);
 // extern int32_t ext_crypto_sr25519_generate_version_1(void *context, int32_t a, int64_t b);
 // extern int64_t ext_crypto_sr25519_public_keys_version_1(void *context, int32_t a);
@@ -98,6 +101,11 @@
 //
 // extern void ext_transaction_index_index_version_1(void *context, int32_t a, int32_t b, int32_t c);
 // extern void ext_transaction_index_renew_version_1(void *context, int32_t a, int32_t b);
+//
+// extern int32_t ext_wasm_tracing_enabled_version_1(void *context, int64_t a);
+// extern int64_t ext_wasm_tracing_enter_span_version_1(void *context, int64_t a);
+// extern void ext_wasm_tracing_event_version_1(void *context, int64_t a);
+// extern void ext_wasm_tracing_exit_version_1(void *context, int64_t a);
 import "C"
 
 import (
@@ -117,6 +125,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/transaction"
@@ -128,54 +137,148 @@
 
 //export ext_logging_log_version_1
 func ext_logging_log_version_1(context unsafe.Pointer, level C.int32_t, targetData, msgData C.int64_t) {
+	defer profileHostFunction(context, "ext_logging_log_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	target := string(asMemorySlice(instanceContext, targetData))
 	msg := string(asMemorySlice(instanceContext, msgData))
 
-	switch int(level) {
-	case 0:
-		logger.Critical("target=" + target + " message=" + msg)
-	case 1:
-		logger.Warn("target=" + target + " message=" + msg)
-	case 2:
-		logger.Info("target=" + target + " message=" + msg)
-	case 3:
-		logger.Debug("target=" + target + " message=" + msg)
-	case 4:
-		logger.Trace("target=" + target + " message=" + msg)
-	default:
-		logger.Errorf("level=%d target=%s message=%s", int(level), target, msg)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	if tracer, ok := runtimeCtx.Storage.(runtime.LogTargetTracer); ok {
+		tracer.SetTarget(target)
 	}
+
+	targetLoggers.Log(int32(level), target, msg)
 }
 
 //export ext_logging_max_level_version_1
 func ext_logging_max_level_version_1(context unsafe.Pointer) C.int32_t {
+	defer profileHostFunction(context, "ext_logging_max_level_version_1")()
+	logger.Trace("executing...")
+	return C.int32_t(targetLoggers.MaxLevel())
+}
+
+//export ext_wasm_tracing_enabled_version_1
+func ext_wasm_tracing_enabled_version_1(context unsafe.Pointer, metadataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_wasm_tracing_enabled_version_1")()
+	logger.Trace("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return 0
+	}
+
+	enabled, err := runtimeCtx.Tracer.Enabled(runtimeCtx.Storage, asMemorySlice(instanceContext, metadataSpan))
+	if err != nil {
+		logger.Errorf("failed to check if tracing is enabled: %s", err)
+		return 0
+	}
+
+	if enabled {
+		return 1
+	}
+	return 0
+}
+
+//export ext_wasm_tracing_enter_span_version_1
+func ext_wasm_tracing_enter_span_version_1(context unsafe.Pointer, attributesSpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_wasm_tracing_enter_span_version_1")()
+	logger.Trace("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return 0
+	}
+
+	id, err := runtimeCtx.Tracer.EnterSpan(runtimeCtx.Storage, asMemorySlice(instanceContext, attributesSpan))
+	if err != nil {
+		logger.Errorf("failed to enter tracing span: %s", err)
+		return 0
+	}
+
+	return C.int64_t(id)
+}
+
+//export ext_wasm_tracing_exit_version_1
+func ext_wasm_tracing_exit_version_1(context unsafe.Pointer, id C.int64_t) {
+	defer profileHostFunction(context, "ext_wasm_tracing_exit_version_1")()
+	logger.Trace("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return
+	}
+
+	runtimeCtx.Tracer.ExitSpan(runtimeCtx.Storage, uint64(id))
+}
+
+//export ext_wasm_tracing_event_version_1
+func ext_wasm_tracing_event_version_1(context unsafe.Pointer, attributesSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_wasm_tracing_event_version_1")()
 	logger.Trace("executing...")
-	return 4
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return
+	}
+
+	err := runtimeCtx.Tracer.Event(asMemorySlice(instanceContext, attributesSpan))
+	if err != nil {
+		logger.Errorf("failed to log tracing event: %s", err)
+	}
 }
 
 //export ext_transaction_index_index_version_1
-func ext_transaction_index_index_version_1(context unsafe.Pointer, a, b, c C.int32_t) {
+func ext_transaction_index_index_version_1(context unsafe.Pointer, extrinsic, size, contentHash C.int32_t) {
+	defer profileHostFunction(context, "ext_transaction_index_index_version_1")()
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	hash := common.NewHash(memory[contentHash : contentHash+32])
+
+	storage := instanceContext.Data().(*runtime.Context).Storage
+	indexer, ok := storage.(runtime.TransactionIndexer)
+	if !ok {
+		logger.Debugf("storage does not index transactions, ignoring extrinsic %d with hash %s", extrinsic, hash)
+		return
+	}
+
+	logger.Debugf("indexing %d bytes of extrinsic %d with hash %s", size, extrinsic, hash)
+	indexer.IndexTransaction(uint32(extrinsic), hash, uint32(size))
 }
 
 //export ext_transaction_index_renew_version_1
-func ext_transaction_index_renew_version_1(context unsafe.Pointer, a, b C.int32_t) {
+func ext_transaction_index_renew_version_1(context unsafe.Pointer, extrinsic, contentHash C.int32_t) {
+	defer profileHostFunction(context, "ext_transaction_index_renew_version_1")()
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	hash := common.NewHash(memory[contentHash : contentHash+32])
+
+	storage := instanceContext.Data().(*runtime.Context).Storage
+	indexer, ok := storage.(runtime.TransactionIndexer)
+	if !ok {
+		logger.Debugf("storage does not index transactions, ignoring renewal of hash %s by extrinsic %d", hash, extrinsic)
+		return
+	}
+
+	logger.Debugf("renewing hash %s by extrinsic %d", hash, extrinsic)
+	indexer.RenewTransaction(uint32(extrinsic), hash)
 }
 
 //export ext_sandbox_instance_teardown_version_1
 func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+	defer profileHostFunction(context, "ext_sandbox_instance_teardown_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 }
 
 //export ext_sandbox_instantiate_version_1
 func ext_sandbox_instantiate_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_sandbox_instantiate_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 	return 0
@@ -183,6 +286,7 @@ func ext_sandbox_instantiate_version_1(context unsafe.Pointer, a C.int32_t, x, y
 
 //export ext_sandbox_invoke_version_1
 func ext_sandbox_invoke_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z, d, e C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_sandbox_invoke_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 	return 0
@@ -190,6 +294,7 @@ func ext_sandbox_invoke_version_1(context unsafe.Pointer, a C.int32_t, x, y C.in
 
 //export ext_sandbox_memory_get_version_1
 func ext_sandbox_memory_get_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_sandbox_memory_get_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 	return 0
@@ -197,6 +302,7 @@ func ext_sandbox_memory_get_version_1(context unsafe.Pointer, a, z, d, e C.int32
 
 //export ext_sandbox_memory_new_version_1
 func ext_sandbox_memory_new_version_1(context unsafe.Pointer, a, z C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_sandbox_memory_new_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 	return 0
@@ -204,6 +310,7 @@ func ext_sandbox_memory_new_version_1(context unsafe.Pointer, a, z C.int32_t) C.
 
 //export ext_sandbox_memory_set_version_1
 func ext_sandbox_memory_set_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_sandbox_memory_set_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 	return 0
@@ -211,12 +318,14 @@ func ext_sandbox_memory_set_version_1(context unsafe.Pointer, a, z, d, e C.int32
 
 //export ext_sandbox_memory_teardown_version_1
 func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+	defer profileHostFunction(context, "ext_sandbox_memory_teardown_version_1")()
 	logger.Trace("executing...")
 	logger.Warn("unimplemented")
 }
 
 //export ext_crypto_ed25519_generate_version_1
 func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_ed25519_generate_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -252,7 +361,7 @@ func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 		return 0
 	}
 
-	err = ks.Insert(kp)
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
 	if err != nil {
 		logger.Warnf("failed to insert key: %s", err)
 		return 0
@@ -270,6 +379,7 @@ func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 
 //export ext_crypto_ed25519_public_keys_version_1
 func ext_crypto_ed25519_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_ed25519_public_keys_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -293,7 +403,7 @@ func ext_crypto_ed25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 		return C.int64_t(ret)
 	}
 
-	keys := ks.PublicKeys()
+	keys := keystore.PublicKeysOfType(ks, crypto.Ed25519Type)
 
 	var encodedKeys []byte
 	for _, key := range keys {
@@ -319,6 +429,7 @@ func ext_crypto_ed25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 
 //export ext_crypto_ed25519_sign_version_1
 func ext_crypto_ed25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_ed25519_sign_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -369,6 +480,7 @@ func ext_crypto_ed25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.
 
 //export ext_crypto_ed25519_verify_version_1
 func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_ed25519_verify_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -407,6 +519,7 @@ func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, sig C.int32_t,
 
 //export ext_crypto_secp256k1_ecdsa_recover_version_1
 func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_secp256k1_ecdsa_recover_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -444,12 +557,171 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, sig, m
 
 //export ext_crypto_secp256k1_ecdsa_recover_version_2
 func ext_crypto_secp256k1_ecdsa_recover_version_2(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_secp256k1_ecdsa_recover_version_2")()
 	logger.Trace("executing...")
 	return ext_crypto_secp256k1_ecdsa_recover_version_1(context, sig, msg)
 }
 
+//export ext_crypto_ecdsa_generate_version_1
+func ext_crypto_ecdsa_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_ecdsa_generate_version_1")()
+	logger.Trace("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(instanceContext, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp crypto.Keypair
+	if seed != nil {
+		kp, err = secp256k1.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = secp256k1.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(instanceContext, kp.Public().Encode(), 33)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ecdsa keypair with public key: " + kp.Public().Hex())
+	return C.int32_t(ret)
+}
+
+//export ext_crypto_ecdsa_public_keys_version_1
+func ext_crypto_ecdsa_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_ecdsa_public_keys_version_1")()
+	logger.Debug("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	if ks.Type() != crypto.Secp256k1Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected ecdsa",
+			id, ks.Type())
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	keys := keystore.PublicKeysOfType(ks, crypto.Secp256k1Type)
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	ret, err := toWasmMemory(instanceContext, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	return C.int64_t(ret)
+}
+
+//export ext_crypto_ecdsa_sign_version_1
+func ext_crypto_ecdsa_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_ecdsa_sign_version_1")()
+	logger.Debug("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	emptyRet, _ := toWasmMemoryOptional(instanceContext, nil)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return C.int64_t(emptyRet)
+	}
+
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(memory[key : key+33])
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return C.int64_t(emptyRet)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return C.int64_t(emptyRet)
+	}
+
+	// the message is signed by its blake2b-256 hash, which ext_crypto_ecdsa_verify_version_2 verifies
+	hash, err := common.Blake2bHash(asMemorySlice(instanceContext, msg))
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return C.int64_t(emptyRet)
+	}
+
+	sig, err := signingKey.Sign(hash[:])
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return C.int64_t(emptyRet)
+	}
+
+	ret, err := toWasmMemoryFixedSizeOptional(instanceContext, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return C.int64_t(emptyRet)
+	}
+
+	return C.int64_t(ret)
+}
+
 //export ext_crypto_ecdsa_verify_version_2
 func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_ecdsa_verify_version_2")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -498,6 +770,7 @@ func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, ms
 
 //export ext_crypto_secp256k1_ecdsa_recover_compressed_version_1
 func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_secp256k1_ecdsa_recover_compressed_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -530,12 +803,14 @@ func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Poin
 
 //export ext_crypto_secp256k1_ecdsa_recover_compressed_version_2
 func ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_secp256k1_ecdsa_recover_compressed_version_2")()
 	logger.Trace("executing...")
 	return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context, sig, msg)
 }
 
 //export ext_crypto_sr25519_generate_version_1
 func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_sr25519_generate_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -570,7 +845,7 @@ func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 		return 0
 	}
 
-	err = ks.Insert(kp)
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
 	if err != nil {
 		logger.Warnf("failed to insert key: %s", err)
 		return 0
@@ -588,6 +863,7 @@ func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 
 //export ext_crypto_sr25519_public_keys_version_1
 func ext_crypto_sr25519_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_sr25519_public_keys_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -611,7 +887,7 @@ func ext_crypto_sr25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 		return C.int64_t(ret)
 	}
 
-	keys := ks.PublicKeys()
+	keys := keystore.PublicKeysOfType(ks, crypto.Sr25519Type)
 
 	var encodedKeys []byte
 	for _, key := range keys {
@@ -637,6 +913,7 @@ func ext_crypto_sr25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 
 //export ext_crypto_sr25519_sign_version_1
 func ext_crypto_sr25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_crypto_sr25519_sign_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -683,6 +960,7 @@ func ext_crypto_sr25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.
 
 //export ext_crypto_sr25519_verify_version_1
 func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_sr25519_verify_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -725,6 +1003,7 @@ func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t,
 
 //export ext_crypto_sr25519_verify_version_2
 func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_sr25519_verify_version_2")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -766,6 +1045,7 @@ func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t,
 
 //export ext_crypto_start_batch_verify_version_1
 func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
+	defer profileHostFunction(context, "ext_crypto_start_batch_verify_version_1")()
 	logger.Debug("executing...")
 
 	// TODO: fix and re-enable signature verification (#1405)
@@ -774,6 +1054,7 @@ func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
 
 //export ext_crypto_finish_batch_verify_version_1
 func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t {
+	defer profileHostFunction(context, "ext_crypto_finish_batch_verify_version_1")()
 	logger.Debug("executing...")
 
 	// TODO: fix and re-enable signature verification (#1405)
@@ -783,6 +1064,7 @@ func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t
 
 //export ext_trie_blake2_256_root_version_1
 func ext_trie_blake2_256_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_trie_blake2_256_root_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -827,6 +1109,7 @@ type kv struct {
 
 //export ext_trie_blake2_256_ordered_root_version_1
 func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_trie_blake2_256_ordered_root_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -875,12 +1158,14 @@ func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan
 
 //export ext_trie_blake2_256_ordered_root_version_2
 func ext_trie_blake2_256_ordered_root_version_2(context unsafe.Pointer, dataSpan C.int64_t, version C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_trie_blake2_256_ordered_root_version_2")()
 	// TODO: update to use state trie version 1 (#2418)
 	return ext_trie_blake2_256_ordered_root_version_1(context, dataSpan)
 }
 
 //export ext_trie_blake2_256_verify_proof_version_1
 func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer, rootSpan C.int32_t, proofSpan, keySpan, valueSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_trie_blake2_256_verify_proof_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -915,6 +1200,7 @@ func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer, rootSpan
 
 //export ext_misc_print_hex_version_1
 func ext_misc_print_hex_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_misc_print_hex_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -923,7 +1209,8 @@ func ext_misc_print_hex_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 }
 
 //export ext_misc_print_num_version_1
-func ext_misc_print_num_version_1(_ unsafe.Pointer, data C.int64_t) {
+func ext_misc_print_num_version_1(context unsafe.Pointer, data C.int64_t) {
+	defer profileHostFunction(context, "ext_misc_print_num_version_1")()
 	logger.Trace("executing...")
 
 	logger.Debugf("num: %d", int64(data))
@@ -931,6 +1218,7 @@ func ext_misc_print_num_version_1(_ unsafe.Pointer, data C.int64_t) {
 
 //export ext_misc_print_utf8_version_1
 func ext_misc_print_utf8_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_misc_print_utf8_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -940,6 +1228,7 @@ func ext_misc_print_utf8_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 
 //export ext_misc_runtime_version_version_1
 func ext_misc_runtime_version_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_misc_runtime_version_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -981,6 +1270,7 @@ func ext_misc_runtime_version_version_1(context unsafe.Pointer, dataSpan C.int64
 
 //export ext_default_child_storage_read_version_1
 func ext_default_child_storage_read_version_1(context unsafe.Pointer, childStorageKey, key, valueOut C.int64_t, offset C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_default_child_storage_read_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1011,6 +1301,7 @@ func ext_default_child_storage_read_version_1(context unsafe.Pointer, childStora
 
 //export ext_default_child_storage_clear_version_1
 func ext_default_child_storage_clear_version_1(context unsafe.Pointer, childStorageKey, keySpan C.int64_t) {
+	defer profileHostFunction(context, "ext_default_child_storage_clear_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1028,6 +1319,7 @@ func ext_default_child_storage_clear_version_1(context unsafe.Pointer, childStor
 
 //export ext_default_child_storage_clear_prefix_version_1
 func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, childStorageKey, prefixSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_default_child_storage_clear_prefix_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1045,6 +1337,7 @@ func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, ch
 
 //export ext_default_child_storage_exists_version_1
 func ext_default_child_storage_exists_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_default_child_storage_exists_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1063,6 +1356,7 @@ func ext_default_child_storage_exists_version_1(context unsafe.Pointer, childSto
 
 //export ext_default_child_storage_get_version_1
 func ext_default_child_storage_get_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_default_child_storage_get_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1085,6 +1379,7 @@ func ext_default_child_storage_get_version_1(context unsafe.Pointer, childStorag
 
 //export ext_default_child_storage_next_key_version_1
 func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_default_child_storage_next_key_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1107,6 +1402,7 @@ func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childS
 
 //export ext_default_child_storage_root_version_1
 func ext_default_child_storage_root_version_1(context unsafe.Pointer, childStorageKey C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_default_child_storage_root_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1135,6 +1431,7 @@ func ext_default_child_storage_root_version_1(context unsafe.Pointer, childStora
 
 //export ext_default_child_storage_set_version_1
 func ext_default_child_storage_set_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan, valueSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_default_child_storage_set_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1157,6 +1454,7 @@ func ext_default_child_storage_set_version_1(context unsafe.Pointer, childStorag
 
 //export ext_default_child_storage_storage_kill_version_1
 func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, childStorageKeySpan C.int64_t) {
+	defer profileHostFunction(context, "ext_default_child_storage_storage_kill_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1169,6 +1467,7 @@ func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, ch
 
 //export ext_default_child_storage_storage_kill_version_2
 func ext_default_child_storage_storage_kill_version_2(context unsafe.Pointer, childStorageKeySpan, lim C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_default_child_storage_storage_kill_version_2")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1209,6 +1508,7 @@ func (someRemain) Index() uint {
 
 //export ext_default_child_storage_storage_kill_version_3
 func ext_default_child_storage_storage_kill_version_3(context unsafe.Pointer, childStorageKeySpan, lim C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_default_child_storage_storage_kill_version_3")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1261,6 +1561,7 @@ func ext_default_child_storage_storage_kill_version_3(context unsafe.Pointer, ch
 
 //export ext_allocator_free_version_1
 func ext_allocator_free_version_1(context unsafe.Pointer, addr C.int32_t) {
+	defer profileHostFunction(context, "ext_allocator_free_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1274,6 +1575,7 @@ func ext_allocator_free_version_1(context unsafe.Pointer, addr C.int32_t) {
 
 //export ext_allocator_malloc_version_1
 func ext_allocator_malloc_version_1(context unsafe.Pointer, size C.int32_t) C.int32_t {
+	defer profileHostFunction(context, "ext_allocator_malloc_version_1")()
 	logger.Tracef("executing with size %d...", int64(size))
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1291,6 +1593,7 @@ func ext_allocator_malloc_version_1(context unsafe.Pointer, size C.int32_t) C.in
 
 //export ext_hashing_blake2_128_version_1
 func ext_hashing_blake2_128_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_blake2_128_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1317,6 +1620,7 @@ func ext_hashing_blake2_128_version_1(context unsafe.Pointer, dataSpan C.int64_t
 
 //export ext_hashing_blake2_256_version_1
 func ext_hashing_blake2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_blake2_256_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1341,6 +1645,7 @@ func ext_hashing_blake2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t
 
 //export ext_hashing_keccak_256_version_1
 func ext_hashing_keccak_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_keccak_256_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1365,6 +1670,7 @@ func ext_hashing_keccak_256_version_1(context unsafe.Pointer, dataSpan C.int64_t
 
 //export ext_hashing_sha2_256_version_1
 func ext_hashing_sha2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_sha2_256_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1384,6 +1690,7 @@ func ext_hashing_sha2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 
 //export ext_hashing_twox_256_version_1
 func ext_hashing_twox_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_twox_256_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1408,6 +1715,7 @@ func ext_hashing_twox_256_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 
 //export ext_hashing_twox_128_version_1
 func ext_hashing_twox_128_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_twox_128_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1433,6 +1741,7 @@ func ext_hashing_twox_128_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 
 //export ext_hashing_twox_64_version_1
 func ext_hashing_twox_64_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_hashing_twox_64_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1459,6 +1768,7 @@ func ext_hashing_twox_64_version_1(context unsafe.Pointer, dataSpan C.int64_t) C
 
 //export ext_offchain_index_set_version_1
 func ext_offchain_index_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_offchain_index_set_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1476,6 +1786,7 @@ func ext_offchain_index_set_version_1(context unsafe.Pointer, keySpan, valueSpan
 
 //export ext_offchain_local_storage_clear_version_1
 func ext_offchain_local_storage_clear_version_1(context unsafe.Pointer, kind C.int32_t, key C.int64_t) {
+	defer profileHostFunction(context, "ext_offchain_local_storage_clear_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1501,6 +1812,7 @@ func ext_offchain_local_storage_clear_version_1(context unsafe.Pointer, kind C.i
 
 //export ext_offchain_is_validator_version_1
 func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
+	defer profileHostFunction(context, "ext_offchain_is_validator_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1513,6 +1825,7 @@ func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
 
 //export ext_offchain_local_storage_compare_and_set_version_1
 func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer, kind C.int32_t, key, oldValue, newValue C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_offchain_local_storage_compare_and_set_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1552,6 +1865,7 @@ func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer
 
 //export ext_offchain_local_storage_get_version_1
 func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, kind C.int32_t, key C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_offchain_local_storage_get_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1582,6 +1896,7 @@ func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, kind C.int
 
 //export ext_offchain_local_storage_set_version_1
 func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, kind C.int32_t, key, value C.int64_t) {
+	defer profileHostFunction(context, "ext_offchain_local_storage_set_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1606,6 +1921,7 @@ func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, kind C.int
 
 //export ext_offchain_network_state_version_1
 func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
+	defer profileHostFunction(context, "ext_offchain_network_state_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1636,6 +1952,7 @@ func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
 
 //export ext_offchain_random_seed_version_1
 func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
+	defer profileHostFunction(context, "ext_offchain_random_seed_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1653,6 +1970,7 @@ func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
 
 //export ext_offchain_submit_transaction_version_1
 func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, data C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_offchain_submit_transaction_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1679,7 +1997,8 @@ func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, data C.in
 }
 
 //export ext_offchain_timestamp_version_1
-func ext_offchain_timestamp_version_1(_ unsafe.Pointer) C.int64_t {
+func ext_offchain_timestamp_version_1(context unsafe.Pointer) C.int64_t {
+	defer profileHostFunction(context, "ext_offchain_timestamp_version_1")()
 	logger.Trace("executing...")
 
 	now := time.Now().Unix()
@@ -1687,7 +2006,8 @@ func ext_offchain_timestamp_version_1(_ unsafe.Pointer) C.int64_t {
 }
 
 //export ext_offchain_sleep_until_version_1
-func ext_offchain_sleep_until_version_1(_ unsafe.Pointer, deadline C.int64_t) {
+func ext_offchain_sleep_until_version_1(context unsafe.Pointer, deadline C.int64_t) {
+	defer profileHostFunction(context, "ext_offchain_sleep_until_version_1")()
 	logger.Trace("executing...")
 
 	dur := time.Until(time.UnixMilli(int64(deadline)))
@@ -1698,6 +2018,7 @@ func ext_offchain_sleep_until_version_1(_ unsafe.Pointer, deadline C.int64_t) {
 
 //export ext_offchain_http_request_start_version_1
 func ext_offchain_http_request_start_version_1(context unsafe.Pointer, methodSpan, uriSpan, metaSpan C.int64_t) C.int64_t { // skipcq: RVV-B0012
+	defer profileHostFunction(context, "ext_offchain_http_request_start_version_1")()
 	logger.Debug("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1740,6 +2061,7 @@ func ext_offchain_http_request_start_version_1(context unsafe.Pointer, methodSpa
 
 //export ext_offchain_http_request_add_header_version_1
 func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer, reqID C.int32_t, nameSpan, valueSpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_offchain_http_request_add_header_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -1826,6 +2148,7 @@ func storageAppend(storage runtime.Storage, key, valueToAppend []byte) error {
 
 //export ext_storage_append_version_1
 func ext_storage_append_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_storage_append_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1848,6 +2171,7 @@ func ext_storage_append_version_1(context unsafe.Pointer, keySpan, valueSpan C.i
 
 //export ext_storage_changes_root_version_1
 func ext_storage_changes_root_version_1(context unsafe.Pointer, parentHashSpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_changes_root_version_1")()
 	logger.Trace("executing...")
 	logger.Debug("returning None")
 
@@ -1864,6 +2188,7 @@ func ext_storage_changes_root_version_1(context unsafe.Pointer, parentHashSpan C
 
 //export ext_storage_clear_version_1
 func ext_storage_clear_version_1(context unsafe.Pointer, keySpan C.int64_t) {
+	defer profileHostFunction(context, "ext_storage_clear_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1877,6 +2202,7 @@ func ext_storage_clear_version_1(context unsafe.Pointer, keySpan C.int64_t) {
 
 //export ext_storage_clear_prefix_version_1
 func ext_storage_clear_prefix_version_1(context unsafe.Pointer, prefixSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_storage_clear_prefix_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1893,6 +2219,7 @@ func ext_storage_clear_prefix_version_1(context unsafe.Pointer, prefixSpan C.int
 
 //export ext_storage_clear_prefix_version_2
 func ext_storage_clear_prefix_version_2(context unsafe.Pointer, prefixSpan, lim C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_clear_prefix_version_2")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1938,6 +2265,7 @@ func ext_storage_clear_prefix_version_2(context unsafe.Pointer, prefixSpan, lim
 
 //export ext_storage_exists_version_1
 func ext_storage_exists_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int32_t {
+	defer profileHostFunction(context, "ext_storage_exists_version_1")()
 	logger.Trace("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1955,6 +2283,7 @@ func ext_storage_exists_version_1(context unsafe.Pointer, keySpan C.int64_t) C.i
 
 //export ext_storage_get_version_1
 func ext_storage_get_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_get_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1978,6 +2307,7 @@ func ext_storage_get_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int6
 
 //export ext_storage_next_key_version_1
 func ext_storage_next_key_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_next_key_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -2001,6 +2331,7 @@ func ext_storage_next_key_version_1(context unsafe.Pointer, keySpan C.int64_t) C
 
 //export ext_storage_read_version_1
 func ext_storage_read_version_1(context unsafe.Pointer, keySpan, valueOut C.int64_t, offset C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_read_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -2039,6 +2370,7 @@ func ext_storage_read_version_1(context unsafe.Pointer, keySpan, valueOut C.int6
 
 //export ext_storage_root_version_1
 func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_root_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -2063,12 +2395,14 @@ func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
 
 //export ext_storage_root_version_2
 func ext_storage_root_version_2(context unsafe.Pointer, version C.int32_t) C.int64_t {
+	defer profileHostFunction(context, "ext_storage_root_version_2")()
 	// TODO: update to use state trie version 1 (#2418)
 	return ext_storage_root_version_1(context)
 }
 
 //export ext_storage_set_version_1
 func ext_storage_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
+	defer profileHostFunction(context, "ext_storage_set_version_1")()
 	logger.Trace("executing...")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -2089,6 +2423,7 @@ func ext_storage_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int6
 
 //export ext_storage_start_transaction_version_1
 func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
+	defer profileHostFunction(context, "ext_storage_start_transaction_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.BeginStorageTransaction()
@@ -2096,6 +2431,7 @@ func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
 
 //export ext_storage_rollback_transaction_version_1
 func ext_storage_rollback_transaction_version_1(context unsafe.Pointer) {
+	defer profileHostFunction(context, "ext_storage_rollback_transaction_version_1")()
 	logger.Debug("executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.RollbackStorageTransaction()
@@ -2103,6 +2439,7 @@ func ext_storage_rollback_transaction_version_1(context unsafe.Pointer) {
 
 //export ext_storage_commit_transaction_version_1
 func ext_storage_commit_transaction_version_1(context unsafe.Pointer) {
+	defer profileHostFunction(context, "ext_storage_commit_transaction_version_1")()
 	logger.Debug("[ext_storage_commit_transaction_version_1] executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.CommitStorageTransaction()
@@ -2112,6 +2449,7 @@ func ext_storage_commit_transaction_version_1(context unsafe.Pointer) {
 func asMemorySlice(context wasm.InstanceContext, span C.int64_t) []byte {
 	memory := context.Memory().Data()
 	ptr, size := runtime.Int64ToPointerAndSize(int64(span))
+	profileBytes(context, int(size))
 	return memory[ptr : ptr+size]
 }
 
@@ -2132,6 +2470,7 @@ func toWasmMemory(context wasm.InstanceContext, data []byte) (int64, error) {
 	}
 
 	copy(memory[out:out+size], data)
+	profileBytes(context, int(size))
 	return runtime.PointerAndSizeToInt64(int32(out), int32(size)), nil
 }
 
@@ -2150,6 +2489,7 @@ func toWasmMemorySized(context wasm.InstanceContext, data []byte, size uint32) (
 
 	memory := context.Memory().Data()
 	copy(memory[out:out+size], data)
+	profileBytes(context, int(size))
 
 	return out, nil
 }
@@ -2305,6 +2645,18 @@ func ImportsNodeRuntime() (*wasm.Imports, error) { //nolint:gocyclo
 	if err != nil {
 		return nil, err
 	}
+	_, err = imports.Append("ext_crypto_ecdsa_generate_version_1", ext_crypto_ecdsa_generate_version_1, C.ext_crypto_ecdsa_generate_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_public_keys_version_1", ext_crypto_ecdsa_public_keys_version_1, C.ext_crypto_ecdsa_public_keys_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_sign_version_1", ext_crypto_ecdsa_sign_version_1, C.ext_crypto_ecdsa_sign_version_1)
+	if err != nil {
+		return nil, err
+	}
 	_, err = imports.Append("ext_crypto_ecdsa_verify_version_2", ext_crypto_ecdsa_verify_version_2, C.ext_crypto_ecdsa_verify_version_2)
 	if err != nil {
 		return nil, err
@@ -2581,5 +2933,22 @@ func ImportsNodeRuntime() (*wasm.Imports, error) { //nolint:gocyclo
 		return nil, err
 	}
 
+	_, err = imports.Append("ext_wasm_tracing_enabled_version_1", ext_wasm_tracing_enabled_version_1, C.ext_wasm_tracing_enabled_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_wasm_tracing_enter_span_version_1", ext_wasm_tracing_enter_span_version_1, C.ext_wasm_tracing_enter_span_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_wasm_tracing_event_version_1", ext_wasm_tracing_event_version_1, C.ext_wasm_tracing_event_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_wasm_tracing_exit_version_1", ext_wasm_tracing_exit_version_1, C.ext_wasm_tracing_exit_version_1)
+	if err != nil {
+		return nil, err
+	}
+
 	return imports, nil
 }
@@ -993,6 +993,39 @@ func Test_ext_crypto_sr25519_generate_version_1(t *testing.T) {
 	require.NotNil(t, kp)
 }
 
+func Test_ext_crypto_ecdsa_generate_version_1(t *testing.T) {
+	t.Parallel()
+	t.Skip("host API tester does not yet contain rtm_ext_crypto_ecdsa_generate_version_1")
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	idData := []byte(keystore.BeefName)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
+	require.Equal(t, 0, ks.Size())
+
+	mnemonic, err := crypto.NewBIP39Mnemonic()
+	require.NoError(t, err)
+
+	mnemonicBytes := []byte(mnemonic)
+	var data = &mnemonicBytes
+	seedData, err := scale.Marshal(data)
+	require.NoError(t, err)
+
+	ret, err := inst.Exec("rtm_ext_crypto_ecdsa_generate_version_1", append(idData, seedData...))
+	require.NoError(t, err)
+
+	var out []byte
+	err = scale.Unmarshal(ret, &out)
+	require.NoError(t, err)
+
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(out)
+	require.NoError(t, err)
+	require.Equal(t, 1, ks.Size())
+
+	kp := ks.GetKeypair(pubKey)
+	require.NotNil(t, kp)
+}
+
 func Test_ext_crypto_secp256k1_ecdsa_recover_version_1(t *testing.T) {
 	t.Parallel()
 	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
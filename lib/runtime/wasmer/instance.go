@@ -8,6 +8,7 @@
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/common"
@@ -28,13 +29,16 @@
 
 // Check that runtime interfaces are satisfied
 var (
-	_ runtime.Instance = (*Instance)(nil)
-	_ runtime.Memory   = (*wasm.Memory)(nil)
+	_ runtime.Instance           = (*Instance)(nil)
+	_ runtime.PoolableInstance   = (*Instance)(nil)
+	_ runtime.ProfilableInstance = (*Instance)(nil)
+	_ runtime.Memory             = (*wasm.Memory)(nil)
 
 	logger = log.NewFromGlobal(
 		log.AddContext("pkg", "runtime"),
 		log.AddContext("module", "go-wasmer"),
 	)
+	targetLoggers = runtime.NewTargetLoggers(logger)
 )
 
 // Config represents a wasmer configuration
@@ -50,6 +54,14 @@ type Instance struct {
 	imports  func() (*wasm.Imports, error)
 	isClosed bool
 	codeHash common.Hash
+	code     []byte
+	// version is the version of the runtime code,
+	// cached when it is first requested.
+	version runtime.Version
+	// snapshot is the content of the memory right after instantiation,
+	// which is restored when the instance is reset.
+	snapshot  []byte
+	heapPages uint64
 	sync.Mutex
 }
 
@@ -90,58 +102,29 @@ func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
 	return NewInstance(bytes, cfg)
 }
 
+// NewRuntimeInstance instantiates a runtime importing the node runtime host functions
+// from raw wasm bytecode, and implements runtime.NewInstanceFunc.
+func NewRuntimeInstance(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+	instance, err := NewInstance(code, &Config{
+		InstanceConfig: cfg,
+		Imports:        ImportsNodeRuntime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
 // NewInstance instantiates a runtime from raw wasm bytecode
 func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 	if len(code) == 0 {
 		return nil, errors.New("code is empty")
 	}
 
-	var err error
-	code, err = decompressWasm(code)
-	if err != nil {
-		return nil, fmt.Errorf("cannot decompress WASM code: %w", err)
-	}
-
 	logger.Patch(log.SetLevel(cfg.LogLvl), log.SetCallerFunc(true))
 
-	imports, err := cfg.Imports()
-	if err != nil {
-		return nil, err
-	}
-
-	// Provide importable memory for newer runtimes
-	// TODO: determine memory descriptor size that the runtime wants from the wasm. (#1268)
-	// should be doable w/ wasmer 1.0.0.
-	memory, err := wasm.NewMemory(23, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = imports.AppendMemory("memory", memory)
-	if err != nil {
-		return nil, err
-	}
-
-	// Instantiates the WebAssembly module.
-	instance, err := wasm.NewInstanceWithImports(code, imports)
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO: get __heap_base exported value from runtime.
-	// wasmer 0.3.x does not support this, but wasmer 1.0.0 does (#1268)
-	heapBase := runtime.DefaultHeapBase
-
-	// Assume imported memory is used if runtime does not export any
-	if !instance.HasMemory() {
-		instance.Memory = memory
-	}
-
-	allocator := runtime.NewAllocator(instance.Memory, heapBase)
-
 	runtimeCtx := &runtime.Context{
 		Storage:         cfg.Storage,
-		Allocator:       allocator,
 		Keystore:        cfg.Keystore,
 		Validator:       cfg.Role == byte(4),
 		NodeStorage:     cfg.NodeStorage,
@@ -149,18 +132,23 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 		Transaction:     cfg.Transaction,
 		SigVerifier:     crypto.NewSignatureVerifier(logger),
 		OffchainHTTPSet: offchain.NewHTTPSet(),
+		Profiler:        runtime.NewInstanceProfiler(),
+		Tracer:          runtime.NewWasmTracer(targetLoggers),
 	}
 
-	logger.Debugf("NewInstance called with runtimeCtx: %v", runtimeCtx)
-	instance.SetContextData(runtimeCtx)
-
 	inst := &Instance{
-		vm:       instance,
-		ctx:      runtimeCtx,
-		imports:  cfg.Imports,
-		codeHash: cfg.CodeHash,
+		ctx:       runtimeCtx,
+		imports:   cfg.Imports,
+		codeHash:  cfg.CodeHash,
+		heapPages: runtime.HeapPages(cfg.Storage),
+	}
+
+	err := inst.setupInstanceVM(code)
+	if err != nil {
+		return nil, err
 	}
 
+	logger.Debugf("NewInstance called with runtimeCtx: %v", runtimeCtx)
 	return inst, nil
 }
 
@@ -185,6 +173,11 @@ func (in *Instance) GetCodeHash() common.Hash {
 	return in.codeHash
 }
 
+// GetHeapPages returns the number of heap pages of the memory of the instance
+func (in *Instance) GetHeapPages() uint64 {
+	return in.heapPages
+}
+
 // GetContext returns the context of the instance
 func (in *Instance) GetContext() *runtime.Context {
 	return in.ctx
@@ -204,14 +197,18 @@ func (in *Instance) UpdateRuntimeCode(code []byte) error {
 
 // CheckRuntimeVersion calculates runtime Version for runtime blob passed in
 func (in *Instance) CheckRuntimeVersion(code []byte) (runtime.Version, error) {
+	// the temporary instance has its own copy of the context, since
+	// its allocator is set to the memory of the temporary instance.
+	in.Lock()
+	runtimeCtx := *in.ctx
+	in.Unlock()
+
 	tmp := &Instance{
-		imports: in.imports,
-		ctx:     in.ctx,
+		imports:   in.imports,
+		ctx:       &runtimeCtx,
+		heapPages: in.heapPages,
 	}
 
-	in.Lock()
-	defer in.Unlock()
-
 	err := tmp.setupInstanceVM(code)
 	if err != nil {
 		return nil, err
@@ -221,14 +218,39 @@ func (in *Instance) CheckRuntimeVersion(code []byte) (runtime.Version, error) {
 }
 
 func (in *Instance) setupInstanceVM(code []byte) error {
+	in.version = nil
+
+	code, err := decompressWasm(code)
+	if err != nil {
+		return fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	// the compiled module is shared with the other instances of the code,
+	// and is only needed until the instance is created.
+	module, release, err := moduleCache.Module(code)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// wasmer 0.3.x does not expose the memory limits nor the
+	// exported globals of a module, so these are read from the code.
+	info, err := runtime.ReadModuleInfo(code)
+	if err != nil {
+		return fmt.Errorf("cannot read module info: %w", err)
+	}
+
+	pages, err := runtime.MemoryPages(info.MemoryPages, in.heapPages)
+	if err != nil {
+		return err
+	}
+
 	imports, err := in.imports()
 	if err != nil {
 		return err
 	}
 
-	// TODO: determine memory descriptor size that the runtime wants from the wasm.
-	// should be doable w/ wasmer 1.0.0. (#1268)
-	memory, err := wasm.NewMemory(23, 0)
+	memory, err := wasm.NewMemory(pages, 0)
 	if err != nil {
 		return err
 	}
@@ -239,7 +261,7 @@ func (in *Instance) setupInstanceVM(code []byte) error {
 	}
 
 	// Instantiates the WebAssembly module.
-	in.vm, err = wasm.NewInstanceWithImports(code, imports)
+	in.vm, err = module.InstantiateWithImports(imports)
 	if err != nil {
 		return err
 	}
@@ -247,17 +269,68 @@ func (in *Instance) setupInstanceVM(code []byte) error {
 	// Assume imported memory is used if runtime does not export any
 	if !in.vm.HasMemory() {
 		in.vm.Memory = memory
+	} else if current := uint32(uint64(in.vm.Memory.Length()) / runtime.PageSize); current < pages {
+		err = in.vm.Memory.Grow(pages - current)
+		if err != nil {
+			return fmt.Errorf("cannot grow memory by %d heap pages: %w", in.heapPages, err)
+		}
 	}
 
-	// TODO: get __heap_base exported value from runtime.
-	// wasmer 0.3.x does not support this, but wasmer 1.0.0 does (#1268)
-	heapBase := runtime.DefaultHeapBase
-
-	in.ctx.Allocator = runtime.NewAllocator(in.vm.Memory, heapBase)
+	in.ctx.Allocator = runtime.NewAllocator(in.vm.Memory, info.HeapBase)
 	in.vm.SetContextData(in.ctx)
+	in.code = code
+	// the heap pages are initially empty, so only the pages
+	// of the module, holding its data and stack, are saved.
+	in.snapshot = append([]byte(nil), in.vm.Memory.Data()[:int(info.MemoryPages)*runtime.PageSize]...)
 	return nil
 }
 
+// Clone returns a new instance of the runtime code of the instance,
+// with the same context storage, keystore and services.
+func (in *Instance) Clone() (runtime.Instance, error) {
+	in.Lock()
+	defer in.Unlock()
+
+	runtimeCtx := &runtime.Context{
+		Storage:         in.ctx.Storage,
+		Keystore:        in.ctx.Keystore,
+		Validator:       in.ctx.Validator,
+		NodeStorage:     in.ctx.NodeStorage,
+		Network:         in.ctx.Network,
+		Transaction:     in.ctx.Transaction,
+		SigVerifier:     crypto.NewSignatureVerifier(logger),
+		OffchainHTTPSet: offchain.NewHTTPSet(),
+		Profiler:        runtime.NewInstanceProfiler(),
+		Tracer:          runtime.NewWasmTracer(targetLoggers),
+	}
+
+	clone := &Instance{
+		ctx:       runtimeCtx,
+		imports:   in.imports,
+		codeHash:  in.codeHash,
+		heapPages: in.heapPages,
+	}
+
+	err := clone.setupInstanceVM(in.code)
+	if err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// Reset restores the module pages of the memory of the instance to their content
+// right after instantiation, resets its allocator and the heap written by its
+// allocations, and unsets its context storage.
+func (in *Instance) Reset() {
+	in.Lock()
+	defer in.Unlock()
+
+	in.ctx.Allocator.Reset()
+	copy(in.vm.Memory.Data(), in.snapshot)
+	in.ctx.Storage = nil
+}
+
 // SetContextStorage sets the runtime's storage. It should be set before calls to the below functions.
 func (in *Instance) SetContextStorage(s runtime.Storage) {
 	in.Lock()
@@ -265,6 +338,13 @@ func (in *Instance) SetContextStorage(s runtime.Storage) {
 	in.ctx.Storage = s
 }
 
+// SetProfiler implements runtime.ProfilableInstance.
+func (in *Instance) SetProfiler(profiler *runtime.Profiler) {
+	in.Lock()
+	defer in.Unlock()
+	in.ctx.Profiler = profiler
+}
+
 // Stop func
 func (in *Instance) Stop() {
 	in.Lock()
@@ -321,13 +401,29 @@ func (in *Instance) exec(function string, data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("could not find exported function %s", function)
 	}
 
+	if in.ctx.Profiler != nil {
+		in.ctx.Profiler.StartEntrypoint(function)
+		atomic.AddInt32(&profiledCalls, 1)
+		defer atomic.AddInt32(&profiledCalls, -1)
+	}
+
 	res, err := runtimeFunc(int32(ptr), datalen)
+	if storage, ok := in.ctx.Storage.(runtime.FallibleStorage); ok {
+		if storageErr := storage.Err(); storageErr != nil {
+			return nil, fmt.Errorf("cannot access storage: %w", storageErr)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	offset, length := runtime.Int64ToPointerAndSize(res.ToI64())
-	return in.load(offset, length), nil
+
+	// the memory is reset when the instance is checked in to a runtime pool,
+	// so the result is copied.
+	output := make([]byte, length)
+	copy(output, in.load(offset, length))
+	return output, nil
 }
 
 func (in *Instance) malloc(size uint32) (uint32, error) {
@@ -6,6 +6,7 @@
 import (
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/ChainSafe/gossamer/lib/runtime"
@@ -44,8 +45,11 @@ func TestInstance_CheckRuntimeVersion(t *testing.T) {
 	require.NoError(t, err)
 	code, err := os.ReadFile(fp)
 	require.NoError(t, err)
+	allocator := instance.ctx.Allocator
 	version, err := instance.CheckRuntimeVersion(code)
 	require.NoError(t, err)
+	// the context of the instance is not modified by the temporary instance.
+	require.Same(t, allocator, instance.ctx.Allocator)
 
 	expected := runtime.NewVersionData(
 		[]byte("polkadot"),
@@ -66,6 +70,23 @@ func TestInstance_CheckRuntimeVersion(t *testing.T) {
 	require.Equal(t, expected.TransactionVersion(), version.TransactionVersion())
 }
 
+func TestInstance_Profiler(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	profiler := runtime.NewProfiler(false)
+	instance.SetProfiler(profiler)
+
+	_, err := instance.Version()
+	require.NoError(t, err)
+	require.Zero(t, atomic.LoadInt32(&profiledCalls))
+
+	hostFunctions := profiler.Profile()[runtime.CoreVersion]
+	require.NotEmpty(t, hostFunctions)
+	for _, hostFunction := range hostFunctions {
+		require.NotZero(t, hostFunction.Calls)
+	}
+}
+
 func TestDecompressWasm(t *testing.T) {
 	encoder, err := zstd.NewWriter(nil)
 	require.NoError(t, err)
@@ -0,0 +1,50 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// profiledCalls is the number of runtime calls in progress with a profiler,
+// so the host functions only look up their profiler if it is not zero.
+var profiledCalls int32
+
+func endNoProfile() {}
+
+// profileHostFunction starts recording a call to the given host function if
+// the instance has a profiler, and returns the function ending the record.
+// It is deferred by the host functions, and costs an atomic load if no
+// runtime call is profiled.
+func profileHostFunction(context unsafe.Pointer, name string) (end func()) {
+	if atomic.LoadInt32(&profiledCalls) == 0 {
+		return endNoProfile
+	}
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	profiler := instanceContext.Data().(*runtime.Context).Profiler
+	if profiler == nil {
+		return endNoProfile
+	}
+
+	profiler.StartHostFunction(name)
+	return profiler.EndHostFunction
+}
+
+// profileBytes adds the given number of bytes to the ones transferred by the
+// current host function call, if the instance has a profiler.
+func profileBytes(context wasm.InstanceContext, n int) {
+	if atomic.LoadInt32(&profiledCalls) == 0 {
+		return
+	}
+
+	if profiler := context.Data().(*runtime.Context).Profiler; profiler != nil {
+		profiler.AddBytes(n)
+	}
+}
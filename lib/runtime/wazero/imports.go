@@ -0,0 +1,2309 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtype "github.com/ChainSafe/gossamer/lib/common/types"
+	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+func ext_logging_log_version_1(ctx context.Context, m api.Module, level int32, targetData, msgData int64) {
+	logger.Trace("executing...")
+
+	target := string(asMemorySlice(ctx, m, targetData))
+	msg := string(asMemorySlice(ctx, m, msgData))
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if tracer, ok := runtimeCtx.Storage.(runtime.LogTargetTracer); ok {
+		tracer.SetTarget(target)
+	}
+
+	targetLoggers.Log(level, target, msg)
+}
+
+func ext_logging_max_level_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Trace("executing...")
+	return targetLoggers.MaxLevel()
+}
+
+func ext_wasm_tracing_enabled_version_1(ctx context.Context, m api.Module, metadataSpan int64) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return 0
+	}
+
+	enabled, err := runtimeCtx.Tracer.Enabled(runtimeCtx.Storage, asMemorySlice(ctx, m, metadataSpan))
+	if err != nil {
+		logger.Errorf("failed to check if tracing is enabled: %s", err)
+		return 0
+	}
+
+	if enabled {
+		return 1
+	}
+	return 0
+}
+
+func ext_wasm_tracing_enter_span_version_1(ctx context.Context, m api.Module, attributesSpan int64) int64 {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return 0
+	}
+
+	id, err := runtimeCtx.Tracer.EnterSpan(runtimeCtx.Storage, asMemorySlice(ctx, m, attributesSpan))
+	if err != nil {
+		logger.Errorf("failed to enter tracing span: %s", err)
+		return 0
+	}
+
+	return int64(id)
+}
+
+func ext_wasm_tracing_exit_version_1(ctx context.Context, m api.Module, id int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return
+	}
+
+	runtimeCtx.Tracer.ExitSpan(runtimeCtx.Storage, uint64(id))
+}
+
+func ext_wasm_tracing_event_version_1(ctx context.Context, m api.Module, attributesSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Tracer == nil {
+		return
+	}
+
+	err := runtimeCtx.Tracer.Event(asMemorySlice(ctx, m, attributesSpan))
+	if err != nil {
+		logger.Errorf("failed to log tracing event: %s", err)
+	}
+}
+
+func ext_transaction_index_index_version_1(ctx context.Context, m api.Module, extrinsic, size, contentHash int32) {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	hash := common.NewHash(memory[contentHash : contentHash+32])
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+	indexer, ok := storage.(runtime.TransactionIndexer)
+	if !ok {
+		logger.Debugf("storage does not index transactions, ignoring extrinsic %d with hash %s", extrinsic, hash)
+		return
+	}
+
+	logger.Debugf("indexing %d bytes of extrinsic %d with hash %s", size, extrinsic, hash)
+	indexer.IndexTransaction(uint32(extrinsic), hash, uint32(size))
+}
+
+func ext_transaction_index_renew_version_1(ctx context.Context, m api.Module, extrinsic, contentHash int32) {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	hash := common.NewHash(memory[contentHash : contentHash+32])
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+	indexer, ok := storage.(runtime.TransactionIndexer)
+	if !ok {
+		logger.Debugf("storage does not index transactions, ignoring renewal of hash %s by extrinsic %d", hash, extrinsic)
+		return
+	}
+
+	logger.Debugf("renewing hash %s by extrinsic %d", hash, extrinsic)
+	indexer.RenewTransaction(uint32(extrinsic), hash)
+}
+
+func ext_sandbox_instance_teardown_version_1(ctx context.Context, m api.Module, a int32) {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+}
+
+func ext_sandbox_instantiate_version_1(ctx context.Context, m api.Module, a int32, x, y int64, z int32) int32 {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+	return 0
+}
+
+func ext_sandbox_invoke_version_1(ctx context.Context, m api.Module, a int32, x, y int64, z, d, e int32) int32 {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+	return 0
+}
+
+func ext_sandbox_memory_get_version_1(ctx context.Context, m api.Module, a, z, d, e int32) int32 {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+	return 0
+}
+
+func ext_sandbox_memory_new_version_1(ctx context.Context, m api.Module, a, z int32) int32 {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+	return 0
+}
+
+func ext_sandbox_memory_set_version_1(ctx context.Context, m api.Module, a, z, d, e int32) int32 {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+	return 0
+}
+
+func ext_sandbox_memory_teardown_version_1(ctx context.Context, m api.Module, a int32) {
+	logger.Trace("executing...")
+	logger.Warn("unimplemented")
+}
+
+func ext_crypto_ed25519_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(ctx, m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp crypto.Keypair
+
+	if seed != nil {
+		kp, err = ed25519.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = ed25519.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode(), 32)
+	if err != nil {
+		logger.Warnf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ed25519 keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_ed25519_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	if ks.Type() != crypto.Ed25519Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected ed25519",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	keys := keystore.PublicKeysOfType(ks, crypto.Ed25519Type)
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ed25519_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	pubKeyData := memory[key : key+32]
+	pubKey, err := ed25519.NewPublicKey(pubKeyData)
+	if err != nil {
+		logger.Errorf("failed to get public keys: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemoryOptional(ctx, m, nil)
+		return int64(ret)
+	}
+
+	var ret int64
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		ret, err = toWasmMemoryOptional(ctx, m, nil)
+		if err != nil {
+			logger.Errorf("failed to allocate memory: %s", err)
+			return 0
+		}
+		return int64(ret)
+	}
+
+	sig, err := signingKey.Sign(asMemorySlice(ctx, m, msg))
+	if err != nil {
+		logger.Error("could not sign message")
+	}
+
+	ret, err = toWasmMemoryFixedSizeOptional(ctx, m, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ed25519_verify_version_1(ctx context.Context, m api.Module, sig int32, msg int64, key int32) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := ctx.Value(runtimeContextKey).(*runtime.Context).SigVerifier
+
+	signature := memory[sig : sig+64]
+	message := asMemorySlice(ctx, m, msg)
+	pubKeyData := memory[key : key+32]
+
+	pubKey, err := ed25519.NewPublicKey(pubKeyData)
+	if err != nil {
+		logger.Error("failed to create public key")
+		return 0
+	}
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pubKey.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: ed25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pubKey.Verify(message, signature); err != nil || !ok {
+		logger.Error("failed to verify")
+		return 0
+	}
+
+	logger.Debug("verified ed25519 signature")
+	return 1
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_version_1(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	// msg must be the 32-byte hash of the message to be signed.
+	// sig must be a 65-byte compact ECDSA signature containing the
+	// recovery id as the last element
+	message := memory[msg : msg+32]
+	signature := memory[sig : sig+65]
+
+	pub, err := secp256k1.RecoverPublicKey(message, signature)
+	if err != nil {
+		logger.Errorf("failed to recover public key: %s", err)
+		var ret int64
+		ret, err = toWasmMemoryResult(ctx, m, nil)
+		if err != nil {
+			logger.Errorf("failed to allocate memory: %s", err)
+			return 0
+		}
+		return int64(ret)
+	}
+
+	logger.Debugf(
+		"recovered public key of length %d: 0x%x",
+		len(pub), pub)
+
+	ret, err := toWasmMemoryResult(ctx, m, pub[1:])
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_version_2(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_version_1(ctx, m, sig, msg)
+}
+
+func ext_crypto_ecdsa_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(ctx, m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp crypto.Keypair
+	if seed != nil {
+		kp, err = secp256k1.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = secp256k1.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode(), 33)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ecdsa keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_ecdsa_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	if ks.Type() != crypto.Secp256k1Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected ecdsa",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	keys := keystore.PublicKeysOfType(ks, crypto.Secp256k1Type)
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ecdsa_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	emptyRet, _ := toWasmMemoryOptional(ctx, m, nil)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return int64(emptyRet)
+	}
+
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(memory[key : key+33])
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return int64(emptyRet)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return int64(emptyRet)
+	}
+
+	// the message is signed by its blake2b-256 hash, which ext_crypto_ecdsa_verify_version_2 verifies
+	hash, err := common.Blake2bHash(asMemorySlice(ctx, m, msg))
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return int64(emptyRet)
+	}
+
+	sig, err := signingKey.Sign(hash[:])
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return int64(emptyRet)
+	}
+
+	ret, err := toWasmMemoryFixedSizeOptional(ctx, m, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return int64(emptyRet)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ecdsa_verify_version_2(ctx context.Context, m api.Module, sig int32, msg int64, key int32) int32 {
+	logger.Trace("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := ctx.Value(runtimeContextKey).(*runtime.Context).SigVerifier
+
+	message := asMemorySlice(ctx, m, msg)
+	signature := memory[sig : sig+64]
+	pubKey := memory[key : key+33]
+
+	pub := new(secp256k1.PublicKey)
+	err := pub.Decode(pubKey)
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return int32(0)
+	}
+
+	logger.Debugf("pub=%s, message=0x%x, signature=0x%x",
+		pub.Hex(), fmt.Sprintf("0x%x", message), fmt.Sprintf("0x%x", signature))
+
+	hash, err := common.Blake2bHash(message)
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return int32(0)
+	}
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        hash[:],
+			VerifyFunc: secp256k1.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return int32(1)
+	}
+
+	if ok, err := pub.Verify(hash[:], signature); err != nil || !ok {
+		logger.Errorf("failed to validate signature: %s", err)
+		return int32(0)
+	}
+
+	logger.Debug("validated signature")
+	return int32(1)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	// msg must be the 32-byte hash of the message to be signed.
+	// sig must be a 65-byte compact ECDSA signature containing the
+	// recovery id as the last element
+	message := memory[msg : msg+32]
+	signature := memory[sig : sig+65]
+
+	cpub, err := secp256k1.RecoverPublicKeyCompressed(message, signature)
+	if err != nil {
+		logger.Errorf("failed to recover public key: %s", err)
+		ret, _ := toWasmMemoryResult(ctx, m, nil)
+		return int64(ret)
+	}
+
+	logger.Debugf(
+		"recovered public key of length %d: 0x%x",
+		len(cpub), cpub)
+
+	ret, err := toWasmMemoryResult(ctx, m, cpub)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx, m, sig, msg)
+}
+
+func ext_crypto_sr25519_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(ctx, m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp crypto.Keypair
+	if seed != nil {
+		kp, err = sr25519.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = sr25519.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Tracef("cannot generate key: %s", err)
+		panic(err)
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id "+common.BytesToHex(id)+": %s", err)
+		return 0
+	}
+
+	err = runtimeCtx.Keystore.InsertGenerated(ks, kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode(), 32)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated sr25519 keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_sr25519_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id "+common.BytesToHex(id)+": %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	if ks.Type() != crypto.Sr25519Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"keystore type for id 0x%x is %s and not expected sr25519",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	keys := keystore.PublicKeysOfType(ks, crypto.Sr25519Type)
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_sr25519_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	memory := memoryData(m)
+
+	emptyRet, _ := toWasmMemoryOptional(ctx, m, nil)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return int64(emptyRet)
+	}
+
+	var ret int64
+	pubKey, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Errorf("failed to get public key: %s", err)
+		return int64(emptyRet)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return int64(emptyRet)
+	}
+
+	msgData := asMemorySlice(ctx, m, msg)
+	sig, err := signingKey.Sign(msgData)
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return int64(emptyRet)
+	}
+
+	ret, err = toWasmMemoryFixedSizeOptional(ctx, m, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return int64(emptyRet)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_sr25519_verify_version_1(ctx context.Context, m api.Module, sig int32, msg int64, key int32) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := ctx.Value(runtimeContextKey).(*runtime.Context).SigVerifier
+
+	message := asMemorySlice(ctx, m, msg)
+	signature := memory[sig : sig+64]
+
+	pub, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Error("invalid sr25519 public key")
+		return 0
+	}
+
+	logger.Debugf(
+		"pub=%s message=0x%x signature=0x%x",
+		pub.Hex(), message, signature)
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: sr25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pub.VerifyDeprecated(message, signature); err != nil || !ok {
+		logger.Debugf("failed to validate signature: %s", err)
+		// this fails at block 3876, which seems to be expected, based on discussions
+		return 1
+	}
+
+	logger.Debug("verified sr25519 signature")
+	return 1
+}
+
+func ext_crypto_sr25519_verify_version_2(ctx context.Context, m api.Module, sig int32, msg int64, key int32) int32 {
+	logger.Trace("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := ctx.Value(runtimeContextKey).(*runtime.Context).SigVerifier
+
+	message := asMemorySlice(ctx, m, msg)
+	signature := memory[sig : sig+64]
+
+	pub, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Error("invalid sr25519 public key")
+		return 0
+	}
+
+	logger.Debugf(
+		"pub=%s; message=0x%x; signature=0x%x",
+		pub.Hex(), message, signature)
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: sr25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pub.Verify(message, signature); err != nil || !ok {
+		logger.Errorf("failed to validate signature: %s", err)
+		return 0
+	}
+
+	logger.Debug("validated signature")
+	return int32(1)
+}
+
+func ext_crypto_start_batch_verify_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+
+	// TODO: fix and re-enable signature verification (#1405)
+	// beginBatchVerify(context)
+}
+
+func ext_crypto_finish_batch_verify_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	// TODO: fix and re-enable signature verification (#1405)
+	// return finishBatchVerify(context)
+	return 1
+}
+
+func ext_trie_blake2_256_root_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	t := trie.NewEmptyTrie()
+
+	type kv struct {
+		Key, Value []byte
+	}
+
+	// this function is expecting an array of (key, value) tuples
+	var kvs []kv
+	if err := scale.Unmarshal(data, &kvs); err != nil {
+		logger.Errorf("[ext_trie_blake2_256_root_version_1]: %s", err)
+		return 0
+	}
+
+	for _, kv := range kvs {
+		t.Put(kv.Key, kv.Value)
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := runtimeCtx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_root_version_1]: %s", err)
+		return 0
+	}
+
+	hash, err := t.Hash()
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_root_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf("[ext_trie_blake2_256_root_version_1]: root hash is %s", hash)
+	copy(memory[ptr:ptr+32], hash[:])
+	return int32(ptr)
+}
+
+func ext_trie_blake2_256_ordered_root_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	t := trie.NewEmptyTrie()
+	var values [][]byte
+	err := scale.Unmarshal(data, &values)
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_ordered_root_version_1]: %s", err)
+		return 0
+	}
+
+	for i, val := range values {
+		key, err := scale.Marshal(big.NewInt(int64(i)))
+		if err != nil {
+			logger.Errorf("[ext_trie_blake2_256_ordered_root_version_1]: %s", err)
+			return 0
+		}
+		logger.Tracef(
+			"put key=0x%x and value=0x%x",
+			key, val)
+
+		t.Put(key, val)
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := runtimeCtx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_ordered_root_version_1]: %s", err)
+		return 0
+	}
+
+	hash, err := t.Hash()
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_ordered_root_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf("[ext_trie_blake2_256_ordered_root_version_1]: root hash is %s", hash)
+	copy(memory[ptr:ptr+32], hash[:])
+	return int32(ptr)
+}
+
+func ext_trie_blake2_256_ordered_root_version_2(ctx context.Context, m api.Module, dataSpan int64, version int32) int32 {
+	// TODO: update to use state trie version 1 (#2418)
+	return ext_trie_blake2_256_ordered_root_version_1(ctx, m, dataSpan)
+}
+
+func ext_trie_blake2_256_verify_proof_version_1(ctx context.Context, m api.Module, rootSpan int32, proofSpan, keySpan, valueSpan int64) int32 {
+	logger.Debug("executing...")
+
+	toDecProofs := asMemorySlice(ctx, m, proofSpan)
+	var decProofs [][]byte
+	err := scale.Unmarshal(toDecProofs, &decProofs)
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_verify_proof_version_1]: %s", err)
+		return int32(0)
+	}
+
+	key := asMemorySlice(ctx, m, keySpan)
+	value := asMemorySlice(ctx, m, valueSpan)
+
+	mem := memoryData(m)
+	trieRoot := mem[rootSpan : rootSpan+32]
+
+	exists, err := trie.VerifyProof(decProofs, trieRoot, []trie.Pair{{Key: key, Value: value}})
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_verify_proof_version_1]: %s", err)
+		return int32(0)
+	}
+
+	var result int32 = 0
+	if exists {
+		result = 1
+	}
+
+	return result
+}
+
+func ext_misc_print_hex_version_1(ctx context.Context, m api.Module, dataSpan int64) {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+	logger.Debugf("data: 0x%x", data)
+}
+
+func ext_misc_print_num_version_1(ctx context.Context, m api.Module, data int64) {
+	logger.Trace("executing...")
+
+	logger.Debugf("num: %d", int64(data))
+}
+
+func ext_misc_print_utf8_version_1(ctx context.Context, m api.Module, dataSpan int64) {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+	logger.Debug("utf8: " + string(data))
+}
+
+func ext_misc_runtime_version_version_1(ctx context.Context, m api.Module, dataSpan int64) int64 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	cfg := &Config{}
+	cfg.LogLvl = log.DoNotChange
+	cfg.Storage, _ = rtstorage.NewTrieState(nil)
+
+	instance, err := NewInstance(data, cfg)
+	if err != nil {
+		logger.Errorf("failed to create instance: %s", err)
+		return 0
+	}
+	defer instance.Stop()
+
+	version, err := instance.Version()
+	if err != nil {
+		logger.Errorf("failed to get runtime version: %s", err)
+		out, _ := toWasmMemoryOptional(ctx, m, nil)
+		return int64(out)
+	}
+
+	encodedData, err := version.Encode()
+	if err != nil {
+		logger.Errorf("failed to encode result: %s", err)
+		return 0
+	}
+
+	out, err := toWasmMemoryOptional(ctx, m, encodedData)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(out)
+}
+
+func ext_default_child_storage_read_version_1(ctx context.Context, m api.Module, childStorageKey, key, valueOut int64, offset int32) int64 {
+	logger.Debug("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+	memory := memoryData(m)
+
+	value, err := storage.GetChildStorage(asMemorySlice(ctx, m, childStorageKey), asMemorySlice(ctx, m, key))
+	if err != nil {
+		logger.Errorf("failed to get child storage: %s", err)
+		return 0
+	}
+
+	valueBuf, valueLen := runtime.Int64ToPointerAndSize(int64(valueOut))
+	copy(memory[valueBuf:valueBuf+valueLen], value[offset:])
+
+	size := uint32(len(value[offset:]))
+	sizeBuf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBuf, size)
+
+	sizeSpan, err := toWasmMemoryOptional(ctx, m, sizeBuf)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(sizeSpan)
+}
+
+func ext_default_child_storage_clear_version_1(ctx context.Context, m api.Module, childStorageKey, keySpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	keyToChild := asMemorySlice(ctx, m, childStorageKey)
+	key := asMemorySlice(ctx, m, keySpan)
+
+	err := storage.ClearChildStorage(keyToChild, key)
+	if err != nil {
+		logger.Errorf("failed to clear child storage: %s", err)
+	}
+}
+
+func ext_default_child_storage_clear_prefix_version_1(ctx context.Context, m api.Module, childStorageKey, prefixSpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	keyToChild := asMemorySlice(ctx, m, childStorageKey)
+	prefix := asMemorySlice(ctx, m, prefixSpan)
+
+	err := storage.ClearPrefixInChild(keyToChild, prefix)
+	if err != nil {
+		logger.Errorf("failed to clear prefix in child: %s", err)
+	}
+}
+
+func ext_default_child_storage_exists_version_1(ctx context.Context, m api.Module, childStorageKey, key int64) int32 {
+	logger.Debug("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	child, err := storage.GetChildStorage(asMemorySlice(ctx, m, childStorageKey), asMemorySlice(ctx, m, key))
+	if err != nil {
+		logger.Errorf("failed to get child from child storage: %s", err)
+		return 0
+	}
+	if child != nil {
+		return 1
+	}
+	return 0
+}
+
+func ext_default_child_storage_get_version_1(ctx context.Context, m api.Module, childStorageKey, key int64) int64 {
+	logger.Debug("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	child, err := storage.GetChildStorage(asMemorySlice(ctx, m, childStorageKey), asMemorySlice(ctx, m, key))
+	if err != nil {
+		logger.Errorf("failed to get child from child storage: %s", err)
+		return 0
+	}
+
+	value, err := toWasmMemoryOptional(ctx, m, child)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(value)
+}
+
+func ext_default_child_storage_next_key_version_1(ctx context.Context, m api.Module, childStorageKey, key int64) int64 {
+	logger.Debug("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	child, err := storage.GetChildNextKey(asMemorySlice(ctx, m, childStorageKey), asMemorySlice(ctx, m, key))
+	if err != nil {
+		logger.Errorf("failed to get child's next key: %s", err)
+		return 0
+	}
+
+	value, err := toWasmMemoryOptional(ctx, m, child)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(value)
+}
+
+func ext_default_child_storage_root_version_1(ctx context.Context, m api.Module, childStorageKey int64) int64 {
+	logger.Debug("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	child, err := storage.GetChild(asMemorySlice(ctx, m, childStorageKey))
+	if err != nil {
+		logger.Errorf("failed to retrieve child: %s", err)
+		return 0
+	}
+
+	childRoot, err := child.Hash()
+	if err != nil {
+		logger.Errorf("failed to encode child root: %s", err)
+		return 0
+	}
+
+	root, err := toWasmMemoryOptional(ctx, m, childRoot[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(root)
+}
+
+func ext_default_child_storage_set_version_1(ctx context.Context, m api.Module, childStorageKeySpan, keySpan, valueSpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	childStorageKey := asMemorySlice(ctx, m, childStorageKeySpan)
+	key := asMemorySlice(ctx, m, keySpan)
+	value := asMemorySlice(ctx, m, valueSpan)
+
+	cp := make([]byte, len(value))
+	copy(cp, value)
+
+	err := storage.SetChildStorage(childStorageKey, key, cp)
+	if err != nil {
+		logger.Errorf("failed to set value in child storage: %s", err)
+		return
+	}
+}
+
+func ext_default_child_storage_storage_kill_version_1(ctx context.Context, m api.Module, childStorageKeySpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	childStorageKey := asMemorySlice(ctx, m, childStorageKeySpan)
+	storage.DeleteChild(childStorageKey)
+}
+
+func ext_default_child_storage_storage_kill_version_2(ctx context.Context, m api.Module, childStorageKeySpan, lim int64) int32 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+	childStorageKey := asMemorySlice(ctx, m, childStorageKeySpan)
+
+	limitBytes := asMemorySlice(ctx, m, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+		return 0
+	}
+
+	_, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+	}
+
+	if all {
+		return 1
+	}
+
+	return 0
+}
+
+type noneRemain uint32
+type someRemain uint32
+
+func (noneRemain) Index() uint {
+	return 0
+}
+func (someRemain) Index() uint {
+	return 1
+}
+
+func ext_default_child_storage_storage_kill_version_3(ctx context.Context, m api.Module, childStorageKeySpan, lim int64) int64 {
+	logger.Debug("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+	childStorageKey := asMemorySlice(ctx, m, childStorageKeySpan)
+
+	limitBytes := asMemorySlice(ctx, m, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+	}
+
+	deleted, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+		return int64(0)
+	}
+
+	vdt, err := scale.NewVaryingDataType(noneRemain(0), someRemain(0))
+	if err != nil {
+		logger.Warnf("cannot create new varying data type: %s", err)
+	}
+
+	if all {
+		err = vdt.Set(noneRemain(deleted))
+	} else {
+		err = vdt.Set(someRemain(deleted))
+	}
+	if err != nil {
+		logger.Warnf("cannot set varying data type: %s", err)
+		return int64(0)
+	}
+
+	encoded, err := scale.Marshal(vdt)
+	if err != nil {
+		logger.Warnf("problem marshaling varying data type: %s", err)
+		return int64(0)
+	}
+
+	out, err := toWasmMemoryOptional(ctx, m, encoded)
+	if err != nil {
+		logger.Warnf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(out)
+}
+
+func ext_allocator_free_version_1(ctx context.Context, m api.Module, addr int32) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	// Deallocate memory
+	err := runtimeCtx.Allocator.Deallocate(uint32(addr))
+	if err != nil {
+		logger.Errorf("failed to free memory: %s", err)
+	}
+}
+
+func ext_allocator_malloc_version_1(ctx context.Context, m api.Module, size int32) int32 {
+	logger.Tracef("executing with size %d...", int64(size))
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	// Allocate memory
+	res, err := runtimeCtx.Allocator.Allocate(uint32(size))
+	if err != nil {
+		logger.Criticalf("failed to allocate memory: %s", err)
+		panic(err)
+	}
+
+	return int32(res)
+}
+
+func ext_hashing_blake2_128_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Blake2b128(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_blake2_128_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x has hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash, 16)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_blake2_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Blake2bHash(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_blake2_256_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:], 32)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_keccak_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Keccak256(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_keccak_256_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:], 32)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_sha2_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+	hash := common.Sha256(data)
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:], 32)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Twox256(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_twox_256_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:], 32)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_128_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Twox128Hash(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_twox_128_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x hash hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash, 16)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_64_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(ctx, m, dataSpan)
+
+	hash, err := common.Twox64(data)
+	if err != nil {
+		logger.Errorf("[ext_hashing_twox_64_version_1]: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x has hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash, 8)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_offchain_index_set_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	storageKey := asMemorySlice(ctx, m, keySpan)
+	newValue := asMemorySlice(ctx, m, valueSpan)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	err := runtimeCtx.NodeStorage.BaseDB.Put(storageKey, cp)
+	if err != nil {
+		logger.Errorf("failed to set value in raw storage: %s", err)
+	}
+}
+
+func ext_offchain_local_storage_clear_version_1(ctx context.Context, m api.Module, kind int32, key int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	storageKey := asMemorySlice(ctx, m, key)
+
+	memory := memoryData(m)
+	kindInt := binary.LittleEndian.Uint32(memory[kind : kind+4])
+
+	var err error
+
+	switch runtime.NodeStorageType(kindInt) {
+	case runtime.NodeStorageTypePersistent:
+		err = runtimeCtx.NodeStorage.PersistentStorage.Del(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		err = runtimeCtx.NodeStorage.LocalStorage.Del(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to clear value from storage: %s", err)
+	}
+}
+
+func ext_offchain_is_validator_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Validator {
+		return 1
+	}
+	return 0
+}
+
+func ext_offchain_local_storage_compare_and_set_version_1(ctx context.Context, m api.Module, kind int32, key, oldValue, newValue int64) int32 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	storageKey := asMemorySlice(ctx, m, key)
+
+	var storedValue []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		storedValue, err = runtimeCtx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		storedValue, err = runtimeCtx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+		return 0
+	}
+
+	oldVal := asMemorySlice(ctx, m, oldValue)
+	newVal := asMemorySlice(ctx, m, newValue)
+	if reflect.DeepEqual(storedValue, oldVal) {
+		cp := make([]byte, len(newVal))
+		copy(cp, newVal)
+		err = runtimeCtx.NodeStorage.LocalStorage.Put(storageKey, cp)
+		if err != nil {
+			logger.Errorf("failed to set value in storage: %s", err)
+			return 0
+		}
+	}
+
+	return 1
+}
+
+func ext_offchain_local_storage_get_version_1(ctx context.Context, m api.Module, kind int32, key int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storageKey := asMemorySlice(ctx, m, key)
+
+	var res []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		res, err = runtimeCtx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		res, err = runtimeCtx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+	}
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemoryOptional(ctx, m, res)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+	return int64(ptr)
+}
+
+func ext_offchain_local_storage_set_version_1(ctx context.Context, m api.Module, kind int32, key, value int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storageKey := asMemorySlice(ctx, m, key)
+	newValue := asMemorySlice(ctx, m, value)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	var err error
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		err = runtimeCtx.NodeStorage.PersistentStorage.Put(storageKey, cp)
+	case runtime.NodeStorageTypeLocal:
+		err = runtimeCtx.NodeStorage.LocalStorage.Put(storageKey, cp)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set value in storage: %s", err)
+	}
+}
+
+func ext_offchain_network_state_version_1(ctx context.Context, m api.Module) int64 {
+	logger.Debug("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if runtimeCtx.Network == nil {
+		return 0
+	}
+
+	nsEnc, err := scale.Marshal(runtimeCtx.Network.NetworkState())
+	if err != nil {
+		logger.Errorf("failed at encoding network state: %s", err)
+		return 0
+	}
+
+	// copy network state length to memory writtenOut location
+	nsEncLen := uint32(len(nsEnc))
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, nsEncLen)
+
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemorySized(ctx, m, nsEnc, nsEncLen)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_random_seed_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	if err != nil {
+		logger.Errorf("failed to generate random seed: %s", err)
+	}
+	ptr, err := toWasmMemorySized(ctx, m, seed, 32)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return int32(ptr)
+}
+
+func ext_offchain_submit_transaction_version_1(ctx context.Context, m api.Module, data int64) int64 {
+	logger.Debug("executing...")
+
+	extBytes := asMemorySlice(ctx, m, data)
+
+	var extrinsic []byte
+	err := scale.Unmarshal(extBytes, &extrinsic)
+	if err != nil {
+		logger.Errorf("failed to decode extrinsic data: %s", err)
+	}
+
+	// validate the transaction
+	txv := transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false)
+	vtx := transaction.NewValidTransaction(extrinsic, txv)
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	runtimeCtx.Transaction.AddToPool(vtx)
+
+	ptr, err := toWasmMemoryOptional(ctx, m, nil)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return int64(ptr)
+}
+
+func ext_offchain_timestamp_version_1(ctx context.Context, m api.Module) int64 {
+	logger.Trace("executing...")
+
+	now := time.Now().Unix()
+	return int64(now)
+}
+
+func ext_offchain_sleep_until_version_1(ctx context.Context, m api.Module, deadline int64) {
+	logger.Trace("executing...")
+
+	dur := time.Until(time.UnixMilli(int64(deadline)))
+	if dur > 0 {
+		time.Sleep(dur)
+	}
+}
+
+func ext_offchain_http_request_start_version_1(ctx context.Context, m api.Module, methodSpan, uriSpan, metaSpan int64) int64 { // skipcq: RVV-B0012
+	logger.Debug("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+
+	httpMethod := asMemorySlice(ctx, m, methodSpan)
+	uri := asMemorySlice(ctx, m, uriSpan)
+
+	result := scale.NewResult(int16(0), nil)
+
+	reqID, err := runtimeCtx.OffchainHTTPSet.StartRequest(string(httpMethod), string(uri))
+	if err != nil {
+		// StartRequest error already was logged
+		logger.Errorf("failed to start request: %s", err)
+		err = result.Set(scale.Err, nil)
+	} else {
+		err = result.Set(scale.OK, reqID)
+	}
+
+	// note: just check if an error occurs while setting the result data
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_http_request_add_header_version_1(ctx context.Context, m api.Module, reqID int32, nameSpan, valueSpan int64) int64 {
+	logger.Debug("executing...")
+
+	name := asMemorySlice(ctx, m, nameSpan)
+	value := asMemorySlice(ctx, m, valueSpan)
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	offchainReq := runtimeCtx.OffchainHTTPSet.Get(int16(reqID))
+
+	result := scale.NewResult(nil, nil)
+	resultMode := scale.OK
+
+	err := offchainReq.AddHeader(string(name), string(value))
+	if err != nil {
+		logger.Errorf("failed to add request header: %s", err)
+		resultMode = scale.Err
+	}
+
+	err = result.Set(resultMode, nil)
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func storageAppend(storage runtime.Storage, key, valueToAppend []byte) error {
+	nextLength := big.NewInt(1)
+	var valueRes []byte
+
+	// this function assumes the item in storage is a SCALE encoded array of items
+	// the valueToAppend is a new item, so it appends the item and increases the length prefix by 1
+	valueCurr := storage.Get(key)
+
+	if len(valueCurr) == 0 {
+		valueRes = valueToAppend
+	} else {
+		var currLength *big.Int
+		err := scale.Unmarshal(valueCurr, &currLength)
+		if err != nil {
+			logger.Tracef(
+				"item in storage is not SCALE encoded, overwriting at key 0x%x", key)
+			storage.Set(key, append([]byte{4}, valueToAppend...))
+			return nil
+		}
+
+		lengthBytes, err := scale.Marshal(currLength)
+		if err != nil {
+			return err
+		}
+		// append new item, pop off number of bytes required for length encoding,
+		// since we're not using old scale.Decoder
+		valueRes = append(valueCurr[len(lengthBytes):], valueToAppend...)
+
+		// increase length by 1
+		nextLength = big.NewInt(0).Add(currLength, big.NewInt(1))
+	}
+
+	lengthEnc, err := scale.Marshal(nextLength)
+	if err != nil {
+		logger.Tracef("failed to encode new length: %s", err)
+		return err
+	}
+
+	// append new length prefix to start of items array
+	lengthEnc = append(lengthEnc, valueRes...)
+	logger.Debugf("resulting value: 0x%x", lengthEnc)
+	storage.Set(key, lengthEnc)
+	return nil
+}
+
+func ext_storage_append_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+	valueAppend := asMemorySlice(ctx, m, valueSpan)
+	logger.Debugf(
+		"will append value 0x%x to values at key 0x%x",
+		valueAppend, key)
+
+	cp := make([]byte, len(valueAppend))
+	copy(cp, valueAppend)
+
+	err := storageAppend(storage, key, cp)
+	if err != nil {
+		logger.Errorf("[ext_storage_append_version_1]: %s", err)
+	}
+}
+
+func ext_storage_changes_root_version_1(ctx context.Context, m api.Module, parentHashSpan int64) int64 {
+	logger.Trace("executing...")
+	logger.Debug("returning None")
+
+	rootSpan, err := toWasmMemoryOptional(ctx, m, nil)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(rootSpan)
+}
+
+func ext_storage_clear_version_1(ctx context.Context, m api.Module, keySpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+
+	logger.Debugf("key: 0x%x", key)
+	storage.Delete(key)
+}
+
+func ext_storage_clear_prefix_version_1(ctx context.Context, m api.Module, prefixSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	prefix := asMemorySlice(ctx, m, prefixSpan)
+	logger.Debugf("prefix: 0x%x", prefix)
+
+	err := storage.ClearPrefix(prefix)
+	if err != nil {
+		logger.Errorf("[ext_storage_clear_prefix_version_1]: %s", err)
+	}
+}
+
+func ext_storage_clear_prefix_version_2(ctx context.Context, m api.Module, prefixSpan, lim int64) int64 {
+	logger.Trace("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	prefix := asMemorySlice(ctx, m, prefixSpan)
+	logger.Debugf("prefix: 0x%x", prefix)
+
+	limitBytes := asMemorySlice(ctx, m, lim)
+
+	var limit []byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("[ext_storage_clear_prefix_version_2]: cannot generate limit: %s", err)
+		ret, _ := toWasmMemory(ctx, m, nil)
+		return int64(ret)
+	}
+
+	if len(limit) == 0 {
+		// limit is None, set limit to max
+		limit = []byte{0xff, 0xff, 0xff, 0xff}
+	}
+
+	limitUint := binary.LittleEndian.Uint32(limit)
+	numRemoved, all := storage.ClearPrefixLimit(prefix, limitUint)
+	encBytes, err := toKillStorageResultEnum(all, numRemoved)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, nil)
+		return int64(ret)
+	}
+
+	valueSpan, err := toWasmMemory(ctx, m, encBytes)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		ptr, _ := toWasmMemory(ctx, m, nil)
+		return int64(ptr)
+	}
+
+	return int64(valueSpan)
+}
+
+func ext_storage_exists_version_1(ctx context.Context, m api.Module, keySpan int64) int32 {
+	logger.Trace("executing...")
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+	logger.Debugf("key: 0x%x", key)
+
+	val := storage.Get(key)
+	if len(val) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func ext_storage_get_version_1(ctx context.Context, m api.Module, keySpan int64) int64 {
+	logger.Trace("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+	logger.Debugf("key: 0x%x", key)
+
+	value := storage.Get(key)
+	logger.Debugf("value: 0x%x", value)
+
+	valueSpan, err := toWasmMemoryOptional(ctx, m, value)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		ptr, _ := toWasmMemoryOptional(ctx, m, nil)
+		return int64(ptr)
+	}
+
+	return int64(valueSpan)
+}
+
+func ext_storage_next_key_version_1(ctx context.Context, m api.Module, keySpan int64) int64 {
+	logger.Trace("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+
+	next := storage.NextKey(key)
+	logger.Debugf(
+		"key: 0x%x; next key 0x%x",
+		key, next)
+
+	nextSpan, err := toWasmMemoryOptional(ctx, m, next)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(nextSpan)
+}
+
+func ext_storage_read_version_1(ctx context.Context, m api.Module, keySpan, valueOut int64, offset int32) int64 {
+	logger.Trace("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+	memory := memoryData(m)
+
+	key := asMemorySlice(ctx, m, keySpan)
+	value := storage.Get(key)
+	logger.Debugf(
+		"key 0x%x has value 0x%x",
+		key, value)
+
+	if value == nil {
+		ret, _ := toWasmMemoryOptional(ctx, m, nil)
+		return int64(ret)
+	}
+
+	var size uint32
+
+	if int(offset) > len(value) {
+		size = uint32(0)
+	} else {
+		size = uint32(len(value[offset:]))
+		valueBuf, valueLen := runtime.Int64ToPointerAndSize(int64(valueOut))
+		copy(memory[valueBuf:valueBuf+valueLen], value[offset:])
+	}
+
+	sizeSpan, err := toWasmMemoryOptionalUint32(ctx, m, &size)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(sizeSpan)
+}
+
+func ext_storage_root_version_1(ctx context.Context, m api.Module) int64 {
+	logger.Trace("executing...")
+
+	storage := ctx.Value(runtimeContextKey).(*runtime.Context).Storage
+
+	root, err := storage.Root()
+	if err != nil {
+		logger.Errorf("failed to get storage root: %s", err)
+		return 0
+	}
+
+	logger.Debugf("root hash is: %s", root)
+
+	rootSpan, err := toWasmMemory(ctx, m, root[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(rootSpan)
+}
+
+func ext_storage_root_version_2(ctx context.Context, m api.Module, version int32) int64 {
+	// TODO: update to use state trie version 1 (#2418)
+	return ext_storage_root_version_1(ctx, m)
+}
+
+func ext_storage_set_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+
+	runtimeCtx := ctx.Value(runtimeContextKey).(*runtime.Context)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(ctx, m, keySpan)
+	value := asMemorySlice(ctx, m, valueSpan)
+
+	cp := make([]byte, len(value))
+	copy(cp, value)
+
+	logger.Debugf(
+		"key 0x%x has value 0x%x",
+		key, value)
+	storage.Set(key, cp)
+}
+
+func ext_storage_start_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+	ctx.Value(runtimeContextKey).(*runtime.Context).Storage.BeginStorageTransaction()
+}
+
+func ext_storage_rollback_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+	ctx.Value(runtimeContextKey).(*runtime.Context).Storage.RollbackStorageTransaction()
+}
+
+func ext_storage_commit_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("[ext_storage_commit_transaction_version_1] executing...")
+	ctx.Value(runtimeContextKey).(*runtime.Context).Storage.CommitStorageTransaction()
+}
+
+// Convert 64bit wasm span descriptor to Go memory slice
+func asMemorySlice(ctx context.Context, m api.Module, span int64) []byte {
+	memory := memoryData(m)
+	ptr, size := runtime.Int64ToPointerAndSize(span)
+	profileBytes(ctx, int(size))
+	return memory[ptr : ptr+size]
+}
+
+// Copy a byte slice to wasm memory and return the resulting 64bit span descriptor
+func toWasmMemory(ctx context.Context, m api.Module, data []byte) (int64, error) {
+	allocator := ctx.Value(runtimeContextKey).(*runtime.Context).Allocator
+	size := uint32(len(data))
+
+	out, err := allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	if !m.Memory().Write(out, data) {
+		panic(fmt.Sprintf("length of memory is less than expected, want %d have %d", out+size, m.Memory().Size()))
+	}
+	profileBytes(ctx, int(size))
+
+	return runtime.PointerAndSizeToInt64(int32(out), int32(size)), nil
+}
+
+// Copy a byte slice of a fixed size to wasm memory and return resulting pointer
+func toWasmMemorySized(ctx context.Context, m api.Module, data []byte, size uint32) (uint32, error) {
+	if int(size) != len(data) {
+		return 0, errors.New("internal byte array size missmatch")
+	}
+
+	allocator := ctx.Value(runtimeContextKey).(*runtime.Context).Allocator
+
+	out, err := allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	m.Memory().Write(out, data)
+	profileBytes(ctx, int(size))
+
+	return out, nil
+}
+
+// Wraps slice in optional.Bytes and copies result to wasm memory. Returns resulting 64bit span descriptor
+func toWasmMemoryOptional(ctx context.Context, m api.Module, data []byte) (int64, error) {
+	var opt *[]byte
+	if data != nil {
+		temp := data
+		opt = &temp
+	}
+
+	enc, err := scale.Marshal(opt)
+	if err != nil {
+		return 0, err
+	}
+
+	return toWasmMemory(ctx, m, enc)
+}
+
+// Wraps slice in Result type and copies result to wasm memory. Returns resulting 64bit span descriptor
+func toWasmMemoryResult(ctx context.Context, m api.Module, data []byte) (int64, error) {
+	var res *rtype.Result
+	if len(data) == 0 {
+		res = rtype.NewResult(byte(1), nil)
+	} else {
+		res = rtype.NewResult(byte(0), data)
+	}
+
+	enc, err := res.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	return toWasmMemory(ctx, m, enc)
+}
+
+// Wraps slice in optional and copies result to wasm memory. Returns resulting 64bit span descriptor
+func toWasmMemoryOptionalUint32(ctx context.Context, m api.Module, data *uint32) (int64, error) {
+	var opt *uint32
+	if data != nil {
+		temp := *data
+		opt = &temp
+	}
+
+	enc, err := scale.Marshal(opt)
+	if err != nil {
+		return int64(0), err
+	}
+	return toWasmMemory(ctx, m, enc)
+}
+
+// toKillStorageResult returns enum encoded value
+func toKillStorageResultEnum(allRemoved bool, numRemoved uint32) ([]byte, error) {
+	var b, sbytes []byte
+	sbytes, err := scale.Marshal(numRemoved)
+	if err != nil {
+		return nil, err
+	}
+
+	if allRemoved {
+		// No key remains in the child trie.
+		b = append(b, byte(0))
+	} else {
+		// At least one key still resides in the child trie due to the supplied limit.
+		b = append(b, byte(1))
+	}
+
+	b = append(b, sbytes...)
+
+	return b, err
+}
+
+// Wraps slice in optional.FixedSizeBytes and copies result to wasm memory. Returns resulting 64bit span descriptor
+func toWasmMemoryFixedSizeOptional(ctx context.Context, m api.Module, data []byte) (int64, error) {
+	var opt [64]byte
+	copy(opt[:], data)
+	enc, err := scale.Marshal(&opt)
+	if err != nil {
+		return 0, err
+	}
+	return toWasmMemory(ctx, m, enc)
+}
+
+// instantiateHostModule instantiates the "env" host module exporting the host functions
+// imported by the runtime into the wazero runtime given.
+func instantiateHostModule(ctx context.Context, r wazero.Runtime) error {
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(ext_allocator_free_version_1).Export("ext_allocator_free_version_1").
+		NewFunctionBuilder().WithFunc(ext_allocator_malloc_version_1).Export("ext_allocator_malloc_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ecdsa_generate_version_1).Export("ext_crypto_ecdsa_generate_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ecdsa_public_keys_version_1).Export("ext_crypto_ecdsa_public_keys_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ecdsa_sign_version_1).Export("ext_crypto_ecdsa_sign_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ecdsa_verify_version_2).Export("ext_crypto_ecdsa_verify_version_2").
+		NewFunctionBuilder().WithFunc(ext_crypto_ed25519_generate_version_1).Export("ext_crypto_ed25519_generate_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ed25519_public_keys_version_1).Export("ext_crypto_ed25519_public_keys_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ed25519_sign_version_1).Export("ext_crypto_ed25519_sign_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_ed25519_verify_version_1).Export("ext_crypto_ed25519_verify_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_finish_batch_verify_version_1).Export("ext_crypto_finish_batch_verify_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_secp256k1_ecdsa_recover_compressed_version_1).Export("ext_crypto_secp256k1_ecdsa_recover_compressed_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_secp256k1_ecdsa_recover_compressed_version_2).Export("ext_crypto_secp256k1_ecdsa_recover_compressed_version_2").
+		NewFunctionBuilder().WithFunc(ext_crypto_secp256k1_ecdsa_recover_version_1).Export("ext_crypto_secp256k1_ecdsa_recover_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_secp256k1_ecdsa_recover_version_2).Export("ext_crypto_secp256k1_ecdsa_recover_version_2").
+		NewFunctionBuilder().WithFunc(ext_crypto_sr25519_generate_version_1).Export("ext_crypto_sr25519_generate_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_sr25519_public_keys_version_1).Export("ext_crypto_sr25519_public_keys_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_sr25519_sign_version_1).Export("ext_crypto_sr25519_sign_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_sr25519_verify_version_1).Export("ext_crypto_sr25519_verify_version_1").
+		NewFunctionBuilder().WithFunc(ext_crypto_sr25519_verify_version_2).Export("ext_crypto_sr25519_verify_version_2").
+		NewFunctionBuilder().WithFunc(ext_crypto_start_batch_verify_version_1).Export("ext_crypto_start_batch_verify_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_clear_prefix_version_1).Export("ext_default_child_storage_clear_prefix_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_clear_version_1).Export("ext_default_child_storage_clear_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_exists_version_1).Export("ext_default_child_storage_exists_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_get_version_1).Export("ext_default_child_storage_get_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_next_key_version_1).Export("ext_default_child_storage_next_key_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_read_version_1).Export("ext_default_child_storage_read_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_root_version_1).Export("ext_default_child_storage_root_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_set_version_1).Export("ext_default_child_storage_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_storage_kill_version_1).Export("ext_default_child_storage_storage_kill_version_1").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_storage_kill_version_2).Export("ext_default_child_storage_storage_kill_version_2").
+		NewFunctionBuilder().WithFunc(ext_default_child_storage_storage_kill_version_3).Export("ext_default_child_storage_storage_kill_version_3").
+		NewFunctionBuilder().WithFunc(ext_hashing_blake2_128_version_1).Export("ext_hashing_blake2_128_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_blake2_256_version_1).Export("ext_hashing_blake2_256_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_keccak_256_version_1).Export("ext_hashing_keccak_256_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_sha2_256_version_1).Export("ext_hashing_sha2_256_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_twox_128_version_1).Export("ext_hashing_twox_128_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_twox_256_version_1).Export("ext_hashing_twox_256_version_1").
+		NewFunctionBuilder().WithFunc(ext_hashing_twox_64_version_1).Export("ext_hashing_twox_64_version_1").
+		NewFunctionBuilder().WithFunc(ext_logging_log_version_1).Export("ext_logging_log_version_1").
+		NewFunctionBuilder().WithFunc(ext_logging_max_level_version_1).Export("ext_logging_max_level_version_1").
+		NewFunctionBuilder().WithFunc(ext_misc_print_hex_version_1).Export("ext_misc_print_hex_version_1").
+		NewFunctionBuilder().WithFunc(ext_misc_print_num_version_1).Export("ext_misc_print_num_version_1").
+		NewFunctionBuilder().WithFunc(ext_misc_print_utf8_version_1).Export("ext_misc_print_utf8_version_1").
+		NewFunctionBuilder().WithFunc(ext_misc_runtime_version_version_1).Export("ext_misc_runtime_version_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_http_request_add_header_version_1).Export("ext_offchain_http_request_add_header_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_http_request_start_version_1).Export("ext_offchain_http_request_start_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_index_set_version_1).Export("ext_offchain_index_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_is_validator_version_1).Export("ext_offchain_is_validator_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_local_storage_clear_version_1).Export("ext_offchain_local_storage_clear_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_local_storage_compare_and_set_version_1).Export("ext_offchain_local_storage_compare_and_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_local_storage_get_version_1).Export("ext_offchain_local_storage_get_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_local_storage_set_version_1).Export("ext_offchain_local_storage_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_network_state_version_1).Export("ext_offchain_network_state_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_random_seed_version_1).Export("ext_offchain_random_seed_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_sleep_until_version_1).Export("ext_offchain_sleep_until_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_submit_transaction_version_1).Export("ext_offchain_submit_transaction_version_1").
+		NewFunctionBuilder().WithFunc(ext_offchain_timestamp_version_1).Export("ext_offchain_timestamp_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_instance_teardown_version_1).Export("ext_sandbox_instance_teardown_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_instantiate_version_1).Export("ext_sandbox_instantiate_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_invoke_version_1).Export("ext_sandbox_invoke_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_memory_get_version_1).Export("ext_sandbox_memory_get_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_memory_new_version_1).Export("ext_sandbox_memory_new_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_memory_set_version_1).Export("ext_sandbox_memory_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_sandbox_memory_teardown_version_1).Export("ext_sandbox_memory_teardown_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_append_version_1).Export("ext_storage_append_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_changes_root_version_1).Export("ext_storage_changes_root_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_clear_prefix_version_1).Export("ext_storage_clear_prefix_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_clear_prefix_version_2).Export("ext_storage_clear_prefix_version_2").
+		NewFunctionBuilder().WithFunc(ext_storage_clear_version_1).Export("ext_storage_clear_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_commit_transaction_version_1).Export("ext_storage_commit_transaction_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_exists_version_1).Export("ext_storage_exists_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_get_version_1).Export("ext_storage_get_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_next_key_version_1).Export("ext_storage_next_key_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_read_version_1).Export("ext_storage_read_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_rollback_transaction_version_1).Export("ext_storage_rollback_transaction_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_root_version_1).Export("ext_storage_root_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_root_version_2).Export("ext_storage_root_version_2").
+		NewFunctionBuilder().WithFunc(ext_storage_set_version_1).Export("ext_storage_set_version_1").
+		NewFunctionBuilder().WithFunc(ext_storage_start_transaction_version_1).Export("ext_storage_start_transaction_version_1").
+		NewFunctionBuilder().WithFunc(ext_transaction_index_index_version_1).Export("ext_transaction_index_index_version_1").
+		NewFunctionBuilder().WithFunc(ext_transaction_index_renew_version_1).Export("ext_transaction_index_renew_version_1").
+		NewFunctionBuilder().WithFunc(ext_trie_blake2_256_ordered_root_version_1).Export("ext_trie_blake2_256_ordered_root_version_1").
+		NewFunctionBuilder().WithFunc(ext_trie_blake2_256_ordered_root_version_2).Export("ext_trie_blake2_256_ordered_root_version_2").
+		NewFunctionBuilder().WithFunc(ext_trie_blake2_256_root_version_1).Export("ext_trie_blake2_256_root_version_1").
+		NewFunctionBuilder().WithFunc(ext_trie_blake2_256_verify_proof_version_1).Export("ext_trie_blake2_256_verify_proof_version_1").
+		NewFunctionBuilder().WithFunc(ext_wasm_tracing_enabled_version_1).Export("ext_wasm_tracing_enabled_version_1").
+		NewFunctionBuilder().WithFunc(ext_wasm_tracing_enter_span_version_1).Export("ext_wasm_tracing_enter_span_version_1").
+		NewFunctionBuilder().WithFunc(ext_wasm_tracing_event_version_1).Export("ext_wasm_tracing_event_version_1").
+		NewFunctionBuilder().WithFunc(ext_wasm_tracing_exit_version_1).Export("ext_wasm_tracing_exit_version_1").
+		Instantiate(ctx)
+	return err
+}
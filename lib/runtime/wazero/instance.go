@@ -0,0 +1,478 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// Name represents the name of the interpreter
+const Name = "wazero"
+
+// Check that runtime interfaces are satisfied
+var (
+	_ runtime.Instance           = (*Instance)(nil)
+	_ runtime.PoolableInstance   = (*Instance)(nil)
+	_ runtime.ProfilableInstance = (*Instance)(nil)
+	_ runtime.Memory             = (*memory)(nil)
+
+	logger = log.NewFromGlobal(
+		log.AddContext("pkg", "runtime"),
+		log.AddContext("module", "wazero"),
+	)
+	targetLoggers = runtime.NewTargetLoggers(logger)
+)
+
+// contextKey is the type of the key of the runtime context
+// in the context given to the host functions.
+type contextKey struct{}
+
+var runtimeContextKey = contextKey{}
+
+// Config represents a wazero configuration
+type Config struct {
+	runtime.InstanceConfig
+}
+
+// Instance represents a runtime wazero instance
+type Instance struct {
+	runtime  wazero.Runtime
+	module   api.Module
+	ctx      *runtime.Context
+	isClosed bool
+	codeHash common.Hash
+	code     []byte
+	// version is the version of the runtime code,
+	// cached when it is first requested.
+	version runtime.Version
+	// snapshot is the content of the memory right after instantiation,
+	// which is restored when the instance is reset.
+	snapshot  []byte
+	heapPages uint64
+	sync.Mutex
+}
+
+// memory implements runtime.Memory for the memory of a wazero module.
+type memory struct {
+	api.Memory
+}
+
+// Data returns the memory content. It is only valid until the memory grows.
+func (m *memory) Data() []byte {
+	data, _ := m.Read(0, m.Size())
+	return data
+}
+
+// Length returns the memory size in bytes.
+func (m *memory) Length() uint32 {
+	return m.Size()
+}
+
+// Grow grows the memory by the given number of pages.
+func (m *memory) Grow(numPages uint32) error {
+	if _, ok := m.Memory.Grow(numPages); !ok {
+		return fmt.Errorf("cannot grow memory by %d pages", numPages)
+	}
+	return nil
+}
+
+// NewRuntimeFromGenesis creates a runtime instance from the genesis data
+func NewRuntimeFromGenesis(cfg *Config) (runtime.Instance, error) {
+	if cfg.Storage == nil {
+		return nil, errors.New("storage is nil")
+	}
+
+	code := cfg.Storage.LoadCode()
+	if len(code) == 0 {
+		return nil, fmt.Errorf("cannot find :code in state")
+	}
+
+	return NewInstance(code, cfg)
+}
+
+// NewInstanceFromTrie returns a new runtime instance with the code provided in the given trie
+func NewInstanceFromTrie(t *trie.Trie, cfg *Config) (*Instance, error) {
+	code := t.Get(common.CodeKey)
+	if len(code) == 0 {
+		return nil, fmt.Errorf("cannot find :code in trie")
+	}
+
+	return NewInstance(code, cfg)
+}
+
+// NewInstanceFromFile instantiates a runtime from a .wasm file
+func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
+	bytes, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInstance(bytes, cfg)
+}
+
+// NewRuntimeInstance instantiates a runtime from raw wasm bytecode,
+// and implements runtime.NewInstanceFunc.
+func NewRuntimeInstance(code []byte, cfg runtime.InstanceConfig) (runtime.Instance, error) {
+	instance, err := NewInstance(code, &Config{InstanceConfig: cfg})
+	if err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// NewInstance instantiates a runtime from raw wasm bytecode
+func NewInstance(code []byte, cfg *Config) (*Instance, error) {
+	logger.Patch(log.SetLevel(cfg.LogLvl), log.SetCallerFunc(true))
+
+	runtimeCtx := &runtime.Context{
+		Storage:         cfg.Storage,
+		Keystore:        cfg.Keystore,
+		Validator:       cfg.Role == byte(4),
+		NodeStorage:     cfg.NodeStorage,
+		Network:         cfg.Network,
+		Transaction:     cfg.Transaction,
+		SigVerifier:     crypto.NewSignatureVerifier(logger),
+		OffchainHTTPSet: offchain.NewHTTPSet(),
+		Profiler:        runtime.NewInstanceProfiler(),
+		Tracer:          runtime.NewWasmTracer(targetLoggers),
+	}
+
+	inst := &Instance{
+		ctx:       runtimeCtx,
+		codeHash:  cfg.CodeHash,
+		heapPages: runtime.HeapPages(cfg.Storage),
+	}
+
+	err := inst.setupInstanceVM(code)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("NewInstance called with runtimeCtx: %v", runtimeCtx)
+	return inst, nil
+}
+
+// decompressWasm decompresses a Wasm blob that may or may not be compressed with zstd
+// ref: https://github.com/paritytech/substrate/blob/master/primitives/maybe-compressed-blob/src/lib.rs
+func decompressWasm(code []byte) ([]byte, error) {
+	compressionFlag := []byte{82, 188, 83, 118, 70, 219, 142, 5}
+	if !bytes.HasPrefix(code, compressionFlag) {
+		return code, nil
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+
+	return decoder.DecodeAll(code[len(compressionFlag):], nil)
+}
+
+// GetCodeHash returns the code of the instance
+func (in *Instance) GetCodeHash() common.Hash {
+	return in.codeHash
+}
+
+// GetHeapPages returns the number of heap pages of the memory of the instance
+func (in *Instance) GetHeapPages() uint64 {
+	return in.heapPages
+}
+
+// GetContext returns the context of the instance
+func (in *Instance) GetContext() *runtime.Context {
+	return in.ctx
+}
+
+// UpdateRuntimeCode updates the runtime instance to run the given code
+func (in *Instance) UpdateRuntimeCode(code []byte) error {
+	in.Stop()
+
+	err := in.setupInstanceVM(code)
+	if err != nil {
+		return err
+	}
+
+	in.isClosed = false
+	return nil
+}
+
+// CheckRuntimeVersion calculates runtime Version for runtime blob passed in
+func (in *Instance) CheckRuntimeVersion(code []byte) (runtime.Version, error) {
+	// the temporary instance has its own copy of the context, since
+	// its allocator is set to the memory of the temporary instance.
+	in.Lock()
+	runtimeCtx := *in.ctx
+	in.Unlock()
+
+	tmp := &Instance{
+		ctx:       &runtimeCtx,
+		heapPages: in.heapPages,
+	}
+
+	err := tmp.setupInstanceVM(code)
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Stop()
+
+	return tmp.Version()
+}
+
+// setupInstanceVM compiles and instantiates the given code in a new wazero runtime,
+// together with the host functions, and sets the allocator of the instance context.
+func (in *Instance) setupInstanceVM(code []byte) error {
+	if len(code) == 0 {
+		return errors.New("code is empty")
+	}
+
+	in.version = nil
+
+	code, err := decompressWasm(code)
+	if err != nil {
+		return fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	code, err = defineImportedMemory(code)
+	if err != nil {
+		return fmt.Errorf("cannot define imported memory: %w", err)
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+
+	// the host functions calls are recorded when the instance has a profiler.
+	err = instantiateHostModule(context.WithValue(ctx,
+		experimental.FunctionListenerFactoryKey{}, hostFunctionProfiler{}), r)
+	if err != nil {
+		_ = r.Close(ctx)
+		return fmt.Errorf("cannot instantiate host module: %w", err)
+	}
+
+	compiled, err := r.CompileModule(ctx, code)
+	if err != nil {
+		_ = r.Close(ctx)
+		return fmt.Errorf("cannot compile WASM code: %w", err)
+	}
+
+	mod, err := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig())
+	if err != nil {
+		_ = r.Close(ctx)
+		return fmt.Errorf("cannot instantiate WASM module: %w", err)
+	}
+
+	if mod.Memory() == nil {
+		_ = r.Close(ctx)
+		return errors.New("WASM module has no memory")
+	}
+
+	// the memory has the minimum size of the module, and the heap pages are added to it.
+	modulePages := mod.Memory().Size() / runtime.PageSize
+	pages, err := runtime.MemoryPages(modulePages, in.heapPages)
+	if err != nil {
+		_ = r.Close(ctx)
+		return err
+	}
+
+	if _, ok := mod.Memory().Grow(pages - modulePages); !ok {
+		_ = r.Close(ctx)
+		return fmt.Errorf("cannot grow memory by %d heap pages", in.heapPages)
+	}
+
+	heapBase := runtime.DefaultHeapBase
+	if global := mod.ExportedGlobal("__heap_base"); global != nil {
+		heapBase = uint32(global.Get())
+	}
+
+	in.runtime = r
+	in.module = mod
+	in.ctx.Allocator = runtime.NewAllocator(&memory{Memory: mod.Memory()}, heapBase)
+	in.code = code
+	// the heap pages are initially empty, so only the pages
+	// of the module, holding its data and stack, are saved.
+	in.snapshot = append([]byte(nil), memoryData(mod)[:int(modulePages)*runtime.PageSize]...)
+	return nil
+}
+
+// Clone returns a new instance of the runtime code of the instance,
+// with the same context storage, keystore and services.
+func (in *Instance) Clone() (runtime.Instance, error) {
+	in.Lock()
+	defer in.Unlock()
+
+	runtimeCtx := &runtime.Context{
+		Storage:         in.ctx.Storage,
+		Keystore:        in.ctx.Keystore,
+		Validator:       in.ctx.Validator,
+		NodeStorage:     in.ctx.NodeStorage,
+		Network:         in.ctx.Network,
+		Transaction:     in.ctx.Transaction,
+		SigVerifier:     crypto.NewSignatureVerifier(logger),
+		OffchainHTTPSet: offchain.NewHTTPSet(),
+		Profiler:        runtime.NewInstanceProfiler(),
+		Tracer:          runtime.NewWasmTracer(targetLoggers),
+	}
+
+	clone := &Instance{
+		ctx:       runtimeCtx,
+		codeHash:  in.codeHash,
+		heapPages: in.heapPages,
+	}
+
+	err := clone.setupInstanceVM(in.code)
+	if err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// Reset restores the module pages of the memory of the instance to their content
+// right after instantiation, resets its allocator and the heap written by its
+// allocations, and unsets its context storage.
+func (in *Instance) Reset() {
+	in.Lock()
+	defer in.Unlock()
+
+	in.ctx.Allocator.Reset()
+	copy(memoryData(in.module), in.snapshot)
+	in.ctx.Storage = nil
+}
+
+// SetContextStorage sets the runtime's storage. It should be set before calls to the below functions.
+func (in *Instance) SetContextStorage(s runtime.Storage) {
+	in.Lock()
+	defer in.Unlock()
+	in.ctx.Storage = s
+}
+
+// SetProfiler implements runtime.ProfilableInstance.
+func (in *Instance) SetProfiler(profiler *runtime.Profiler) {
+	in.Lock()
+	defer in.Unlock()
+	in.ctx.Profiler = profiler
+}
+
+// Stop func
+func (in *Instance) Stop() {
+	in.Lock()
+	defer in.Unlock()
+	if !in.isClosed {
+		err := in.runtime.Close(context.Background())
+		if err != nil {
+			logger.Errorf("cannot close wazero runtime: %s", err)
+		}
+		in.isClosed = true
+	}
+}
+
+// Exec calls the given function with the given data
+func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
+	return in.exec(function, data)
+}
+
+// Exec func
+func (in *Instance) exec(function string, data []byte) ([]byte, error) {
+	if in.ctx.Storage == nil {
+		return nil, runtime.ErrNilStorage
+	}
+
+	in.Lock()
+	defer in.Unlock()
+
+	if in.isClosed {
+		return nil, errors.New("instance is stopped")
+	}
+
+	ptr, err := in.ctx.Allocator.Allocate(uint32(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	defer in.ctx.Allocator.Clear()
+
+	mem := in.module.Memory()
+	if !mem.Write(ptr, data) {
+		return nil, fmt.Errorf("cannot write %d bytes at offset %d to memory", len(data), ptr)
+	}
+
+	runtimeFunc := in.module.ExportedFunction(function)
+	if runtimeFunc == nil {
+		return nil, fmt.Errorf("could not find exported function %s", function)
+	}
+
+	if in.ctx.Profiler != nil {
+		in.ctx.Profiler.StartEntrypoint(function)
+	}
+
+	ctx := context.WithValue(context.Background(), runtimeContextKey, in.ctx)
+	res, err := runtimeFunc.Call(ctx, uint64(ptr), uint64(len(data)))
+	if storage, ok := in.ctx.Storage.(runtime.FallibleStorage); ok {
+		if storageErr := storage.Err(); storageErr != nil {
+			return nil, fmt.Errorf("cannot access storage: %w", storageErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) != 1 {
+		return nil, fmt.Errorf("exported function %s returned %d values, expected 1", function, len(res))
+	}
+
+	offset, length := runtime.Int64ToPointerAndSize(int64(res[0]))
+	result, ok := mem.Read(uint32(offset), uint32(length))
+	if !ok {
+		return nil, fmt.Errorf("cannot read %d bytes at offset %d from memory", length, offset)
+	}
+
+	// the memory view is reused by the next calls, so the result is copied.
+	output := make([]byte, length)
+	copy(output, result)
+	return output, nil
+}
+
+// NodeStorage to get reference to runtime node service
+func (in *Instance) NodeStorage() runtime.NodeStorage {
+	return in.ctx.NodeStorage
+}
+
+// NetworkService to get referernce to runtime network service
+func (in *Instance) NetworkService() runtime.BasicNetwork {
+	return in.ctx.Network
+}
+
+// Keystore to get reference to runtime keystore
+func (in *Instance) Keystore() *keystore.GlobalKeystore {
+	return in.ctx.Keystore
+}
+
+// Validator returns the context's Validator
+func (in *Instance) Validator() bool {
+	return in.ctx.Validator
+}
+
+// memoryData returns the content of the memory of the module given.
+// It is only valid until the memory grows.
+func memoryData(m api.Module) []byte {
+	data, _ := m.Memory().Read(0, m.Memory().Size())
+	return data
+}
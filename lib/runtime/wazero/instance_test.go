@@ -0,0 +1,86 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Instance_CheckRuntimeVersion(t *testing.T) {
+	t.Parallel()
+
+	// the node runtime of the polkadot.js tests is part of the repository.
+	code, err := os.ReadFile(filepath.Join("..", "..", "..",
+		"tests", "polkadotjs_test", "test", "node_runtime.compact.wasm"))
+	require.NoError(t, err)
+
+	s, err := storage.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, err)
+
+	instance, err := NewInstance(code, &Config{
+		InstanceConfig: runtime.InstanceConfig{
+			Storage: s,
+			LogLvl:  log.Critical,
+		},
+	})
+	require.NoError(t, err)
+	defer instance.Stop()
+
+	expected, err := instance.Version()
+	require.NoError(t, err)
+
+	allocator := instance.ctx.Allocator
+
+	version, err := instance.CheckRuntimeVersion(code)
+	require.NoError(t, err)
+	assert.Equal(t, expected, version)
+
+	// the context of the instance is not modified by the temporary instance.
+	assert.Same(t, allocator, instance.ctx.Allocator)
+
+	_, err = instance.Exec(runtime.CoreVersion, nil)
+	assert.NoError(t, err)
+}
+
+func Test_Instance_Version_KusamaRuntime(t *testing.T) {
+	t.Parallel()
+
+	gen, err := genesis.NewGenesisFromJSONRaw(utils.GetKusamaGenesisPath(t))
+	require.NoError(t, err)
+
+	genTrie, err := genesis.NewTrieFromGenesis(gen)
+	require.NoError(t, err)
+
+	s, err := storage.NewTrieState(genTrie)
+	require.NoError(t, err)
+
+	instance, err := NewInstance(s.LoadCode(), &Config{
+		InstanceConfig: runtime.InstanceConfig{
+			Storage: s,
+			LogLvl:  log.Critical,
+		},
+	})
+	require.NoError(t, err)
+	defer instance.Stop()
+
+	version, err := instance.Version()
+	require.NoError(t, err)
+	require.Equal(t, []byte("kusama"), version.SpecName())
+	require.Equal(t, uint32(1020), version.SpecVersion())
+
+	// the node starts with the genesis runtime of kusama.
+	err = runtime.CheckRequiredAPIs(version)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,250 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const (
+	importSectionID = 2
+	memorySectionID = 5
+
+	importKindFunction = 0x00
+	importKindTable    = 0x01
+	importKindMemory   = 0x02
+	importKindGlobal   = 0x03
+)
+
+var (
+	wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+	errInvalidModule       = errors.New("invalid wasm module")
+	errMultipleMemories    = errors.New("wasm module imports and defines a memory")
+	errUnexpectedEndOfCode = errors.New("unexpected end of wasm code")
+)
+
+// defineImportedMemory rewrites the wasm code given so the memory imported by the
+// module, if any, is defined by the module itself with the same limits. Host
+// modules cannot export a memory in wazero, whereas substrate runtimes import
+// their memory from the host, so the host functions use the module memory instead.
+// The index of the memory is unchanged, since a module has at most one memory.
+func defineImportedMemory(code []byte) ([]byte, error) {
+	if !bytes.HasPrefix(code, wasmHeader) {
+		return nil, fmt.Errorf("%w: bad magic number or version", errInvalidModule)
+	}
+
+	type section struct {
+		id      byte
+		content []byte
+	}
+
+	var sections []section
+	r := &reader{data: code, offset: len(wasmHeader)}
+	for r.offset < len(r.data) {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read section with id %d: %w", id, err)
+		}
+
+		sections = append(sections, section{id: id, content: content})
+	}
+
+	var memoryLimits []byte
+	for i, s := range sections {
+		switch s.id {
+		case importSectionID:
+			content, limits, err := removeMemoryImport(s.content)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read import section: %w", err)
+			}
+			sections[i].content = content
+			memoryLimits = limits
+		case memorySectionID:
+			if memoryLimits != nil {
+				return nil, errMultipleMemories
+			}
+		}
+	}
+
+	if memoryLimits == nil {
+		return code, nil
+	}
+
+	memorySection := section{
+		id:      memorySectionID,
+		content: append(encodeU32(1), memoryLimits...),
+	}
+
+	rewritten := bytes.NewBuffer(make([]byte, 0, len(code)+len(memoryLimits)+8))
+	rewritten.Write(wasmHeader)
+	inserted := false
+	for _, s := range sections {
+		// the memory section goes before the first known section with a greater order,
+		// which are all the non custom sections with an id greater than the memory one.
+		if !inserted && s.id > memorySectionID {
+			writeSection(rewritten, memorySection.id, memorySection.content)
+			inserted = true
+		}
+		writeSection(rewritten, s.id, s.content)
+	}
+	if !inserted {
+		writeSection(rewritten, memorySection.id, memorySection.content)
+	}
+
+	return rewritten.Bytes(), nil
+}
+
+// removeMemoryImport returns the content of the import section given without the
+// memory import, and the encoded limits of the memory, or nil if no memory is imported.
+func removeMemoryImport(content []byte) (rewritten, limits []byte, err error) {
+	r := &reader{data: content}
+	count, err := r.u32()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([][]byte, 0, count)
+	for i := uint32(0); i < count; i++ {
+		start := r.offset
+		for j := 0; j < 2; j++ { // module and field names
+			length, err := r.u32()
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, err = r.bytes(int(length)); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		kind, err := r.byte()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch kind {
+		case importKindFunction:
+			_, err = r.u32()
+		case importKindTable:
+			if _, err = r.byte(); err == nil {
+				_, err = r.limits()
+			}
+		case importKindMemory:
+			limits, err = r.limits()
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		case importKindGlobal:
+			_, err = r.bytes(2)
+		default:
+			err = fmt.Errorf("%w: unknown import kind %d", errInvalidModule, kind)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entries = append(entries, r.data[start:r.offset])
+	}
+
+	if limits == nil {
+		return content, nil, nil
+	}
+
+	rewritten = encodeU32(uint32(len(entries)))
+	for _, entry := range entries {
+		rewritten = append(rewritten, entry...)
+	}
+	return rewritten, limits, nil
+}
+
+func writeSection(buffer *bytes.Buffer, id byte, content []byte) {
+	buffer.WriteByte(id)
+	buffer.Write(encodeU32(uint32(len(content))))
+	buffer.Write(content)
+}
+
+// encodeU32 encodes the value given as unsigned LEB128.
+func encodeU32(value uint32) (encoded []byte) {
+	for {
+		b := byte(value & 0x7f)
+		value >>= 7
+		if value == 0 {
+			return append(encoded, b)
+		}
+		encoded = append(encoded, b|0x80)
+	}
+}
+
+type reader struct {
+	data   []byte
+	offset int
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.offset >= len(r.data) {
+		return 0, errUnexpectedEndOfCode
+	}
+	b := r.data[r.offset]
+	r.offset++
+	return b, nil
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.offset+n > len(r.data) {
+		return nil, errUnexpectedEndOfCode
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b, nil
+}
+
+// u32 reads an unsigned LEB128 encoded 32 bits integer.
+func (r *reader) u32() (value uint32, err error) {
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: integer too large", errInvalidModule)
+}
+
+// limits reads the limits of a table or memory, and returns their encoding.
+func (r *reader) limits() ([]byte, error) {
+	start := r.offset
+	flag, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = r.u32(); err != nil { // minimum
+		return nil, err
+	}
+
+	// the lowest bit of the flag indicates a maximum, the second one a shared memory
+	if flag&0x01 != 0 {
+		if _, err = r.u32(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.data[start:r.offset], nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+)
+
+func concat(slices ...[]byte) (concatenated []byte) {
+	for _, slice := range slices {
+		concatenated = append(concatenated, slice...)
+	}
+	return concatenated
+}
+
+func Test_defineImportedMemory(t *testing.T) {
+	t.Parallel()
+
+	typeSection := []byte{0x01, 0x04, 0x01, 0x60, 0x00, 0x00}
+	functionImport := []byte{0x03, 'e', 'n', 'v', 0x01, 'f', 0x00, 0x00}
+	memoryImport := []byte{0x03, 'e', 'n', 'v', 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x01, 0x14, 0x80, 0x01}
+	exportSection := []byte{0x07, 0x0a, 0x01, 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00}
+
+	testCases := map[string]struct {
+		code       []byte
+		rewritten  []byte
+		errWrapped error
+		errMessage string
+	}{
+		"imported memory": {
+			code: concat(wasmHeader, typeSection,
+				[]byte{0x02, 0x19, 0x02}, functionImport, memoryImport,
+				exportSection),
+			rewritten: concat(wasmHeader, typeSection,
+				[]byte{0x02, 0x09, 0x01}, functionImport,
+				[]byte{0x05, 0x05, 0x01, 0x01, 0x14, 0x80, 0x01},
+				exportSection),
+		},
+		"imported memory in last section": {
+			code: concat(wasmHeader, []byte{0x02, 0x11, 0x01}, memoryImport),
+			rewritten: concat(wasmHeader, []byte{0x02, 0x01, 0x00},
+				[]byte{0x05, 0x05, 0x01, 0x01, 0x14, 0x80, 0x01}),
+		},
+		"no imported memory": {
+			code: concat(wasmHeader, typeSection,
+				[]byte{0x02, 0x09, 0x01}, functionImport),
+			rewritten: concat(wasmHeader, typeSection,
+				[]byte{0x02, 0x09, 0x01}, functionImport),
+		},
+		"imported and defined memories": {
+			code: concat(wasmHeader, []byte{0x02, 0x11, 0x01}, memoryImport,
+				[]byte{0x05, 0x03, 0x01, 0x00, 0x01}),
+			errWrapped: errMultipleMemories,
+			errMessage: "wasm module imports and defines a memory",
+		},
+		"bad header": {
+			code:       []byte{0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+			errWrapped: errInvalidModule,
+			errMessage: "invalid wasm module: bad magic number or version",
+		},
+		"truncated section": {
+			code:       concat(wasmHeader, []byte{0x02, 0x19, 0x02}, functionImport),
+			errWrapped: errUnexpectedEndOfCode,
+			errMessage: "cannot read section with id 2: unexpected end of wasm code",
+		},
+		"unknown import kind": {
+			code:       concat(wasmHeader, []byte{0x02, 0x09, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'f', 0x07, 0x00}),
+			errWrapped: errInvalidModule,
+			errMessage: "cannot read import section: invalid wasm module: unknown import kind 7",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rewritten, err := defineImportedMemory(testCase.code)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.rewritten, rewritten)
+		})
+	}
+}
+
+func Test_defineImportedMemory_compiles(t *testing.T) {
+	t.Parallel()
+
+	code := concat(wasmHeader,
+		[]byte{0x02, 0x0f, 0x01, 0x03, 'e', 'n', 'v', 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00, 0x14},
+		[]byte{0x07, 0x0a, 0x01, 0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00})
+
+	rewritten, err := defineImportedMemory(code)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	compiled, err := r.CompileModule(ctx, rewritten)
+	require.NoError(t, err)
+	assert.Empty(t, compiled.ImportedMemories())
+
+	mod, err := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(20*65536), mod.Memory().Size())
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// hostFunctionProfiler is the function listener recording the host function
+// calls of the runtime, if the instance calling them has a profiler.
+type hostFunctionProfiler struct{}
+
+// NewListener implements experimental.FunctionListenerFactory.
+func (hostFunctionProfiler) NewListener(api.FunctionDefinition) experimental.FunctionListener {
+	return hostFunctionProfiler{}
+}
+
+// Before implements experimental.FunctionListener.
+func (hostFunctionProfiler) Before(ctx context.Context, _ api.Module, def api.FunctionDefinition,
+	_ []uint64) context.Context {
+	if profiler := contextProfiler(ctx); profiler != nil {
+		profiler.StartHostFunction(def.Name())
+	}
+	return ctx
+}
+
+// After implements experimental.FunctionListener.
+func (hostFunctionProfiler) After(ctx context.Context, _ api.Module, _ api.FunctionDefinition,
+	_ error, _ []uint64) {
+	if profiler := contextProfiler(ctx); profiler != nil {
+		profiler.EndHostFunction()
+	}
+}
+
+// profileBytes adds the given number of bytes to the ones transferred by the
+// current host function call, if the instance has a profiler.
+func profileBytes(ctx context.Context, n int) {
+	if profiler := contextProfiler(ctx); profiler != nil {
+		profiler.AddBytes(n)
+	}
+}
+
+func contextProfiler(ctx context.Context) *runtime.Profiler {
+	runtimeCtx, ok := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if !ok {
+		return nil
+	}
+	return runtimeCtx.Profiler
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+func Test_hostFunctionProfiler(t *testing.T) {
+	t.Parallel()
+
+	// module exporting a run function calling the imported env.f function.
+	code := concat(wasmHeader,
+		[]byte{0x01, 0x04, 0x01, 0x60, 0x00, 0x00},
+		[]byte{0x02, 0x09, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'f', 0x00, 0x00},
+		[]byte{0x03, 0x02, 0x01, 0x00},
+		[]byte{0x07, 0x07, 0x01, 0x03, 'r', 'u', 'n', 0x00, 0x01},
+		[]byte{0x0a, 0x06, 0x01, 0x04, 0x00, 0x10, 0x00, 0x0b})
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, _ api.Module) {
+		profileBytes(ctx, 8)
+	}).Export("f").
+		Instantiate(context.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, hostFunctionProfiler{}))
+	require.NoError(t, err)
+
+	mod, err := r.InstantiateWithConfig(ctx, code, wazero.NewModuleConfig())
+	require.NoError(t, err)
+
+	// the host function calls are not recorded without a profiler.
+	_, err = mod.ExportedFunction("run").Call(context.WithValue(ctx, runtimeContextKey, &runtime.Context{}))
+	require.NoError(t, err)
+
+	profiler := runtime.NewProfiler(false)
+	profiler.StartEntrypoint(runtime.CoreVersion)
+	runtimeCtx := context.WithValue(ctx, runtimeContextKey, &runtime.Context{Profiler: profiler})
+	for i := 0; i < 2; i++ {
+		_, err = mod.ExportedFunction("run").Call(runtimeCtx)
+		require.NoError(t, err)
+	}
+
+	hostFunction := profiler.Profile()[runtime.CoreVersion]["f"]
+	assert.Equal(t, uint64(2), hostFunction.Calls)
+	assert.Equal(t, uint64(16), hostFunction.Bytes)
+}
@@ -31,14 +31,32 @@ func (t *Trie) PutChild(keyToChild []byte, child *Trie) error {
 }
 
 // GetChild returns the child trie at key :child_storage:[keyToChild]
+// For a trie loaded lazily from the database, the child trie is loaded
+// lazily as well the first time it is requested.
 func (t *Trie) GetChild(keyToChild []byte) (*Trie, error) {
 	key := append(ChildStorageKeyPrefix, keyToChild...)
 	childHash := t.Get(key)
+	if err := t.Err(); err != nil {
+		return nil, err
+	}
 	if childHash == nil {
 		return nil, fmt.Errorf("%w at key 0x%x%x", ErrChildTrieDoesNotExist, ChildStorageKeyPrefix, keyToChild)
 	}
 
-	return t.childTries[common.BytesToHash(childHash)], nil
+	childRootHash := common.BytesToHash(childHash)
+	child, ok := t.childTries[childRootHash]
+	if ok || t.lazy == nil {
+		return child, nil
+	}
+
+	child, err := NewLazyTrie(t.lazy.db, t.lazy.cache, childRootHash).Trie()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load child trie with root hash %s: %w", childRootHash, err)
+	}
+	child.generation = t.generation
+	child.resolveErr = t.resolveErr
+	t.childTries[childRootHash] = child
+	return child, nil
 }
 
 // PutIntoChild puts a key-value pair into the child trie located in the main trie at key :child_storage:[keyToChild]
@@ -54,6 +72,11 @@ func (t *Trie) PutIntoChild(keyToChild, key, value []byte) error {
 	}
 
 	child.Put(key, value)
+	err = child.Err()
+	if err != nil {
+		return err
+	}
+
 	childHash, err := child.Hash()
 	if err != nil {
 		return err
@@ -78,7 +101,7 @@ func (t *Trie) GetFromChild(keyToChild, key []byte) ([]byte, error) {
 	}
 
 	val := child.Get(key)
-	return val, nil
+	return val, child.Err()
 }
 
 // DeleteChild deletes the child storage trie
@@ -97,5 +120,5 @@ func (t *Trie) ClearFromChild(keyToChild, key []byte) error {
 		return fmt.Errorf("%w at key 0x%x%x", ErrChildTrieDoesNotExist, ChildStorageKeyPrefix, keyToChild)
 	}
 	child.Delete(key)
-	return nil
+	return child.Err()
 }
@@ -0,0 +1,360 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// ChangeType is the type of change of a key between two tries.
+type ChangeType byte
+
+const (
+	// Added is the change type of a key only in the second trie.
+	Added ChangeType = iota
+	// Modified is the change type of a key with different values in both tries.
+	Modified
+	// Removed is the change type of a key only in the first trie.
+	Removed
+)
+
+func (c ChangeType) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	default:
+		return fmt.Sprintf("unknown change type %d", byte(c))
+	}
+}
+
+// Change is a key whose value differs between two tries.
+type Change struct {
+	// KeyToChild is the key of the child trie in the main trie, without
+	// the child storage key prefix, or nil for the keys of the main trie.
+	KeyToChild []byte
+	Key        []byte
+	Type       ChangeType
+	// OldValue is the value in the first trie, nil if the key was added.
+	OldValue []byte
+	// NewValue is the value in the second trie, nil if the key was removed.
+	NewValue []byte
+}
+
+// Diff calls the given function with each key whose value differs between the trie and
+// the other trie, in key order. The keys of the child tries are given after the key of
+// their root hash in the main trie. Both tries are walked in parallel and subtrees with
+// the same hash in both tries are skipped, so only the nodes on the path to changed keys
+// are loaded from the database.
+func (t *LazyTrie) Diff(other *LazyTrie, fn func(change Change) error) error {
+	return diffLazyTries(t, other, nil, fn)
+}
+
+func diffLazyTries(from, to *LazyTrie, keyToChild []byte, fn func(change Change) error) error {
+	if from.rootHash == to.rootHash {
+		return nil
+	}
+
+	fromRoot, err := from.root()
+	if err != nil {
+		return err
+	}
+
+	toRoot, err := to.root()
+	if err != nil {
+		return err
+	}
+
+	d := &differ{
+		from:       from,
+		to:         to,
+		keyToChild: keyToChild,
+		fn:         fn,
+	}
+	return d.diff(fromRoot, toRoot, nil)
+}
+
+// differ compares the nodes of two tries at the same position.
+type differ struct {
+	from       *LazyTrie
+	to         *LazyTrie
+	keyToChild []byte
+	fn         func(change Change) error
+}
+
+// diff compares the subtrees of the first and second trie whose root nodes are
+// the given nodes, both located at the given prefix in nibbles.
+func (d *differ) diff(fromNode, toNode Node, prefix []byte) (err error) {
+	if fromNode == nil && toNode == nil {
+		return nil
+	}
+
+	if fromNode != nil && toNode != nil && sameHash(fromNode, toNode) {
+		return nil
+	}
+
+	fromNode, err = d.from.resolve(fromNode)
+	if err != nil {
+		return err
+	}
+
+	toNode, err = d.to.resolve(toNode)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case fromNode == nil:
+		return d.walk(d.to, toNode, prefix, Added)
+	case toNode == nil:
+		return d.walk(d.from, fromNode, prefix, Removed)
+	}
+
+	fromKey, toKey := fromNode.GetKey(), toNode.GetKey()
+	commonLength := lenCommonPrefix(fromKey, toKey)
+
+	switch {
+	case commonLength == len(fromKey) && commonLength == len(toKey):
+		err = d.diffValues(concatenateSlices(prefix, fromKey), nodeValue(fromNode), nodeValue(toNode))
+		if err != nil {
+			return err
+		}
+
+		fromChildren, toChildren := nodeChildren(fromNode), nodeChildren(toNode)
+		for i := range fromChildren {
+			childPrefix := makeChildPrefix(prefix, fromKey, i)
+			err = d.diff(fromChildren[i], toChildren[i], childPrefix)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case commonLength == len(fromKey):
+		// the node of the second trie is below a child of the node of the first trie
+		err = d.diffValues(concatenateSlices(prefix, fromKey), nodeValue(fromNode), nil)
+		if err != nil {
+			return err
+		}
+
+		childIndex := int(toKey[commonLength])
+		shortened := withKey(toNode, toKey[commonLength+1:])
+		for i, child := range nodeChildren(fromNode) {
+			childPrefix := makeChildPrefix(prefix, fromKey, i)
+			if i == childIndex {
+				err = d.diff(child, shortened, childPrefix)
+			} else {
+				err = d.walk(d.from, child, childPrefix, Removed)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case commonLength == len(toKey):
+		// the node of the first trie is below a child of the node of the second trie
+		err = d.diffValues(concatenateSlices(prefix, toKey), nil, nodeValue(toNode))
+		if err != nil {
+			return err
+		}
+
+		childIndex := int(fromKey[commonLength])
+		shortened := withKey(fromNode, fromKey[commonLength+1:])
+		for i, child := range nodeChildren(toNode) {
+			childPrefix := makeChildPrefix(prefix, toKey, i)
+			if i == childIndex {
+				err = d.diff(shortened, child, childPrefix)
+			} else {
+				err = d.walk(d.to, child, childPrefix, Added)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the keys of the nodes diverge, so no key is in both subtrees
+	if fromKey[commonLength] < toKey[commonLength] {
+		err = d.walk(d.from, fromNode, prefix, Removed)
+		if err != nil {
+			return err
+		}
+		return d.walk(d.to, toNode, prefix, Added)
+	}
+
+	err = d.walk(d.to, toNode, prefix, Added)
+	if err != nil {
+		return err
+	}
+	return d.walk(d.from, fromNode, prefix, Removed)
+}
+
+// walk calls the diff function for all the keys of the subtree of the given
+// trie whose root is the given node, as added or removed keys.
+func (d *differ) walk(t *LazyTrie, n Node, prefix []byte, changeType ChangeType) (err error) {
+	n, err = t.resolve(n)
+	if err != nil || n == nil {
+		return err
+	}
+
+	fullKey := concatenateSlices(prefix, n.GetKey())
+	if value := nodeValue(n); value != nil {
+		if changeType == Added {
+			err = d.diffValues(fullKey, nil, value)
+		} else {
+			err = d.diffValues(fullKey, value, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, child := range nodeChildren(n) {
+		childPrefix := makeChildPrefix(prefix, n.GetKey(), i)
+		err = d.walk(t, child, childPrefix, changeType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffValues calls the diff function if the values at the given full key
+// in nibbles differ, where a nil value means the key is not in the trie.
+// If the key is a child storage key, the child tries are compared as well.
+func (d *differ) diffValues(fullKey, oldValue, newValue []byte) error {
+	change := Change{
+		KeyToChild: d.keyToChild,
+		Key:        codec.NibblesToKeyLE(fullKey),
+	}
+
+	switch {
+	case oldValue == nil && newValue == nil:
+		return nil
+	case oldValue == nil:
+		change.Type = Added
+	case newValue == nil:
+		change.Type = Removed
+	case bytes.Equal(oldValue, newValue):
+		return nil
+	default:
+		change.Type = Modified
+	}
+
+	// cached nodes are shared, so the values must not be modified
+	if oldValue != nil {
+		change.OldValue = make([]byte, len(oldValue))
+		copy(change.OldValue, oldValue)
+	}
+	if newValue != nil {
+		change.NewValue = make([]byte, len(newValue))
+		copy(change.NewValue, newValue)
+	}
+
+	err := d.fn(change)
+	if err != nil {
+		return err
+	}
+
+	if d.keyToChild != nil || !isChildStorageKey(fullKey) {
+		return nil
+	}
+
+	oldRoot, err := childRootHash(fullKey, oldValue)
+	if err != nil {
+		return err
+	}
+
+	newRoot, err := childRootHash(fullKey, newValue)
+	if err != nil {
+		return err
+	}
+
+	keyToChild := change.Key[len(ChildStorageKeyPrefix):]
+	err = diffLazyTries(
+		NewLazyTrie(d.from.db, d.from.cache, oldRoot),
+		NewLazyTrie(d.to.db, d.to.cache, newRoot),
+		keyToChild, d.fn)
+	if err != nil {
+		return fmt.Errorf("cannot diff child tries at key 0x%x: %w", change.Key, err)
+	}
+
+	return nil
+}
+
+// childRootHash returns the child trie root hash stored at the given child
+// storage key in nibbles, or the empty hash if there is no child trie.
+func childRootHash(fullKey, value []byte) (common.Hash, error) {
+	if value == nil {
+		return EmptyHash, nil
+	}
+
+	if len(value) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("child trie root hash 0x%x at key 0x%x has an invalid length",
+			value, codec.NibblesToKeyLE(fullKey))
+	}
+
+	return common.BytesToHash(value), nil
+}
+
+// sameHash returns true if both nodes have the same hash digest,
+// in which case their subtrees are identical.
+func sameHash(a, b Node) bool {
+	aHash, bHash := a.GetHash(), b.GetHash()
+	return len(aHash) > 0 && bytes.Equal(aHash, bHash)
+}
+
+// nodeValue returns the value of the node, or nil if the node is a branch without
+// value. The value of a leaf is never nil, since a leaf always holds a value.
+func nodeValue(n Node) []byte {
+	switch n := n.(type) {
+	case *node.Leaf:
+		if n.Value == nil {
+			return []byte{}
+		}
+		return n.Value
+	case *node.Branch:
+		return n.Value
+	default:
+		return nil
+	}
+}
+
+// nodeChildren returns the children of the node, which are all nil for a leaf.
+func nodeChildren(n Node) (children [16]node.Node) {
+	branch, ok := n.(*node.Branch)
+	if !ok {
+		return children
+	}
+	return branch.Children
+}
+
+// withKey returns a copy of the resolved node given with the given key and without hash
+// digest, used to compare a node with the child of a node with a shorter key.
+func withKey(n Node, key []byte) Node {
+	switch n := n.(type) {
+	case *node.Leaf:
+		return &node.Leaf{
+			Key:   key,
+			Value: n.Value,
+		}
+	case *node.Branch:
+		return &node.Branch{
+			Key:      key,
+			Value:    n.Value,
+			Children: n.Children,
+		}
+	default:
+		return nil
+	}
+}
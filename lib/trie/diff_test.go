@@ -0,0 +1,140 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectChanges(t *testing.T, from, to *LazyTrie) (changes []Change) {
+	t.Helper()
+
+	err := from.Diff(to, func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	})
+	require.NoError(t, err)
+	return changes
+}
+
+// entriesChanges returns the changes between the entries of two in-memory tries.
+func entriesChanges(from, to *Trie) (changes []Change) {
+	fromEntries, toEntries := from.Entries(), to.Entries()
+	for key, newValue := range toEntries {
+		oldValue, ok := fromEntries[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: []byte(key), Type: Added, NewValue: newValue})
+		case !bytes.Equal(oldValue, newValue):
+			changes = append(changes, Change{Key: []byte(key), Type: Modified, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, oldValue := range fromEntries {
+		if _, ok := toEntries[key]; !ok {
+			changes = append(changes, Change{Key: []byte(key), Type: Removed, OldValue: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].Key, changes[j].Key) < 0
+	})
+	return changes
+}
+
+func Test_LazyTrie_Diff(t *testing.T) {
+	t.Parallel()
+
+	from, fromTrie, _ := newTestLazyTrie(t)
+
+	toTrie := fromTrie.Snapshot()
+	toTrie.Put([]byte{0x01, 0x35}, []byte("pencil"))
+	toTrie.Put([]byte{0x01, 0x35, 0x79, 0x01}, []byte("penguins"))
+	toTrie.Put([]byte{0x01}, []byte("p"))
+	toTrie.Delete([]byte{0xf2})
+	toTrie.Put([]byte("new_key"), make([]byte, 40))
+
+	err := toTrie.Store(from.db)
+	require.NoError(t, err)
+	to := NewLazyTrie(from.db, from.cache, toTrie.MustHash())
+
+	changes := collectChanges(t, from, to)
+	assert.Equal(t, entriesChanges(fromTrie, toTrie), changes)
+
+	// the reverse diff swaps added and removed keys
+	reverseChanges := collectChanges(t, to, from)
+	assert.Equal(t, entriesChanges(toTrie, fromTrie), reverseChanges)
+
+	assert.Empty(t, collectChanges(t, from, from))
+}
+
+func Test_LazyTrie_Diff_emptyTrie(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, _ := newTestLazyTrie(t)
+	empty := NewLazyTrie(lazyTrie.db, nil, EmptyHash)
+
+	changes := collectChanges(t, empty, lazyTrie)
+
+	var mainChanges []Change
+	for _, change := range changes {
+		if change.KeyToChild == nil {
+			mainChanges = append(mainChanges, change)
+		}
+	}
+	assert.Equal(t, entriesChanges(NewEmptyTrie(), trie), mainChanges)
+	// the two keys of the child trie are added
+	assert.Len(t, changes, len(mainChanges)+2)
+}
+
+func Test_LazyTrie_Diff_childTrie(t *testing.T) {
+	t.Parallel()
+
+	from, fromTrie, _ := newTestLazyTrie(t)
+
+	toTrie := fromTrie.Snapshot()
+	err := toTrie.PutIntoChild([]byte("child"), []byte("child_key"), []byte("new_child_value"))
+	require.NoError(t, err)
+	err = toTrie.Store(from.db)
+	require.NoError(t, err)
+	to := NewLazyTrie(from.db, from.cache, toTrie.MustHash())
+
+	changes := collectChanges(t, from, to)
+	require.Len(t, changes, 2)
+
+	childStorageKey := append(append([]byte{}, ChildStorageKeyPrefix...), []byte("child")...)
+	assert.Equal(t, childStorageKey, changes[0].Key)
+	assert.Nil(t, changes[0].KeyToChild)
+	assert.Equal(t, Modified, changes[0].Type)
+
+	expectedChildChange := Change{
+		KeyToChild: []byte("child"),
+		Key:        []byte("child_key"),
+		Type:       Modified,
+		OldValue:   []byte("child_value"),
+		NewValue:   []byte("new_child_value"),
+	}
+	assert.Equal(t, expectedChildChange, changes[1])
+}
+
+func Test_LazyTrie_Diff_error(t *testing.T) {
+	t.Parallel()
+
+	from, fromTrie, _ := newTestLazyTrie(t)
+	toTrie := fromTrie.Snapshot()
+	toTrie.Put([]byte("new_key"), []byte("value"))
+	err := toTrie.Store(from.db)
+	require.NoError(t, err)
+	to := NewLazyTrie(from.db, from.cache, toTrie.MustHash())
+
+	errTest := errors.New("test error")
+	err = from.Diff(to, func(change Change) error {
+		return errTest
+	})
+	assert.ErrorIs(t, err, errTest)
+}
@@ -0,0 +1,428 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// LazyTrie is a read only trie backed by the database.
+// Only the root hash is kept in memory, and nodes are decoded from the
+// database as they are traversed. Decoded nodes are kept in a node cache
+// which can be shared by lazy tries of different root hashes, since tries
+// of successive blocks share most of their nodes.
+// A LazyTrie is safe for concurrent use if its node cache is.
+type LazyTrie struct {
+	db       chaindb.Database
+	cache    NodeCache
+	rootHash common.Hash
+}
+
+// NewLazyTrie returns a lazy trie with the given root hash, resolving
+// its nodes from the database given. The cache can be nil to disable caching.
+func NewLazyTrie(db chaindb.Database, cache NodeCache, rootHash common.Hash) *LazyTrie {
+	return &LazyTrie{
+		db:       db,
+		cache:    cache,
+		rootHash: rootHash,
+	}
+}
+
+// Hash returns the root hash of the trie.
+func (t *LazyTrie) Hash() common.Hash {
+	return t.rootHash
+}
+
+// isHashReference returns true if the node is a placeholder only holding
+// the hash of a node stored in the database, as set by node.Decode for
+// the children of a branch which are not inlined in its encoding.
+func isHashReference(n Node) bool {
+	leaf, ok := n.(*node.Leaf)
+	return ok && len(leaf.HashDigest) > 0 && leaf.Encoding == nil
+}
+
+func (t *LazyTrie) root() (root Node, err error) {
+	if t.rootHash == EmptyHash {
+		return nil, nil
+	}
+
+	root, err = t.loadNode(t.rootHash.ToBytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot load root node: %w", err)
+	}
+	return root, nil
+}
+
+// resolve returns the node the given node references if it is a
+// hash reference, and the given node otherwise.
+func (t *LazyTrie) resolve(n Node) (resolved Node, err error) {
+	if n == nil || !isHashReference(n) {
+		return n, nil
+	}
+	return t.loadNode(n.GetHash())
+}
+
+// loadNode returns the node with the given hash from the
+// node cache, or decodes it from the database.
+func (t *LazyTrie) loadNode(hash []byte) (n Node, err error) {
+	cacheKey := common.BytesToHash(hash)
+	if t.cache != nil {
+		n, ok := t.cache.Get(cacheKey)
+		if ok {
+			return n, nil
+		}
+	}
+
+	encoding, err := t.db.Get(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find node with hash 0x%x in database: %w", hash, err)
+	}
+
+	n, err = node.Decode(bytes.NewReader(encoding))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode node with hash 0x%x: %w", hash, err)
+	}
+
+	n.SetDirty(false)
+	n.SetEncodingAndHash(encoding, hash)
+
+	if branch, ok := n.(*node.Branch); ok {
+		// set the encoding and hash digest of the leaves inlined in the
+		// branch encoding, so the node is not modified once shared.
+		for _, child := range branch.Children {
+			if child == nil || isHashReference(child) {
+				continue
+			}
+
+			_, _, err = child.EncodeAndHash(false)
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode inlined child of node with hash 0x%x: %w", hash, err)
+			}
+			child.SetDirty(false)
+		}
+	}
+
+	if t.cache != nil {
+		t.cache.Put(cacheKey, n)
+	}
+	return n, nil
+}
+
+// Get returns a copy of the value at the given key in little Endian format.
+func (t *LazyTrie) Get(keyLE []byte) (value []byte, err error) {
+	root, err := t.root()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = t.retrieve(root, codec.KeyLEToNibbles(keyLE))
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	// cached nodes are shared, so the value must not be modified
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	return valueCopy, nil
+}
+
+func (t *LazyTrie) retrieve(parent Node, key []byte) (value []byte, err error) {
+	parent, err = t.resolve(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent := parent.(type) {
+	case nil:
+		return nil, nil
+	case *node.Leaf:
+		return retrieveFromLeaf(parent, key), nil
+	}
+
+	branch := parent.(*node.Branch)
+	if len(key) == 0 || bytes.Equal(branch.Key, key) {
+		return branch.Value, nil
+	}
+
+	if len(branch.Key) > len(key) && bytes.HasPrefix(branch.Key, key) {
+		return nil, nil
+	}
+
+	commonPrefixLength := lenCommonPrefix(branch.Key, key)
+	childIndex := key[commonPrefixLength]
+	childKey := key[commonPrefixLength+1:]
+	return t.retrieve(branch.Children[childIndex], childKey)
+	// Note: do not wrap error since it's called recursively.
+}
+
+// NextKey returns the next key in the trie in lexicographic order,
+// in little Endian format. It returns nil if no next key is found.
+func (t *LazyTrie) NextKey(keyLE []byte) (nextKeyLE []byte, err error) {
+	root, err := t.root()
+	if err != nil {
+		return nil, err
+	}
+
+	nextKey, err := t.findNextKey(root, nil, codec.KeyLEToNibbles(keyLE))
+	if err != nil || nextKey == nil {
+		return nil, err
+	}
+
+	return codec.NibblesToKeyLE(nextKey), nil
+}
+
+func (t *LazyTrie) findNextKey(parent Node, prefix, searchKey []byte) (nextKey []byte, err error) {
+	parent, err = t.resolve(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent := parent.(type) {
+	case nil:
+		return nil, nil
+	case *node.Leaf:
+		return findNextKeyLeaf(parent, prefix, searchKey), nil
+	}
+
+	branch := parent.(*node.Branch)
+	fullKey := concatenateSlices(prefix, branch.Key)
+
+	if bytes.Equal(searchKey, fullKey) {
+		const startChildIndex = 0
+		return t.findNextKeyChild(branch.Children, startChildIndex, fullKey, searchKey)
+	}
+
+	if keyIsLexicographicallyBigger(searchKey, fullKey) {
+		if len(searchKey) < len(fullKey) {
+			return nil, nil
+		} else if len(searchKey) > len(fullKey) {
+			startChildIndex := searchKey[len(fullKey)]
+			return t.findNextKeyChild(branch.Children, startChildIndex, fullKey, searchKey)
+		}
+	}
+
+	// search key is smaller than full key
+	if branch.Value != nil {
+		return fullKey, nil
+	}
+	const startChildIndex = 0
+	return t.findNextKeyChild(branch.Children, startChildIndex, fullKey, searchKey)
+}
+
+func (t *LazyTrie) findNextKeyChild(children [16]node.Node, startIndex byte,
+	fullKey, key []byte) (nextKey []byte, err error) {
+	for i := startIndex; i < node.ChildrenCapacity; i++ {
+		child := children[i]
+		if child == nil {
+			continue
+		}
+
+		childFullKey := concatenateSlices(fullKey, []byte{i})
+		next, err := t.findNextKey(child, childFullKey, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(next) > 0 {
+			return next, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetKeysWithPrefix returns all keys in little Endian format
+// which have the given little Endian formatted prefix.
+func (t *LazyTrie) GetKeysWithPrefix(prefixLE []byte) (keysLE [][]byte, err error) {
+	root, err := t.root()
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixNibbles []byte
+	if len(prefixLE) > 0 {
+		prefixNibbles = codec.KeyLEToNibbles(prefixLE)
+		prefixNibbles = bytes.TrimSuffix(prefixNibbles, []byte{0})
+	}
+
+	return t.getKeysWithPrefix(root, []byte{}, prefixNibbles, keysLE)
+}
+
+func (t *LazyTrie) getKeysWithPrefix(parent Node, prefix, key []byte,
+	keysLE [][]byte) (newKeysLE [][]byte, err error) {
+	parent, err = t.resolve(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent := parent.(type) {
+	case nil:
+		return keysLE, nil
+	case *node.Leaf:
+		return getKeysWithPrefixFromLeaf(parent, prefix, key, keysLE), nil
+	}
+
+	branch := parent.(*node.Branch)
+	if len(key) == 0 || bytes.HasPrefix(branch.Key, key) {
+		return t.addAllKeys(branch, prefix, keysLE)
+	}
+
+	noPossiblePrefixedKeys :=
+		len(branch.Key) > len(key) &&
+			!bytes.HasPrefix(branch.Key, key)
+	if noPossiblePrefixedKeys {
+		return keysLE, nil
+	}
+
+	key = key[len(branch.Key):]
+	childIndex := key[0]
+	childPrefix := makeChildPrefix(prefix, branch.Key, int(childIndex))
+	return t.getKeysWithPrefix(branch.Children[childIndex], childPrefix, key[1:], keysLE)
+}
+
+func (t *LazyTrie) addAllKeys(parent Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte, err error) {
+	parent, err = t.resolve(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent := parent.(type) {
+	case nil:
+		return keysLE, nil
+	case *node.Leaf:
+		return append(keysLE, makeFullKeyLE(prefix, parent.Key)), nil
+	}
+
+	branch := parent.(*node.Branch)
+	if branch.Value != nil {
+		keysLE = append(keysLE, makeFullKeyLE(prefix, branch.Key))
+	}
+
+	for i, child := range branch.Children {
+		if child == nil {
+			continue
+		}
+
+		childPrefix := makeChildPrefix(prefix, branch.Key, i)
+		keysLE, err = t.addAllKeys(child, childPrefix, keysLE)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keysLE, nil
+}
+
+// GetChild returns the lazy child trie at key :child_storage:[keyToChild].
+func (t *LazyTrie) GetChild(keyToChild []byte) (*LazyTrie, error) {
+	key := append(ChildStorageKeyPrefix, keyToChild...)
+	childHash, err := t.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if childHash == nil {
+		return nil, fmt.Errorf("%w at key 0x%x%x", ErrChildTrieDoesNotExist, ChildStorageKeyPrefix, keyToChild)
+	}
+
+	return NewLazyTrie(t.db, t.cache, common.BytesToHash(childHash)), nil
+}
+
+// GetFromChild returns the value at the given key in the child trie
+// at key :child_storage:[keyToChild].
+func (t *LazyTrie) GetFromChild(keyToChild, key []byte) ([]byte, error) {
+	child, err := t.GetChild(keyToChild)
+	if err != nil {
+		return nil, err
+	}
+
+	return child.Get(key)
+}
+
+// Trie returns a modifiable trie with the root hash of the lazy trie.
+// Its nodes and child tries are resolved from the database through the
+// node cache only when they are traversed, so the trie can be created for
+// any state root without loading the whole state in memory.
+// The error of a node which cannot be loaded from the database is
+// returned by the Err method of the trie.
+func (t *LazyTrie) Trie() (*Trie, error) {
+	root, err := t.root()
+	if err != nil {
+		return nil, err
+	}
+
+	trie := NewTrie(root)
+	trie.lazy = t
+	trie.resolveErr = &errorRecord{}
+	// The resolved nodes are shared with the node cache and have
+	// the generation zero, so they are copied before being modified
+	// as for nodes of a previous snapshot.
+	trie.generation = 1
+	return trie, nil
+}
+
+// WithNodeCache returns a snapshot of the trie resolving its nodes, and
+// the nodes of its child tries, through the given node cache instead of
+// the node cache of the lazy trie it was loaded from.
+// It returns the trie itself if it was not loaded lazily from the database.
+func (t *Trie) WithNodeCache(cache NodeCache) *Trie {
+	if t.lazy == nil {
+		return t
+	}
+
+	snapshot := t.Snapshot()
+	snapshot.lazy = NewLazyTrie(t.lazy.db, cache, t.lazy.rootHash)
+	for _, child := range snapshot.childTries {
+		if child.lazy != nil {
+			child.lazy = NewLazyTrie(child.lazy.db, cache, child.lazy.rootHash)
+		}
+	}
+	return snapshot
+}
+
+// errorRecord records the first error set, and is safe for concurrent use
+// since the nodes of a trie can be resolved by concurrent reads.
+// A nil record records nothing.
+type errorRecord struct {
+	mutex sync.Mutex
+	err   error
+}
+
+func (r *errorRecord) set(err error) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *errorRecord) get() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// copy returns a new record holding the error of the record.
+func (r *errorRecord) copy() *errorRecord {
+	if r == nil {
+		return nil
+	}
+
+	return &errorRecord{err: r.get()}
+}
@@ -0,0 +1,263 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLazyTrie(t *testing.T) (lazyTrie *LazyTrie, trie *Trie, cache *LRUNodeCache) {
+	t.Helper()
+
+	trie = NewEmptyTrie()
+	generator := newGenerator()
+	for key, value := range generateKeyValues(t, generator, 200) {
+		trie.Put([]byte(key), value)
+	}
+	trie.Put([]byte{0x01, 0x35}, []byte("pen"))
+	trie.Put([]byte{0x01, 0x35, 0x79}, []byte("penguin"))
+	trie.Put([]byte{0xf2}, []byte("a"))
+
+	childTrie := NewEmptyTrie()
+	childTrie.Put([]byte("child_key"), []byte("child_value"))
+	childTrie.Put([]byte("child_key2"), make([]byte, 64))
+	err := trie.PutChild([]byte("child"), childTrie)
+	require.NoError(t, err)
+
+	db := newTestDB(t)
+	err = trie.Store(db)
+	require.NoError(t, err)
+
+	cache = NewLRUNodeCache(1 << 20)
+	lazyTrie = NewLazyTrie(db, cache, trie.MustHash())
+	return lazyTrie, trie, cache
+}
+
+func Test_LazyTrie_Get(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, cache := newTestLazyTrie(t)
+
+	for keyLE, expected := range trie.Entries() {
+		value, err := lazyTrie.Get([]byte(keyLE))
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	}
+
+	value, err := lazyTrie.Get([]byte{0x01, 0x36})
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	stats := cache.Stats()
+	assert.Greater(t, stats.Hits, uint64(0))
+	assert.Greater(t, stats.Nodes, 0)
+
+	// values returned are copies of the cached node values
+	value, err = lazyTrie.Get([]byte{0x01, 0x35})
+	require.NoError(t, err)
+	value[0] = 'x'
+	value, err = lazyTrie.Get([]byte{0x01, 0x35})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pen"), value)
+}
+
+func Test_LazyTrie_Get_emptyTrie(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie := NewLazyTrie(newTestDB(t), nil, EmptyHash)
+	value, err := lazyTrie.Get([]byte{1})
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func Test_LazyTrie_Get_missingNode(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie := NewLazyTrie(newTestDB(t), nil, common.Hash{1})
+	_, err := lazyTrie.Get([]byte{1})
+	assert.ErrorContains(t, err, "cannot load root node: cannot find node with hash "+
+		"0x0100000000000000000000000000000000000000000000000000000000000000 in database")
+}
+
+func Test_LazyTrie_NextKey(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, _ := newTestLazyTrie(t)
+
+	keys := [][]byte{nil, {0x01}, {0x01, 0x35}, {0x01, 0x35, 0x79}, {0xf2}, {0xff, 0xff}}
+	for keyLE := range trie.Entries() {
+		keys = append(keys, []byte(keyLE))
+	}
+
+	for _, key := range keys {
+		nextKey, err := lazyTrie.NextKey(key)
+		require.NoError(t, err)
+		assert.Equal(t, trie.NextKey(key), nextKey)
+	}
+}
+
+func Test_LazyTrie_GetKeysWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, _ := newTestLazyTrie(t)
+
+	prefixes := [][]byte{nil, {0x01}, {0x01, 0x35}, {0xf2}, {0x99}, ChildStorageKeyPrefix}
+	for _, prefix := range prefixes {
+		keys, err := lazyTrie.GetKeysWithPrefix(prefix)
+		require.NoError(t, err)
+		assert.Equal(t, trie.GetKeysWithPrefix(prefix), keys)
+	}
+}
+
+func Test_LazyTrie_GetFromChild(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, _, _ := newTestLazyTrie(t)
+
+	value, err := lazyTrie.GetFromChild([]byte("child"), []byte("child_key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("child_value"), value)
+
+	_, err = lazyTrie.GetFromChild([]byte("other"), []byte("child_key"))
+	assert.ErrorIs(t, err, ErrChildTrieDoesNotExist)
+}
+
+func Test_LazyTrie_Trie(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, cache := newTestLazyTrie(t)
+
+	loaded, err := lazyTrie.Trie()
+	require.NoError(t, err)
+
+	// only the root node is resolved
+	assert.Equal(t, 1, cache.Stats().Nodes)
+	assert.Equal(t, trie.MustHash(), loaded.MustHash())
+
+	assert.Equal(t, []byte("penguin"), loaded.Get([]byte{0x01, 0x35, 0x79}))
+	assert.Less(t, cache.Stats().Nodes, 10)
+
+	assert.Equal(t, trie.Entries(), loaded.Entries())
+
+	childTrie, err := loaded.GetChild([]byte("child"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("child_value"), childTrie.Get([]byte("child_key")))
+
+	// the loaded trie is modified as the in-memory trie
+	expected := trie.Snapshot()
+	for _, tr := range []*Trie{expected, loaded} {
+		tr.Put([]byte{0x01, 0x35}, []byte("pencil"))
+		tr.Put([]byte{0x01, 0x35, 0x79, 0x01}, []byte("penguins"))
+		tr.Delete([]byte{0xf2})
+		tr.ClearPrefix([]byte{0x01, 0x35, 0x79})
+		tr.ClearPrefixLimit([]byte{0x02}, 1)
+		err = tr.PutIntoChild([]byte("child"), []byte("child_key3"), []byte("child_value3"))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, expected.MustHash(), loaded.MustHash())
+	assert.Equal(t, expected.Entries(), loaded.Entries())
+	assert.Equal(t, expected.GetDeletedNodeHashes(), loaded.GetDeletedNodeHashes())
+
+	expectedInserted, err := expected.GetInsertedNodeHashes()
+	require.NoError(t, err)
+	inserted, err := loaded.GetInsertedNodeHashes()
+	require.NoError(t, err)
+	assert.Equal(t, expectedInserted, inserted)
+
+	// the cached nodes are not modified
+	value, err := lazyTrie.Get([]byte{0x01, 0x35})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pen"), value)
+
+	reloaded, err := lazyTrie.Trie()
+	require.NoError(t, err)
+	assert.Equal(t, trie.MustHash(), reloaded.MustHash())
+	assert.Equal(t, trie.Entries(), reloaded.Entries())
+
+	// the modified trie is written to the database
+	err = loaded.WriteDirty(lazyTrie.db)
+	require.NoError(t, err)
+	written, err := NewLazyTrie(lazyTrie.db, cache, loaded.MustHash()).Trie()
+	require.NoError(t, err)
+	assert.Equal(t, expected.Entries(), written.Entries())
+}
+
+func Test_LazyTrie_Trie_missingNode(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLazyTrie(newTestDB(t), nil, common.Hash{1}).Trie()
+	assert.ErrorContains(t, err, "cannot load root node: cannot find node with hash "+
+		"0x0100000000000000000000000000000000000000000000000000000000000000 in database")
+}
+
+func Test_LazyTrie_Trie_missingChildNode(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, _, _ := newTestLazyTrie(t)
+	rootHash := lazyTrie.Hash()
+	encodedRoot, err := lazyTrie.db.Get(rootHash.ToBytes())
+	require.NoError(t, err)
+
+	// only the root node is in the database
+	db := newTestDB(t)
+	err = db.Put(rootHash.ToBytes(), encodedRoot)
+	require.NoError(t, err)
+
+	loaded, err := NewLazyTrie(db, nil, rootHash).Trie()
+	require.NoError(t, err)
+	require.NoError(t, loaded.Err())
+
+	snapshot := loaded.Snapshot()
+
+	const errMessage = "cannot resolve trie node: cannot find node with hash "
+	assert.Nil(t, loaded.Get([]byte{0x01, 0x35}))
+	assert.ErrorContains(t, loaded.Err(), errMessage)
+
+	// the error of the trie is kept by its snapshots
+	assert.ErrorContains(t, loaded.Snapshot().Err(), errMessage)
+
+	// the snapshots taken before the failure are not affected
+	assert.NoError(t, snapshot.Err())
+	snapshot.Put([]byte{0x01, 0x35}, []byte("pencil"))
+	assert.ErrorContains(t, snapshot.Err(), errMessage)
+	_, err = snapshot.GetFromChild([]byte("child"), []byte("child_key"))
+	assert.ErrorContains(t, err, errMessage)
+}
+
+func Test_LazyTrie_Trie_concurrentModifications(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, _ := newTestLazyTrie(t)
+	expectedHash := trie.MustHash()
+
+	const parallelism = 4
+	errs := make(chan error, parallelism)
+	for i := 0; i < parallelism; i++ {
+		go func(i byte) {
+			loaded, err := lazyTrie.Trie()
+			if err != nil {
+				errs <- err
+				return
+			}
+			for keyLE := range trie.Entries() {
+				loaded.Put([]byte(keyLE), []byte{i})
+			}
+			_, err = loaded.Hash()
+			errs <- err
+		}(byte(i))
+	}
+
+	for i := 0; i < parallelism; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	reloaded, err := lazyTrie.Trie()
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, reloaded.MustHash())
+}
@@ -0,0 +1,312 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+const (
+	// approximate memory overhead of the node structures,
+	// on top of their key, value and encoding byte slices.
+	leafOverhead   = 128
+	branchOverhead = leafOverhead + node.ChildrenCapacity*16
+)
+
+// NodeCache is a cache of decoded trie nodes keyed by node hash.
+// Since a node hash is the hash of its encoding, a cached node is valid
+// for all the tries containing it, whatever their root hash is.
+// Cached nodes are shared and must not be modified.
+type NodeCache interface {
+	Get(hash common.Hash) (n Node, ok bool)
+	Put(hash common.Hash, n Node)
+}
+
+// NodeCacheStats holds the statistics of a LRUNodeCache.
+type NodeCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Size is the approximate memory size of the cached nodes, in bytes.
+	Size uint64
+	// PinnedSize is the approximate memory size of the pinned nodes,
+	// in bytes, which is included in Size.
+	PinnedSize uint64
+	// Nodes is the number of cached nodes.
+	Nodes int
+}
+
+var _ NodeCache = (*LRUNodeCache)(nil)
+
+// LRUNodeCache is a thread safe least recently used node cache,
+// bounded by the approximate memory size of the nodes it contains.
+// The nodes resolved by the tries of pinned roots are pinned in the
+// cache and not evicted, nor counted in its maximum size, until
+// their root is unpinned.
+type LRUNodeCache struct {
+	mutex   sync.Mutex
+	maxSize uint64
+	// list contains the entries of the nodes not pinned,
+	// from the most recently used to the least recently used.
+	list    *list.List
+	entries map[common.Hash]*nodeCacheEntry
+	// pinnedRoots maps each pinned root hash to
+	// the hashes of the nodes pinned for the root.
+	pinnedRoots map[common.Hash]map[common.Hash]struct{}
+	stats       NodeCacheStats
+}
+
+type nodeCacheEntry struct {
+	hash common.Hash
+	node Node
+	size uint64
+	// pins is the number of roots the node is pinned for.
+	pins uint
+	// element is the element of the entry in the list,
+	// and is nil if the node is pinned.
+	element *list.Element
+}
+
+// NewLRUNodeCache returns a node cache keeping up to maxSize bytes of nodes.
+func NewLRUNodeCache(maxSize uint64) *LRUNodeCache {
+	return &LRUNodeCache{
+		maxSize:     maxSize,
+		list:        list.New(),
+		entries:     make(map[common.Hash]*nodeCacheEntry),
+		pinnedRoots: make(map[common.Hash]map[common.Hash]struct{}),
+	}
+}
+
+// Get returns the node with the given hash and marks
+// it as the most recently used node, if it is cached.
+func (c *LRUNodeCache) Get(hash common.Hash) (n Node, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.get(hash)
+	if !ok {
+		return nil, false
+	}
+	return entry.node, true
+}
+
+// Put adds the node with the given hash to the cache, evicting
+// the least recently used nodes if the cache is full.
+// A node larger than the cache maximum size is not cached.
+func (c *LRUNodeCache) Put(hash common.Hash, n Node) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.put(hash, n)
+}
+
+// PinRoot pins the given root hash, so the nodes got and put through
+// the view returned by Pinned for the root are not evicted.
+func (c *LRUNodeCache) PinRoot(root common.Hash) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.pinnedRoots[root]
+	if !ok {
+		c.pinnedRoots[root] = make(map[common.Hash]struct{})
+	}
+}
+
+// UnpinRoot unpins the given root hash, so its pinned nodes can be
+// evicted again once they are not pinned for another root.
+func (c *LRUNodeCache) UnpinRoot(root common.Hash) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	hashes := c.pinnedRoots[root]
+	delete(c.pinnedRoots, root)
+
+	for hash := range hashes {
+		entry := c.entries[hash]
+		entry.pins--
+		if entry.pins == 0 {
+			entry.element = c.list.PushFront(entry)
+			c.stats.PinnedSize -= entry.size
+		}
+	}
+
+	c.evict(0)
+}
+
+// Pinned returns a view of the cache pinning the nodes got and put through
+// it for the given root, if the root is pinned. It is meant to be used by
+// the trie of the root to keep its nodes in memory.
+func (c *LRUNodeCache) Pinned(root common.Hash) NodeCache {
+	return &pinnedNodeCache{
+		cache: c,
+		root:  root,
+	}
+}
+
+// Resize sets the maximum size of the cache, evicting
+// the least recently used nodes if needed.
+func (c *LRUNodeCache) Resize(maxSize uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxSize = maxSize
+	c.evict(0)
+}
+
+// Stats returns the statistics of the cache.
+func (c *LRUNodeCache) Stats() (stats NodeCacheStats) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats = c.stats
+	stats.Nodes = len(c.entries)
+	return stats
+}
+
+// get must be called with the mutex locked.
+func (c *LRUNodeCache) get(hash common.Hash) (entry *nodeCacheEntry, ok bool) {
+	entry, ok = c.entries[hash]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.stats.Hits++
+	if entry.element != nil {
+		c.list.MoveToFront(entry.element)
+	}
+	return entry, true
+}
+
+// put must be called with the mutex locked.
+func (c *LRUNodeCache) put(hash common.Hash, n Node) {
+	if entry, ok := c.entries[hash]; ok {
+		if entry.element != nil {
+			c.list.MoveToFront(entry.element)
+		}
+		return
+	}
+
+	size := nodeSize(n)
+	if size > c.maxSize {
+		return
+	}
+
+	c.evict(size)
+
+	entry := &nodeCacheEntry{
+		hash: hash,
+		node: n,
+		size: size,
+	}
+	entry.element = c.list.PushFront(entry)
+	c.entries[hash] = entry
+	c.stats.Size += size
+}
+
+// pin pins the node with the given hash for the given root,
+// adding it to the cache if needed. It returns false if the
+// root is not pinned, in which case nothing is done.
+// It must be called with the mutex locked.
+func (c *LRUNodeCache) pin(root, hash common.Hash, n Node) (pinned bool) {
+	hashes, ok := c.pinnedRoots[root]
+	if !ok {
+		return false
+	}
+
+	if _, ok := hashes[hash]; ok {
+		return true
+	}
+
+	entry, ok := c.entries[hash]
+	if !ok {
+		entry = &nodeCacheEntry{
+			hash: hash,
+			node: n,
+			size: nodeSize(n),
+		}
+		c.entries[hash] = entry
+		c.stats.Size += entry.size
+	}
+
+	hashes[hash] = struct{}{}
+	if entry.pins == 0 {
+		if entry.element != nil {
+			c.list.Remove(entry.element)
+			entry.element = nil
+		}
+		c.stats.PinnedSize += entry.size
+	}
+	entry.pins++
+	return true
+}
+
+// evict evicts the least recently used nodes not pinned until
+// a node of the given size fits in the cache.
+// It must be called with the mutex locked.
+func (c *LRUNodeCache) evict(size uint64) {
+	for c.stats.Size-c.stats.PinnedSize+size > c.maxSize {
+		element := c.list.Back()
+		if element == nil {
+			return
+		}
+
+		entry := c.list.Remove(element).(*nodeCacheEntry)
+		delete(c.entries, entry.hash)
+		c.stats.Size -= entry.size
+		c.stats.Evictions++
+	}
+}
+
+// pinnedNodeCache is a view of a LRUNodeCache
+// pinning the nodes it gets and puts for a root.
+type pinnedNodeCache struct {
+	cache *LRUNodeCache
+	root  common.Hash
+}
+
+func (p *pinnedNodeCache) Get(hash common.Hash) (n Node, ok bool) {
+	p.cache.mutex.Lock()
+	defer p.cache.mutex.Unlock()
+
+	entry, ok := p.cache.get(hash)
+	if !ok {
+		return nil, false
+	}
+
+	p.cache.pin(p.root, hash, entry.node)
+	return entry.node, true
+}
+
+func (p *pinnedNodeCache) Put(hash common.Hash, n Node) {
+	p.cache.mutex.Lock()
+	defer p.cache.mutex.Unlock()
+
+	if !p.cache.pin(p.root, hash, n) {
+		p.cache.put(hash, n)
+	}
+}
+
+// nodeSize returns the approximate memory size of a decoded node.
+// It does not include the size of the children nodes.
+func nodeSize(n Node) (size uint64) {
+	switch n := n.(type) {
+	case *node.Leaf:
+		size = leafOverhead
+		size += uint64(len(n.Key) + len(n.Value) + len(n.Encoding) + len(n.HashDigest))
+	case *node.Branch:
+		size = branchOverhead
+		size += uint64(len(n.Key) + len(n.Value) + len(n.Encoding) + len(n.HashDigest))
+		for _, child := range n.Children {
+			leaf, ok := child.(*node.Leaf)
+			if ok {
+				size += leafOverhead + uint64(len(leaf.Key)+len(leaf.Value)+len(leaf.HashDigest))
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LRUNodeCache(t *testing.T) {
+	t.Parallel()
+
+	leafA := &node.Leaf{Key: []byte{1}, Value: []byte{1}}
+	leafB := &node.Leaf{Key: []byte{2}, Value: []byte{2}}
+	leafC := &node.Leaf{Key: []byte{3}, Value: []byte{3}}
+	leafSize := nodeSize(leafA)
+
+	cache := NewLRUNodeCache(2 * leafSize)
+
+	cache.Put(common.Hash{1}, leafA)
+	cache.Put(common.Hash{2}, leafB)
+	n, ok := cache.Get(common.Hash{1})
+	assert.True(t, ok)
+	assert.Same(t, leafA, n)
+
+	// leaf B is the least recently used node and gets evicted
+	cache.Put(common.Hash{3}, leafC)
+	_, ok = cache.Get(common.Hash{2})
+	assert.False(t, ok)
+
+	assert.Equal(t, NodeCacheStats{
+		Hits:      1,
+		Misses:    1,
+		Evictions: 1,
+		Size:      2 * leafSize,
+		Nodes:     2,
+	}, cache.Stats())
+
+	// nodes larger than the cache are not cached
+	cache.Put(common.Hash{4}, &node.Leaf{Value: make([]byte, 3*leafSize)})
+	_, ok = cache.Get(common.Hash{4})
+	assert.False(t, ok)
+
+	cache.Resize(leafSize)
+	stats := cache.Stats()
+	assert.Equal(t, 1, stats.Nodes)
+	assert.Equal(t, uint64(2), stats.Evictions)
+	_, ok = cache.Get(common.Hash{3})
+	assert.True(t, ok)
+}
+
+func Test_LRUNodeCache_Pinned(t *testing.T) {
+	t.Parallel()
+
+	leafA := &node.Leaf{Key: []byte{1}, Value: []byte{1}}
+	leafB := &node.Leaf{Key: []byte{2}, Value: []byte{2}}
+	leafSize := nodeSize(leafA)
+	rootA, rootB := common.Hash{0xa}, common.Hash{0xb}
+
+	cache := NewLRUNodeCache(leafSize)
+	cache.PinRoot(rootA)
+	cache.PinRoot(rootB)
+
+	// pinned nodes are not evicted nor counted in the maximum size
+	cache.Put(common.Hash{1}, leafA)
+	cache.Pinned(rootA).Get(common.Hash{1})
+	cache.Pinned(rootB).Put(common.Hash{2}, leafB)
+	cache.Pinned(rootA).Put(common.Hash{2}, leafB)
+	cache.Put(common.Hash{3}, &node.Leaf{Key: []byte{3}, Value: []byte{3}})
+	assert.Equal(t, NodeCacheStats{
+		Hits:       1,
+		Size:       3 * leafSize,
+		PinnedSize: 2 * leafSize,
+		Nodes:      3,
+	}, cache.Stats())
+
+	// leaf B is still pinned for root B
+	cache.UnpinRoot(rootA)
+	_, ok := cache.Get(common.Hash{1})
+	assert.True(t, ok)
+	_, ok = cache.Get(common.Hash{3})
+	assert.False(t, ok)
+	stats := cache.Stats()
+	assert.Equal(t, leafSize, stats.PinnedSize)
+	assert.Equal(t, 2, stats.Nodes)
+
+	cache.UnpinRoot(rootB)
+	stats = cache.Stats()
+	assert.Equal(t, uint64(0), stats.PinnedSize)
+	assert.Equal(t, 1, stats.Nodes)
+
+	// nodes are not pinned for roots not pinned
+	cache.Pinned(rootA).Put(common.Hash{4}, leafA)
+	assert.Equal(t, uint64(0), cache.Stats().PinnedSize)
+}
+
+func Test_nodeSize(t *testing.T) {
+	t.Parallel()
+
+	leaf := &node.Leaf{Key: []byte{1, 2}, Value: []byte{3}, Encoding: []byte{4, 5, 6}}
+	assert.Equal(t, uint64(leafOverhead+6), nodeSize(leaf))
+
+	branch := &node.Branch{Key: []byte{1}, Value: []byte{2}}
+	branch.Children[3] = &node.Leaf{HashDigest: make([]byte, 32)}
+	assert.Equal(t, uint64(branchOverhead+2+leafOverhead+32), nodeSize(branch))
+}
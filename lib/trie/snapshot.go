@@ -0,0 +1,246 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// nodesLoaderBatchSize is the size of the encoded nodes
+// written to the database batch before it is flushed.
+const nodesLoaderBatchSize = 16 * 1024 * 1024
+
+var (
+	ErrNodeNotExpected = errors.New("node is not expected")
+	ErrNodesMissing    = errors.New("nodes are missing")
+)
+
+// WalkNodes calls the given function with the encoding of each node of the trie
+// and of its child tries stored in the database. Nodes are visited depth first, so
+// each node is visited before its children and before the root of the child tries
+// it references. Only the nodes on the path being walked are kept in memory.
+func (t *LazyTrie) WalkNodes(fn func(encodedNode []byte) error) error {
+	if t.rootHash == EmptyHash {
+		return nil
+	}
+
+	const isChildTrie = false
+	return t.walkNodes(t.rootHash.ToBytes(), nil, isChildTrie, fn)
+}
+
+func (t *LazyTrie) walkNodes(hash, prefix []byte, isChildTrie bool,
+	fn func(encodedNode []byte) error) error {
+	encoding, err := t.db.Get(hash)
+	if err != nil {
+		return fmt.Errorf("cannot find node with hash 0x%x in database: %w", hash, err)
+	}
+
+	err = fn(encoding)
+	if err != nil {
+		return err
+	}
+
+	n, err := node.Decode(bytes.NewReader(encoding))
+	if err != nil {
+		return fmt.Errorf("cannot decode node with hash 0x%x: %w", hash, err)
+	}
+
+	return visitReferences(n, prefix, isChildTrie,
+		func(hash, prefix []byte, isChildTrie bool) error {
+			return t.walkNodes(hash, prefix, isChildTrie, fn)
+		})
+}
+
+// visitReferences calls the given function for each node referenced by hash by the
+// node given, which are its children not inlined in its encoding and the roots of the
+// child tries whose hashes are the values of the child storage keys of the main trie.
+func visitReferences(n Node, prefix []byte, isChildTrie bool,
+	fn func(hash, prefix []byte, isChildTrie bool) error) error {
+	fullKey := concatenateSlices(prefix, n.GetKey())
+
+	if !isChildTrie && n.GetValue() != nil && isChildStorageKey(fullKey) {
+		childRootHash := n.GetValue()
+		if len(childRootHash) != common.HashLength {
+			return fmt.Errorf("child trie root hash 0x%x at key 0x%x has an invalid length",
+				childRootHash, codec.NibblesToKeyLE(fullKey))
+		}
+
+		if !bytes.Equal(childRootHash, EmptyHash.ToBytes()) {
+			const childIsChildTrie = true
+			err := fn(childRootHash, nil, childIsChildTrie)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	branch, ok := n.(*node.Branch)
+	if !ok {
+		return nil
+	}
+
+	for i, child := range branch.Children {
+		if child == nil {
+			continue
+		}
+
+		childPrefix := concatenateSlices(fullKey, []byte{byte(i)})
+		var err error
+		if isHashReference(child) {
+			// branches with an encoding shorter than a hash are inlined in the
+			// encoding of their parent, but are stored with their encoding as
+			// key so they are referenced by their encoding as well.
+			err = fn(child.GetHash(), childPrefix, isChildTrie)
+		} else {
+			// inlined leaf
+			err = visitReferences(child, childPrefix, isChildTrie, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var childStorageKeyPrefixNibbles = codec.KeyLEToNibbles(ChildStorageKeyPrefix)
+
+// isChildStorageKey returns true if the full key given in nibbles
+// is the key of a child trie root hash in the main trie.
+func isChildStorageKey(fullKey []byte) bool {
+	return bytes.HasPrefix(fullKey, childStorageKeyPrefixNibbles)
+}
+
+// nodeReference is the position of a node referenced by hash in the trie.
+type nodeReference struct {
+	prefix      []byte
+	isChildTrie bool
+}
+
+// NodesLoader writes the encoded nodes of a trie and of its child tries to the
+// database, as visited by WalkNodes, and verifies each node is referenced by the
+// trie with the given root hash. Only the nodes referenced but not yet loaded are
+// kept in memory, so tries larger than the memory available can be loaded.
+type NodesLoader struct {
+	batch    chaindb.Batch
+	rootHash common.Hash
+	// expected holds the references to the nodes not yet loaded. Identical
+	// subtrees are walked once per reference, so a node can be referenced
+	// multiple times.
+	expected map[string][]nodeReference
+	pending  uint
+	loaded   uint
+}
+
+// NewNodesLoader returns a nodes loader writing to the given database
+// the nodes of the trie with the given root hash.
+func NewNodesLoader(db chaindb.Database, rootHash common.Hash) *NodesLoader {
+	loader := &NodesLoader{
+		batch:    db.NewBatch(),
+		rootHash: rootHash,
+		expected: make(map[string][]nodeReference),
+	}
+
+	if rootHash != EmptyHash {
+		loader.expect(rootHash.ToBytes(), nodeReference{})
+	}
+
+	return loader
+}
+
+// Load verifies the encoded node given is referenced by an already loaded
+// node, or is the root node, and writes it to the database.
+func (l *NodesLoader) Load(encodedNode []byte) error {
+	hash, err := common.Blake2bHash(encodedNode)
+	if err != nil {
+		return fmt.Errorf("cannot hash node: %w", err)
+	}
+
+	key := hash.ToBytes()
+	references := l.expected[string(key)]
+	if len(references) == 0 && len(encodedNode) < common.HashLength {
+		// inlined branch referenced by its encoding
+		key = encodedNode
+		references = l.expected[string(key)]
+	}
+
+	if len(references) == 0 {
+		return fmt.Errorf("%w: node with hash %s is not referenced by the trie with root hash %s",
+			ErrNodeNotExpected, hash, l.rootHash)
+	}
+
+	reference := references[0]
+	if len(references) == 1 {
+		delete(l.expected, string(key))
+	} else {
+		l.expected[string(key)] = references[1:]
+	}
+	l.pending--
+
+	n, err := node.Decode(bytes.NewReader(encodedNode))
+	if err != nil {
+		return fmt.Errorf("cannot decode node with hash %s: %w", hash, err)
+	}
+
+	err = visitReferences(n, reference.prefix, reference.isChildTrie,
+		func(hash, prefix []byte, isChildTrie bool) error {
+			l.expect(hash, nodeReference{
+				prefix:      prefix,
+				isChildTrie: isChildTrie,
+			})
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = l.batch.Put(key, encodedNode)
+	if err != nil {
+		return fmt.Errorf("cannot write node with hash %s: %w", hash, err)
+	}
+	l.loaded++
+
+	if l.batch.ValueSize() >= nodesLoaderBatchSize {
+		return l.flush()
+	}
+
+	return nil
+}
+
+func (l *NodesLoader) expect(key []byte, reference nodeReference) {
+	l.expected[string(key)] = append(l.expected[string(key)], reference)
+	l.pending++
+}
+
+func (l *NodesLoader) flush() error {
+	err := l.batch.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot flush nodes: %w", err)
+	}
+	l.batch.Reset()
+	return nil
+}
+
+// Done writes the remaining nodes to the database and verifies all
+// the nodes of the trie were loaded. It returns the number of loaded nodes.
+func (l *NodesLoader) Done() (loaded uint, err error) {
+	err = l.flush()
+	if err != nil {
+		return 0, err
+	}
+
+	if l.pending > 0 {
+		return 0, fmt.Errorf("%w: %d nodes of the trie with root hash %s are not loaded",
+			ErrNodesMissing, l.pending, l.rootHash)
+	}
+
+	return l.loaded, nil
+}
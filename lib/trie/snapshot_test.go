@@ -0,0 +1,117 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func walkEncodedNodes(t *testing.T, lazyTrie *LazyTrie) (encodedNodes [][]byte) {
+	t.Helper()
+
+	err := lazyTrie.WalkNodes(func(encodedNode []byte) error {
+		encodedNodes = append(encodedNodes, encodedNode)
+		return nil
+	})
+	require.NoError(t, err)
+	return encodedNodes
+}
+
+func Test_NodesLoader(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, trie, _ := newTestLazyTrie(t)
+	rootHash := lazyTrie.Hash()
+	encodedNodes := walkEncodedNodes(t, lazyTrie)
+
+	db := newTestDB(t)
+	loader := NewNodesLoader(db, rootHash)
+	for _, encodedNode := range encodedNodes {
+		err := loader.Load(encodedNode)
+		require.NoError(t, err)
+	}
+
+	loaded, err := loader.Done()
+	require.NoError(t, err)
+	assert.Equal(t, uint(len(encodedNodes)), loaded)
+
+	loadedTrie, err := NewLazyTrie(db, nil, rootHash).Trie()
+	require.NoError(t, err)
+	assert.Equal(t, trie.MustHash(), loadedTrie.MustHash())
+	assert.Equal(t, trie.Entries(), loadedTrie.Entries())
+
+	value, err := loadedTrie.GetFromChild([]byte("child"), []byte("child_key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("child_value"), value)
+}
+
+func Test_NodesLoader_duplicateSubtrees(t *testing.T) {
+	t.Parallel()
+
+	trie := NewEmptyTrie()
+	for _, keyToChild := range []string{"first", "second"} {
+		childTrie := NewEmptyTrie()
+		childTrie.Put([]byte("key"), make([]byte, 40))
+		childTrie.Put([]byte("key2"), make([]byte, 40))
+		err := trie.PutChild([]byte(keyToChild), childTrie)
+		require.NoError(t, err)
+	}
+
+	db := newTestDB(t)
+	err := trie.Store(db)
+	require.NoError(t, err)
+
+	rootHash := trie.MustHash()
+	encodedNodes := walkEncodedNodes(t, NewLazyTrie(db, nil, rootHash))
+
+	loader := NewNodesLoader(newTestDB(t), rootHash)
+	for _, encodedNode := range encodedNodes {
+		err := loader.Load(encodedNode)
+		require.NoError(t, err)
+	}
+
+	_, err = loader.Done()
+	require.NoError(t, err)
+}
+
+func Test_NodesLoader_errors(t *testing.T) {
+	t.Parallel()
+
+	lazyTrie, _, _ := newTestLazyTrie(t)
+	rootHash := lazyTrie.Hash()
+	encodedNodes := walkEncodedNodes(t, lazyTrie)
+
+	t.Run("node not expected", func(t *testing.T) {
+		t.Parallel()
+
+		loader := NewNodesLoader(newTestDB(t), rootHash)
+		err := loader.Load(encodedNodes[1])
+		assert.ErrorIs(t, err, ErrNodeNotExpected)
+	})
+
+	t.Run("root hash mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		loader := NewNodesLoader(newTestDB(t), common.Hash{1})
+		err := loader.Load(encodedNodes[0])
+		assert.ErrorIs(t, err, ErrNodeNotExpected)
+	})
+
+	t.Run("nodes missing", func(t *testing.T) {
+		t.Parallel()
+
+		loader := NewNodesLoader(newTestDB(t), rootHash)
+		for _, encodedNode := range encodedNodes[:len(encodedNodes)-1] {
+			err := loader.Load(encodedNode)
+			require.NoError(t, err)
+		}
+
+		_, err := loader.Done()
+		assert.ErrorIs(t, err, ErrNodesMissing)
+	})
+}
@@ -22,6 +22,13 @@ type Trie struct {
 	root        Node
 	childTries  map[common.Hash]*Trie
 	deletedKeys map[common.Hash]struct{}
+	// lazy resolves the nodes referenced by their hash only,
+	// for a trie loaded lazily from the database, and is nil otherwise.
+	lazy *LazyTrie
+	// resolveErr records the error of the first node which could not be
+	// resolved from the database, for a trie loaded lazily from the
+	// database, and is nil otherwise.
+	resolveErr *errorRecord
 }
 
 // NewEmptyTrie creates a trie with a nil root
@@ -45,6 +52,7 @@ func NewTrie(root Node) *Trie {
 // It does a snapshot of all child tries as well, and resets
 // the set of deleted hashes.
 func (t *Trie) Snapshot() (newTrie *Trie) {
+	resolveErr := t.resolveErr.copy()
 	childTries := make(map[common.Hash]*Trie, len(t.childTries))
 	rootCopySettings := node.DefaultCopySettings
 	rootCopySettings.CopyCached = true
@@ -53,6 +61,8 @@ func (t *Trie) Snapshot() (newTrie *Trie) {
 			generation:  childTrie.generation + 1,
 			root:        childTrie.root.Copy(rootCopySettings),
 			deletedKeys: make(map[common.Hash]struct{}),
+			lazy:        childTrie.lazy,
+			resolveErr:  resolveErr,
 		}
 	}
 
@@ -61,6 +71,8 @@ func (t *Trie) Snapshot() (newTrie *Trie) {
 		root:        t.root,
 		childTries:  childTries,
 		deletedKeys: make(map[common.Hash]struct{}),
+		lazy:        t.lazy,
+		resolveErr:  resolveErr,
 	}
 }
 
@@ -124,6 +136,8 @@ func (t *Trie) DeepCopy() (trieCopy *Trie) {
 
 	trieCopy = &Trie{
 		generation: t.generation,
+		lazy:       t.lazy,
+		resolveErr: t.resolveErr.copy(),
 	}
 
 	if t.deletedKeys != nil {
@@ -148,6 +162,52 @@ func (t *Trie) DeepCopy() (trieCopy *Trie) {
 	return trieCopy
 }
 
+// resolveError is the panic value aborting the current trie
+// operation when a node cannot be resolved from the database.
+type resolveError struct {
+	err error
+}
+
+// resolve returns the node the given node references if it is a hash
+// reference to a node not loaded yet from the database, and the given
+// node otherwise. If the node cannot be loaded, it aborts the current
+// operation, which must defer a call to recoverResolveError.
+func (t *Trie) resolve(n Node) (resolved Node) {
+	if t.lazy == nil || n == nil || !isHashReference(n) {
+		return n
+	}
+
+	resolved, err := t.lazy.loadNode(n.GetHash())
+	if err != nil {
+		panic(resolveError{err: fmt.Errorf("cannot resolve trie node: %w", err)})
+	}
+	return resolved
+}
+
+// recoverResolveError recovers from an operation aborted by resolve
+// and records its error, to be returned by Err.
+func (t *Trie) recoverResolveError() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	resolveErr, ok := r.(resolveError)
+	if !ok {
+		panic(r)
+	}
+
+	t.resolveErr.set(resolveErr.err)
+}
+
+// Err returns the error of the first node of the trie which could not be
+// resolved from the database, if any. The operation which failed to resolve
+// the node returned zero values and may have left the trie partially modified,
+// so the trie should no longer be used once Err returns an error.
+func (t *Trie) Err() error {
+	return t.resolveErr.get()
+}
+
 // RootNode returns a copy of the root node of the trie.
 func (t *Trie) RootNode() Node {
 	copySettings := node.DefaultCopySettings
@@ -194,11 +254,14 @@ func (t *Trie) Hash() (rootHash common.Hash, err error) {
 
 // Entries returns all the key-value pairs in the trie as a map of keys to values
 // where the keys are encoded in Little Endian.
-func (t *Trie) Entries() map[string][]byte {
-	return entries(t.root, nil, make(map[string][]byte))
+func (t *Trie) Entries() (entries map[string][]byte) {
+	defer t.recoverResolveError()
+
+	return t.entries(t.root, nil, make(map[string][]byte))
 }
 
-func entries(parent Node, prefix []byte, kv map[string][]byte) map[string][]byte {
+func (t *Trie) entries(parent Node, prefix []byte, kv map[string][]byte) map[string][]byte {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return kv
 	}
@@ -222,7 +285,7 @@ func entries(parent Node, prefix []byte, kv map[string][]byte) map[string][]byte
 
 	for i, child := range branch.Children {
 		childPrefix := concatenateSlices(prefix, branch.Key, intToByteSlice(i))
-		entries(child, childPrefix, kv)
+		t.entries(child, childPrefix, kv)
 	}
 
 	return kv
@@ -231,10 +294,12 @@ func entries(parent Node, prefix []byte, kv map[string][]byte) map[string][]byte
 // NextKey returns the next key in the trie in lexicographic order.
 // It returns nil if no next key is found.
 func (t *Trie) NextKey(keyLE []byte) (nextKeyLE []byte) {
+	defer t.recoverResolveError()
+
 	prefix := []byte(nil)
 	key := codec.KeyLEToNibbles(keyLE)
 
-	nextKey := findNextKey(t.root, prefix, key)
+	nextKey := t.findNextKey(t.root, prefix, key)
 	if nextKey == nil {
 		return nil
 	}
@@ -243,7 +308,8 @@ func (t *Trie) NextKey(keyLE []byte) (nextKeyLE []byte) {
 	return nextKeyLE
 }
 
-func findNextKey(parent Node, prefix, searchKey []byte) (nextKey []byte) {
+func (t *Trie) findNextKey(parent Node, prefix, searchKey []byte) (nextKey []byte) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil
 	}
@@ -255,7 +321,7 @@ func findNextKey(parent Node, prefix, searchKey []byte) (nextKey []byte) {
 
 	// Branch
 	parentBranch := parent.(*node.Branch)
-	return findNextKeyBranch(parentBranch, prefix, searchKey)
+	return t.findNextKeyBranch(parentBranch, prefix, searchKey)
 }
 
 func findNextKeyLeaf(leaf *node.Leaf, prefix, searchKey []byte) (nextKey []byte) {
@@ -269,12 +335,12 @@ func findNextKeyLeaf(leaf *node.Leaf, prefix, searchKey []byte) (nextKey []byte)
 	return fullKey
 }
 
-func findNextKeyBranch(parentBranch *node.Branch, prefix, searchKey []byte) (nextKey []byte) {
+func (t *Trie) findNextKeyBranch(parentBranch *node.Branch, prefix, searchKey []byte) (nextKey []byte) {
 	fullKey := concatenateSlices(prefix, parentBranch.Key)
 
 	if bytes.Equal(searchKey, fullKey) {
 		const startChildIndex = 0
-		return findNextKeyChild(parentBranch.Children, startChildIndex, fullKey, searchKey)
+		return t.findNextKeyChild(parentBranch.Children, startChildIndex, fullKey, searchKey)
 	}
 
 	if keyIsLexicographicallyBigger(searchKey, fullKey) {
@@ -282,7 +348,7 @@ func findNextKeyBranch(parentBranch *node.Branch, prefix, searchKey []byte) (nex
 			return nil
 		} else if len(searchKey) > len(fullKey) {
 			startChildIndex := searchKey[len(fullKey)]
-			return findNextKeyChild(parentBranch.Children,
+			return t.findNextKeyChild(parentBranch.Children,
 				startChildIndex, fullKey, searchKey)
 		}
 	}
@@ -292,7 +358,7 @@ func findNextKeyBranch(parentBranch *node.Branch, prefix, searchKey []byte) (nex
 		return fullKey
 	}
 	const startChildIndex = 0
-	return findNextKeyChild(parentBranch.Children, startChildIndex,
+	return t.findNextKeyChild(parentBranch.Children, startChildIndex,
 		fullKey, searchKey)
 }
 
@@ -305,7 +371,7 @@ func keyIsLexicographicallyBigger(key, key2 []byte) (bigger bool) {
 
 // findNextKeyChild searches for a next key in the children
 // given and returns a next key or nil if no next key is found.
-func findNextKeyChild(children [16]node.Node, startIndex byte,
+func (t *Trie) findNextKeyChild(children [16]node.Node, startIndex byte,
 	fullKey, key []byte) (nextKey []byte) {
 	for i := startIndex; i < node.ChildrenCapacity; i++ {
 		child := children[i]
@@ -314,7 +380,7 @@ func findNextKeyChild(children [16]node.Node, startIndex byte,
 		}
 
 		childFullKey := concatenateSlices(fullKey, []byte{i})
-		next := findNextKey(child, childFullKey, key)
+		next := t.findNextKey(child, childFullKey, key)
 		if len(next) > 0 {
 			return next
 		}
@@ -326,6 +392,8 @@ func findNextKeyChild(children [16]node.Node, startIndex byte,
 // Put inserts a value into the trie at the
 // key specified in little Endian format.
 func (t *Trie) Put(keyLE, value []byte) {
+	defer t.recoverResolveError()
+
 	nibblesKey := codec.KeyLEToNibbles(keyLE)
 	t.put(nibblesKey, value)
 }
@@ -346,6 +414,8 @@ func (t *Trie) insert(parent Node, key, value []byte) (newParent Node) {
 		}
 	}
 
+	parent = t.resolve(parent)
+
 	// TODO ensure all values have dirty set to true
 
 	switch parent.Type() {
@@ -502,6 +572,8 @@ func (t *Trie) LoadFromMap(data map[string]string) (err error) {
 // format from nodes in the trie that have the given little
 // Endian formatted prefix in their key.
 func (t *Trie) GetKeysWithPrefix(prefixLE []byte) (keysLE [][]byte) {
+	defer t.recoverResolveError()
+
 	var prefixNibbles []byte
 	if len(prefixLE) > 0 {
 		prefixNibbles = codec.KeyLEToNibbles(prefixLE)
@@ -510,15 +582,16 @@ func (t *Trie) GetKeysWithPrefix(prefixLE []byte) (keysLE [][]byte) {
 
 	prefix := []byte{}
 	key := prefixNibbles
-	return getKeysWithPrefix(t.root, prefix, key, keysLE)
+	return t.getKeysWithPrefix(t.root, prefix, key, keysLE)
 }
 
 // getKeysWithPrefix returns all keys in little Endian format that have the
 // prefix given. The prefix and key byte slices are in nibbles format.
 // TODO pass in map of keysLE if order is not needed.
 // TODO do all processing on nibbles keys and then convert to LE.
-func getKeysWithPrefix(parent Node, prefix, key []byte,
+func (t *Trie) getKeysWithPrefix(parent Node, prefix, key []byte,
 	keysLE [][]byte) (newKeysLE [][]byte) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return keysLE
 	}
@@ -529,7 +602,7 @@ func getKeysWithPrefix(parent Node, prefix, key []byte,
 	}
 
 	parentBranch := parent.(*node.Branch)
-	return getKeysWithPrefixFromBranch(parentBranch, prefix, key, keysLE)
+	return t.getKeysWithPrefixFromBranch(parentBranch, prefix, key, keysLE)
 }
 
 func getKeysWithPrefixFromLeaf(parent *node.Leaf, prefix, key []byte,
@@ -541,10 +614,10 @@ func getKeysWithPrefixFromLeaf(parent *node.Leaf, prefix, key []byte,
 	return keysLE
 }
 
-func getKeysWithPrefixFromBranch(parent *node.Branch, prefix, key []byte,
+func (t *Trie) getKeysWithPrefixFromBranch(parent *node.Branch, prefix, key []byte,
 	keysLE [][]byte) (newKeysLE [][]byte) {
 	if len(key) == 0 || bytes.HasPrefix(parent.Key, key) {
-		return addAllKeys(parent, prefix, keysLE)
+		return t.addAllKeys(parent, prefix, keysLE)
 	}
 
 	noPossiblePrefixedKeys :=
@@ -559,14 +632,15 @@ func getKeysWithPrefixFromBranch(parent *node.Branch, prefix, key []byte,
 	child := parent.Children[childIndex]
 	childPrefix := makeChildPrefix(prefix, parent.Key, int(childIndex))
 	childKey := key[1:]
-	return getKeysWithPrefix(child, childPrefix, childKey, keysLE)
+	return t.getKeysWithPrefix(child, childPrefix, childKey, keysLE)
 }
 
 // addAllKeys appends all keys of descendant nodes of the parent node
 // to the slice of keys given and returns this slice.
 // It uses the prefix in nibbles format to determine the full key.
 // The slice of keys has its keys formatted in little Endian.
-func addAllKeys(parent Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte) {
+func (t *Trie) addAllKeys(parent Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return keysLE
 	}
@@ -586,7 +660,7 @@ func addAllKeys(parent Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte
 
 	for i, child := range branchParent.Children {
 		childPrefix := makeChildPrefix(prefix, branchParent.Key, i)
-		keysLE = addAllKeys(child, childPrefix, keysLE)
+		keysLE = t.addAllKeys(child, childPrefix, keysLE)
 	}
 
 	return keysLE
@@ -608,11 +682,14 @@ func makeChildPrefix(branchPrefix, branchKey []byte,
 // which matches its key with the key given.
 // Note the key argument is given in little Endian format.
 func (t *Trie) Get(keyLE []byte) (value []byte) {
+	defer t.recoverResolveError()
+
 	keyNibbles := codec.KeyLEToNibbles(keyLE)
-	return retrieve(t.root, keyNibbles)
+	return t.retrieve(t.root, keyNibbles)
 }
 
-func retrieve(parent Node, key []byte) (value []byte) {
+func (t *Trie) retrieve(parent Node, key []byte) (value []byte) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil
 	}
@@ -624,7 +701,7 @@ func retrieve(parent Node, key []byte) (value []byte) {
 
 	// Branches
 	branch := parent.(*node.Branch)
-	return retrieveFromBranch(branch, key)
+	return t.retrieveFromBranch(branch, key)
 }
 
 func retrieveFromLeaf(leaf *node.Leaf, key []byte) (value []byte) {
@@ -634,7 +711,7 @@ func retrieveFromLeaf(leaf *node.Leaf, key []byte) (value []byte) {
 	return nil
 }
 
-func retrieveFromBranch(branch *node.Branch, key []byte) (value []byte) {
+func (t *Trie) retrieveFromBranch(branch *node.Branch, key []byte) (value []byte) {
 	if len(key) == 0 || bytes.Equal(branch.Key, key) {
 		return branch.Value
 	}
@@ -647,7 +724,7 @@ func retrieveFromBranch(branch *node.Branch, key []byte) (value []byte) {
 	childIndex := key[commonPrefixLength]
 	childKey := key[commonPrefixLength+1:]
 	child := branch.Children[childIndex]
-	return retrieve(child, childKey)
+	return t.retrieve(child, childKey)
 }
 
 // ClearPrefixLimit deletes the keys having the prefix given in little
@@ -655,6 +732,8 @@ func retrieveFromBranch(branch *node.Branch, key []byte) (value []byte) {
 // keys and a boolean indicating if all keys with the prefix were deleted
 // within the limit.
 func (t *Trie) ClearPrefixLimit(prefixLE []byte, limit uint32) (deleted uint32, allDeleted bool) {
+	defer t.recoverResolveError()
+
 	if limit == 0 {
 		return 0, false
 	}
@@ -671,6 +750,7 @@ func (t *Trie) ClearPrefixLimit(prefixLE []byte, limit uint32) (deleted uint32,
 // allDeleted boolean indicating if there is no key left with the prefix.
 func (t *Trie) clearPrefixLimit(parent Node, prefix []byte, limit uint32) (
 	newParent Node, valuesDeleted uint32, allDeleted bool) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil, 0, true
 	}
@@ -728,7 +808,7 @@ func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, prefix []byte, limit
 	copySettings := node.DefaultCopySettings
 	branch = t.prepBranchForMutation(branch, copySettings)
 	branch.Children[childIndex] = child
-	newParent = handleDeletion(branch, prefix)
+	newParent = t.handleDeletion(branch, prefix)
 	return newParent, valuesDeleted, allDeleted
 }
 
@@ -756,7 +836,7 @@ func (t *Trie) clearPrefixLimitChild(branch *node.Branch, prefix []byte, limit u
 	branch = t.prepBranchForMutation(branch, copySettings)
 	branch.Children[childIndex] = child
 
-	newParent = handleDeletion(branch, prefix)
+	newParent = t.handleDeletion(branch, prefix)
 	allDeleted = branch.Children[childIndex] == nil
 	return newParent, valuesDeleted, allDeleted
 }
@@ -767,6 +847,7 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 		return parent, 0
 	}
 
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil, 0
 	}
@@ -797,7 +878,7 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 		limit -= newDeleted
 		valuesDeleted += newDeleted
 
-		newParent = handleDeletion(branch, fullKey)
+		newParent = t.handleDeletion(branch, fullKey)
 		if nilChildren == node.ChildrenCapacity &&
 			branch.Value == nil {
 			return nil, valuesDeleted
@@ -818,6 +899,8 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 // ClearPrefix deletes all nodes in the trie for which the key contains the
 // prefix given in little Endian format.
 func (t *Trie) ClearPrefix(prefixLE []byte) {
+	defer t.recoverResolveError()
+
 	if len(prefixLE) == 0 {
 		t.root = nil
 		return
@@ -831,6 +914,7 @@ func (t *Trie) ClearPrefix(prefixLE []byte) {
 
 func (t *Trie) clearPrefix(parent Node, prefix []byte) (
 	newParent Node, updated bool) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil, false
 	}
@@ -858,7 +942,7 @@ func (t *Trie) clearPrefix(parent Node, prefix []byte) (
 		copySettings := node.DefaultCopySettings
 		branch = t.prepBranchForMutation(branch, copySettings)
 		branch.Children[childIndex] = nil
-		newParent = handleDeletion(branch, prefix)
+		newParent = t.handleDeletion(branch, prefix)
 		return newParent, true
 	}
 
@@ -880,7 +964,7 @@ func (t *Trie) clearPrefix(parent Node, prefix []byte) (
 	copySettings := node.DefaultCopySettings
 	branch = t.prepBranchForMutation(branch, copySettings)
 	branch.Children[childIndex] = child
-	newParent = handleDeletion(branch, prefix)
+	newParent = t.handleDeletion(branch, prefix)
 	return newParent, true
 }
 
@@ -888,11 +972,14 @@ func (t *Trie) clearPrefix(parent Node, prefix []byte) (
 // matching the key given in little Endian format.
 // If no node is found at this key, nothing is deleted.
 func (t *Trie) Delete(keyLE []byte) {
+	defer t.recoverResolveError()
+
 	key := codec.KeyLEToNibbles(keyLE)
 	t.root, _ = t.delete(t.root, key)
 }
 
 func (t *Trie) delete(parent Node, key []byte) (newParent Node, deleted bool) {
+	parent = t.resolve(parent)
 	if parent == nil {
 		return nil, false
 	}
@@ -923,7 +1010,7 @@ func (t *Trie) deleteBranch(branch *node.Branch, key []byte) (newParent Node, de
 		// we need to set to nil if the branch has the same generation
 		// as the current trie.
 		branch.Value = nil
-		return handleDeletion(branch, key), true
+		return t.handleDeletion(branch, key), true
 	}
 
 	commonPrefixLength := lenCommonPrefix(branch.Key, key)
@@ -939,7 +1026,7 @@ func (t *Trie) deleteBranch(branch *node.Branch, key []byte) (newParent Node, de
 	copySettings := node.DefaultCopySettings
 	branch = t.prepBranchForMutation(branch, copySettings)
 	branch.Children[childIndex] = newChild
-	newParent = handleDeletion(branch, key)
+	newParent = t.handleDeletion(branch, key)
 	return newParent, true
 }
 
@@ -947,7 +1034,7 @@ func (t *Trie) deleteBranch(branch *node.Branch, key []byte) (newParent Node, de
 // the eventual mutation of the branch depending on its children.
 // If the branch has no value and a single child, it will be combined with this child.
 // If the branch has a value and no child, it will be changed into a leaf.
-func handleDeletion(branch *node.Branch, key []byte) (newNode Node) {
+func (t *Trie) handleDeletion(branch *node.Branch, key []byte) (newNode Node) {
 	// TODO try to remove key argument just use branch.Key instead?
 	childrenCount := 0
 	firstChildIndex := -1
@@ -974,7 +1061,7 @@ func handleDeletion(branch *node.Branch, key []byte) (newNode Node) {
 		}
 	case childrenCount == 1 && branch.Value == nil:
 		childIndex := firstChildIndex
-		child := branch.Children[firstChildIndex]
+		child := t.resolve(branch.Children[firstChildIndex])
 
 		if child.Type() == node.LeafType {
 			childLeafKey := child.GetKey()
@@ -949,7 +949,7 @@ func Test_nextKey(t *testing.T) {
 
 			originalTrie := testCase.trie.DeepCopy()
 
-			nextKey := findNextKey(testCase.trie.root, nil, testCase.key)
+			nextKey := testCase.trie.findNextKey(testCase.trie.root, nil, testCase.key)
 
 			assert.Equal(t, testCase.nextKey, nextKey)
 			assert.Equal(t, *originalTrie, testCase.trie) // ensure no mutation
@@ -1838,7 +1838,8 @@ func Test_getKeysWithPrefix(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			keys := getKeysWithPrefix(testCase.parent,
+			trie := NewEmptyTrie()
+			keys := trie.getKeysWithPrefix(testCase.parent,
 				testCase.prefix, testCase.key, testCase.keys)
 
 			assert.Equal(t, testCase.expectedKeys, keys)
@@ -1905,7 +1906,8 @@ func Test_addAllKeys(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			keys := addAllKeys(testCase.parent,
+			trie := NewEmptyTrie()
+			keys := trie.addAllKeys(testCase.parent,
 				testCase.prefix, testCase.keys)
 
 			assert.Equal(t, testCase.expectedKeys, keys)
@@ -2051,7 +2053,8 @@ func Test_retrieve(t *testing.T) {
 				expectedParent = testCase.parent.Copy(copySettings)
 			}
 
-			value := retrieve(testCase.parent, testCase.key)
+			trie := NewEmptyTrie()
+			value := trie.retrieve(testCase.parent, testCase.key)
 
 			assert.Equal(t, testCase.value, value)
 			assert.Equal(t, expectedParent, testCase.parent)
@@ -3397,7 +3400,8 @@ func Test_handleDeletion(t *testing.T) {
 				copy(expectedKey, testCase.deletedKey)
 			}
 
-			newNode := handleDeletion(testCase.branch, testCase.deletedKey)
+			trie := NewEmptyTrie()
+			newNode := trie.handleDeletion(testCase.branch, testCase.deletedKey)
 
 			assert.Equal(t, testCase.newNode, newNode)
 			assert.Equal(t, expectedKey, testCase.deletedKey)